@@ -0,0 +1,480 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the GIF decoder
+	_ "image/jpeg" // register the JPEG decoder
+	"image/png"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"merchant/blob"
+	"merchant/model"
+	"merchant/repository"
+	"merchant/util/imageutil"
+)
+
+// multipartOverhead is allowed on top of an asset's size limit for the
+// multipart boundaries and headers of the request.
+const multipartOverhead = 64 << 10
+
+// assetKeyRegex matches the keys uploaded images are stored under, capturing
+// the id of the merchant.
+var assetKeyRegex = regexp.MustCompile(`^merchants/([0-9a-f-]{36})/(?:logo|banner)-[0-9a-f-]{36}(?:\.png|\.jpg|\.gif|-thumb\.png)$`)
+
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+}
+
+type merchantAsset struct {
+	name                    string
+	maxBytes                int64
+	minWidth, minHeight     int
+	maxWidth, maxHeight     int
+	thumbWidth, thumbHeight int
+
+	keys    func(m *model.Merchant) (key, thumbnailKey string)
+	setKeys func(m *model.Merchant, key, thumbnailKey string)
+	update  func(db repository.Repository, id string, version int64, key, thumbnailKey string) error
+}
+
+var (
+	merchantLogo = &merchantAsset{
+		name:        "logo",
+		maxBytes:    2 << 20,
+		minWidth:    64,
+		minHeight:   64,
+		maxWidth:    4096,
+		maxHeight:   4096,
+		thumbWidth:  128,
+		thumbHeight: 128,
+		keys: func(m *model.Merchant) (string, string) {
+			return m.LogoKey, m.LogoThumbnailKey
+		},
+		setKeys: func(m *model.Merchant, key, thumbnailKey string) {
+			m.LogoKey, m.LogoThumbnailKey = key, thumbnailKey
+		},
+		update: repository.Repository.UpdateMerchantLogoById,
+	}
+
+	merchantBanner = &merchantAsset{
+		name:        "banner",
+		maxBytes:    5 << 20,
+		minWidth:    600,
+		minHeight:   150,
+		maxWidth:    6000,
+		maxHeight:   3000,
+		thumbWidth:  600,
+		thumbHeight: 200,
+		keys: func(m *model.Merchant) (string, string) {
+			return m.BannerKey, m.BannerThumbnailKey
+		},
+		setKeys: func(m *model.Merchant, key, thumbnailKey string) {
+			m.BannerKey, m.BannerThumbnailKey = key, thumbnailKey
+		},
+		update: repository.Repository.UpdateMerchantBannerById,
+	}
+)
+
+// UploadMerchantLogo godoc
+// @Summary Upload merchant logo
+// @Description upload a PNG, JPEG or GIF logo of up to 2MB and between 64x64 and 4096x4096 pixels
+// @Accept  mpfd
+// @Produce  json
+// @Param id path string true "Merchant ID"
+// @Param If-Match header string false "ETag of the merchant as read"
+// @Param file formData file true "Logo image"
+// @Success 200 {object} model.MerchantDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404,409,412,413,415,428 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/logo [put]
+func (srv *Server) HandleUploadMerchantLogo(w http.ResponseWriter, r *http.Request) {
+	srv.handleUploadMerchantAsset(w, r, merchantLogo)
+}
+
+// DeleteMerchantLogo godoc
+// @Summary Delete merchant logo
+// @Description remove the logo of a merchant
+// @Param id path string true "Merchant ID"
+// @Param If-Match header string false "ETag of the merchant as read"
+// @Success 200 {string} string	"ok"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404,409,412,428 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/logo [delete]
+func (srv *Server) HandleDeleteMerchantLogo(w http.ResponseWriter, r *http.Request) {
+	srv.handleDeleteMerchantAsset(w, r, merchantLogo)
+}
+
+// UploadMerchantBanner godoc
+// @Summary Upload merchant banner
+// @Description upload a PNG, JPEG or GIF banner of up to 5MB and between 600x150 and 6000x3000 pixels
+// @Accept  mpfd
+// @Produce  json
+// @Param id path string true "Merchant ID"
+// @Param If-Match header string false "ETag of the merchant as read"
+// @Param file formData file true "Banner image"
+// @Success 200 {object} model.MerchantDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404,409,412,413,415,428 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/banner [put]
+func (srv *Server) HandleUploadMerchantBanner(w http.ResponseWriter, r *http.Request) {
+	srv.handleUploadMerchantAsset(w, r, merchantBanner)
+}
+
+// DeleteMerchantBanner godoc
+// @Summary Delete merchant banner
+// @Description remove the banner of a merchant
+// @Param id path string true "Merchant ID"
+// @Param If-Match header string false "ETag of the merchant as read"
+// @Success 200 {string} string	"ok"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404,409,412,428 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/banner [delete]
+func (srv *Server) HandleDeleteMerchantBanner(w http.ResponseWriter, r *http.Request) {
+	srv.handleDeleteMerchantAsset(w, r, merchantBanner)
+}
+
+// ReadAsset godoc
+// @Summary Read asset
+// @Description download the logo or banner of a merchant, or their thumbnails. Only the images merchants currently use are served.
+// @Param key path string true "Asset key"
+// @Success 200 {file} file
+// @Failure 404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /assets/{key} [get]
+func (srv *Server) HandleReadAsset(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "*")
+
+	// The store holds private documents too, so only the keys a merchant
+	// points to as its images are served.
+	match := assetKeyRegex.FindStringSubmatch(key)
+	if match == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	merchant, err := srv.DB.ReadMerchantById(match[1])
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+	if !isMerchantAsset(merchant, key) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	rc, err := srv.Blob.Get(r.Context(), key)
+	if err != nil {
+		if err == blob.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrBlobStorageFailure)
+		return
+	}
+	defer rc.Close()
+
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	if _, err := io.Copy(w, rc); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+func (srv *Server) handleUploadMerchantAsset(w http.ResponseWriter, r *http.Request, asset *merchantAsset) {
+	merchant, ok := srv.readOwnMerchant(w, r)
+	if !ok {
+		return
+	}
+
+	if !srv.checkIfMatch(w, r, merchant.Version) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, asset.maxBytes+multipartOverhead)
+	data, _, ok := srv.readMultipartFile(w, r, "file", asset.maxBytes)
+	if !ok {
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrUnsupportedMediaType)
+		return
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrImageDecodingFailure)
+		return
+	}
+
+	if cfg.Width < asset.minWidth || cfg.Height < asset.minHeight ||
+		cfg.Width > asset.maxWidth || cfg.Height > asset.maxHeight {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%s must be between %dx%d and %dx%d pixels"}`,
+			asset.name, asset.minWidth, asset.minHeight, asset.maxWidth, asset.maxHeight)
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrImageDecodingFailure)
+		return
+	}
+
+	thumbnail := &bytes.Buffer{}
+	if err := png.Encode(thumbnail, imageutil.Thumbnail(img, asset.thumbWidth, asset.thumbHeight)); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrImageDecodingFailure)
+		return
+	}
+
+	prefix := fmt.Sprintf("merchants/%s/%s-%s", merchant.ID, asset.name, uuid.New().String())
+	key, thumbnailKey := prefix+ext, prefix+"-thumb.png"
+
+	if err := srv.Blob.Put(r.Context(), key, bytes.NewReader(data)); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrBlobStorageFailure)
+		return
+	}
+	if err := srv.Blob.Put(r.Context(), thumbnailKey, thumbnail); err != nil {
+		srv.Logger.Warn(err.Error())
+		srv.deleteBlobs(r, key)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrBlobStorageFailure)
+		return
+	}
+
+	oldKey, oldThumbnailKey := asset.keys(merchant)
+	asset.setKeys(merchant, key, thumbnailKey)
+
+	err = srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := asset.update(tx, merchant.ID, merchant.Version, key, thumbnailKey); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantUpdated, merchant))
+	})
+	if err != nil {
+		srv.deleteBlobs(r, key, thumbnailKey)
+
+		if err == repository.ErrConflict {
+			writeVersionConflict(w, r)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	srv.deleteBlobs(r, oldKey, oldThumbnailKey)
+
+	merchant, err = srv.DB.ReadMerchantById(merchant.ID)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	dto := merchant.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+func (srv *Server) handleDeleteMerchantAsset(w http.ResponseWriter, r *http.Request, asset *merchantAsset) {
+	merchant, ok := srv.readOwnMerchant(w, r)
+	if !ok {
+		return
+	}
+
+	key, thumbnailKey := asset.keys(merchant)
+	if key == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !srv.checkIfMatch(w, r, merchant.Version) {
+		return
+	}
+
+	asset.setKeys(merchant, "", "")
+
+	err := srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := asset.update(tx, merchant.ID, merchant.Version, "", ""); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantUpdated, merchant))
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			writeVersionConflict(w, r)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	srv.deleteBlobs(r, key, thumbnailKey)
+}
+
+// isMerchantAsset reports whether the key is one of the images of the
+// merchant.
+func isMerchantAsset(m *model.Merchant, key string) bool {
+	for _, asset := range []*merchantAsset{merchantLogo, merchantBanner} {
+		k, thumbnailKey := asset.keys(m)
+		if key == k || key == thumbnailKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readOwnMerchant reads the merchant in the URL, which must be the
+// authenticated merchant.
+func (srv *Server) readOwnMerchant(w http.ResponseWriter, r *http.Request) (*model.Merchant, bool) {
+	id := chi.URLParam(r, "id")
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if id != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrAccessForbidden)
+		return nil, false
+	}
+
+	merchant, err := srv.DB.ReadMerchantById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	return merchant, true
+}
+
+// readMultipartFile reads the content and file name of the named file part
+// of a multipart request, writing an error response when it is missing or
+// larger than maxBytes.
+func (srv *Server) readMultipartFile(w http.ResponseWriter, r *http.Request, name string, maxBytes int64) ([]byte, string, bool) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return nil, "", false
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%s is a required field"}`, name)
+			return nil, "", false
+		}
+		if err != nil {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrFileTooLarge)
+			return nil, "", false
+		}
+
+		if part.FormName() != name {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(io.LimitReader(part, maxBytes+1))
+		if err != nil {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrFileTooLarge)
+			return nil, "", false
+		}
+
+		if int64(len(data)) > maxBytes {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrFileTooLarge)
+			return nil, "", false
+		}
+
+		return data, part.FileName(), true
+	}
+}
+
+// deleteBlobs removes blobs which are no longer referenced. Failures only
+// leave orphaned objects behind, so they are logged rather than reported.
+func (srv *Server) deleteBlobs(r *http.Request, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		if err := srv.Blob.Delete(r.Context(), key); err != nil {
+			srv.Logger.Warn(err.Error())
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/blob/fsblob"
+	"merchant/model"
+	"merchant/repository"
+)
+
+func newUploadRequest(s *Suite, merchantId string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, err := mw.CreateFormFile("file", "logo.png")
+	require.NoError(s.T(), err)
+	_, err = fw.Write(content)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), mw.Close())
+
+	r := httptest.NewRequest(http.MethodPut, "/merchants/"+merchantId+"/logo", body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", merchantId)
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, model.CtxKeyXUser, model.CtxUser{UserId: uuid.MustParse(merchantId)})
+
+	return r.WithContext(ctx)
+}
+
+func (s *Suite) Test_handler_Upload_Merchant_Logo() {
+	dir, err := ioutil.TempDir("", "assets")
+	require.NoError(s.T(), err)
+	defer os.RemoveAll(dir)
+
+	s.server.Blob, err = fsblob.New(dir)
+	require.NoError(s.T(), err)
+
+	id := uuid.New().String()
+	merchant := &model.Merchant{Model: model.Model{ID: id, Version: 2}}
+
+	img := &bytes.Buffer{}
+	require.NoError(s.T(), png.Encode(img, image.NewRGBA(image.Rect(0, 0, 256, 128))))
+
+	s.db.EXPECT().ReadMerchantById(id).Return(merchant, nil).Times(2)
+	s.expectTransaction()
+	s.db.EXPECT().UpdateMerchantLogoById(id, int64(2), gomock.Any(), gomock.Any()).Return(nil)
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).DoAndReturn(func(e *model.OutboxEvent) error {
+		require.Equal(s.T(), model.EventMerchantUpdated, e.Type)
+		require.Contains(s.T(), e.Payload, "/logo-")
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUploadMerchantLogo(rr, newUploadRequest(s, id, img.Bytes()))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+}
+
+func (s *Suite) Test_handler_Delete_Merchant_Logo_Concurrent_Update() {
+	id := uuid.New().String()
+	merchant := &model.Merchant{Model: model.Model{ID: id, Version: 2}, LogoKey: "merchants/" + id + "/logo-a.png"}
+
+	s.db.EXPECT().ReadMerchantById(id).Return(merchant, nil)
+	s.expectTransaction()
+	s.db.EXPECT().UpdateMerchantLogoById(id, int64(2), "", "").Return(repository.ErrConflict)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDeleteMerchantLogo(rr, newMerchantRequest(http.MethodDelete, "/merchants/"+id+"/logo", "", id, id))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Upload_Merchant_Logo_Unsupported_Type() {
+	id := uuid.New().String()
+	merchant := &model.Merchant{Model: model.Model{ID: id}}
+
+	s.db.EXPECT().ReadMerchantById(id).Return(merchant, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUploadMerchantLogo(rr, newUploadRequest(s, id, []byte("not an image")))
+
+	require.Equal(s.T(), http.StatusUnsupportedMediaType, rr.Code)
+}
+
+func (s *Suite) Test_handler_Upload_Merchant_Logo_Of_Another_Merchant() {
+	r := newUploadRequest(s, uuid.New().String(), []byte("not an image"))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", uuid.New().String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUploadMerchantLogo(rr, r)
+
+	require.Equal(s.T(), http.StatusForbidden, rr.Code)
+}
+
+func newAssetRequest(key string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/assets/"+key, nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("*", key)
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func (s *Suite) Test_handler_Read_Asset() {
+	dir, err := ioutil.TempDir("", "assets")
+	require.NoError(s.T(), err)
+	defer os.RemoveAll(dir)
+
+	s.server.Blob, err = fsblob.New(dir)
+	require.NoError(s.T(), err)
+
+	id := uuid.New().String()
+	key := "merchants/" + id + "/logo-" + uuid.New().String() + ".png"
+	require.NoError(s.T(), s.server.Blob.Put(context.Background(), key, bytes.NewReader([]byte("logo"))))
+
+	s.db.EXPECT().ReadMerchantById(id).Return(&model.Merchant{Model: model.Model{ID: id}, LogoKey: key}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadAsset(rr, newAssetRequest(key))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), "logo", rr.Body.String())
+}
+
+func (s *Suite) Test_handler_Read_Asset_No_Longer_Used() {
+	id := uuid.New().String()
+	key := "merchants/" + id + "/banner-" + uuid.New().String() + "-thumb.png"
+
+	s.db.EXPECT().ReadMerchantById(id).Return(&model.Merchant{Model: model.Model{ID: id}}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadAsset(rr, newAssetRequest(key))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Read_Asset_Of_Private_Document() {
+	rr := httptest.NewRecorder()
+	s.server.HandleReadAsset(rr, newAssetRequest("merchants/"+uuid.New().String()+"/invoices/INV-000001.pdf"))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
@@ -11,6 +11,7 @@ import (
 	"gorm.io/gorm"
 
 	"merchant/model"
+	"merchant/repository"
 )
 
 var (
@@ -26,28 +27,43 @@ var (
 // @Router /auth/register [POST]
 // @Accept json
 // @Param body body model.RegistrationForm true "Register merchant"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
 //
 // @Success 201 {string} string
 // @Failure 422 {object} validator.ErrResponse
 // @Failure 409 {object} model.SrvError
 // @Failure 500 {object} model.SrvError
 func (srv *Server) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	merchant, ok := srv.createMerchant(w, r, nil)
+	if !ok {
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New User created: %s", merchant.Email))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// createMerchant registers the merchant of the registration form in the
+// request body, under the parent if any, along with its default settings. It
+// writes the error response and returns false when the merchant can not be
+// created.
+func (srv *Server) createMerchant(w http.ResponseWriter, r *http.Request, parentId *string) (*model.Merchant, bool) {
 	form := &model.RegistrationForm{}
 	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
 		srv.Logger.Warn(err.Error())
 
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
-		return
+		return nil, false
 	}
 	if srv.handleValidationErrors(w, form) {
-		return
+		return nil, false
 	}
 
 	if form.Password != form.ConfirmPassword {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		fmt.Fprintf(w, `{"error": "%v"}`, valErrPasswordMatchFailure)
-		return
+		return nil, false
 	}
 
 	merchant, err := srv.DB.ReadMerchantByEmail(form.Email)
@@ -56,13 +72,13 @@ func (srv *Server) HandleRegister(w http.ResponseWriter, r *http.Request) {
 
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		fmt.Fprintf(w, `{"error": "%v"}`, srvErrHashGenerationFailure)
-		return
+		return nil, false
 	}
 
 	if merchant != nil {
 		w.WriteHeader(http.StatusConflict)
 		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDuplicateInsertion)
-		return
+		return nil, false
 	}
 
 	pass, err := bcrypt.GenerateFromPassword([]byte(form.Password), bcrypt.DefaultCost)
@@ -71,22 +87,33 @@ func (srv *Server) HandleRegister(w http.ResponseWriter, r *http.Request) {
 
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		fmt.Fprintf(w, `{"error": "%v"}`, srvErrHashGenerationFailure)
-		return
+		return nil, false
 	}
 
 	merchant = form.ToMerchantModel()
 	merchant.Password = string(pass)
+	merchant.ParentID = parentId
+
+	err = srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.CreateMerchant(merchant); err != nil {
+			return err
+		}
+
+		if err := tx.SaveMerchantSettings(model.DefaultMerchantSettings(merchant.ID)); err != nil {
+			return err
+		}
 
-	if err = srv.DB.CreateMerchant(merchant); err != nil {
+		return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantRegistered, merchant))
+	})
+	if err != nil {
 		srv.Logger.Warn(err.Error())
 
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
-		return
+		return nil, false
 	}
 
-	srv.Logger.Info(fmt.Sprintf("New User created: %s", merchant.Email))
-	w.WriteHeader(http.StatusCreated)
+	return merchant, true
 }
 
 // LoginMerchant godoc
@@ -149,7 +176,7 @@ func (srv *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
 
 	tk := &model.Token{
 		UserId: user.ID,
-		Email:  user.Email,
+		Email:  user.Email.String(),
 		StandardClaims: &jwt.StandardClaims{
 			ExpiresAt: expiresAt,
 		},
@@ -4,14 +4,165 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"merchant/model"
 	"merchant/util/validator"
 )
 
 var (
 	valErrPasswordMatchFailure = "Passwords didn't match. Try again."
+	valErrHolidayHoursMissing  = "Holiday exceptions must be closed or have opening and closing times."
+	valErrDuplicateSku         = "Variants must have unique SKUs."
+	valErrInvalidPagination    = "Limit must be between 1 and 100 and offset must not be negative."
+	valErrInvalidDate          = "Dates must be formatted as 2006-01-02 or RFC 3339."
+	valErrUnknownVariant       = "Order lines must reference active variants of active products."
+	valErrCurrencyMismatch     = "Order lines must share one currency."
+	valErrDiscountTooLarge     = "Discount must not exceed the order subtotal."
+	valErrNegativeDiscount     = "Discount must not be negative."
+	valErrOrderTooLarge        = "Order subtotal must not exceed 100000000000000 minor units."
+	valErrCaptureTooLarge      = "Capture amount must not exceed the authorized amount."
+	valErrWebhookUrl           = "Webhook URLs must be https URLs of public hosts."
+	valErrCouponWindow         = "Coupons must end after they start."
+	valErrUnknownCoupon        = "Coupon code is not valid."
+	valErrIdempotencyKeyLength = "Idempotency keys must be at most 255 characters."
+	valErrIdempotencyKeyReused = "Idempotency key was used for another request."
+	valErrInvalidDryRun        = "Dry run must be true or false."
+	valErrImportColumns        = "Import files must have givenName, familyName and email columns."
+	valErrImportTooManyRows    = "Import files must list at most 10000 team members."
+	valErrImportUnreadable     = "Import files must be CSV or XLSX files."
+	valErrUnknownExportColumn  = "Export columns must be fields of the exported records."
+	valErrUnknownParent        = "Parent must be an existing merchant."
+	valErrParentCycle          = "Merchants can not be moved under themselves or their descendants."
+	valErrInvalidMetadataKey   = "Metadata keys must be letters, digits, dots, dashes and underscores up to 40 characters."
 )
 
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// parsePagination reads the limit and offset query parameters, writing an
+// unprocessable entity response when they are out of range.
+func parsePagination(w http.ResponseWriter, r *http.Request) (limit, offset int, ok bool) {
+	limit, offset = defaultPageLimit, 0
+
+	var err error
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxPageLimit {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrInvalidPagination)
+			return 0, 0, false
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrInvalidPagination)
+			return 0, 0, false
+		}
+	}
+
+	return limit, offset, true
+}
+
+// parseMetadataFilter reads the metadata[key]=value query parameters,
+// writing an unprocessable entity response when a key is not valid. It
+// returns nil when there are none.
+func parseMetadataFilter(w http.ResponseWriter, r *http.Request) (model.Metadata, bool) {
+	var metadata model.Metadata
+	for name, values := range r.URL.Query() {
+		if !strings.HasPrefix(name, "metadata[") || !strings.HasSuffix(name, "]") {
+			continue
+		}
+
+		key := strings.TrimSuffix(strings.TrimPrefix(name, "metadata["), "]")
+		if !validator.IsMetadataKey(key) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrInvalidMetadataKey)
+			return nil, false
+		}
+
+		if metadata == nil {
+			metadata = model.Metadata{}
+		}
+		metadata[key] = values[0]
+	}
+
+	return metadata, true
+}
+
+// parseDateParam reads a date or date-time query parameter. A date is read as
+// midnight UTC, or as the midnight after it when endOfDay is set, so that a
+// date range includes its last day.
+func parseDateParam(r *http.Request, name string, endOfDay bool) (*time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// etag returns the entity tag of a record at the given version.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// checkIfMatch compares the If-Match header of a request with the version of
+// the record it changes. It writes a precondition failed response when none
+// of the listed entity tags matches, and a precondition required response
+// when the header is missing but the server requires it.
+func (srv *Server) checkIfMatch(w http.ResponseWriter, r *http.Request, version int64) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		if srv.RequireIfMatch {
+			w.WriteHeader(http.StatusPreconditionRequired)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrPreconditionRequired)
+			return false
+		}
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == etag(version) {
+			return true
+		}
+	}
+
+	w.WriteHeader(http.StatusPreconditionFailed)
+	fmt.Fprintf(w, `{"error": "%v"}`, srvErrPreconditionFailed)
+	return false
+}
+
+// writeVersionConflict answers a write that lost the race against another
+// write of the record: with precondition failed when the request was
+// conditional on the version it read, and with conflict otherwise.
+func writeVersionConflict(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("If-Match") != "" {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrPreconditionFailed)
+		return
+	}
+
+	w.WriteHeader(http.StatusConflict)
+	fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataConcurrentUpdate)
+}
+
 func (srv Server) handleValidationErrors(w http.ResponseWriter, form interface{}) bool {
 	if err := srv.Validator.Struct(form); err != nil {
 		//srv.Logger.Warn().Err(err).Msg("")
@@ -0,0 +1,381 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// ListCoupon godoc
+// @Summary List coupons
+// @Description get the coupons of the merchant, newest first
+// @tags coupons
+// @Produce  json
+// @Success 200 {array} model.CouponDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /coupons [get]
+func (srv *Server) HandleListCoupon(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	coupons, err := srv.DB.ListCouponsByMerchantId(merchantId)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(coupons) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := coupons.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreateCoupon godoc
+// @Summary Create coupon
+// @Description create a percentage or fixed amount coupon; codes are unique per merchant and matched case-insensitively
+// @tags coupons
+// @Accept  json
+// @Produce  json
+// @Param body body model.CouponForm true "Create a coupon"
+// @Success 201 {object} model.CouponDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /coupons [post]
+func (srv *Server) HandleCreateCoupon(w http.ResponseWriter, r *http.Request) {
+	form := &model.CouponForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	form.Normalize()
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+	if !form.HasValidWindow() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrCouponWindow)
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	coupon := form.ToModel(merchantId)
+
+	if err := srv.DB.CreateCoupon(coupon); err != nil {
+		if err == repository.ErrDuplicate {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDuplicateInsertion)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Coupon created: %s", coupon.Code))
+	w.WriteHeader(http.StatusCreated)
+
+	dto := coupon.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+		return
+	}
+}
+
+// ReadCoupon godoc
+// @Summary Read coupon
+// @Description get a coupon with the number of times it was redeemed
+// @tags coupons
+// @Produce  json
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} model.CouponDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /coupons/{id} [get]
+func (srv *Server) HandleReadCoupon(w http.ResponseWriter, r *http.Request) {
+	coupon, ok := srv.readOwnedCoupon(w, r)
+	if !ok {
+		return
+	}
+
+	dto := coupon.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// UpdateCoupon godoc
+// @Summary Update coupon
+// @Description change the validity window, limits or active flag of a coupon; the code and discount can not change
+// @tags coupons
+// @Accept  json
+// @Param body body model.CouponUpdateForm true "Update a coupon"
+// @Param id path string true "Coupon ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /coupons/{id} [put]
+func (srv *Server) HandleUpdateCoupon(w http.ResponseWriter, r *http.Request) {
+	form := &model.CouponUpdateForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+	if !form.HasValidWindow() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrCouponWindow)
+		return
+	}
+
+	existing, ok := srv.readOwnedCoupon(w, r)
+	if !ok {
+		return
+	}
+
+	if err := srv.DB.UpdateCouponById(existing.ID, form.ToModel(existing.ID)); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// RedeemCoupon godoc
+// @Summary Redeem coupon on order
+// @Description apply a coupon to a draft order, replacing its discount, and count the redemption against the limits of the coupon
+// @tags coupons
+// @Accept  json
+// @Produce  json
+// @Param body body model.CouponRedemptionForm true "Coupon code"
+// @Param id path string true "Order ID"
+// @Success 200 {object} model.OrderDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /orders/{id}/coupon [post]
+func (srv *Server) HandleRedeemCoupon(w http.ResponseWriter, r *http.Request) {
+	form := &model.CouponRedemptionForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	order, ok := srv.readOwnedOrder(w, r)
+	if !ok {
+		return
+	}
+
+	if !order.IsEditable() {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotEditable)
+		return
+	}
+	if order.CouponID != "" {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderHasCoupon)
+		return
+	}
+
+	coupon, err := srv.DB.ReadCouponByCode(order.MerchantID, model.NormalizeCouponCode(form.Code))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrUnknownCoupon)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if err := coupon.CheckValidAt(time.Now()); err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, err)
+		return
+	}
+	if err := coupon.Apply(order); err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, err)
+		return
+	}
+
+	redemption := coupon.NewRedemption(uuid.New().String(), order)
+
+	if err := srv.DB.RedeemCoupon(redemption, order); err != nil {
+		switch err {
+		case model.ErrCouponExhausted, model.ErrCouponCustomerLimit:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, err)
+		case model.ErrCouponCustomerRequired:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, err)
+		case repository.ErrDuplicate:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderHasCoupon)
+		case repository.ErrConflict:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotEditable)
+		default:
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		}
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("Coupon %s redeemed on Order %s", coupon.Code, order.ID))
+
+	dto := order.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// RemoveCoupon godoc
+// @Summary Remove coupon from order
+// @Description take the coupon off a draft order, giving the redemption back to the coupon
+// @tags coupons
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Success 200 {object} model.OrderDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /orders/{id}/coupon [delete]
+func (srv *Server) HandleRemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	order, ok := srv.readOwnedOrder(w, r)
+	if !ok {
+		return
+	}
+
+	if !order.IsEditable() {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotEditable)
+		return
+	}
+	if order.CouponID == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	couponId := order.CouponID
+	if err := order.RemoveCoupon(); err != nil {
+		srv.writeOrderPricingError(w, err)
+		return
+	}
+
+	if err := srv.DB.ReleaseCoupon(couponId, order); err != nil {
+		if err == repository.ErrConflict {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotEditable)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	dto := order.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// readOwnedCoupon reads the coupon in the URL and writes a not found
+// response when it does not exist or belongs to another merchant.
+func (srv *Server) readOwnedCoupon(w http.ResponseWriter, r *http.Request) (*model.Coupon, bool) {
+	id := chi.URLParam(r, "id")
+
+	coupon, err := srv.DB.ReadCouponById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if coupon.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return coupon, true
+}
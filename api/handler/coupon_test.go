@@ -0,0 +1,156 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func draftOrder(merchantId string) *model.Order {
+	orderId := uuid.New().String()
+
+	return &model.Order{
+		Model:         model.Model{ID: orderId},
+		MerchantID:    merchantId,
+		State:         model.OrderStateDraft,
+		Currency:      "EUR",
+		CustomerEmail: "ada@example.com",
+		Items: []*model.OrderItem{
+			{OrderID: orderId, UnitPrice: 2000, Quantity: 2, TaxCategory: model.TaxCategoryStandard},
+		},
+	}
+}
+
+func summerCoupon(merchantId string) *model.Coupon {
+	return &model.Coupon{
+		Model:          model.Model{ID: uuid.New().String()},
+		MerchantID:     merchantId,
+		Code:           "SUMMER10",
+		Type:           model.CouponTypePercentage,
+		PercentOff:     1000,
+		MaxRedemptions: 100,
+		Active:         true,
+	}
+}
+
+func (s *Suite) Test_handler_Redeem_Coupon() {
+	merchantId := uuid.New().String()
+	order, coupon := draftOrder(merchantId), summerCoupon(merchantId)
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().ReadCouponByCode(merchantId, "SUMMER10").Return(coupon, nil)
+	s.db.EXPECT().RedeemCoupon(gomock.Any(), gomock.Any()).DoAndReturn(func(rd *model.CouponRedemption, o *model.Order) error {
+		require.Equal(s.T(), coupon.ID, rd.CouponID)
+		require.Equal(s.T(), order.ID, rd.OrderID)
+		require.Equal(s.T(), int64(400), rd.Amount)
+		require.Equal(s.T(), coupon.ID, o.CouponID)
+		require.Equal(s.T(), int64(3600), o.Subtotal-o.DiscountTotal)
+		return nil
+	})
+
+	r := newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/coupon", `{"code": " summer10 "}`, merchantId, order.ID)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRedeemCoupon(rr, r)
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Contains(s.T(), rr.Body.String(), `"couponCode":"SUMMER10"`)
+	require.Contains(s.T(), rr.Body.String(), `"discountTotal":400`)
+}
+
+func (s *Suite) Test_handler_Redeem_Coupon_Exhausted() {
+	merchantId := uuid.New().String()
+	order, coupon := draftOrder(merchantId), summerCoupon(merchantId)
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().ReadCouponByCode(merchantId, "SUMMER10").Return(coupon, nil)
+	s.db.EXPECT().RedeemCoupon(gomock.Any(), gomock.Any()).Return(model.ErrCouponExhausted)
+
+	r := newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/coupon", `{"code": "SUMMER10"}`, merchantId, order.ID)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRedeemCoupon(rr, r)
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Redeem_Expired_Coupon() {
+	merchantId := uuid.New().String()
+	order, coupon := draftOrder(merchantId), summerCoupon(merchantId)
+	ended := time.Now().Add(-time.Hour)
+	coupon.EndsAt = &ended
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().ReadCouponByCode(merchantId, "SUMMER10").Return(coupon, nil)
+
+	r := newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/coupon", `{"code": "SUMMER10"}`, merchantId, order.ID)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRedeemCoupon(rr, r)
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+	require.Contains(s.T(), rr.Body.String(), model.ErrCouponExpired.Error())
+}
+
+func (s *Suite) Test_handler_Remove_Coupon() {
+	merchantId := uuid.New().String()
+	order, coupon := draftOrder(merchantId), summerCoupon(merchantId)
+	require.NoError(s.T(), coupon.Apply(order))
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().ReleaseCoupon(coupon.ID, gomock.Any()).DoAndReturn(func(_ string, o *model.Order) error {
+		require.Equal(s.T(), "", o.CouponID)
+		require.Equal(s.T(), int64(0), o.DiscountTotal)
+		return nil
+	})
+
+	r := newMerchantRequest(http.MethodDelete, "/orders/"+order.ID+"/coupon", "", merchantId, order.ID)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRemoveCoupon(rr, r)
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Order_Moves_Redemption_To_New_Customer() {
+	merchantId := uuid.New().String()
+	order, coupon := draftOrder(merchantId), summerCoupon(merchantId)
+	coupon.MaxRedemptionsPerCustomer = 1
+	order.CouponID = coupon.ID
+
+	variantId := uuid.New().String()
+	products := model.Products{{
+		Model:      model.Model{ID: uuid.New().String()},
+		MerchantID: merchantId,
+		Active:     true,
+		Variants: []*model.ProductVariant{
+			{Model: model.Model{ID: variantId}, MerchantID: merchantId, Price: 2000, Currency: "EUR", Active: true},
+		},
+	}}
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().ListProductsByVariantIds([]string{variantId}).Return(products, nil)
+	s.db.EXPECT().ReadCouponById(coupon.ID).Return(coupon, nil)
+	s.db.EXPECT().UpdateRedeemedDraftOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(rd *model.CouponRedemption, o *model.Order) error {
+		require.Equal(s.T(), coupon.ID, rd.CouponID)
+		require.Equal(s.T(), order.ID, rd.OrderID)
+		require.Equal(s.T(), "grace@example.com", rd.CustomerEmail)
+		require.Equal(s.T(), int64(200), rd.Amount)
+		return model.ErrCouponCustomerLimit
+	})
+
+	body := `{"customerEmail": "Grace@example.com", "items": [{"variantID": "` + variantId + `", "quantity": 1}]}`
+	r := newMerchantRequest(http.MethodPut, "/orders/"+order.ID, body, merchantId, order.ID)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateOrder(rr, r)
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+	require.Contains(s.T(), rr.Body.String(), model.ErrCouponCustomerLimit.Error())
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"merchant/dataexport"
+	"merchant/model"
+)
+
+// CreateDataExport godoc
+// @Summary Export merchant data
+// @Description assemble a ZIP archive of all the data of the merchant, as JSON files, in the background. Poll the export for a download link; the archive is deleted when the link expires.
+// @tags data-exports
+// @Produce  json
+// @Success 202 {object} model.DataExportDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /data-exports [post]
+func (srv *Server) HandleCreateDataExport(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+
+	e := &model.DataExport{
+		Model: model.Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID: userDetails.UserId.String(),
+		Status:     model.DataExportStatusPending,
+	}
+	if err := srv.DB.CreateDataExport(e); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/data-exports/"+e.ID)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(e.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+// ReadDataExport godoc
+// @Summary Read data export
+// @Description get the progress of an export and, once its archive is ready, a link to download it
+// @tags data-exports
+// @Produce  json
+// @Param id path string true "Export ID"
+// @Success 200 {object} model.DataExportDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /data-exports/{id} [get]
+func (srv *Server) HandleReadDataExport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	e, err := srv.DB.ReadDataExportById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if e.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	dto := e.ToDto()
+	if now := time.Now(); e.Downloadable(now) {
+		link, expires := srv.DataExports.Link(e, now)
+		dto.DownloadURL = link
+		dto.DownloadExpiresAt = &expires
+	}
+
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// HandleDownloadDataExport serves the archive of an export to whoever holds
+// a download link which has not expired, without further authentication.
+func (srv *Server) HandleDownloadDataExport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	now := time.Now()
+
+	w.Header().Set("Content-Type", "application/json;charset=utf8")
+
+	switch err := srv.DataExports.VerifyLink(id, r.URL.Query(), now); err {
+	case nil:
+	case dataexport.ErrLinkExpired:
+		w.WriteHeader(http.StatusGone)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDownloadLinkExpired)
+		return
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrInvalidDownloadLink)
+		return
+	}
+
+	e, err := srv.DB.ReadDataExportById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusGone)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDownloadLinkExpired)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+	if !e.Downloadable(now) {
+		w.WriteHeader(http.StatusGone)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDownloadLinkExpired)
+		return
+	}
+
+	rc, err := srv.Blob.Get(r.Context(), e.BlobKey)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrBlobStorageFailure)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="merchant-data-%s.zip"`, e.CreatedAt.Format("2006-01-02")))
+	w.Header().Set("Cache-Control", "no-store")
+	if e.Size > 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(e.Size))
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/blob/fsblob"
+	"merchant/model"
+)
+
+func newDownloadRequest(link, id string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, link, nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func finishedDataExport(merchantId string) *model.DataExport {
+	now := time.Now()
+	e := &model.DataExport{
+		Model:      model.Model{ID: uuid.New().String(), CreatedAt: &now},
+		MerchantID: merchantId,
+		Status:     model.DataExportStatusPending,
+		BlobKey:    "private/exports/" + merchantId + "/archive.zip",
+		Size:       3,
+	}
+	e.Finish(now, 24*time.Hour, nil)
+	return e
+}
+
+func (s *Suite) Test_handler_Create_Data_Export() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().CreateDataExport(gomock.Any()).DoAndReturn(func(e *model.DataExport) error {
+		require.Equal(s.T(), merchantId, e.MerchantID)
+		require.Equal(s.T(), model.DataExportStatusPending, e.Status)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateDataExport(rr, newMerchantRequest(http.MethodPost, "/data-exports", "", merchantId, ""))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+	require.True(s.T(), strings.HasPrefix(rr.Header().Get("Location"), "/api/v1/data-exports/"))
+}
+
+func (s *Suite) Test_handler_Download_Data_Export() {
+	dir, err := ioutil.TempDir("", "exports")
+	require.NoError(s.T(), err)
+	defer os.RemoveAll(dir)
+
+	s.server.Blob, err = fsblob.New(dir)
+	require.NoError(s.T(), err)
+
+	merchantId := uuid.New().String()
+	e := finishedDataExport(merchantId)
+	name := filepath.Join(dir, filepath.FromSlash(e.BlobKey))
+	require.NoError(s.T(), os.MkdirAll(filepath.Dir(name), 0755))
+	require.NoError(s.T(), ioutil.WriteFile(name, []byte("zip"), 0644))
+
+	s.db.EXPECT().ReadDataExportById(e.ID).Return(e, nil).Times(2)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadDataExport(rr, newMerchantRequest(http.MethodGet, "/data-exports/"+e.ID, "", merchantId, e.ID))
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dto := &model.DataExportDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.True(s.T(), strings.HasPrefix(dto.DownloadURL, "/downloads/data-exports/"+e.ID+"?"))
+	require.NotNil(s.T(), dto.DownloadExpiresAt)
+
+	rr = httptest.NewRecorder()
+	s.server.HandleDownloadDataExport(rr, newDownloadRequest(dto.DownloadURL, e.ID))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), "application/zip", rr.Header().Get("Content-Type"))
+	require.Equal(s.T(), "zip", rr.Body.String())
+}
+
+func (s *Suite) Test_handler_Download_Data_Export_Invalid_Link() {
+	e := finishedDataExport(uuid.New().String())
+	link, _ := s.server.DataExports.Link(e, time.Now())
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDownloadDataExport(rr, newDownloadRequest(link, uuid.New().String()))
+	require.Equal(s.T(), http.StatusForbidden, rr.Code)
+
+	link, _ = s.server.DataExports.Link(e, time.Now().Add(-48*time.Hour))
+
+	rr = httptest.NewRecorder()
+	s.server.HandleDownloadDataExport(rr, newDownloadRequest(link, e.ID))
+	require.Equal(s.T(), http.StatusGone, rr.Code)
+}
+
+func (s *Suite) Test_handler_Read_Data_Export_Of_Another_Merchant() {
+	e := finishedDataExport(uuid.New().String())
+
+	s.db.EXPECT().ReadDataExportById(e.ID).Return(e, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadDataExport(rr, newMerchantRequest(http.MethodGet, "/data-exports/"+e.ID, "", uuid.New().String(), e.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// RotateDataKeyAdmin godoc
+// @Summary Rotate the data key
+// @Description create a data key which encrypts sensitive columns from now on; the values encrypted before are encrypted again with it in the background
+// @tags admin
+// @Produce  json
+// @Success 201 {object} model.DataKeyDto
+// @Header 200 {string} X-Admin-Token "qwerty"
+// @Failure 401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /admin/v1/data-keys [post]
+func (srv *Server) HandleRotateDataKeyAdmin(w http.ResponseWriter, r *http.Request) {
+	key, err := srv.Keys.Rotate()
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("Data key rotated: %s", key.ID))
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(key.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+		return
+	}
+}
@@ -0,0 +1,53 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/keyring"
+	"merchant/model"
+	"merchant/util/cryptoutil"
+)
+
+func (s *Suite) newKeys() {
+	master, err := cryptoutil.NewAESGCM(bytes.Repeat([]byte{7}, 32))
+	require.NoError(s.T(), err)
+	s.server.Keys = keyring.NewManager(s.db, cryptoutil.NewKeyring(master), s.server.Logger)
+}
+
+func (s *Suite) Test_handler_Rotate_Data_Key() {
+	s.newKeys()
+
+	s.db.EXPECT().CreateDataKey(gomock.Any()).DoAndReturn(func(k *model.DataKey) error {
+		require.Equal(s.T(), model.DataKeyPurposeEncryption, k.Purpose)
+		require.NotEmpty(s.T(), k.WrappedKey)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRotateDataKeyAdmin(rr, httptest.NewRequest(http.MethodPost, "/admin/v1/data-keys", nil))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	dto := &model.DataKeyDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.NotEmpty(s.T(), dto.ID)
+	require.Equal(s.T(), model.DataKeyPurposeEncryption, dto.Purpose)
+}
+
+func (s *Suite) Test_handler_Rotate_Data_Key_Failure() {
+	s.newKeys()
+
+	s.db.EXPECT().CreateDataKey(gomock.Any()).Return(errors.New("connection lost"))
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRotateDataKeyAdmin(rr, httptest.NewRequest(http.MethodPost, "/admin/v1/data-keys", nil))
+
+	require.Equal(s.T(), http.StatusInternalServerError, rr.Code)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"merchant/model"
+	"merchant/util/exportutil"
+)
+
+// exportBatchSize is the number of records read from the database at a time
+// while streaming an export.
+const exportBatchSize = 500
+
+// exportBatch returns the records following the one with the id, and the id
+// of the last record returned.
+type exportBatch func(afterId string) ([]interface{}, string, error)
+
+// ExportMerchants godoc
+// @Summary Export merchants
+// @Description stream the merchant and every merchant under it in the hierarchy as CSV, with a header line, or as NDJSON, one object per line, as asked for by the Accept header
+// @tags exports
+// @Produce  text/csv,application/x-ndjson
+// @Param columns query string false "Comma separated columns to export, in order; all of them by default"
+// @Success 200 {string} string "merchants"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 406 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /exports/merchants [get]
+func (srv *Server) HandleExportMerchants(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	var ids []string
+	srv.export(w, r, "merchants", exportutil.Columns(model.MerchantDto{}), func(afterId string) ([]interface{}, string, error) {
+		if ids == nil {
+			descendants, err := srv.DB.ListMerchantDescendantIds(merchantId)
+			if err != nil {
+				return nil, "", err
+			}
+			ids = append([]string{merchantId}, descendants...)
+		}
+
+		merchants, err := srv.DB.ListMerchantsByIdsAfter(ids, afterId, exportBatchSize)
+		if err != nil || len(merchants) == 0 {
+			return nil, "", err
+		}
+
+		records := make([]interface{}, len(merchants))
+		for i, m := range merchants {
+			records[i] = m.ToDto()
+		}
+		return records, merchants[len(merchants)-1].ID, nil
+	})
+}
+
+// ExportTeamMembers godoc
+// @Summary Export team members
+// @Description stream every team member of the merchant as CSV, with a header line, or as NDJSON, one object per line, as asked for by the Accept header
+// @tags exports
+// @Produce  text/csv,application/x-ndjson
+// @Param columns query string false "Comma separated columns to export, in order; all of them by default"
+// @Success 200 {string} string "team members"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400 {object} httputil.HTTPError
+// @Failure 406 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /exports/team-members [get]
+func (srv *Server) HandleExportTeamMembers(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	srv.export(w, r, "team-members", exportutil.Columns(model.TeamMemberDto{}), func(afterId string) ([]interface{}, string, error) {
+		members, err := srv.DB.ListTeamMembersByMerchantIdAfter(merchantId, afterId, exportBatchSize)
+		if err != nil || len(members) == 0 {
+			return nil, "", err
+		}
+
+		records := make([]interface{}, len(members))
+		for i, m := range members {
+			records[i] = m.ToDto()
+		}
+		return records, members[len(members)-1].ID, nil
+	})
+}
+
+// export streams the records read batch by batch in the format asked for by
+// the Accept header. Failures found before the first batch is read are
+// answered with a JSON error. Once the response has started, a failure cuts
+// it off, so that a client can tell a broken export from a complete one.
+func (srv *Server) export(w http.ResponseWriter, r *http.Request, name string, available []string, next exportBatch) {
+	w.Header().Set("Content-Type", "application/json;charset=utf8")
+
+	format, ok := exportutil.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		w.WriteHeader(http.StatusNotAcceptable)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrNotAcceptable)
+		return
+	}
+
+	columns, err := exportutil.SelectColumns(available, r.URL.Query().Get("columns"))
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrUnknownExportColumn)
+		return
+	}
+
+	records, afterId, err := next("")
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	w.Header().Set("Content-Type", exportutil.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	w.Header().Set("Cache-Control", "no-store")
+
+	ew := exportutil.NewWriter(w, format, columns)
+	flusher, _ := w.(http.Flusher)
+	err = ew.WriteHeader()
+	for err == nil {
+		for _, record := range records {
+			if err = ew.Write(record); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = ew.Flush()
+		}
+		if err != nil {
+			break
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if len(records) < exportBatchSize || r.Context().Err() != nil {
+			return
+		}
+		records, afterId, err = next(afterId)
+	}
+
+	srv.Logger.Warn(err.Error())
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_handler_Export_Team_Members_CSV_Columns() {
+	merchantId := uuid.New().String()
+	members := model.TeamMembers{
+		{Model: model.Model{ID: "1"}, GivenName: "Ada", FamilyName: "Lovelace, Countess", Email: "ada@example.com", IsOwner: true, MerchantID: merchantId},
+		{Model: model.Model{ID: "2"}, GivenName: "=cmd", FamilyName: "Hopper", Email: "grace@example.com", MerchantID: merchantId},
+	}
+
+	s.db.EXPECT().ListTeamMembersByMerchantIdAfter(merchantId, "", 500).Return(members, nil)
+
+	r := newMerchantRequest(http.MethodGet, "/exports/team-members?columns=email,givenName,familyName,isOwner", "", merchantId, "")
+	r.Header.Set("Accept", "text/csv")
+	rr := httptest.NewRecorder()
+	s.server.HandleExportTeamMembers(rr, r)
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	require.Equal(s.T(), `attachment; filename="team-members.csv"`, rr.Header().Get("Content-Disposition"))
+	require.Equal(s.T(), "email,givenName,familyName,isOwner\n"+
+		"ada@example.com,Ada,\"Lovelace, Countess\",true\n"+
+		"grace@example.com,'=cmd,Hopper,false\n", rr.Body.String())
+}
+
+func (s *Suite) Test_handler_Export_Team_Members_NDJSON_Batches() {
+	merchantId := uuid.New().String()
+
+	first := make(model.TeamMembers, 500)
+	for i := range first {
+		first[i] = &model.TeamMember{Model: model.Model{ID: fmt.Sprintf("%04d", i)}, MerchantID: merchantId}
+	}
+	last := model.TeamMembers{{Model: model.Model{ID: "0500"}, MerchantID: merchantId}}
+
+	s.db.EXPECT().ListTeamMembersByMerchantIdAfter(merchantId, "", 500).Return(first, nil)
+	s.db.EXPECT().ListTeamMembersByMerchantIdAfter(merchantId, "0499", 500).Return(last, nil)
+
+	r := newMerchantRequest(http.MethodGet, "/exports/team-members?columns=id", "", merchantId, "")
+	r.Header.Set("Accept", "application/json;q=0.9, application/x-ndjson")
+	rr := httptest.NewRecorder()
+	s.server.HandleExportTeamMembers(rr, r)
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n"), "\n")
+	require.Len(s.T(), lines, 501)
+	require.Equal(s.T(), `{"id":"0000"}`, lines[0])
+	require.Equal(s.T(), `{"id":"0500"}`, lines[500])
+}
+
+func (s *Suite) Test_handler_Export_Merchants_Not_Acceptable() {
+	r := httptest.NewRequest(http.MethodGet, "/exports/merchants", nil)
+	r.Header.Set("Accept", "application/json")
+	rr := httptest.NewRecorder()
+	s.server.HandleExportMerchants(rr, r)
+
+	require.Equal(s.T(), http.StatusNotAcceptable, rr.Code)
+	require.Equal(s.T(), "application/json;charset=utf8", rr.Header().Get("Content-Type"))
+}
+
+func (s *Suite) Test_handler_Export_Merchants_Unknown_Column() {
+	r := httptest.NewRequest(http.MethodGet, "/exports/merchants?columns=id,password", nil)
+	rr := httptest.NewRecorder()
+	s.server.HandleExportMerchants(rr, r)
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Export_Merchants_Hierarchy() {
+	merchantId := uuid.New().String()
+	brandId := uuid.New().String()
+	merchants := model.Merchants{
+		{Model: model.Model{ID: merchantId}, Email: "organisation@example.com"},
+		{Model: model.Model{ID: brandId}, Email: "brand@example.com", ParentID: &merchantId},
+	}
+
+	s.db.EXPECT().ListMerchantDescendantIds(merchantId).Return([]string{brandId}, nil)
+	s.db.EXPECT().ListMerchantsByIdsAfter([]string{merchantId, brandId}, "", 500).Return(merchants, nil)
+
+	r := newMerchantRequest(http.MethodGet, "/exports/merchants?columns=id,email", "", merchantId, "")
+	r.Header.Set("Accept", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+	s.server.HandleExportMerchants(rr, r)
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), `{"id":"`+merchantId+`","email":"organisation@example.com"}`+"\n"+
+		`{"id":"`+brandId+`","email":"brand@example.com"}`+"\n", rr.Body.String())
+}
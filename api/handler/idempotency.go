@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// MaxIdempotentRequestBytes bounds the JSON bodies of requests which
+// Idempotent buffers. Uploads take larger bodies, and pass their own limits
+// to IdempotentWithLimit.
+const MaxIdempotentRequestBytes = 1 << 20
+
+// Idempotent makes POST requests sent with an Idempotency-Key header safe to
+// retry. The first request with a key is handled and its response stored
+// for a day; retries with the same key and body get that response replayed
+// instead of being handled again. Reusing a key for another request is
+// rejected, as are retries while the first request is still in flight.
+// Server errors are not stored, so the request can be retried after them.
+// Keys of signed in merchants are kept apart from each other; keys of
+// requests sent before signing in are kept apart by client. The
+// request is handled within the request timeout; its key stays in flight
+// until it finishes, and is never taken over by a retry meanwhile.
+func (srv *Server) Idempotent(next http.Handler) http.Handler {
+	return srv.IdempotentWithLimit(MaxIdempotentRequestBytes)(next)
+}
+
+// IdempotentWithLimit is Idempotent for requests whose bodies may be up to
+// maxBytes long.
+func (srv *Server) IdempotentWithLimit(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return srv.idempotent(next, maxBytes)
+	}
+}
+
+func (srv *Server) idempotent(next http.Handler, maxBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrIdempotencyKeyLength)
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+		if err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrFileTooLarge)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		fingerprint := model.IdempotencyFingerprint(r.Method, r.URL.Path, body)
+
+		var scope string
+		if userDetails, ok := r.Context().Value(model.CtxKeyXUser).(model.CtxUser); ok {
+			scope = userDetails.UserId.String()
+		} else {
+			client, _, _ := net.SplitHostPort(r.RemoteAddr)
+			scope = model.AnonymousIdempotencyScope(client)
+		}
+
+		record, ok := srv.takeIdempotencyKey(w, scope, key, fingerprint)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), srv.RequestTimeout)
+		defer cancel()
+
+		cw := &capturingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(cw, r.WithContext(ctx))
+
+		if cw.statusCode >= http.StatusInternalServerError {
+			err = srv.DB.DeleteIdempotencyKey(record.ID)
+		} else {
+			err = srv.DB.CompleteIdempotencyKey(record.ID, cw.statusCode, replayedHeaders(cw.Header()), cw.body.String())
+		}
+		if err != nil {
+			srv.Logger.Warn(err.Error())
+		}
+	})
+}
+
+// replayedHeaders returns the JSON encoded headers of a response to replay
+// with it.
+func replayedHeaders(h http.Header) string {
+	headers := make(map[string]string)
+	for _, name := range model.IdempotencyReplayedHeaders {
+		if v := h.Get(name); v != "" {
+			headers[name] = v
+		}
+	}
+
+	b, _ := json.Marshal(headers)
+	return string(b)
+}
+
+// takeIdempotencyKey records the key as in flight for a new request. When a
+// request used the key before it answers the retry itself, by replaying the
+// stored response or rejecting the retry, and returns false.
+func (srv *Server) takeIdempotencyKey(w http.ResponseWriter, scope, key, fingerprint string) (*model.IdempotencyKey, bool) {
+	now := time.Now()
+	record := &model.IdempotencyKey{
+		Model: model.Model{
+			ID: uuid.New().String(),
+		},
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(model.IdempotencyKeyTTL),
+	}
+
+	// A key which expired is deleted and taken again, which
+	// may race with another retry doing the same; the loser sees the key in
+	// flight.
+	for attempt := 0; attempt < 2; attempt++ {
+		err := srv.DB.CreateIdempotencyKey(record)
+		if err == nil {
+			return record, true
+		}
+		if err != repository.ErrDuplicate {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+			return nil, false
+		}
+
+		existing, err := srv.DB.ReadIdempotencyKey(scope, key)
+		if err == gorm.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+			return nil, false
+		}
+
+		if existing.ReusableAt(now) {
+			if err := srv.DB.DeleteIdempotencyKey(existing.ID); err != nil {
+				srv.Logger.Warn(err.Error())
+
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDeleteFailure)
+				return nil, false
+			}
+			continue
+		}
+
+		switch {
+		case existing.Fingerprint != fingerprint:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrIdempotencyKeyReused)
+		case existing.InFlight():
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrIdempotentRequestInFlight)
+		default:
+			headers := make(map[string]string)
+			if existing.ResponseHeaders != "" {
+				if err := json.Unmarshal([]byte(existing.ResponseHeaders), &headers); err != nil {
+					srv.Logger.Warn(err.Error())
+				}
+			}
+			for name, v := range headers {
+				w.Header().Set(name, v)
+			}
+			w.Header().Set(idempotentReplayedHeader, "true")
+			w.WriteHeader(existing.StatusCode)
+			_, _ = w.Write([]byte(existing.ResponseBody))
+		}
+		return nil, false
+	}
+
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusConflict)
+	fmt.Fprintf(w, `{"error": "%v"}`, srvErrIdempotentRequestInFlight)
+	return nil, false
+}
+
+// capturingWriter passes a response through while keeping a copy of its
+// status code and body.
+type capturingWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (cw *capturingWriter) WriteHeader(statusCode int) {
+	if !cw.wroteHeader {
+		cw.statusCode = statusCode
+		cw.wroteHeader = true
+	}
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (cw *capturingWriter) Write(b []byte) (int, error) {
+	cw.wroteHeader = true
+	cw.body.Write(b)
+	return cw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,221 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+func newIdempotentRequest(merchantId, key, body string) *http.Request {
+	r := newMerchantRequest(http.MethodPost, "/api/v1/team-members", body, merchantId, "")
+	r.Header.Set("Idempotency-Key", key)
+	return r
+}
+
+func createdHandler(calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Location", "/api/v1/team-members/1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": "1"}`)
+	})
+}
+
+func (s *Suite) Test_handler_Idempotent_Stores_Response() {
+	merchantId := uuid.New().String()
+
+	var record *model.IdempotencyKey
+	s.db.EXPECT().CreateIdempotencyKey(gomock.Any()).DoAndReturn(func(k *model.IdempotencyKey) error {
+		require.Equal(s.T(), merchantId, k.Scope)
+		require.Equal(s.T(), "retry-1", k.Key)
+		record = k
+		return nil
+	})
+	s.db.EXPECT().CompleteIdempotencyKey(gomock.Any(), http.StatusCreated, `{"Location":"/api/v1/team-members/1"}`, `{"id": "1"}`).
+		DoAndReturn(func(id string, _ int, _, _ string) error {
+			require.Equal(s.T(), record.ID, id)
+			return nil
+		})
+
+	var calls int
+	rr := httptest.NewRecorder()
+	s.server.Idempotent(createdHandler(&calls)).ServeHTTP(rr, newIdempotentRequest(merchantId, "retry-1", `{"email": "ada@example.com"}`))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+	require.Equal(s.T(), 1, calls)
+}
+
+func (s *Suite) Test_handler_Idempotent_Replays_Response() {
+	merchantId := uuid.New().String()
+	body := `{"email": "ada@example.com"}`
+	createdAt := time.Now().Add(-time.Hour)
+
+	s.db.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(repository.ErrDuplicate)
+	s.db.EXPECT().ReadIdempotencyKey(merchantId, "retry-2").Return(&model.IdempotencyKey{
+		Model:           model.Model{ID: uuid.New().String(), CreatedAt: &createdAt},
+		Fingerprint:     model.IdempotencyFingerprint(http.MethodPost, "/api/v1/team-members", []byte(body)),
+		StatusCode:      http.StatusCreated,
+		ResponseHeaders: `{"Content-Type":"application/json","Location":"/api/v1/team-members/1"}`,
+		ResponseBody:    `{"id": "1"}`,
+		ExpiresAt:       createdAt.Add(model.IdempotencyKeyTTL),
+	}, nil)
+
+	var calls int
+	rr := httptest.NewRecorder()
+	s.server.Idempotent(createdHandler(&calls)).ServeHTTP(rr, newIdempotentRequest(merchantId, "retry-2", body))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+	require.Equal(s.T(), `{"id": "1"}`, rr.Body.String())
+	require.Equal(s.T(), "/api/v1/team-members/1", rr.Header().Get("Location"))
+	require.Equal(s.T(), "application/json", rr.Header().Get("Content-Type"))
+	require.Equal(s.T(), "true", rr.Header().Get("Idempotent-Replayed"))
+	require.Equal(s.T(), 0, calls)
+}
+
+func (s *Suite) Test_handler_Idempotent_Anonymous_Keys_Scoped_By_Client() {
+	scopes := make([]string, 0)
+	s.db.EXPECT().CreateIdempotencyKey(gomock.Any()).DoAndReturn(func(k *model.IdempotencyKey) error {
+		scopes = append(scopes, k.Scope)
+		return nil
+	}).Times(2)
+	s.db.EXPECT().CompleteIdempotencyKey(gomock.Any(), http.StatusCreated, gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	var calls int
+	for _, client := range []string{"192.0.2.1:40000", "192.0.2.2:40000"} {
+		r := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(`{"email": "shop@example.com"}`))
+		r.Header.Set("Idempotency-Key", "register")
+		r.RemoteAddr = client
+
+		rr := httptest.NewRecorder()
+		s.server.Idempotent(createdHandler(&calls)).ServeHTTP(rr, r)
+
+		require.Equal(s.T(), http.StatusCreated, rr.Code)
+	}
+
+	require.Equal(s.T(), 2, calls)
+	require.NotEqual(s.T(), scopes[0], scopes[1])
+	require.NotEmpty(s.T(), scopes[0])
+}
+
+func (s *Suite) Test_handler_Idempotent_Anonymous_Key_Reused() {
+	createdAt := time.Now().Add(-time.Hour)
+	scope := model.AnonymousIdempotencyScope("192.0.2.1")
+
+	s.db.EXPECT().CreateIdempotencyKey(gomock.Any()).DoAndReturn(func(k *model.IdempotencyKey) error {
+		require.Equal(s.T(), scope, k.Scope)
+		return repository.ErrDuplicate
+	})
+	s.db.EXPECT().ReadIdempotencyKey(scope, "register").Return(&model.IdempotencyKey{
+		Model:        model.Model{ID: uuid.New().String(), CreatedAt: &createdAt},
+		Scope:        scope,
+		Fingerprint:  model.IdempotencyFingerprint(http.MethodPost, "/auth/register", []byte(`{"email": "shop@example.com"}`)),
+		StatusCode:   http.StatusCreated,
+		ResponseBody: `{"id": "1"}`,
+		ExpiresAt:    createdAt.Add(model.IdempotencyKeyTTL),
+	}, nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(`{"email": "other@example.com"}`))
+	r.Header.Set("Idempotency-Key", "register")
+	r.RemoteAddr = "192.0.2.1:40000"
+
+	var calls int
+	rr := httptest.NewRecorder()
+	s.server.Idempotent(createdHandler(&calls)).ServeHTTP(rr, r)
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+	require.Equal(s.T(), 0, calls)
+}
+
+func (s *Suite) Test_handler_Idempotent_Key_Reused() {
+	merchantId := uuid.New().String()
+	createdAt := time.Now().Add(-time.Hour)
+
+	s.db.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(repository.ErrDuplicate)
+	s.db.EXPECT().ReadIdempotencyKey(merchantId, "retry-3").Return(&model.IdempotencyKey{
+		Model:        model.Model{ID: uuid.New().String(), CreatedAt: &createdAt},
+		Fingerprint:  model.IdempotencyFingerprint(http.MethodPost, "/api/v1/team-members", []byte(`{"email": "ada@example.com"}`)),
+		StatusCode:   http.StatusCreated,
+		ResponseBody: `{"id": "1"}`,
+		ExpiresAt:    createdAt.Add(model.IdempotencyKeyTTL),
+	}, nil)
+
+	var calls int
+	rr := httptest.NewRecorder()
+	s.server.Idempotent(createdHandler(&calls)).ServeHTTP(rr, newIdempotentRequest(merchantId, "retry-3", `{"email": "grace@example.com"}`))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+	require.Equal(s.T(), 0, calls)
+}
+
+func (s *Suite) Test_handler_Idempotent_In_Flight() {
+	merchantId := uuid.New().String()
+	body := `{"email": "ada@example.com"}`
+	createdAt := time.Now()
+
+	s.db.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(repository.ErrDuplicate)
+	s.db.EXPECT().ReadIdempotencyKey(merchantId, "retry-4").Return(&model.IdempotencyKey{
+		Model:       model.Model{ID: uuid.New().String(), CreatedAt: &createdAt},
+		Fingerprint: model.IdempotencyFingerprint(http.MethodPost, "/api/v1/team-members", []byte(body)),
+		ExpiresAt:   createdAt.Add(model.IdempotencyKeyTTL),
+	}, nil)
+
+	var calls int
+	rr := httptest.NewRecorder()
+	s.server.Idempotent(createdHandler(&calls)).ServeHTTP(rr, newIdempotentRequest(merchantId, "retry-4", body))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+	require.Equal(s.T(), "1", rr.Header().Get("Retry-After"))
+	require.Equal(s.T(), 0, calls)
+}
+
+func (s *Suite) Test_handler_Idempotent_Slow_Request_Not_Taken_Over() {
+	merchantId := uuid.New().String()
+	body := `{"email": "ada@example.com"}`
+	createdAt := time.Now().Add(-time.Hour)
+
+	s.db.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(repository.ErrDuplicate)
+	s.db.EXPECT().ReadIdempotencyKey(merchantId, "retry-6").Return(&model.IdempotencyKey{
+		Model:       model.Model{ID: uuid.New().String(), CreatedAt: &createdAt},
+		Fingerprint: model.IdempotencyFingerprint(http.MethodPost, "/api/v1/team-members", []byte(body)),
+		ExpiresAt:   createdAt.Add(model.IdempotencyKeyTTL),
+	}, nil)
+
+	var calls int
+	rr := httptest.NewRecorder()
+	s.server.Idempotent(createdHandler(&calls)).ServeHTTP(rr, newIdempotentRequest(merchantId, "retry-6", body))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+	require.Equal(s.T(), 0, calls)
+}
+
+func (s *Suite) Test_handler_Idempotent_Server_Error_Releases_Key() {
+	merchantId := uuid.New().String()
+
+	var record *model.IdempotencyKey
+	s.db.EXPECT().CreateIdempotencyKey(gomock.Any()).DoAndReturn(func(k *model.IdempotencyKey) error {
+		record = k
+		return nil
+	})
+	s.db.EXPECT().DeleteIdempotencyKey(gomock.Any()).DoAndReturn(func(id string) error {
+		require.Equal(s.T(), record.ID, id)
+		return nil
+	})
+
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.Idempotent(failing).ServeHTTP(rr, newIdempotentRequest(merchantId, "retry-5", `{}`))
+
+	require.Equal(s.T(), http.StatusInternalServerError, rr.Code)
+}
@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// ListInventory godoc
+// @Summary List inventory levels
+// @Description get the stock levels of the merchant per variant and location
+// @tags inventory
+// @Produce  json
+// @Param variantId query string false "Filter by variant"
+// @Param locationId query string false "Filter by location"
+// @Success 200 {array} model.InventoryLevelDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /inventory [get]
+func (srv *Server) HandleListInventory(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	levels, err := srv.DB.ListInventoryLevels(merchantId, r.URL.Query().Get("variantId"), r.URL.Query().Get("locationId"))
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(levels) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := levels.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// AdjustInventory godoc
+// @Summary Adjust stock
+// @Description add stock to or remove stock from a variant at a location; stock on hand can not drop below the reserved stock
+// @tags inventory
+// @Accept  json
+// @Produce  json
+// @Param body body model.StockAdjustmentForm true "Stock adjustment"
+// @Success 200 {object} model.InventoryLevelDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /inventory/adjustments [post]
+func (srv *Server) HandleAdjustInventory(w http.ResponseWriter, r *http.Request) {
+	form := &model.StockAdjustmentForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	srv.applyStockMovement(w, form.ToModel(merchantId))
+}
+
+// ReserveInventory godoc
+// @Summary Reserve stock
+// @Description set available stock of a variant at a location aside
+// @tags inventory
+// @Accept  json
+// @Produce  json
+// @Param body body model.StockReservationForm true "Stock reservation"
+// @Success 200 {object} model.InventoryLevelDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /inventory/reservations [post]
+func (srv *Server) HandleReserveInventory(w http.ResponseWriter, r *http.Request) {
+	srv.handleStockReservation(w, r, model.StockMovementReservation)
+}
+
+// ReleaseInventory godoc
+// @Summary Release stock
+// @Description return reserved stock of a variant at a location
+// @tags inventory
+// @Accept  json
+// @Produce  json
+// @Param body body model.StockReservationForm true "Stock release"
+// @Success 200 {object} model.InventoryLevelDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /inventory/releases [post]
+func (srv *Server) HandleReleaseInventory(w http.ResponseWriter, r *http.Request) {
+	srv.handleStockReservation(w, r, model.StockMovementRelease)
+}
+
+// UpdateInventoryThreshold godoc
+// @Summary Update low stock threshold
+// @Description set the available stock at or below which a low stock alert is raised; zero disables alerts
+// @tags inventory
+// @Accept  json
+// @Param body body model.StockThresholdForm true "Low stock threshold"
+// @Success 202 {string} string "accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /inventory/thresholds [put]
+func (srv *Server) HandleUpdateInventoryThreshold(w http.ResponseWriter, r *http.Request) {
+	form := &model.StockThresholdForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	if !srv.checkStockOwnership(w, merchantId, form.VariantID, form.LocationID) {
+		return
+	}
+
+	if err := srv.DB.UpdateInventoryThreshold(merchantId, form.VariantID, form.LocationID, form.Threshold); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ListStockMovement godoc
+// @Summary List stock movements
+// @Description get the stock ledger of the merchant, newest first
+// @tags inventory
+// @Produce  json
+// @Param variantId query string false "Filter by variant"
+// @Param locationId query string false "Filter by location"
+// @Param limit query integer false "Page size, 1 to 100" default(20)
+// @Param offset query integer false "Number of movements to skip" default(0)
+// @Success 200 {array} model.StockMovementDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /inventory/movements [get]
+func (srv *Server) HandleListStockMovement(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	movements, err := srv.DB.ListStockMovements(merchantId, r.URL.Query().Get("variantId"), r.URL.Query().Get("locationId"), limit, offset)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(movements) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := movements.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// ListStockAlert godoc
+// @Summary List low stock alerts
+// @Description get the low stock alerts of the merchant, newest first
+// @tags inventory
+// @Produce  json
+// @Success 200 {array} model.StockAlertDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /inventory/alerts [get]
+func (srv *Server) HandleListStockAlert(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	alerts, err := srv.DB.ListStockAlertsByMerchantId(merchantId)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(alerts) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := alerts.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+func (srv *Server) handleStockReservation(w http.ResponseWriter, r *http.Request, kind string) {
+	form := &model.StockReservationForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	srv.applyStockMovement(w, form.ToModel(merchantId, kind))
+}
+
+// applyStockMovement applies the movement of the merchant and writes the
+// resulting inventory level.
+func (srv *Server) applyStockMovement(w http.ResponseWriter, m *model.StockMovement) {
+	if !srv.checkStockOwnership(w, m.MerchantID, m.VariantID, m.LocationID) {
+		return
+	}
+
+	level, alert, err := srv.DB.ApplyStockMovement(m)
+	if err != nil {
+		switch err {
+		case model.ErrInsufficientStock:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrInsufficientStock)
+		case model.ErrInvalidQuantity:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrInvalidQuantity)
+		default:
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		}
+		return
+	}
+
+	if alert != nil {
+		srv.Logger.Info(fmt.Sprintf("Low stock of variant %s at location %s: %d available", alert.VariantID, alert.LocationID, alert.Available))
+	}
+
+	dto := level.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// checkStockOwnership writes a not found response unless both the variant and
+// the location belong to the merchant.
+func (srv *Server) checkStockOwnership(w http.ResponseWriter, merchantId, variantId, locationId string) bool {
+	variant, err := srv.DB.ReadProductVariantById(variantId)
+	if err == nil && variant.MerchantID == merchantId {
+		var location *model.Location
+		location, err = srv.DB.ReadLocationById(locationId)
+		if err == nil && location.MerchantID == merchantId {
+			return true
+		}
+	}
+
+	if err != nil && err != gorm.ErrRecordNotFound {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	return false
+}
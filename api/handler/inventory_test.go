@@ -0,0 +1,192 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// expectStockOwnership expects the variant and the location of a stock
+// movement to be looked up, both belonging to the merchant.
+func (s *Suite) expectStockOwnership(merchantId, variantId, locationId string) {
+	s.db.EXPECT().ReadProductVariantById(variantId).Return(&model.ProductVariant{Model: model.Model{ID: variantId}, MerchantID: merchantId}, nil)
+	s.db.EXPECT().ReadLocationById(locationId).Return(&model.Location{Model: model.Model{ID: locationId}, MerchantID: merchantId}, nil)
+}
+
+func stockBody(variantId, locationId, quantity string) string {
+	return `{"variantID": "` + variantId + `", "locationID": "` + locationId + `", "quantity": ` + quantity + `, "reference": "order-1001", "reason": "recount"}`
+}
+
+func (s *Suite) Test_handler_Reserve_Inventory() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	s.expectStockOwnership(merchantId, variantId, locationId)
+	s.db.EXPECT().ApplyStockMovement(gomock.Any()).DoAndReturn(func(m *model.StockMovement) (*model.InventoryLevel, *model.StockAlert, error) {
+		require.Equal(s.T(), model.StockMovementReservation, m.Kind)
+		require.Equal(s.T(), int64(2), m.Quantity)
+		return &model.InventoryLevel{MerchantID: merchantId, VariantID: variantId, LocationID: locationId, OnHand: 10, Reserved: 2}, nil, nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReserveInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/reservations", stockBody(variantId, locationId, "2"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dto := &model.InventoryLevelDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), int64(8), dto.Available)
+	require.False(s.T(), dto.LowStock)
+}
+
+func (s *Suite) Test_handler_Reserve_Inventory_Low_Stock() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	level := &model.InventoryLevel{MerchantID: merchantId, VariantID: variantId, LocationID: locationId, OnHand: 10, Reserved: 7, LowStockThreshold: 5}
+
+	s.expectStockOwnership(merchantId, variantId, locationId)
+	s.db.EXPECT().ApplyStockMovement(gomock.Any()).Return(level, level.NewStockAlert(), nil)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := s.server.Logger
+	s.server.Logger = zap.New(core)
+	defer func() { s.server.Logger = logger }()
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReserveInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/reservations", stockBody(variantId, locationId, "2"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dto := &model.InventoryLevelDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.True(s.T(), dto.LowStock)
+
+	alerts := logs.FilterMessage("Low stock of variant " + variantId + " at location " + locationId + ": 3 available")
+	require.Equal(s.T(), 1, alerts.Len())
+}
+
+func (s *Suite) Test_handler_Reserve_Inventory_Insufficient_Stock() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	s.expectStockOwnership(merchantId, variantId, locationId)
+	s.db.EXPECT().ApplyStockMovement(gomock.Any()).Return(nil, nil, model.ErrInsufficientStock)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReserveInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/reservations", stockBody(variantId, locationId, "20"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Adjust_Inventory_Below_Reserved() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	s.expectStockOwnership(merchantId, variantId, locationId)
+	s.db.EXPECT().ApplyStockMovement(gomock.Any()).DoAndReturn(func(m *model.StockMovement) (*model.InventoryLevel, *model.StockAlert, error) {
+		require.Equal(s.T(), model.StockMovementAdjustment, m.Kind)
+		require.Equal(s.T(), int64(-5), m.Quantity)
+		return nil, nil, model.ErrInsufficientStock
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleAdjustInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/adjustments", stockBody(variantId, locationId, "-5"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Release_Inventory_Invalid_Quantity() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	s.expectStockOwnership(merchantId, variantId, locationId)
+	s.db.EXPECT().ApplyStockMovement(gomock.Any()).Return(nil, nil, model.ErrInvalidQuantity)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReleaseInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/releases", stockBody(variantId, locationId, "3"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Reserve_Inventory_Invalid() {
+	merchantId := uuid.New().String()
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReserveInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/reservations", stockBody(uuid.New().String(), "x", "0"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Adjust_Inventory_Of_Another_Merchants_Variant() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	s.db.EXPECT().ReadProductVariantById(variantId).Return(&model.ProductVariant{Model: model.Model{ID: variantId}, MerchantID: uuid.New().String()}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleAdjustInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/adjustments", stockBody(variantId, locationId, "5"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Adjust_Inventory_At_Another_Merchants_Location() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	s.db.EXPECT().ReadProductVariantById(variantId).Return(&model.ProductVariant{Model: model.Model{ID: variantId}, MerchantID: merchantId}, nil)
+	s.db.EXPECT().ReadLocationById(locationId).Return(&model.Location{Model: model.Model{ID: locationId}, MerchantID: uuid.New().String()}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleAdjustInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/adjustments", stockBody(variantId, locationId, "5"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Reserve_Inventory_Unknown_Variant() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	s.db.EXPECT().ReadProductVariantById(variantId).Return(nil, gorm.ErrRecordNotFound)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReserveInventory(rr, newMerchantRequest(http.MethodPost, "/inventory/reservations", stockBody(variantId, locationId, "1"), merchantId, ""))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Inventory_Threshold() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	body := `{"variantID": "` + variantId + `", "locationID": "` + locationId + `", "threshold": 5}`
+
+	s.expectStockOwnership(merchantId, variantId, locationId)
+	s.db.EXPECT().UpdateInventoryThreshold(merchantId, variantId, locationId, int64(5)).Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateInventoryThreshold(rr, newMerchantRequest(http.MethodPut, "/inventory/thresholds", body, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Inventory_Threshold_Of_Another_Merchant() {
+	merchantId, variantId, locationId := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	body := `{"variantID": "` + variantId + `", "locationID": "` + locationId + `", "threshold": 5}`
+
+	s.db.EXPECT().ReadProductVariantById(variantId).Return(&model.ProductVariant{Model: model.Model{ID: variantId}, MerchantID: uuid.New().String()}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateInventoryThreshold(rr, newMerchantRequest(http.MethodPut, "/inventory/thresholds", body, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Inventory_Threshold_Negative() {
+	merchantId := uuid.New().String()
+	body := `{"variantID": "` + uuid.New().String() + `", "locationID": "` + uuid.New().String() + `", "threshold": -1}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateInventoryThreshold(rr, newMerchantRequest(http.MethodPut, "/inventory/thresholds", body, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
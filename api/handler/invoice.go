@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"merchant/blob"
+	"merchant/invoice"
+	"merchant/model"
+	"merchant/repository"
+)
+
+// ListInvoice godoc
+// @Summary List invoices
+// @Description get the invoices and receipts of the merchant, newest first; the total number is in the X-Total-Count header
+// @tags invoices
+// @Produce  json
+// @Param limit query integer false "Page size, 1 to 100" default(20)
+// @Param offset query integer false "Number of invoices to skip" default(0)
+// @Success 200 {array} model.InvoiceDtos
+// @Header 200 {string} Token "qwerty"
+// @Header 200 {integer} X-Total-Count "Number of invoices"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /invoices [get]
+func (srv *Server) HandleListInvoice(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	invoices, total, err := srv.DB.ListInvoicesByMerchantId(merchantId, limit, offset)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	if len(invoices) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := invoices.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreateInvoice godoc
+// @Summary Create invoice
+// @Description issue the invoice of a placed order, or a receipt if it is already paid, numbered in sequence per merchant
+// @tags invoices
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Success 201 {object} model.InvoiceDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /orders/{id}/invoice [post]
+func (srv *Server) HandleCreateInvoice(w http.ResponseWriter, r *http.Request) {
+	order, ok := srv.readOwnedOrder(w, r)
+	if !ok {
+		return
+	}
+
+	if !order.IsInvoiceable() {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotInvoiceable)
+		return
+	}
+
+	merchant, err := srv.DB.ReadMerchantById(order.MerchantID)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	inv := model.NewInvoice(uuid.New().String(), order, merchant, srv.readLogo(r.Context(), merchant), time.Now())
+	if err := srv.DB.CreateInvoice(inv); err != nil {
+		if err == repository.ErrDuplicate {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderAlreadyInvoiced)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	// The invoice is issued either way; documents which fail to render now
+	// are rendered from its snapshot when they are first downloaded.
+	if err := srv.renderInvoice(r.Context(), inv); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Invoice created: %s", inv.ID))
+	w.WriteHeader(http.StatusCreated)
+
+	dto := inv.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+		return
+	}
+}
+
+// ReadInvoice godoc
+// @Summary Read invoice
+// @Description get an invoice or receipt
+// @tags invoices
+// @Produce  json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} model.InvoiceDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /invoices/{id} [get]
+func (srv *Server) HandleReadInvoice(w http.ResponseWriter, r *http.Request) {
+	inv, ok := srv.readOwnedInvoice(w, r)
+	if !ok {
+		return
+	}
+
+	dto := inv.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// ReadInvoicePdf godoc
+// @Summary Download invoice PDF
+// @Description download an invoice or receipt as a PDF document
+// @tags invoices
+// @Produce  application/pdf
+// @Param id path string true "Invoice ID"
+// @Success 200 {file} file
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /invoices/{id}.pdf [get]
+func (srv *Server) HandleReadInvoicePdf(w http.ResponseWriter, r *http.Request) {
+	srv.handleReadInvoiceDocument(w, r, ".pdf", "application/pdf")
+}
+
+// ReadInvoiceHtml godoc
+// @Summary Download invoice HTML
+// @Description download an invoice or receipt as an HTML page
+// @tags invoices
+// @Produce  html
+// @Param id path string true "Invoice ID"
+// @Success 200 {file} file
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /invoices/{id}.html [get]
+func (srv *Server) HandleReadInvoiceHtml(w http.ResponseWriter, r *http.Request) {
+	srv.handleReadInvoiceDocument(w, r, ".html", "text/html; charset=utf-8")
+}
+
+func (srv *Server) handleReadInvoiceDocument(w http.ResponseWriter, r *http.Request, ext, contentType string) {
+	inv, ok := srv.readOwnedInvoice(w, r)
+	if !ok {
+		return
+	}
+
+	key := inv.PdfKey
+	if ext == ".html" {
+		key = inv.HtmlKey
+	}
+
+	var (
+		rc  io.ReadCloser
+		err = blob.ErrNotFound
+	)
+	if key != "" {
+		rc, err = srv.Blob.Get(r.Context(), key)
+	}
+	if err == blob.ErrNotFound {
+		if err = srv.renderInvoice(r.Context(), inv); err == nil {
+			rc, err = srv.Blob.Get(r.Context(), inv.DocumentKey(ext))
+		}
+	}
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDocumentRenderFailure)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s%s"`, inv.DisplayNumber(), ext))
+
+	if _, err := io.Copy(w, rc); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+// renderInvoice renders the PDF and HTML documents of the invoice from its
+// snapshot, stores them and records their keys. Invoices are never rendered
+// from the merchant and the order as they are now, which may have changed
+// since the invoice was issued.
+func (srv *Server) renderInvoice(ctx context.Context, inv *model.Invoice) error {
+	if inv.Snapshot == nil {
+		return fmt.Errorf("invoice %s has no snapshot to render", inv.ID)
+	}
+
+	doc := &invoice.Document{
+		Receipt:       inv.Receipt,
+		Number:        inv.DisplayNumber(),
+		IssuedAt:      inv.IssuedAt,
+		BusinessName:  inv.Snapshot.BusinessName,
+		Logo:          srv.decodeLogo(inv.Snapshot.Logo),
+		CustomerName:  inv.CustomerName,
+		CustomerEmail: inv.CustomerEmail,
+		Currency:      inv.Currency,
+		Note:          inv.Snapshot.Note,
+		Lines:         make([]*invoice.Line, len(inv.Snapshot.Lines)),
+		Subtotal:      inv.Subtotal,
+		DiscountTotal: inv.DiscountTotal,
+		TaxTotal:      inv.TaxTotal,
+		Total:         inv.Total,
+	}
+	for k, line := range inv.Snapshot.Lines {
+		doc.Lines[k] = &invoice.Line{
+			Description: line.Description,
+			SKU:         line.SKU,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Discount:    line.Discount,
+			Tax:         line.Tax,
+			Total:       line.Total,
+		}
+	}
+
+	pdf, html := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := invoice.RenderPDF(pdf, doc); err != nil {
+		return err
+	}
+	if err := invoice.RenderHTML(html, doc); err != nil {
+		return err
+	}
+
+	pdfKey, htmlKey := inv.DocumentKey(".pdf"), inv.DocumentKey(".html")
+	if err := srv.Blob.Put(ctx, pdfKey, pdf); err != nil {
+		return err
+	}
+	if err := srv.Blob.Put(ctx, htmlKey, html); err != nil {
+		return err
+	}
+
+	if err := srv.DB.UpdateInvoiceDocumentsById(inv.ID, pdfKey, htmlKey); err != nil {
+		return err
+	}
+	inv.PdfKey, inv.HtmlKey = pdfKey, htmlKey
+
+	return nil
+}
+
+// readLogo returns the logo thumbnail of the merchant, or nil when there is
+// none. A logo which can not be read is left off the invoice.
+func (srv *Server) readLogo(ctx context.Context, merchant *model.Merchant) []byte {
+	if merchant.LogoThumbnailKey == "" {
+		return nil
+	}
+
+	rc, err := srv.Blob.Get(ctx, merchant.LogoThumbnailKey)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+		return nil
+	}
+	defer rc.Close()
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+		return nil
+	}
+
+	return b
+}
+
+// decodeLogo decodes the logo of an invoice, or returns nil when there is
+// none. A logo which can not be decoded is left off the document.
+func (srv *Server) decodeLogo(b []byte) image.Image {
+	if len(b) == 0 {
+		return nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+		return nil
+	}
+
+	return img
+}
+
+// readOwnedInvoice reads the invoice in the URL and writes a not found
+// response when it does not exist or belongs to another merchant.
+func (srv *Server) readOwnedInvoice(w http.ResponseWriter, r *http.Request) (*model.Invoice, bool) {
+	id := chi.URLParam(r, "id")
+
+	inv, err := srv.DB.ReadInvoiceById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if inv.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return inv, true
+}
@@ -0,0 +1,143 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/blob/fsblob"
+	"merchant/model"
+)
+
+func (s *Suite) Test_handler_Create_Invoice() {
+	dir, err := ioutil.TempDir("", "invoices")
+	require.NoError(s.T(), err)
+	defer os.RemoveAll(dir)
+
+	s.server.Blob, err = fsblob.New(dir)
+	require.NoError(s.T(), err)
+
+	merchantId := uuid.New().String()
+	order := placedOrder(merchantId)
+	order.Items = []*model.OrderItem{{Name: "T-shirt", SKU: "TS-M", UnitPrice: 2500, Quantity: 1, Subtotal: 2500, Total: 2500}}
+	merchant := &model.Merchant{Model: model.Model{ID: merchantId}, BusinessName: "Corner Shop"}
+
+	var issued *model.Invoice
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(merchant, nil)
+	s.db.EXPECT().CreateInvoice(gomock.Any()).DoAndReturn(func(i *model.Invoice) error {
+		require.Equal(s.T(), order.ID, i.OrderID)
+		require.False(s.T(), i.Receipt)
+		require.Equal(s.T(), "Corner Shop", i.Snapshot.BusinessName)
+		require.Equal(s.T(), "T-shirt", i.Snapshot.Lines[0].Description)
+		i.Number = 7
+		issued = i
+		return nil
+	})
+	s.db.EXPECT().UpdateInvoiceDocumentsById(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(id, pdfKey, htmlKey string) error {
+			require.Equal(s.T(), "private/invoices/"+merchantId+"/"+id+".pdf", pdfKey)
+			require.Equal(s.T(), "private/invoices/"+merchantId+"/"+id+".html", htmlKey)
+			return nil
+		})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateInvoice(rr, newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/invoice", "", merchantId, order.ID))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	dto := &model.InvoiceDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), "INV-000007", dto.Number)
+	require.Equal(s.T(), "/api/v1/invoices/"+dto.ID+".pdf", dto.PdfURL)
+
+	s.db.EXPECT().ReadInvoiceById(dto.ID).Return(issued, nil)
+
+	rr = httptest.NewRecorder()
+	s.server.HandleReadInvoicePdf(rr, newMerchantRequest(http.MethodGet, "/invoices/"+dto.ID+".pdf", "", merchantId, dto.ID))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), "application/pdf", rr.Header().Get("Content-Type"))
+	require.True(s.T(), bytes.HasPrefix(rr.Body.Bytes(), []byte("%PDF-")))
+}
+
+func (s *Suite) Test_handler_Read_Invoice_Pdf_Renders_Snapshot() {
+	dir, err := ioutil.TempDir("", "invoices")
+	require.NoError(s.T(), err)
+	defer os.RemoveAll(dir)
+
+	s.server.Blob, err = fsblob.New(dir)
+	require.NoError(s.T(), err)
+
+	merchantId := uuid.New().String()
+	invoice := &model.Invoice{
+		Model:      model.Model{ID: uuid.New().String()},
+		MerchantID: merchantId,
+		Number:     7,
+		Snapshot: &model.InvoiceSnapshot{
+			BusinessName: "Corner Shop",
+			Lines:        []*model.InvoiceLine{{Description: "T-shirt", Quantity: 1, UnitPrice: 2500, Total: 2500}},
+		},
+	}
+
+	// Neither the merchant nor the order is read again.
+	s.db.EXPECT().ReadInvoiceById(invoice.ID).Return(invoice, nil)
+	s.db.EXPECT().UpdateInvoiceDocumentsById(invoice.ID,
+		"private/invoices/"+merchantId+"/"+invoice.ID+".pdf", "private/invoices/"+merchantId+"/"+invoice.ID+".html").Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadInvoicePdf(rr, newMerchantRequest(http.MethodGet, "/invoices/"+invoice.ID+".pdf", "", merchantId, invoice.ID))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.True(s.T(), bytes.HasPrefix(rr.Body.Bytes(), []byte("%PDF-")))
+}
+
+func (s *Suite) Test_handler_Read_Invoice_Pdf_Without_Snapshot() {
+	merchantId := uuid.New().String()
+	invoice := &model.Invoice{
+		Model:      model.Model{ID: uuid.New().String()},
+		MerchantID: merchantId,
+		Number:     7,
+	}
+
+	s.db.EXPECT().ReadInvoiceById(invoice.ID).Return(invoice, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadInvoicePdf(rr, newMerchantRequest(http.MethodGet, "/invoices/"+invoice.ID+".pdf", "", merchantId, invoice.ID))
+
+	require.Equal(s.T(), http.StatusInternalServerError, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Invoice_For_Draft_Order() {
+	merchantId := uuid.New().String()
+	order := placedOrder(merchantId)
+	order.State = model.OrderStateDraft
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateInvoice(rr, newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/invoice", "", merchantId, order.ID))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Read_Invoice_Pdf_Of_Other_Merchant() {
+	invoice := &model.Invoice{
+		Model:      model.Model{ID: uuid.New().String()},
+		MerchantID: uuid.New().String(),
+	}
+
+	s.db.EXPECT().ReadInvoiceById(invoice.ID).Return(invoice, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadInvoicePdf(rr, newMerchantRequest(http.MethodGet, "/invoices/"+invoice.ID+".pdf", "", uuid.New().String(), invoice.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// ListLocation godoc
+// @Summary List locations
+// @Description get the store locations of the merchant
+// @Produce  json
+// @Success 200 {array} model.LocationDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /locations [get]
+func (srv *Server) HandleListLocation(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	locations, err := srv.DB.ListLocationsByMerchantId(merchantId)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(locations) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := locations.ToDto(time.Now())
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreateLocation godoc
+// @Summary Create location
+// @Description add a store location to the merchant
+// @Accept  json
+// @Param body body model.LocationForm true "Create a location"
+// @Success 201 {string} string "created"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /locations [post]
+func (srv *Server) HandleCreateLocation(w http.ResponseWriter, r *http.Request) {
+	form := &model.LocationForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	if !form.HasCompleteHolidayHours() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrHolidayHoursMissing)
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	location := form.ToModel(merchantId)
+
+	if err := srv.DB.CreateLocation(location); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Location created: %s", location.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// ReadLocation godoc
+// @Summary Read location
+// @Description get a store location, including whether it is open now
+// @Produce  json
+// @Param id path string true "Location ID"
+// @Success 200 {object} model.LocationDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /locations/{id} [get]
+func (srv *Server) HandleReadLocation(w http.ResponseWriter, r *http.Request) {
+	location, ok := srv.readOwnedLocation(w, r)
+	if !ok {
+		return
+	}
+
+	dto := location.ToDto(time.Now())
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// UpdateLocation godoc
+// @Summary Update location
+// @Description update a store location, replacing its opening hours and holiday exceptions
+// @Accept  json
+// @Param body body model.LocationForm true "Update a location"
+// @Param id path string true "Location ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /locations/{id} [put]
+func (srv *Server) HandleUpdateLocation(w http.ResponseWriter, r *http.Request) {
+	form := &model.LocationForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	if !form.HasCompleteHolidayHours() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrHolidayHoursMissing)
+		return
+	}
+
+	existing, ok := srv.readOwnedLocation(w, r)
+	if !ok {
+		return
+	}
+
+	location := form.ToModelWithId(existing.ID, existing.MerchantID)
+
+	if err := srv.DB.UpdateLocationById(existing.ID, location); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeleteLocation godoc
+// @Summary Delete location
+// @Description delete a store location
+// @Param id path string true "Location ID"
+// @Success 200 {string} string	"ok"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /locations/{id} [delete]
+func (srv *Server) HandleDeleteLocation(w http.ResponseWriter, r *http.Request) {
+	location, ok := srv.readOwnedLocation(w, r)
+	if !ok {
+		return
+	}
+
+	if err := srv.DB.DeleteLocation(location.ID); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDeleteFailure)
+		return
+	}
+}
+
+// readOwnedLocation reads the location in the URL and writes a not found
+// response when it does not exist or belongs to another merchant.
+func (srv *Server) readOwnedLocation(w http.ResponseWriter, r *http.Request) (*model.Location, bool) {
+	id := chi.URLParam(r, "id")
+
+	location, err := srv.DB.ReadLocationById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if location.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return location, true
+}
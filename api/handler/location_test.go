@@ -0,0 +1,136 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+const locationBody = `{"name": "Soho", "addressLine1": "1 Dean Street", "city": "London", "countryCode": "GB", "latitude": 51.51, "longitude": -0.13, "timezone": "Europe/London", "openingHours": [{"weekday": 1, "opens": "09:00", "closes": "17:30"}]}`
+
+func ownedLocation(merchantId string) *model.Location {
+	return &model.Location{
+		Model:      model.Model{ID: uuid.New().String()},
+		MerchantID: merchantId,
+		Name:       "Soho",
+		Timezone:   "Europe/London",
+	}
+}
+
+func (s *Suite) Test_handler_Create_Location() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().CreateLocation(gomock.Any()).DoAndReturn(func(l *model.Location) error {
+		require.Equal(s.T(), merchantId, l.MerchantID)
+		require.Equal(s.T(), "Soho", l.Name)
+		require.Len(s.T(), l.OpeningHours, 1)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateLocation(rr, newMerchantRequest(http.MethodPost, "/locations", locationBody, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Location_Invalid() {
+	body := `{"name": "Soho", "addressLine1": "1 Dean Street", "city": "London", "countryCode": "GB", "latitude": 91, "longitude": -0.13, "timezone": "Europe/Soho"}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateLocation(rr, newMerchantRequest(http.MethodPost, "/locations", body, uuid.New().String(), ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Location_Holiday_Hours_Missing() {
+	body := `{"name": "Soho", "addressLine1": "1 Dean Street", "city": "London", "countryCode": "GB", "latitude": 51.51, "longitude": -0.13, "timezone": "Europe/London", "holidayExceptions": [{"date": "2026-12-24", "opens": "10:00"}]}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateLocation(rr, newMerchantRequest(http.MethodPost, "/locations", body, uuid.New().String(), ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Read_Location() {
+	merchantId := uuid.New().String()
+	location := ownedLocation(merchantId)
+
+	s.db.EXPECT().ReadLocationById(location.ID).Return(location, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadLocation(rr, newMerchantRequest(http.MethodGet, "/locations/"+location.ID, "", merchantId, location.ID))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dto := &model.LocationDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), location.ID, dto.ID)
+}
+
+func (s *Suite) Test_handler_Read_Location_Of_Another_Merchant() {
+	location := ownedLocation(uuid.New().String())
+
+	s.db.EXPECT().ReadLocationById(location.ID).Return(location, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadLocation(rr, newMerchantRequest(http.MethodGet, "/locations/"+location.ID, "", uuid.New().String(), location.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Read_Location_Not_Found() {
+	id := uuid.New().String()
+
+	s.db.EXPECT().ReadLocationById(id).Return(nil, gorm.ErrRecordNotFound)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadLocation(rr, newMerchantRequest(http.MethodGet, "/locations/"+id, "", uuid.New().String(), id))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Location() {
+	merchantId := uuid.New().String()
+	location := ownedLocation(merchantId)
+
+	s.db.EXPECT().ReadLocationById(location.ID).Return(location, nil)
+	s.db.EXPECT().UpdateLocationById(location.ID, gomock.Any()).DoAndReturn(func(_ string, l *model.Location) error {
+		require.Equal(s.T(), location.ID, l.ID)
+		require.Equal(s.T(), merchantId, l.MerchantID)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateLocation(rr, newMerchantRequest(http.MethodPut, "/locations/"+location.ID, locationBody, merchantId, location.ID))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Location_Of_Another_Merchant() {
+	location := ownedLocation(uuid.New().String())
+
+	s.db.EXPECT().ReadLocationById(location.ID).Return(location, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateLocation(rr, newMerchantRequest(http.MethodPut, "/locations/"+location.ID, locationBody, uuid.New().String(), location.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Delete_Location_Of_Another_Merchant() {
+	location := ownedLocation(uuid.New().String())
+
+	s.db.EXPECT().ReadLocationById(location.ID).Return(location, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDeleteLocation(rr, newMerchantRequest(http.MethodDelete, "/locations/"+location.ID, "", uuid.New().String(), location.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
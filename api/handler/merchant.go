@@ -9,6 +9,7 @@ import (
 	"gorm.io/gorm"
 
 	"merchant/model"
+	"merchant/repository"
 )
 
 // ListMerchant godoc
@@ -16,14 +17,20 @@ import (
 // @Description get merchant list
 // @Produce  json
 // @Param q query string false "name search by q"
+// @Param metadata[key] query string false "only merchants whose metadata holds the value at the key; repeatable with other keys"
 // @Success 200 {array} model.MerchantDtos
 // @Header 200 {string} Token "qwerty"
 // @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /merchants [get]
 func (srv *Server) HandleListMerchant(w http.ResponseWriter, r *http.Request) {
+	metadata, ok := parseMetadataFilter(w, r)
+	if !ok {
+		return
+	}
 
-	merchants, err := srv.DB.ListMerchants()
+	merchants, err := srv.DB.ListMerchants(metadata)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
@@ -51,6 +58,7 @@ func (srv *Server) HandleListMerchant(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Merchant ID"
 // @Success 200 {object} model.Merchant
 // @Header 200 {string} Token "qwerty"
+// @Header 200 {string} ETag "version of the merchant"
 // @Failure 400,404 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /merchants/{id} [get]
@@ -71,6 +79,8 @@ func (srv *Server) HandleReadMerchant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", etag(merchant.Version))
+
 	dto := merchant.ToDto()
 	if err := json.NewEncoder(w).Encode(dto); err != nil {
 		srv.Logger.Warn(err.Error())
@@ -85,9 +95,10 @@ func (srv *Server) HandleReadMerchant(w http.ResponseWriter, r *http.Request) {
 // @Summary Update merchant
 // @Description update merchant
 // @Param id path string true "Merchant ID"
+// @Param If-Match header string false "ETag of the merchant as read"
 // @Success 200 {string} string	"accepted"
 // @Header 200 {string} Token "qwerty"
-// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 400,404,409,412,428 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /merchants/{id} [put]
 func (srv *Server) HandleUpdateMerchant(w http.ResponseWriter, r *http.Request) {
@@ -105,7 +116,8 @@ func (srv *Server) HandleUpdateMerchant(w http.ResponseWriter, r *http.Request)
 
 	id := chi.URLParam(r, "id")
 
-	if _, err := srv.DB.ReadMerchantById(id); err != nil {
+	merchant, err := srv.DB.ReadMerchantById(id)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			return
@@ -118,7 +130,28 @@ func (srv *Server) HandleUpdateMerchant(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := srv.DB.UpdateMerchantDescriptionById(id, form.Description); err != nil {
+	if !srv.checkIfMatch(w, r, merchant.Version) {
+		return
+	}
+
+	merchant.Description = form.Description
+	if form.Metadata != nil {
+		merchant.Metadata = form.Metadata
+	}
+
+	err = srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.UpdateMerchantById(id, merchant.Version, merchant); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantUpdated, merchant))
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			writeVersionConflict(w, r)
+			return
+		}
+
 		srv.Logger.Warn(err.Error())
 
 		w.WriteHeader(http.StatusInternalServerError)
@@ -131,35 +164,14 @@ func (srv *Server) HandleUpdateMerchant(w http.ResponseWriter, r *http.Request)
 
 // DeleteMerchant godoc
 // @Summary Delete merchant
-// @Description delete a merchant
+// @Description request the closure of the merchant account, like POST /merchants/{id}/closure. The account is erased once the grace period ends.
 // @Param id path string true "Merchant ID"
-// @Success 200 {string} string	"ok"
+// @Param If-Match header string false "ETag of the merchant as read"
+// @Success 202 {object} model.MerchantClosureDto
 // @Header 200 {string} Token "qwerty"
-// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 400,403,404,409,412,428 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /merchants/{id} [delete]
 func (srv *Server) HandleDeleteMerchant(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-
-	if _, err := srv.DB.ReadMerchantById(id); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-
-		srv.Logger.Warn(err.Error())
-
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
-		return
-	}
-
-	if err := srv.DB.DeleteMerchant(id); err != nil {
-		srv.Logger.Warn(err.Error())
-
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDeleteFailure)
-		return
-	}
-
+	srv.HandleRequestMerchantClosure(w, r)
 }
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// RequestMerchantClosure godoc
+// @Summary Request merchant closure
+// @Description close the account of the merchant. Its data is erased once the grace period ends, unless the closure is cancelled before; orders, payments and invoices are kept for bookkeeping without the contact details of customers. Requesting a pending closure again changes nothing.
+// @tags merchants
+// @Produce  json
+// @Param id path string true "Merchant ID"
+// @Param If-Match header string false "ETag of the merchant as read"
+// @Success 202 {object} model.MerchantClosureDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404,409,412,428 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/closure [post]
+func (srv *Server) HandleRequestMerchantClosure(w http.ResponseWriter, r *http.Request) {
+	merchant, ok := srv.readOwnMerchant(w, r)
+	if !ok {
+		return
+	}
+
+	if !srv.checkIfMatch(w, r, merchant.Version) {
+		return
+	}
+
+	if merchant.ClosesAt == nil {
+		now := time.Now()
+		closesAt := now.Add(srv.ClosureGracePeriod)
+		merchant.ClosureRequestedAt = &now
+		merchant.ClosesAt = &closesAt
+
+		err := srv.DB.Transaction(func(tx repository.Repository) error {
+			if err := tx.RequestMerchantClosure(merchant.ID, merchant.Version, now, closesAt); err != nil {
+				return err
+			}
+
+			return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantUpdated, merchant))
+		})
+		if err != nil {
+			if err == repository.ErrConflict {
+				writeVersionConflict(w, r)
+				return
+			}
+
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+			return
+		}
+		merchant.Version++
+	}
+
+	w.Header().Set("ETag", etag(merchant.Version))
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(merchant.ClosureToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+// ReadMerchantClosure godoc
+// @Summary Read merchant closure
+// @Description get the pending closure of the merchant and when its data is erased
+// @tags merchants
+// @Produce  json
+// @Param id path string true "Merchant ID"
+// @Success 200 {object} model.MerchantClosureDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/closure [get]
+func (srv *Server) HandleReadMerchantClosure(w http.ResponseWriter, r *http.Request) {
+	merchant, ok := srv.readOwnMerchant(w, r)
+	if !ok {
+		return
+	}
+
+	if merchant.ClosesAt == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("ETag", etag(merchant.Version))
+	if err := json.NewEncoder(w).Encode(merchant.ClosureToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CancelMerchantClosure godoc
+// @Summary Cancel merchant closure
+// @Description keep the account of the merchant open, as long as the grace period of its closure has not ended
+// @tags merchants
+// @Param id path string true "Merchant ID"
+// @Param If-Match header string false "ETag of the merchant as read"
+// @Success 204 {string} string	"ok"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404,409,412,428 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/closure [delete]
+func (srv *Server) HandleCancelMerchantClosure(w http.ResponseWriter, r *http.Request) {
+	merchant, ok := srv.readOwnMerchant(w, r)
+	if !ok {
+		return
+	}
+
+	if merchant.ClosesAt == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	if !merchant.ClosesAt.After(now) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrClosureGracePeriodOver)
+		return
+	}
+
+	if !srv.checkIfMatch(w, r, merchant.Version) {
+		return
+	}
+
+	merchant.ClosureRequestedAt = nil
+	merchant.ClosesAt = nil
+
+	err := srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.CancelMerchantClosure(merchant.ID, merchant.Version, now); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantUpdated, merchant))
+	})
+	if err != nil {
+		switch err {
+		case repository.ErrConflict:
+			writeVersionConflict(w, r)
+			return
+		case model.ErrClosureGracePeriodOver:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrClosureGracePeriodOver)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
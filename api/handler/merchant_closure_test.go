@@ -0,0 +1,119 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_handler_Request_Merchant_Closure() {
+	merchantId := uuid.New().String()
+	merchant := &model.Merchant{Model: model.Model{ID: merchantId, Version: 2}}
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(merchant, nil)
+	s.expectTransaction()
+	s.db.EXPECT().RequestMerchantClosure(merchantId, int64(2), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ string, _ int64, requestedAt, closesAt time.Time) error {
+			require.Equal(s.T(), model.DefaultClosureGracePeriod, closesAt.Sub(requestedAt))
+			return nil
+		})
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).DoAndReturn(func(e *model.OutboxEvent) error {
+		require.Equal(s.T(), model.EventMerchantUpdated, e.Type)
+		require.Contains(s.T(), e.Payload, `"closesAt"`)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDeleteMerchant(rr, newMerchantRequest(http.MethodDelete, "/merchants/"+merchantId, "", merchantId, merchantId))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+	require.Equal(s.T(), `"3"`, rr.Header().Get("ETag"))
+
+	dto := &model.MerchantClosureDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.NotNil(s.T(), dto.ClosesAt)
+	require.WithinDuration(s.T(), time.Now().Add(model.DefaultClosureGracePeriod), *dto.ClosesAt, time.Minute)
+}
+
+func (s *Suite) Test_handler_Request_Merchant_Closure_Pending() {
+	merchantId := uuid.New().String()
+	closesAt := time.Now().Add(time.Hour)
+	merchant := &model.Merchant{Model: model.Model{ID: merchantId, Version: 3}, ClosesAt: &closesAt}
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(merchant, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRequestMerchantClosure(rr, newMerchantRequest(http.MethodPost, "/merchants/"+merchantId+"/closure", "", merchantId, merchantId))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+	require.Equal(s.T(), `"3"`, rr.Header().Get("ETag"))
+}
+
+func (s *Suite) Test_handler_Request_Merchant_Closure_Of_Another_Merchant() {
+	rr := httptest.NewRecorder()
+	s.server.HandleRequestMerchantClosure(rr, newMerchantRequest(http.MethodPost, "/merchants/x/closure", "", uuid.New().String(), uuid.New().String()))
+
+	require.Equal(s.T(), http.StatusForbidden, rr.Code)
+}
+
+func (s *Suite) Test_handler_Cancel_Merchant_Closure() {
+	merchantId := uuid.New().String()
+	closesAt := time.Now().Add(time.Hour)
+	merchant := &model.Merchant{Model: model.Model{ID: merchantId, Version: 3}, ClosesAt: &closesAt}
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(merchant, nil)
+	s.expectTransaction()
+	s.db.EXPECT().CancelMerchantClosure(merchantId, int64(3), gomock.Any()).Return(nil)
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCancelMerchantClosure(rr, newMerchantRequest(http.MethodDelete, "/merchants/"+merchantId+"/closure", "", merchantId, merchantId))
+
+	require.Equal(s.T(), http.StatusNoContent, rr.Code)
+}
+
+func (s *Suite) Test_handler_Cancel_Merchant_Closure_Grace_Period_Over() {
+	merchantId := uuid.New().String()
+	closesAt := time.Now().Add(-time.Minute)
+	merchant := &model.Merchant{Model: model.Model{ID: merchantId, Version: 3}, ClosesAt: &closesAt}
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(merchant, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCancelMerchantClosure(rr, newMerchantRequest(http.MethodDelete, "/merchants/"+merchantId+"/closure", "", merchantId, merchantId))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Cancel_Merchant_Closure_Grace_Period_Ended_Concurrently() {
+	merchantId := uuid.New().String()
+	closesAt := time.Now().Add(time.Hour)
+	merchant := &model.Merchant{Model: model.Model{ID: merchantId, Version: 3}, ClosesAt: &closesAt}
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(merchant, nil)
+	s.expectTransaction()
+	s.db.EXPECT().CancelMerchantClosure(merchantId, int64(3), gomock.Any()).Return(model.ErrClosureGracePeriodOver)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCancelMerchantClosure(rr, newMerchantRequest(http.MethodDelete, "/merchants/"+merchantId+"/closure", "", merchantId, merchantId))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Cancel_Merchant_Closure_Not_Requested() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(&model.Merchant{Model: model.Model{ID: merchantId}}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCancelMerchantClosure(rr, newMerchantRequest(http.MethodDelete, "/merchants/"+merchantId+"/closure", "", merchantId, merchantId))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
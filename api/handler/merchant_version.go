@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// ListMerchantVersion godoc
+// @Summary List merchant versions
+// @Description get the versions of the merchant, newest first. Each write of the merchant records a version.
+// @tags merchants
+// @Produce  json
+// @Param id path string true "Merchant ID"
+// @Param at query string false "only the versions before this date or RFC 3339 date-time, the first being the merchant as it was then"
+// @Param limit query int false "page size, 20 by default and at most 100"
+// @Param offset query int false "number of versions to skip"
+// @Success 200 {array} model.MerchantVersionDtos
+// @Header 200 {string} X-Total-Count "number of matching versions"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/versions [get]
+func (srv *Server) HandleListMerchantVersion(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	at, err := parseDateParam(r, "at", true)
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrInvalidDate)
+		return
+	}
+
+	merchant, ok := srv.readOwnMerchant(w, r)
+	if !ok {
+		return
+	}
+
+	versions, total, err := srv.DB.ListMerchantVersions(merchant.ID, at, limit, offset)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	if len(versions) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := versions.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// ReadMerchantVersion godoc
+// @Summary Read merchant version
+// @Description get the merchant as it was at a version
+// @tags merchants
+// @Produce  json
+// @Param id path string true "Merchant ID"
+// @Param version path int true "Version"
+// @Success 200 {object} model.MerchantVersionDto
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/versions/{version} [get]
+func (srv *Server) HandleReadMerchantVersion(w http.ResponseWriter, r *http.Request) {
+	merchant, ok := srv.readOwnMerchant(w, r)
+	if !ok {
+		return
+	}
+
+	version, ok := srv.readMerchantVersion(w, r, merchant.ID)
+	if !ok {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(version.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// RestoreMerchantVersion godoc
+// @Summary Restore merchant version
+// @Description set the business name, description and metadata of the merchant back to those of a version, which records another version. Logos and banners are not restored, as the images replaced since are deleted.
+// @tags merchants
+// @Produce  json
+// @Param id path string true "Merchant ID"
+// @Param version path int true "Version to restore"
+// @Param If-Match header string false "ETag of the merchant as read"
+// @Success 202 {object} model.MerchantDto
+// @Header 202 {string} ETag "version of the merchant"
+// @Failure 400,403,404,409,412,428 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /merchants/{id}/versions/{version}/restore [post]
+func (srv *Server) HandleRestoreMerchantVersion(w http.ResponseWriter, r *http.Request) {
+	merchant, ok := srv.readOwnMerchant(w, r)
+	if !ok {
+		return
+	}
+
+	version, ok := srv.readMerchantVersion(w, r, merchant.ID)
+	if !ok {
+		return
+	}
+
+	if !srv.checkIfMatch(w, r, merchant.Version) {
+		return
+	}
+
+	merchant.BusinessName = version.BusinessName
+	merchant.Description = version.Description
+	merchant.Metadata = version.Metadata.ToDto()
+
+	err := srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.RestoreMerchantById(merchant.ID, merchant.Version, version); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantUpdated, merchant))
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			writeVersionConflict(w, r)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+	merchant.Version++
+
+	w.Header().Set("ETag", etag(merchant.Version))
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(merchant.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+// readMerchantVersion reads the version in the URL of the merchant and
+// writes a not found response when it does not exist.
+func (srv *Server) readMerchantVersion(w http.ResponseWriter, r *http.Request, merchantId string) (*model.MerchantVersion, bool) {
+	n, err := strconv.ParseInt(chi.URLParam(r, "version"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	version, err := srv.DB.ReadMerchantVersion(merchantId, n)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	return version, true
+}
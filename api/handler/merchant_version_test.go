@@ -0,0 +1,114 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/repository"
+	"merchant/util/cryptoutil"
+)
+
+// newVersionRequest returns a request of the merchant for the version of
+// the record of the id.
+func newVersionRequest(method, target, merchantId, id, version string) *http.Request {
+	r := newMerchantRequest(method, target, "", merchantId, id)
+	chi.RouteContext(r.Context()).URLParams.Add("version", version)
+
+	return r
+}
+
+func (s *Suite) Test_handler_List_Merchant_Version_At() {
+	merchantId := uuid.New().String()
+	createdAt := time.Date(2026, 8, 14, 9, 30, 0, 0, time.UTC)
+	version := &model.MerchantVersion{MerchantID: merchantId, Version: 2, Description: "Handmade ceramics", CreatedAt: &createdAt}
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(&model.Merchant{Model: model.Model{ID: merchantId, Version: 5}}, nil)
+	s.db.EXPECT().ListMerchantVersions(merchantId, gomock.Any(), 1, 0).
+		DoAndReturn(func(_ string, at *time.Time, _, _ int) (model.MerchantVersions, int64, error) {
+			require.Equal(s.T(), time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), *at)
+			return model.MerchantVersions{version}, 3, nil
+		})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleListMerchantVersion(rr, newMerchantRequest(http.MethodGet, "/merchants/"+merchantId+"/versions?at=2026-08-31&limit=1", "", merchantId, merchantId))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), "3", rr.Header().Get("X-Total-Count"))
+
+	dtos := model.MerchantVersionDtos{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(&dtos))
+	require.Len(s.T(), dtos, 1)
+	require.Equal(s.T(), int64(2), dtos[0].Version)
+	require.Equal(s.T(), "Handmade ceramics", dtos[0].Merchant.Description)
+}
+
+func (s *Suite) Test_handler_Read_Merchant_Version_Of_Another_Merchant() {
+	rr := httptest.NewRecorder()
+	s.server.HandleReadMerchantVersion(rr, newVersionRequest(http.MethodGet, "/merchants/x/versions/2", uuid.New().String(), uuid.New().String(), "2"))
+
+	require.Equal(s.T(), http.StatusForbidden, rr.Code)
+}
+
+func (s *Suite) Test_handler_Read_Merchant_Version_Not_Found() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(&model.Merchant{Model: model.Model{ID: merchantId, Version: 5}}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadMerchantVersion(rr, newVersionRequest(http.MethodGet, "/merchants/"+merchantId+"/versions/latest", merchantId, merchantId, "latest"))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Restore_Merchant_Version() {
+	merchantId := uuid.New().String()
+	merchant := &model.Merchant{Model: model.Model{ID: merchantId, Version: 5}, BusinessName: cryptoutil.EncryptedString("Pots Ltd")}
+	version := &model.MerchantVersion{MerchantID: merchantId, Version: 2, BusinessName: cryptoutil.EncryptedString("Pottery Co"), Description: "Handmade ceramics"}
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(merchant, nil)
+	s.db.EXPECT().ReadMerchantVersion(merchantId, int64(2)).Return(version, nil)
+	s.expectTransaction()
+	s.db.EXPECT().RestoreMerchantById(merchantId, int64(5), version).Return(nil)
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).DoAndReturn(func(e *model.OutboxEvent) error {
+		require.Equal(s.T(), model.EventMerchantUpdated, e.Type)
+		require.Contains(s.T(), e.Payload, `"businessName":"Pottery Co"`)
+		return nil
+	})
+
+	r := newVersionRequest(http.MethodPost, "/merchants/"+merchantId+"/versions/2/restore", merchantId, merchantId, "2")
+	r.Header.Set("If-Match", `"5"`)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRestoreMerchantVersion(rr, r)
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+	require.Equal(s.T(), `"6"`, rr.Header().Get("ETag"))
+
+	dto := &model.MerchantDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), "Pottery Co", dto.BusinessName)
+	require.Equal(s.T(), "Handmade ceramics", dto.Description)
+}
+
+func (s *Suite) Test_handler_Restore_Merchant_Version_Lost_Race() {
+	merchantId := uuid.New().String()
+	version := &model.MerchantVersion{MerchantID: merchantId, Version: 2}
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(&model.Merchant{Model: model.Model{ID: merchantId, Version: 5}}, nil)
+	s.db.EXPECT().ReadMerchantVersion(merchantId, int64(2)).Return(version, nil)
+	s.expectTransaction()
+	s.db.EXPECT().RestoreMerchantById(merchantId, int64(5), version).Return(repository.ErrConflict)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRestoreMerchantVersion(rr, newVersionRequest(http.MethodPost, "/merchants/"+merchantId+"/versions/2/restore", merchantId, merchantId, "2"))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
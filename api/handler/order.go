@@ -0,0 +1,425 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// ListOrder godoc
+// @Summary List orders
+// @Description get the orders of the merchant, newest first; the total number of matches is in the X-Total-Count header
+// @tags orders
+// @Produce  json
+// @Param state query string false "Filter by state" Enums(draft, placed, paid, fulfilled, cancelled, refunded)
+// @Param from query string false "Created at or after, as 2006-01-02 or RFC 3339"
+// @Param to query string false "Created before, as RFC 3339, or on or before, as 2006-01-02"
+// @Param limit query integer false "Page size, 1 to 100" default(20)
+// @Param offset query integer false "Number of orders to skip" default(0)
+// @Success 200 {array} model.OrderDtos
+// @Header 200 {string} Token "qwerty"
+// @Header 200 {integer} X-Total-Count "Number of matching orders"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /orders [get]
+func (srv *Server) HandleListOrder(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	from, err := parseDateParam(r, "from", false)
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrInvalidDate)
+		return
+	}
+	to, err := parseDateParam(r, "to", true)
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrInvalidDate)
+		return
+	}
+
+	search := &model.OrderSearch{
+		State:  r.URL.Query().Get("state"),
+		From:   from,
+		To:     to,
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	orders, total, err := srv.DB.SearchOrders(merchantId, search)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	if len(orders) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := orders.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreateOrder godoc
+// @Summary Create order
+// @Description create a draft order; prices, discounts, taxes and totals are calculated from the catalog
+// @tags orders
+// @Accept  json
+// @Produce  json
+// @Param body body model.OrderForm true "Create an order"
+// @Success 201 {object} model.OrderDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /orders [post]
+func (srv *Server) HandleCreateOrder(w http.ResponseWriter, r *http.Request) {
+	form := &model.OrderForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	products, err := srv.DB.ListProductsByVariantIds(form.VariantIds())
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	order, err := form.ToModel(merchantId, products)
+	if err != nil {
+		srv.writeOrderPricingError(w, err)
+		return
+	}
+
+	if err := srv.DB.CreateOrder(order); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Order created: %s", order.ID))
+	w.WriteHeader(http.StatusCreated)
+
+	dto := order.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+		return
+	}
+}
+
+// ReadOrder godoc
+// @Summary Read order
+// @Description get an order with its lines
+// @tags orders
+// @Produce  json
+// @Param id path string true "Order ID"
+// @Success 200 {object} model.OrderDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /orders/{id} [get]
+func (srv *Server) HandleReadOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := srv.readOwnedOrder(w, r)
+	if !ok {
+		return
+	}
+
+	dto := order.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// UpdateOrder godoc
+// @Summary Update order
+// @Description replace the customer details and lines of a draft order; the discount of a redeemed coupon replaces the discount given, and a new customer must be within the per customer limit of the coupon
+// @tags orders
+// @Accept  json
+// @Param body body model.OrderForm true "Update an order"
+// @Param id path string true "Order ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /orders/{id} [put]
+func (srv *Server) HandleUpdateOrder(w http.ResponseWriter, r *http.Request) {
+	form := &model.OrderForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	existing, ok := srv.readOwnedOrder(w, r)
+	if !ok {
+		return
+	}
+
+	if !existing.IsEditable() {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotEditable)
+		return
+	}
+
+	products, err := srv.DB.ListProductsByVariantIds(form.VariantIds())
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	order, err := form.ToModelWithId(existing.ID, existing.MerchantID, products)
+	if err != nil {
+		srv.writeOrderPricingError(w, err)
+		return
+	}
+
+	// A redeemed coupon stays on the order and prices the new lines; its
+	// redemption follows the order to a new customer, within the limits of
+	// the coupon.
+	if existing.CouponID != "" {
+		var coupon *model.Coupon
+		coupon, err = srv.DB.ReadCouponById(existing.CouponID)
+		if err != nil {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+			return
+		}
+
+		if err := coupon.Apply(order); err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, err)
+			return
+		}
+
+		err = srv.DB.UpdateRedeemedDraftOrder(coupon.NewRedemption("", order), order)
+	} else {
+		err = srv.DB.UpdateDraftOrderById(existing.ID, order)
+	}
+	if err != nil {
+		switch err {
+		case model.ErrCouponCustomerLimit:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, err)
+			return
+		case model.ErrCouponCustomerRequired:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, err)
+			return
+		case repository.ErrConflict:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotEditable)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// UpdateOrderState godoc
+// @Summary Update order state
+// @Description move an order along its lifecycle: draft to placed or cancelled, placed to cancelled, paid to fulfilled. Orders are paid by capturing their payments and refunded by refunding them. Cancelling an order voids its authorized payments.
+// @tags orders
+// @Accept  json
+// @Param body body model.OrderStateForm true "New state"
+// @Param id path string true "Order ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500,502 {object} httputil.HTTPError
+// @Router /orders/{id}/state [put]
+func (srv *Server) HandleUpdateOrderState(w http.ResponseWriter, r *http.Request) {
+	form := &model.OrderStateForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	order, ok := srv.readOwnedOrder(w, r)
+	if !ok {
+		return
+	}
+
+	if !order.CanTransitionTo(form.State) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "cannot move order from %s to %s"}`, order.State, form.State)
+		return
+	}
+
+	if form.State == model.OrderStateCancelled && !srv.voidOrderPayments(w, r, order) {
+		return
+	}
+
+	if err := srv.DB.UpdateOrderStateById(order.ID, order.State, form.State, time.Now()); err != nil {
+		if err == repository.ErrConflict {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataConcurrentUpdate)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// voidOrderPayments voids the authorized payments of an order being
+// cancelled, so that the customer is not charged for it. It writes the
+// response and returns false when a payment is still being authorized or can
+// not be voided.
+func (srv *Server) voidOrderPayments(w http.ResponseWriter, r *http.Request, order *model.Order) bool {
+	intents, err := srv.DB.ListPaymentIntents(order.MerchantID, order.ID)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return false
+	}
+
+	for _, intent := range intents {
+		switch intent.Status {
+		case model.PaymentIntentStatusPending:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrPaymentInProgress)
+			return false
+		case model.PaymentIntentStatusAuthorized:
+			res, err := srv.Payments.Void(r.Context(), intent.ProviderReference)
+			if !srv.handleGatewayResult(w, res, err) {
+				return false
+			}
+
+			updated := *intent
+			updated.Status = model.PaymentIntentStatusVoided
+
+			if !srv.updatePaymentIntent(w, intent, &updated) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// writeOrderPricingError writes the response for an error pricing an order
+// form.
+func (srv *Server) writeOrderPricingError(w http.ResponseWriter, err error) {
+	w.WriteHeader(http.StatusUnprocessableEntity)
+
+	switch err {
+	case model.ErrUnknownVariant:
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrUnknownVariant)
+	case model.ErrCurrencyMismatch:
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrCurrencyMismatch)
+	case model.ErrDiscountTooLarge:
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrDiscountTooLarge)
+	case model.ErrNegativeDiscount:
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrNegativeDiscount)
+	case model.ErrOrderTooLarge:
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrOrderTooLarge)
+	default:
+		fmt.Fprintf(w, `{"error": "%v"}`, err)
+	}
+}
+
+// readOwnedOrder reads the order in the URL and writes a not found response
+// when it does not exist or belongs to another merchant.
+func (srv *Server) readOwnedOrder(w http.ResponseWriter, r *http.Request) (*model.Order, bool) {
+	id := chi.URLParam(r, "id")
+
+	order, err := srv.DB.ReadOrderById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if order.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return order, true
+}
@@ -0,0 +1,132 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/payment/fakepay"
+	"merchant/repository"
+)
+
+func newOrderStateRequest(merchantId, orderId, state string) *http.Request {
+	r := httptest.NewRequest(http.MethodPut, "/orders/"+orderId+"/state", strings.NewReader(`{"state": "`+state+`"}`))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", orderId)
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, model.CtxKeyXUser, model.CtxUser{UserId: uuid.MustParse(merchantId)})
+
+	return r.WithContext(ctx)
+}
+
+func (s *Suite) Test_handler_Create_Order_Quantity_Too_Large() {
+	merchantId := uuid.New().String()
+	body := `{"customerEmail": "jane@example.com", "items": [{"variantID": "` + uuid.New().String() + `", "quantity": 10001}]}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateOrder(rr, newMerchantRequest(http.MethodPost, "/orders", body, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Order_State() {
+	merchantId, orderId := uuid.New().String(), uuid.New().String()
+	order := &model.Order{Model: model.Model{ID: orderId}, MerchantID: merchantId, State: model.OrderStatePlaced}
+
+	order.State = model.OrderStatePaid
+
+	s.db.EXPECT().ReadOrderById(orderId).Return(order, nil)
+	s.db.EXPECT().UpdateOrderStateById(orderId, model.OrderStatePaid, model.OrderStateFulfilled, gomock.Any()).Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateOrderState(rr, newOrderStateRequest(merchantId, orderId, model.OrderStateFulfilled))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Order_State_To_Paid() {
+	merchantId, orderId := uuid.New().String(), uuid.New().String()
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateOrderState(rr, newOrderStateRequest(merchantId, orderId, model.OrderStatePaid))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Order_State_Cancel_Voids_Payment() {
+	merchantId, orderId := uuid.New().String(), uuid.New().String()
+	order := &model.Order{Model: model.Model{ID: orderId}, MerchantID: merchantId, State: model.OrderStatePlaced}
+	authorized := &model.PaymentIntent{
+		Model:             model.Model{ID: uuid.New().String()},
+		MerchantID:        merchantId,
+		OrderID:           orderId,
+		ProviderReference: "fake_pay_1",
+		Status:            model.PaymentIntentStatusAuthorized,
+	}
+	declined := &model.PaymentIntent{Model: model.Model{ID: uuid.New().String()}, Status: model.PaymentIntentStatusFailed}
+
+	gateway := fakepay.New("secret")
+	s.server.Payments = gateway
+
+	s.db.EXPECT().ReadOrderById(orderId).Return(order, nil)
+	s.db.EXPECT().ListPaymentIntents(merchantId, orderId).Return(model.PaymentIntents{authorized, declined}, nil)
+	s.db.EXPECT().UpdatePaymentIntentById(authorized.ID, model.PaymentIntentStatusAuthorized, gomock.Any()).
+		DoAndReturn(func(id, from string, p *model.PaymentIntent) error {
+			require.Equal(s.T(), model.PaymentIntentStatusVoided, p.Status)
+			return nil
+		})
+	s.db.EXPECT().UpdateOrderStateById(orderId, model.OrderStatePlaced, model.OrderStateCancelled, gomock.Any()).Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateOrderState(rr, newOrderStateRequest(merchantId, orderId, model.OrderStateCancelled))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+	require.Equal(s.T(), []fakepay.Call{{Op: "void", Reference: "fake_pay_1"}}, gateway.Calls())
+}
+
+func (s *Suite) Test_handler_Update_Order_State_Invalid_Transition() {
+	merchantId, orderId := uuid.New().String(), uuid.New().String()
+	order := &model.Order{Model: model.Model{ID: orderId}, MerchantID: merchantId, State: model.OrderStateCancelled}
+
+	s.db.EXPECT().ReadOrderById(orderId).Return(order, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateOrderState(rr, newOrderStateRequest(merchantId, orderId, model.OrderStateFulfilled))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+	require.Contains(s.T(), rr.Body.String(), "cannot move order from cancelled to fulfilled")
+}
+
+func (s *Suite) Test_handler_Update_Order_State_Concurrent_Update() {
+	merchantId, orderId := uuid.New().String(), uuid.New().String()
+	order := &model.Order{Model: model.Model{ID: orderId}, MerchantID: merchantId, State: model.OrderStatePlaced}
+
+	s.db.EXPECT().ReadOrderById(orderId).Return(order, nil)
+	s.db.EXPECT().ListPaymentIntents(merchantId, orderId).Return(model.PaymentIntents{}, nil)
+	s.db.EXPECT().UpdateOrderStateById(orderId, model.OrderStatePlaced, model.OrderStateCancelled, gomock.Any()).Return(repository.ErrConflict)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateOrderState(rr, newOrderStateRequest(merchantId, orderId, model.OrderStateCancelled))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Order_State_Of_Another_Merchant() {
+	orderId := uuid.New().String()
+	order := &model.Order{Model: model.Model{ID: orderId}, MerchantID: uuid.New().String(), State: model.OrderStatePlaced}
+
+	s.db.EXPECT().ReadOrderById(orderId).Return(order, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateOrderState(rr, newOrderStateRequest(uuid.New().String(), orderId, model.OrderStateCancelled))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
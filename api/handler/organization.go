@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+const organizationHeader = "X-Organization"
+
+// ActingMerchant lets organisations act on their descendants: a request
+// naming a descendant of the signed in merchant in the X-Organization header
+// is handled as if that merchant had signed in. Naming any other merchant is
+// forbidden.
+func (srv *Server) ActingMerchant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userDetails, ok := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+		acting := r.Header.Get(organizationHeader)
+		if !ok || acting == "" || acting == userDetails.UserId.String() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, err := uuid.Parse(acting)
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrAccessForbidden)
+			return
+		}
+
+		ids, err := srv.DB.ListMerchantDescendantIds(userDetails.UserId.String())
+		if err != nil {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+			return
+		}
+		if !containsId(ids, id.String()) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrAccessForbidden)
+			return
+		}
+
+		userDetails.UserId = id
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), model.CtxKeyXUser, userDetails)))
+	})
+}
+
+// ListOrganizationMerchant godoc
+// @Summary List organisation merchants
+// @Description get the merchants under the merchant, at every level of the hierarchy
+// @tags organization
+// @Produce  json
+// @Param X-Organization header string false "Descendant to act on"
+// @Param metadata[key] query string false "only merchants whose metadata holds the value at the key; repeatable with other keys"
+// @Success 200 {array} model.MerchantDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 403 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /organization/merchants [get]
+func (srv *Server) HandleListOrganizationMerchant(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+
+	metadata, ok := parseMetadataFilter(w, r)
+	if !ok {
+		return
+	}
+
+	ids, err := srv.DB.ListMerchantDescendantIds(userDetails.UserId.String())
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(ids) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	merchants, err := srv.DB.ListMerchantsByIds(ids, metadata)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	dtos := merchants.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreateOrganizationMerchant godoc
+// @Summary Create organisation merchant
+// @Description register a merchant, such as a brand or a store, under the merchant
+// @tags organization
+// @Accept  json
+// @Produce  json
+// @Param body body model.RegistrationForm true "Register merchant"
+// @Param X-Organization header string false "Descendant to act on"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
+// @Success 201 {object} model.MerchantDto
+// @Header 201 {string} Location "path of the merchant"
+// @Failure 403,409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /organization/merchants [post]
+func (srv *Server) HandleCreateOrganizationMerchant(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	parentId := userDetails.UserId.String()
+
+	merchant, ok := srv.createMerchant(w, r, &parentId)
+	if !ok {
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Merchant created under %s: %s", parentId, merchant.Email))
+
+	w.Header().Set("Location", "/api/v1/merchants/"+merchant.ID)
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(merchant.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+		return
+	}
+}
+
+// ListOrganizationTeamMember godoc
+// @Summary List organisation team members
+// @Description get the team members of the merchant and of every merchant under it, grouped by merchant
+// @tags organization
+// @Produce  json
+// @Param X-Organization header string false "Descendant to act on"
+// @Param metadata[key] query string false "only members whose metadata holds the value at the key; repeatable with other keys"
+// @Success 200 {array} model.TeamMemberDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 403 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /organization/team-members [get]
+func (srv *Server) HandleListOrganizationTeamMember(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	metadata, ok := parseMetadataFilter(w, r)
+	if !ok {
+		return
+	}
+
+	ids, err := srv.DB.ListMerchantDescendantIds(merchantId)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	members, err := srv.DB.ListTeamMembersByMerchantIds(append([]string{merchantId}, ids...), metadata)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(members) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := members.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// UpdateMerchantParentAdmin godoc
+// @Summary Move merchant in hierarchy
+// @Description move a merchant, along with its descendants, under another merchant or to the top of the hierarchy
+// @tags admin
+// @Accept  json
+// @Param id path string true "Merchant ID"
+// @Param body body model.MerchantParentForm true "New parent"
+// @Success 202 {string} string "accepted"
+// @Header 200 {string} X-Admin-Token "qwerty"
+// @Failure 400,401,404,409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /admin/v1/merchants/{id}/parent [put]
+func (srv *Server) HandleUpdateMerchantParentAdmin(w http.ResponseWriter, r *http.Request) {
+	form := &model.MerchantParentForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	merchant, err := srv.DB.ReadMerchantById(chi.URLParam(r, "id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if form.ParentID != nil {
+		if _, err := srv.DB.ReadMerchantById(*form.ParentID); err != nil {
+			if err == gorm.ErrRecordNotFound {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprintf(w, `{"error": "%v"}`, valErrUnknownParent)
+				return
+			}
+
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+			return
+		}
+	}
+
+	merchant.ParentID = form.ParentID
+
+	// The repository rejects moves under the merchant itself or its
+	// descendants within the transaction, so concurrent moves can not close
+	// a cycle between them.
+	err = srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.UpdateMerchantParentById(merchant.ID, merchant.Version, form.ParentID); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantUpdated, merchant))
+	})
+	if err != nil {
+		switch err {
+		case model.ErrMerchantParentCycle:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrParentCycle)
+			return
+		case gorm.ErrRecordNotFound:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrUnknownParent)
+			return
+		case repository.ErrConflict:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataConcurrentUpdate)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func containsId(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,179 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_handler_Acting_Merchant_Descendant() {
+	organisationId := uuid.New().String()
+	brandId := uuid.New().String()
+
+	s.db.EXPECT().ListMerchantDescendantIds(organisationId).Return([]string{brandId}, nil)
+
+	var acting model.CtxUser
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acting = r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	})
+
+	r := newMerchantRequest(http.MethodGet, "/team-members", "", organisationId, "")
+	r.Header.Set("X-Organization", brandId)
+	rr := httptest.NewRecorder()
+	s.server.ActingMerchant(next).ServeHTTP(rr, r)
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), brandId, acting.UserId.String())
+}
+
+func (s *Suite) Test_handler_Acting_Merchant_Not_Descendant() {
+	organisationId := uuid.New().String()
+
+	s.db.EXPECT().ListMerchantDescendantIds(organisationId).Return([]string{uuid.New().String()}, nil)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.T().Fatal("requests acting on other merchants must not be handled")
+	})
+
+	r := newMerchantRequest(http.MethodGet, "/team-members", "", organisationId, "")
+	r.Header.Set("X-Organization", uuid.New().String())
+	rr := httptest.NewRecorder()
+	s.server.ActingMerchant(next).ServeHTTP(rr, r)
+
+	require.Equal(s.T(), http.StatusForbidden, rr.Code)
+}
+
+func (s *Suite) Test_handler_Acting_Merchant_Without_Header() {
+	merchantId := uuid.New().String()
+
+	var acting model.CtxUser
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acting = r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.ActingMerchant(next).ServeHTTP(rr, newMerchantRequest(http.MethodGet, "/team-members", "", merchantId, ""))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), merchantId, acting.UserId.String())
+}
+
+func (s *Suite) Test_handler_List_Organization_Team_Member() {
+	organisationId := uuid.New().String()
+	brandId := uuid.New().String()
+
+	s.db.EXPECT().ListMerchantDescendantIds(organisationId).Return([]string{brandId}, nil)
+	s.db.EXPECT().ListTeamMembersByMerchantIds([]string{organisationId, brandId}, gomock.Nil()).Return(model.TeamMembers{
+		{Model: model.Model{ID: "a"}, MerchantID: organisationId, Email: "jane@example.com"},
+		{Model: model.Model{ID: "b"}, MerchantID: brandId, Email: "john@example.com"},
+	}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleListOrganizationTeamMember(rr, newMerchantRequest(http.MethodGet, "/organization/team-members", "", organisationId, ""))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dtos := model.TeamMemberDtos{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(&dtos))
+	require.Len(s.T(), dtos, 2)
+	require.Equal(s.T(), "john@example.com", dtos[1].Email)
+}
+
+func (s *Suite) Test_handler_List_Organization_Merchant_None() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().ListMerchantDescendantIds(merchantId).Return([]string{}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleListOrganizationMerchant(rr, newMerchantRequest(http.MethodGet, "/organization/merchants", "", merchantId, ""))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), "[]", rr.Body.String())
+}
+
+func (s *Suite) Test_handler_Create_Organization_Merchant() {
+	organisationId := uuid.New().String()
+
+	s.db.EXPECT().ReadMerchantByEmail("store@example.com").Return(nil, gorm.ErrRecordNotFound)
+	s.expectTransaction()
+	s.db.EXPECT().CreateMerchant(gomock.Any()).DoAndReturn(func(m *model.Merchant) error {
+		require.Equal(s.T(), organisationId, *m.ParentID)
+		return nil
+	})
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).Return(nil)
+	s.db.EXPECT().SaveMerchantSettings(gomock.Any()).Return(nil)
+
+	body := `{"email": "store@example.com", "password": "s3cret", "confirmPassword": "s3cret", "businessName": "Store"}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateOrganizationMerchant(rr, newMerchantRequest(http.MethodPost, "/organization/merchants", body, organisationId, ""))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	dto := &model.MerchantDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), "/api/v1/merchants/"+dto.ID, rr.Header().Get("Location"))
+	require.Equal(s.T(), organisationId, *dto.ParentID)
+}
+
+func (s *Suite) Test_handler_Create_Organization_Merchant_Settings_Failure() {
+	organisationId := uuid.New().String()
+
+	s.db.EXPECT().ReadMerchantByEmail("outlet@example.com").Return(nil, gorm.ErrRecordNotFound)
+	s.expectTransaction()
+	s.db.EXPECT().CreateMerchant(gomock.Any()).Return(nil)
+	s.db.EXPECT().SaveMerchantSettings(gomock.Any()).Return(errors.New("connection reset"))
+
+	body := `{"email": "outlet@example.com", "password": "s3cret", "confirmPassword": "s3cret", "businessName": "Outlet"}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateOrganizationMerchant(rr, newMerchantRequest(http.MethodPost, "/organization/merchants", body, organisationId, ""))
+
+	require.Equal(s.T(), http.StatusInternalServerError, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Merchant_Parent_Admin_Cycle() {
+	organisationId := uuid.New().String()
+	brandId := uuid.New().String()
+
+	s.db.EXPECT().ReadMerchantById(organisationId).Return(&model.Merchant{Model: model.Model{ID: organisationId}}, nil)
+	s.db.EXPECT().ReadMerchantById(brandId).Return(&model.Merchant{Model: model.Model{ID: brandId}}, nil)
+	s.expectTransaction()
+	s.db.EXPECT().UpdateMerchantParentById(organisationId, int64(0), gomock.Any()).Return(model.ErrMerchantParentCycle)
+
+	body := `{"parentId": "` + brandId + `"}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateMerchantParentAdmin(rr, newMerchantRequest(http.MethodPut, "/admin/v1/merchants/"+organisationId+"/parent", body, organisationId, organisationId))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Merchant_Parent_Admin() {
+	organisationId := uuid.New().String()
+	brandId := uuid.New().String()
+
+	s.db.EXPECT().ReadMerchantById(brandId).Return(&model.Merchant{Model: model.Model{ID: brandId, Version: 3}}, nil)
+	s.db.EXPECT().ReadMerchantById(organisationId).Return(&model.Merchant{Model: model.Model{ID: organisationId}}, nil)
+	s.expectTransaction()
+	s.db.EXPECT().UpdateMerchantParentById(brandId, int64(3), gomock.Any()).DoAndReturn(func(_ string, _ int64, parentId *string) error {
+		require.Equal(s.T(), organisationId, *parentId)
+		return nil
+	})
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).Return(nil)
+
+	body := `{"parentId": "` + organisationId + `"}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateMerchantParentAdmin(rr, newMerchantRequest(http.MethodPut, "/admin/v1/merchants/"+brandId+"/parent", body, brandId, brandId))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
@@ -0,0 +1,458 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/payment"
+	"merchant/repository"
+)
+
+// callbackStatuses maps the provider callbacks that change a payment onto
+// the status they move it to.
+var callbackStatuses = map[string]string{
+	payment.CallbackAuthorized: model.PaymentIntentStatusAuthorized,
+	payment.CallbackCaptured:   model.PaymentIntentStatusCaptured,
+	payment.CallbackFailed:     model.PaymentIntentStatusFailed,
+	payment.CallbackVoided:     model.PaymentIntentStatusVoided,
+}
+
+// ListPaymentIntent godoc
+// @Summary List payments
+// @Description get the payments of the merchant, newest first
+// @tags payments
+// @Produce  json
+// @Param orderId query string false "Filter by order"
+// @Success 200 {array} model.PaymentIntentDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /payments [get]
+func (srv *Server) HandleListPaymentIntent(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	payments, err := srv.DB.ListPaymentIntents(merchantId, r.URL.Query().Get("orderId"))
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(payments) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := payments.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreatePaymentIntent godoc
+// @Summary Pay order
+// @Description authorize the total of a placed order on the payment method of the customer; a declined payment is returned with status failed, and an order with a payment in progress is refused
+// @tags payments
+// @Accept  json
+// @Produce  json
+// @Param body body model.PaymentIntentForm true "Payment method"
+// @Param id path string true "Order ID"
+// @Success 201 {object} model.PaymentIntentDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500,502 {object} httputil.HTTPError
+// @Router /orders/{id}/payments [post]
+func (srv *Server) HandleCreatePaymentIntent(w http.ResponseWriter, r *http.Request) {
+	form := &model.PaymentIntentForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	order, ok := srv.readOwnedOrder(w, r)
+	if !ok {
+		return
+	}
+
+	if order.State != model.OrderStatePlaced {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotPayable)
+		return
+	}
+
+	intent := form.ToModel(order, srv.Payments.Name())
+
+	if err := srv.DB.CreatePaymentIntent(intent); err != nil {
+		switch err {
+		case model.ErrPaymentInProgress:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrPaymentInProgress)
+			return
+		case repository.ErrConflict:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrOrderNotPayable)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	// The payment id doubles as idempotency key, so a retried authorization
+	// of this payment can not charge the customer twice.
+	res, err := srv.Payments.Authorize(r.Context(), &payment.AuthorizeRequest{
+		IdempotencyKey: intent.ID,
+		Amount:         intent.Amount,
+		Currency:       intent.Currency,
+		PaymentMethod:  form.PaymentMethod,
+	})
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		// A pending payment refuses further payments of the order and holds
+		// up its cancellation, so the payment is failed for the customer to
+		// try again. A retry authorizes under a new id.
+		failed := *intent
+		failed.Status = model.PaymentIntentStatusFailed
+		failed.DeclineReason = model.PaymentIntentDeclineGatewayError
+		if err := srv.DB.UpdatePaymentIntentById(intent.ID, intent.Status, &failed); err != nil {
+			srv.Logger.Warn(err.Error())
+		}
+
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrPaymentGatewayFailure)
+		return
+	}
+
+	updated := *intent
+	updated.ProviderReference = res.Reference
+	if res.Approved {
+		updated.Status = model.PaymentIntentStatusAuthorized
+	} else {
+		updated.Status = model.PaymentIntentStatusFailed
+		updated.DeclineReason = res.DeclineReason
+	}
+
+	if !srv.updatePaymentIntent(w, intent, &updated) {
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Payment created: %s (%s)", updated.ID, updated.Status))
+	w.WriteHeader(http.StatusCreated)
+
+	dto := updated.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+		return
+	}
+}
+
+// ReadPaymentIntent godoc
+// @Summary Read payment
+// @Description get a payment
+// @tags payments
+// @Produce  json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} model.PaymentIntentDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /payments/{id} [get]
+func (srv *Server) HandleReadPaymentIntent(w http.ResponseWriter, r *http.Request) {
+	intent, ok := srv.readOwnedPaymentIntent(w, r)
+	if !ok {
+		return
+	}
+
+	dto := intent.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CapturePaymentIntent godoc
+// @Summary Capture payment
+// @Description collect an authorized payment, by default in full; the order is marked paid
+// @tags payments
+// @Accept  json
+// @Param body body model.PaymentCaptureForm false "Amount to capture"
+// @Param id path string true "Payment ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 402,409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500,502 {object} httputil.HTTPError
+// @Router /payments/{id}/capture [post]
+func (srv *Server) HandleCapturePaymentIntent(w http.ResponseWriter, r *http.Request) {
+	form := &model.PaymentCaptureForm{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+			return
+		}
+		if srv.handleValidationErrors(w, form) {
+			return
+		}
+	}
+
+	intent, ok := srv.readOwnedPaymentIntent(w, r)
+	if !ok {
+		return
+	}
+
+	if !intent.CanTransitionTo(model.PaymentIntentStatusCaptured) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "cannot move payment from %s to %s"}`, intent.Status, model.PaymentIntentStatusCaptured)
+		return
+	}
+
+	amount := form.Amount
+	if amount == 0 {
+		amount = intent.Amount
+	}
+	if amount > intent.Amount {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrCaptureTooLarge)
+		return
+	}
+
+	res, err := srv.Payments.Capture(r.Context(), intent.ProviderReference, amount)
+	if !srv.handleGatewayResult(w, res, err) {
+		return
+	}
+
+	updated := *intent
+	updated.Status = model.PaymentIntentStatusCaptured
+	updated.CapturedAmount = amount
+
+	if !srv.updatePaymentIntent(w, intent, &updated) {
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// VoidPaymentIntent godoc
+// @Summary Void payment
+// @Description release an authorized payment that will not be captured
+// @tags payments
+// @Param id path string true "Payment ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 402,409 {object} httputil.HTTPError
+// @Failure 500,502 {object} httputil.HTTPError
+// @Router /payments/{id}/void [post]
+func (srv *Server) HandleVoidPaymentIntent(w http.ResponseWriter, r *http.Request) {
+	intent, ok := srv.readOwnedPaymentIntent(w, r)
+	if !ok {
+		return
+	}
+
+	if !intent.CanTransitionTo(model.PaymentIntentStatusVoided) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "cannot move payment from %s to %s"}`, intent.Status, model.PaymentIntentStatusVoided)
+		return
+	}
+
+	res, err := srv.Payments.Void(r.Context(), intent.ProviderReference)
+	if !srv.handleGatewayResult(w, res, err) {
+		return
+	}
+
+	updated := *intent
+	updated.Status = model.PaymentIntentStatusVoided
+
+	if !srv.updatePaymentIntent(w, intent, &updated) {
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// PaymentCallback godoc
+// @Summary Receive payment callback
+// @Description notification of the payment provider about a payment; redelivered callbacks are acknowledged without being applied again
+// @tags payments
+// @Accept  json
+// @Success 200 {string} string	"ok"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /callbacks/payments [post]
+func (srv *Server) HandlePaymentCallback(w http.ResponseWriter, r *http.Request) {
+	cb, err := srv.Payments.ParseCallback(r)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrInvalidCallback)
+		return
+	}
+
+	provider := srv.Payments.Name()
+
+	intent, err := srv.DB.ReadPaymentIntentByProviderReference(provider, cb.Reference)
+	if err != nil {
+		// The callback may overtake the response of the provider that
+		// carries the reference; a not found response makes the provider
+		// deliver it again later.
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	// Callbacks for changes already made through the API are only recorded.
+	var updated *model.PaymentIntent
+	if status, ok := callbackStatuses[cb.Type]; ok && intent.CanTransitionTo(status) {
+		updated = &model.PaymentIntent{}
+		*updated = *intent
+		updated.Status = status
+
+		switch status {
+		case model.PaymentIntentStatusCaptured:
+			if cb.Amount < 0 || cb.Amount > intent.Amount {
+				srv.Logger.Warn(fmt.Sprintf("Callback %s captures %d of payment %s of %d", cb.EventID, cb.Amount, intent.ID, intent.Amount))
+
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprintf(w, `{"error": "%v"}`, valErrCaptureTooLarge)
+				return
+			}
+
+			updated.CapturedAmount = cb.Amount
+			if updated.CapturedAmount == 0 {
+				updated.CapturedAmount = intent.Amount
+			}
+		case model.PaymentIntentStatusFailed:
+			updated.DeclineReason = cb.Reason
+		}
+	}
+
+	processed := &model.ProcessedCallback{
+		Provider: provider,
+		EventID:  cb.EventID,
+		Type:     cb.Type,
+	}
+
+	if err := srv.DB.ProcessPaymentCallback(processed, intent.ID, intent.Status, updated); err != nil {
+		switch err {
+		case repository.ErrDuplicate:
+			return
+		case repository.ErrConflict:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataConcurrentUpdate)
+		default:
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		}
+		return
+	}
+}
+
+// handleGatewayResult writes the response for a failed or declined gateway
+// operation and reports whether the operation was approved.
+func (srv *Server) handleGatewayResult(w http.ResponseWriter, res *payment.Result, err error) bool {
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrPaymentGatewayFailure)
+		return false
+	}
+
+	if !res.Approved {
+		w.WriteHeader(http.StatusPaymentRequired)
+		fmt.Fprintf(w, `{"error": "%v: %s"}`, srvErrPaymentDeclined, res.DeclineReason)
+		return false
+	}
+
+	return true
+}
+
+// updatePaymentIntent writes the updated payment, provided the stored one is
+// unchanged since it was read.
+func (srv *Server) updatePaymentIntent(w http.ResponseWriter, intent, updated *model.PaymentIntent) bool {
+	if err := srv.DB.UpdatePaymentIntentById(intent.ID, intent.Status, updated); err != nil {
+		if err == repository.ErrConflict {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataConcurrentUpdate)
+			return false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return false
+	}
+
+	return true
+}
+
+// readOwnedPaymentIntent reads the payment in the URL and writes a not found
+// response when it does not exist or belongs to another merchant.
+func (srv *Server) readOwnedPaymentIntent(w http.ResponseWriter, r *http.Request) (*model.PaymentIntent, bool) {
+	id := chi.URLParam(r, "id")
+
+	intent, err := srv.DB.ReadPaymentIntentById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if intent.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return intent, true
+}
@@ -0,0 +1,250 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/payment"
+	"merchant/payment/fakepay"
+	"merchant/repository"
+)
+
+func newMerchantRequest(method, target, body, merchantId, id string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, model.CtxKeyXUser, model.CtxUser{UserId: uuid.MustParse(merchantId)})
+
+	return r.WithContext(ctx)
+}
+
+func placedOrder(merchantId string) *model.Order {
+	return &model.Order{
+		Model:      model.Model{ID: uuid.New().String()},
+		MerchantID: merchantId,
+		State:      model.OrderStatePlaced,
+		Currency:   "EUR",
+		Total:      2500,
+	}
+}
+
+func (s *Suite) Test_handler_Create_Payment_Intent() {
+	gateway := fakepay.New("")
+	s.server.Payments = gateway
+
+	merchantId := uuid.New().String()
+	order := placedOrder(merchantId)
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().CreatePaymentIntent(gomock.Any()).Return(nil)
+	s.db.EXPECT().UpdatePaymentIntentById(gomock.Any(), model.PaymentIntentStatusPending, gomock.Any()).
+		DoAndReturn(func(_, _ string, p *model.PaymentIntent) error {
+			require.Equal(s.T(), model.PaymentIntentStatusAuthorized, p.Status)
+			require.Equal(s.T(), "fake_pay_1", p.ProviderReference)
+			return nil
+		})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreatePaymentIntent(rr, newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/payments", `{"paymentMethod": "tok_visa"}`, merchantId, order.ID))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	dto := &model.PaymentIntentDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), int64(2500), dto.Amount)
+	require.Equal(s.T(), "fake", dto.Provider)
+	require.Equal(s.T(), []fakepay.Call{{Op: "authorize", Reference: "fake_pay_1", Amount: 2500}}, gateway.Calls())
+}
+
+func (s *Suite) Test_handler_Create_Payment_Intent_Declined() {
+	gateway := fakepay.New("")
+	gateway.Script(fakepay.Decline("insufficient_funds"))
+	s.server.Payments = gateway
+
+	merchantId := uuid.New().String()
+	order := placedOrder(merchantId)
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().CreatePaymentIntent(gomock.Any()).Return(nil)
+	s.db.EXPECT().UpdatePaymentIntentById(gomock.Any(), model.PaymentIntentStatusPending, gomock.Any()).Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreatePaymentIntent(rr, newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/payments", `{"paymentMethod": "tok_visa"}`, merchantId, order.ID))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	dto := &model.PaymentIntentDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), model.PaymentIntentStatusFailed, dto.Status)
+	require.Equal(s.T(), "insufficient_funds", dto.DeclineReason)
+}
+
+func (s *Suite) Test_handler_Capture_Payment_Intent_Declined() {
+	gateway := fakepay.New("")
+	gateway.Script(fakepay.Decline("expired_authorization"))
+	s.server.Payments = gateway
+
+	merchantId := uuid.New().String()
+	intent := &model.PaymentIntent{
+		Model:             model.Model{ID: uuid.New().String()},
+		MerchantID:        merchantId,
+		ProviderReference: "fake_pay_7",
+		Amount:            2500,
+		Status:            model.PaymentIntentStatusAuthorized,
+	}
+
+	s.db.EXPECT().ReadPaymentIntentById(intent.ID).Return(intent, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCapturePaymentIntent(rr, newMerchantRequest(http.MethodPost, "/payments/"+intent.ID+"/capture", "", merchantId, intent.ID))
+
+	require.Equal(s.T(), http.StatusPaymentRequired, rr.Code)
+}
+
+func (s *Suite) Test_handler_Payment_Callback_Applied_Once() {
+	gateway := fakepay.New("secret")
+	s.server.Payments = gateway
+
+	intent := &model.PaymentIntent{
+		Model:             model.Model{ID: uuid.New().String()},
+		OrderID:           uuid.New().String(),
+		ProviderReference: "fake_pay_3",
+		Amount:            2500,
+		Status:            model.PaymentIntentStatusAuthorized,
+	}
+	cb := &payment.Callback{EventID: "evt_1", Type: payment.CallbackCaptured, Reference: "fake_pay_3"}
+
+	s.db.EXPECT().ReadPaymentIntentByProviderReference("fake", "fake_pay_3").Return(intent, nil).Times(2)
+	gomock.InOrder(
+		s.db.EXPECT().ProcessPaymentCallback(gomock.Any(), intent.ID, model.PaymentIntentStatusAuthorized, gomock.Any()).
+			DoAndReturn(func(_ *model.ProcessedCallback, _, _ string, p *model.PaymentIntent) error {
+				require.Equal(s.T(), model.PaymentIntentStatusCaptured, p.Status)
+				require.Equal(s.T(), int64(2500), p.CapturedAmount)
+				return nil
+			}),
+		s.db.EXPECT().ProcessPaymentCallback(gomock.Any(), intent.ID, model.PaymentIntentStatusAuthorized, gomock.Any()).
+			Return(repository.ErrDuplicate),
+	)
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		s.server.HandlePaymentCallback(rr, gateway.NewCallbackRequest("/callbacks/payments", cb))
+
+		require.Equal(s.T(), http.StatusOK, rr.Code)
+	}
+}
+
+func (s *Suite) Test_handler_Payment_Callback_Captures_More_Than_Authorized() {
+	gateway := fakepay.New("secret")
+	s.server.Payments = gateway
+
+	intent := &model.PaymentIntent{
+		Model:             model.Model{ID: uuid.New().String()},
+		ProviderReference: "fake_pay_3",
+		Amount:            2500,
+		Status:            model.PaymentIntentStatusAuthorized,
+	}
+	cb := &payment.Callback{EventID: "evt_1", Type: payment.CallbackCaptured, Reference: "fake_pay_3", Amount: 2501}
+
+	s.db.EXPECT().ReadPaymentIntentByProviderReference("fake", "fake_pay_3").Return(intent, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandlePaymentCallback(rr, gateway.NewCallbackRequest("/callbacks/payments", cb))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Payment_Callback_Without_Secret() {
+	gateway := fakepay.New("")
+	s.server.Payments = gateway
+
+	cb := &payment.Callback{EventID: "evt_1", Type: payment.CallbackCaptured, Reference: "fake_pay_3"}
+
+	rr := httptest.NewRecorder()
+	s.server.HandlePaymentCallback(rr, gateway.NewCallbackRequest("/callbacks/payments", cb))
+
+	require.Equal(s.T(), http.StatusBadRequest, rr.Code)
+}
+
+func (s *Suite) Test_handler_Payment_Callback_Invalid_Signature() {
+	s.server.Payments = fakepay.New("secret")
+
+	cb := &payment.Callback{EventID: "evt_1", Type: payment.CallbackCaptured, Reference: "fake_pay_3"}
+
+	rr := httptest.NewRecorder()
+	s.server.HandlePaymentCallback(rr, fakepay.New("forged").NewCallbackRequest("/callbacks/payments", cb))
+
+	require.Equal(s.T(), http.StatusBadRequest, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Payment_Intent_In_Progress() {
+	gateway := fakepay.New("")
+	s.server.Payments = gateway
+
+	merchantId := uuid.New().String()
+	order := placedOrder(merchantId)
+
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil)
+	s.db.EXPECT().CreatePaymentIntent(gomock.Any()).Return(model.ErrPaymentInProgress)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreatePaymentIntent(rr, newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/payments", `{"paymentMethod": "tok_visa"}`, merchantId, order.ID))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+	require.Empty(s.T(), gateway.Calls())
+}
+
+func (s *Suite) Test_handler_Create_Payment_Intent_Gateway_Failure_Leaves_Order_Payable() {
+	gateway := fakepay.New("")
+	gateway.Script(fakepay.Fail(errors.New("connection reset")))
+	s.server.Payments = gateway
+
+	merchantId := uuid.New().String()
+	order := placedOrder(merchantId)
+
+	var failed *model.PaymentIntent
+	s.db.EXPECT().ReadOrderById(order.ID).Return(order, nil).Times(3)
+	s.db.EXPECT().CreatePaymentIntent(gomock.Any()).Return(nil).Times(2)
+	gomock.InOrder(
+		s.db.EXPECT().UpdatePaymentIntentById(gomock.Any(), model.PaymentIntentStatusPending, gomock.Any()).
+			DoAndReturn(func(_, _ string, p *model.PaymentIntent) error {
+				failed = p
+				return nil
+			}),
+		s.db.EXPECT().UpdatePaymentIntentById(gomock.Any(), model.PaymentIntentStatusPending, gomock.Any()).Return(nil),
+	)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreatePaymentIntent(rr, newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/payments", `{"paymentMethod": "tok_visa"}`, merchantId, order.ID))
+
+	require.Equal(s.T(), http.StatusBadGateway, rr.Code)
+	require.Equal(s.T(), model.PaymentIntentStatusFailed, failed.Status)
+	require.Equal(s.T(), model.PaymentIntentDeclineGatewayError, failed.DeclineReason)
+
+	// The order can be paid again...
+	rr = httptest.NewRecorder()
+	s.server.HandleCreatePaymentIntent(rr, newMerchantRequest(http.MethodPost, "/orders/"+order.ID+"/payments", `{"paymentMethod": "tok_visa"}`, merchantId, order.ID))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	// ...or cancelled, with nothing to void for the failed payment.
+	s.db.EXPECT().ListPaymentIntents(merchantId, order.ID).Return(model.PaymentIntents{failed}, nil)
+	s.db.EXPECT().UpdateOrderStateById(order.ID, model.OrderStatePlaced, model.OrderStateCancelled, gomock.Any()).Return(nil)
+
+	rr = httptest.NewRecorder()
+	s.server.HandleUpdateOrderState(rr, newOrderStateRequest(merchantId, order.ID, model.OrderStateCancelled))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// ListPayoutAccount godoc
+// @Summary List payout accounts
+// @Description get the bank accounts payouts of the merchant are sent to
+// @tags payouts
+// @Produce  json
+// @Success 200 {array} model.PayoutAccountDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /payout-accounts [get]
+func (srv *Server) HandleListPayoutAccount(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	accounts, err := srv.DB.ListPayoutAccountsByMerchantId(merchantId)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(accounts) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := accounts.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreatePayoutAccount godoc
+// @Summary Create payout account
+// @Description register a bank account, by IBAN or by account number and sort code, for payouts; it starts pending verification
+// @tags payouts
+// @Accept  json
+// @Param body body model.PayoutAccountForm true "Bank account"
+// @Success 201 {string} string "created"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /payout-accounts [post]
+func (srv *Server) HandleCreatePayoutAccount(w http.ResponseWriter, r *http.Request) {
+	form := &model.PayoutAccountForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	form.Normalize()
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	account := form.ToModel(merchantId)
+
+	if err := srv.DB.CreatePayoutAccount(account); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Payout Account created: %s", account.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// ReadPayoutAccount godoc
+// @Summary Read payout account
+// @Description get a payout account with masked account details
+// @tags payouts
+// @Produce  json
+// @Param id path string true "Payout Account ID"
+// @Success 200 {object} model.PayoutAccountDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /payout-accounts/{id} [get]
+func (srv *Server) HandleReadPayoutAccount(w http.ResponseWriter, r *http.Request) {
+	account, ok := srv.readOwnedPayoutAccount(w, r)
+	if !ok {
+		return
+	}
+
+	dto := account.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// DeletePayoutAccount godoc
+// @Summary Delete payout account
+// @Description remove a payout account
+// @tags payouts
+// @Param id path string true "Payout Account ID"
+// @Success 200 {string} string	"ok"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /payout-accounts/{id} [delete]
+func (srv *Server) HandleDeletePayoutAccount(w http.ResponseWriter, r *http.Request) {
+	account, ok := srv.readOwnedPayoutAccount(w, r)
+	if !ok {
+		return
+	}
+
+	if err := srv.DB.DeletePayoutAccount(account.ID); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDeleteFailure)
+		return
+	}
+}
+
+// ListPayoutAccountAdmin godoc
+// @Summary List payout accounts
+// @Description get the payout accounts of all merchants, oldest first
+// @tags admin
+// @Produce  json
+// @Param status query string false "Filter by status" Enums(Pending, Verified, Failed)
+// @Success 200 {array} model.PayoutAccountDtos
+// @Header 200 {string} X-Admin-Token "qwerty"
+// @Failure 400,401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /admin/v1/payout-accounts [get]
+func (srv *Server) HandleListPayoutAccountAdmin(w http.ResponseWriter, r *http.Request) {
+	accounts, err := srv.DB.ListPayoutAccountsByStatus(r.URL.Query().Get("status"))
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(accounts) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := accounts.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// UpdatePayoutAccountStatusAdmin godoc
+// @Summary Update payout account status
+// @Description record the outcome of verifying a payout account
+// @tags admin
+// @Accept  json
+// @Param id path string true "Payout Account ID"
+// @Param body body model.PayoutAccountStatusForm true "Verification outcome"
+// @Success 202 {string} string "accepted"
+// @Header 200 {string} X-Admin-Token "qwerty"
+// @Failure 400,401,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /admin/v1/payout-accounts/{id}/status [put]
+func (srv *Server) HandleUpdatePayoutAccountStatusAdmin(w http.ResponseWriter, r *http.Request) {
+	form := &model.PayoutAccountStatusForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	account, ok := srv.readPayoutAccount(w, r)
+	if !ok {
+		return
+	}
+
+	if err := srv.DB.UpdatePayoutAccountStatusById(account.ID, form.Status); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// readOwnedPayoutAccount reads the payout account in the URL and writes a not
+// found response when it does not exist or belongs to another merchant.
+func (srv *Server) readOwnedPayoutAccount(w http.ResponseWriter, r *http.Request) (*model.PayoutAccount, bool) {
+	account, ok := srv.readPayoutAccount(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if account.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return account, true
+}
+
+func (srv *Server) readPayoutAccount(w http.ResponseWriter, r *http.Request) (*model.PayoutAccount, bool) {
+	id := chi.URLParam(r, "id")
+
+	account, err := srv.DB.ReadPayoutAccountById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	return account, true
+}
@@ -0,0 +1,142 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/util/cryptoutil"
+)
+
+func ownedPayoutAccount(merchantId string) *model.PayoutAccount {
+	return &model.PayoutAccount{
+		Model:             model.Model{ID: uuid.New().String()},
+		MerchantID:        merchantId,
+		AccountHolderName: "Acme Ltd",
+		Currency:          "GBP",
+		IBAN:              cryptoutil.EncryptedString("GB82WEST12345698765432"),
+		Status:            model.PayoutAccountStatusPending,
+	}
+}
+
+func (s *Suite) Test_handler_Create_Payout_Account() {
+	merchantId := uuid.New().String()
+	body := `{"accountHolderName": "Acme Ltd", "currency": "gbp", "iban": "GB82 WEST 1234 5698 7654 32"}`
+
+	s.db.EXPECT().CreatePayoutAccount(gomock.Any()).DoAndReturn(func(p *model.PayoutAccount) error {
+		require.Equal(s.T(), merchantId, p.MerchantID)
+		require.Equal(s.T(), "GBP", p.Currency)
+		require.Equal(s.T(), "GB82WEST12345698765432", p.IBAN.String())
+		require.Equal(s.T(), model.PayoutAccountStatusPending, p.Status)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreatePayoutAccount(rr, newMerchantRequest(http.MethodPost, "/payout-accounts", body, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Payout_Account_Invalid() {
+	bodies := []string{
+		`{"accountHolderName": "Acme Ltd", "currency": "GBP"}`,
+		`{"accountHolderName": "Acme Ltd", "currency": "GBP", "iban": "GB00WEST12345698765432"}`,
+		`{"accountHolderName": "Acme Ltd", "currency": "GBP", "accountNumber": "12345678"}`,
+	}
+
+	for _, body := range bodies {
+		rr := httptest.NewRecorder()
+		s.server.HandleCreatePayoutAccount(rr, newMerchantRequest(http.MethodPost, "/payout-accounts", body, uuid.New().String(), ""))
+
+		require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code, body)
+	}
+}
+
+func (s *Suite) Test_handler_Read_Payout_Account() {
+	merchantId := uuid.New().String()
+	account := ownedPayoutAccount(merchantId)
+
+	s.db.EXPECT().ReadPayoutAccountById(account.ID).Return(account, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadPayoutAccount(rr, newMerchantRequest(http.MethodGet, "/payout-accounts/"+account.ID, "", merchantId, account.ID))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dto := &model.PayoutAccountDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), "GB****************5432", dto.IBAN)
+}
+
+func (s *Suite) Test_handler_Read_Payout_Account_Of_Another_Merchant() {
+	account := ownedPayoutAccount(uuid.New().String())
+
+	s.db.EXPECT().ReadPayoutAccountById(account.ID).Return(account, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadPayoutAccount(rr, newMerchantRequest(http.MethodGet, "/payout-accounts/"+account.ID, "", uuid.New().String(), account.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Delete_Payout_Account() {
+	merchantId := uuid.New().String()
+	account := ownedPayoutAccount(merchantId)
+
+	s.db.EXPECT().ReadPayoutAccountById(account.ID).Return(account, nil)
+	s.db.EXPECT().DeletePayoutAccount(account.ID).Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDeletePayoutAccount(rr, newMerchantRequest(http.MethodDelete, "/payout-accounts/"+account.ID, "", merchantId, account.ID))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+}
+
+func (s *Suite) Test_handler_Delete_Payout_Account_Of_Another_Merchant() {
+	account := ownedPayoutAccount(uuid.New().String())
+
+	s.db.EXPECT().ReadPayoutAccountById(account.ID).Return(account, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDeletePayoutAccount(rr, newMerchantRequest(http.MethodDelete, "/payout-accounts/"+account.ID, "", uuid.New().String(), account.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Payout_Account_Status() {
+	account := ownedPayoutAccount(uuid.New().String())
+
+	s.db.EXPECT().ReadPayoutAccountById(account.ID).Return(account, nil)
+	s.db.EXPECT().UpdatePayoutAccountStatusById(account.ID, model.PayoutAccountStatusVerified).Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdatePayoutAccountStatusAdmin(rr, newMerchantRequest(http.MethodPut, "/admin/v1/payout-accounts/"+account.ID+"/status", `{"status": "Verified"}`, uuid.New().String(), account.ID))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Payout_Account_Status_Invalid() {
+	id := uuid.New().String()
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdatePayoutAccountStatusAdmin(rr, newMerchantRequest(http.MethodPut, "/admin/v1/payout-accounts/"+id+"/status", `{"status": "Pending"}`, uuid.New().String(), id))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Payout_Account_Status_Not_Found() {
+	id := uuid.New().String()
+
+	s.db.EXPECT().ReadPayoutAccountById(id).Return(nil, gorm.ErrRecordNotFound)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdatePayoutAccountStatusAdmin(rr, newMerchantRequest(http.MethodPut, "/admin/v1/payout-accounts/"+id+"/status", `{"status": "Failed"}`, uuid.New().String(), id))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// ListProduct godoc
+// @Summary List products
+// @Description search the products of the merchant, newest first; the total number of matches is in the X-Total-Count header
+// @tags products
+// @Produce  json
+// @Param q query string false "Name contains q or a variant has SKU q"
+// @Param active query boolean false "Filter by active flag"
+// @Param limit query integer false "Page size, 1 to 100" default(20)
+// @Param offset query integer false "Number of products to skip" default(0)
+// @Success 200 {array} model.ProductDtos
+// @Header 200 {string} Token "qwerty"
+// @Header 200 {integer} X-Total-Count "Number of matching products"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /products [get]
+func (srv *Server) HandleListProduct(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	search := &model.ProductSearch{
+		Query:  r.URL.Query().Get("q"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if v := r.URL.Query().Get("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+			return
+		}
+		search.Active = &active
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	products, total, err := srv.DB.SearchProducts(merchantId, search)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	if len(products) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := products.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreateProduct godoc
+// @Summary Create product
+// @Description add a product, optionally with its variants, to the catalog of the merchant
+// @tags products
+// @Accept  json
+// @Param body body model.ProductCreateForm true "Create a product"
+// @Success 201 {string} string "created"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /products [post]
+func (srv *Server) HandleCreateProduct(w http.ResponseWriter, r *http.Request) {
+	form := &model.ProductCreateForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	if !form.HasUniqueSkus() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrDuplicateSku)
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	product := form.ToModel(merchantId)
+
+	if err := srv.DB.CreateProduct(product); err != nil {
+		if err == repository.ErrDuplicate {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDuplicateInsertion)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Product created: %s", product.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// ReadProduct godoc
+// @Summary Read product
+// @Description get a product with its variants
+// @tags products
+// @Produce  json
+// @Param id path string true "Product ID"
+// @Success 200 {object} model.ProductDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /products/{id} [get]
+func (srv *Server) HandleReadProduct(w http.ResponseWriter, r *http.Request) {
+	product, ok := srv.readOwnedProduct(w, r)
+	if !ok {
+		return
+	}
+
+	dto := product.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// UpdateProduct godoc
+// @Summary Update product
+// @Description update the details of a product; its variants are left unchanged
+// @tags products
+// @Accept  json
+// @Param body body model.ProductUpdateForm true "Update a product"
+// @Param id path string true "Product ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /products/{id} [put]
+func (srv *Server) HandleUpdateProduct(w http.ResponseWriter, r *http.Request) {
+	form := &model.ProductUpdateForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	existing, ok := srv.readOwnedProduct(w, r)
+	if !ok {
+		return
+	}
+
+	if err := srv.DB.UpdateProductById(existing.ID, form.ToModel(existing.ID)); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeleteProduct godoc
+// @Summary Delete product
+// @Description delete a product and its variants
+// @tags products
+// @Param id path string true "Product ID"
+// @Success 200 {string} string	"ok"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /products/{id} [delete]
+func (srv *Server) HandleDeleteProduct(w http.ResponseWriter, r *http.Request) {
+	product, ok := srv.readOwnedProduct(w, r)
+	if !ok {
+		return
+	}
+
+	if err := srv.DB.DeleteProduct(product.ID); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDeleteFailure)
+		return
+	}
+}
+
+// CreateProductVariant godoc
+// @Summary Create product variant
+// @Description add a variant to a product
+// @tags products
+// @Accept  json
+// @Param body body model.ProductVariantForm true "Create a variant"
+// @Param id path string true "Product ID"
+// @Success 201 {string} string "created"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /products/{id}/variants [post]
+func (srv *Server) HandleCreateProductVariant(w http.ResponseWriter, r *http.Request) {
+	form := &model.ProductVariantForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	product, ok := srv.readOwnedProduct(w, r)
+	if !ok {
+		return
+	}
+
+	variant := form.ToModel(product.ID, product.MerchantID)
+
+	if err := srv.DB.CreateProductVariant(variant); err != nil {
+		if err == repository.ErrDuplicate {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDuplicateInsertion)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Product Variant created: %s", variant.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// UpdateProductVariant godoc
+// @Summary Update product variant
+// @Description update a variant of a product
+// @tags products
+// @Accept  json
+// @Param body body model.ProductVariantForm true "Update a variant"
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /products/{id}/variants/{variantId} [put]
+func (srv *Server) HandleUpdateProductVariant(w http.ResponseWriter, r *http.Request) {
+	form := &model.ProductVariantForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	existing, ok := srv.readOwnedProductVariant(w, r)
+	if !ok {
+		return
+	}
+
+	variant := form.ToModelWithId(existing.ID, existing.ProductID, existing.MerchantID)
+
+	if err := srv.DB.UpdateProductVariantById(existing.ID, variant); err != nil {
+		if err == repository.ErrDuplicate {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDuplicateInsertion)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeleteProductVariant godoc
+// @Summary Delete product variant
+// @Description delete a variant of a product
+// @tags products
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {string} string	"ok"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /products/{id}/variants/{variantId} [delete]
+func (srv *Server) HandleDeleteProductVariant(w http.ResponseWriter, r *http.Request) {
+	variant, ok := srv.readOwnedProductVariant(w, r)
+	if !ok {
+		return
+	}
+
+	if err := srv.DB.DeleteProductVariant(variant.ID); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDeleteFailure)
+		return
+	}
+}
+
+// readOwnedProduct reads the product in the URL and writes a not found
+// response when it does not exist or belongs to another merchant.
+func (srv *Server) readOwnedProduct(w http.ResponseWriter, r *http.Request) (*model.Product, bool) {
+	id := chi.URLParam(r, "id")
+
+	product, err := srv.DB.ReadProductById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if product.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return product, true
+}
+
+// readOwnedProductVariant reads the variant in the URL and writes a not found
+// response when it does not exist or is not a variant of the product in the
+// URL owned by the merchant.
+func (srv *Server) readOwnedProductVariant(w http.ResponseWriter, r *http.Request) (*model.ProductVariant, bool) {
+	product, ok := srv.readOwnedProduct(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	id := chi.URLParam(r, "variantId")
+
+	variant, err := srv.DB.ReadProductVariantById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	if variant.ProductID != product.ID {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return variant, true
+}
@@ -0,0 +1,224 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+const variantBody = `{"sku": "TEE-M", "name": "Medium", "price": 2500, "currency": "EUR", "active": true}`
+
+func newProductVariantRequest(method, body, merchantId, productId, variantId string) *http.Request {
+	r := httptest.NewRequest(method, "/products/"+productId+"/variants/"+variantId, strings.NewReader(body))
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", productId)
+	rctx.URLParams.Add("variantId", variantId)
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, model.CtxKeyXUser, model.CtxUser{UserId: uuid.MustParse(merchantId)})
+
+	return r.WithContext(ctx)
+}
+
+func ownedProduct(merchantId string) *model.Product {
+	return &model.Product{
+		Model:       model.Model{ID: uuid.New().String()},
+		MerchantID:  merchantId,
+		Name:        "T-shirt",
+		TaxCategory: "standard",
+	}
+}
+
+func (s *Suite) Test_handler_Create_Product() {
+	merchantId := uuid.New().String()
+	body := `{"name": "T-shirt", "taxCategory": "standard", "active": true, "variants": [` + variantBody + `]}`
+
+	s.db.EXPECT().CreateProduct(gomock.Any()).DoAndReturn(func(p *model.Product) error {
+		require.Equal(s.T(), merchantId, p.MerchantID)
+		require.Len(s.T(), p.Variants, 1)
+		require.Equal(s.T(), p.ID, p.Variants[0].ProductID)
+		require.Equal(s.T(), merchantId, p.Variants[0].MerchantID)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateProduct(rr, newMerchantRequest(http.MethodPost, "/products", body, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Product_Invalid() {
+	bodies := []string{
+		`{"name": "T-shirt", "taxCategory": "luxury"}`,
+		`{"name": "T-shirt", "taxCategory": "standard", "variants": [{"sku": "TEE-M", "price": -1, "currency": "EUR"}]}`,
+		`{"name": "T-shirt", "taxCategory": "standard", "variants": [` + variantBody + `, ` + variantBody + `]}`,
+	}
+
+	for _, body := range bodies {
+		rr := httptest.NewRecorder()
+		s.server.HandleCreateProduct(rr, newMerchantRequest(http.MethodPost, "/products", body, uuid.New().String(), ""))
+
+		require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code, body)
+	}
+}
+
+func (s *Suite) Test_handler_Create_Product_Duplicate_Sku() {
+	body := `{"name": "T-shirt", "taxCategory": "standard", "variants": [` + variantBody + `]}`
+
+	s.db.EXPECT().CreateProduct(gomock.Any()).Return(repository.ErrDuplicate)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateProduct(rr, newMerchantRequest(http.MethodPost, "/products", body, uuid.New().String(), ""))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Read_Product() {
+	merchantId := uuid.New().String()
+	product := ownedProduct(merchantId)
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadProduct(rr, newMerchantRequest(http.MethodGet, "/products/"+product.ID, "", merchantId, product.ID))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dto := &model.ProductDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), product.ID, dto.ID)
+}
+
+func (s *Suite) Test_handler_Read_Product_Of_Another_Merchant() {
+	product := ownedProduct(uuid.New().String())
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadProduct(rr, newMerchantRequest(http.MethodGet, "/products/"+product.ID, "", uuid.New().String(), product.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Product_Of_Another_Merchant() {
+	product := ownedProduct(uuid.New().String())
+	body := `{"name": "Long sleeve T-shirt", "taxCategory": "standard"}`
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateProduct(rr, newMerchantRequest(http.MethodPut, "/products/"+product.ID, body, uuid.New().String(), product.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Delete_Product_Not_Found() {
+	id := uuid.New().String()
+
+	s.db.EXPECT().ReadProductById(id).Return(nil, gorm.ErrRecordNotFound)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDeleteProduct(rr, newMerchantRequest(http.MethodDelete, "/products/"+id, "", uuid.New().String(), id))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Product_Variant() {
+	merchantId := uuid.New().String()
+	product := ownedProduct(merchantId)
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+	s.db.EXPECT().CreateProductVariant(gomock.Any()).DoAndReturn(func(v *model.ProductVariant) error {
+		require.Equal(s.T(), product.ID, v.ProductID)
+		require.Equal(s.T(), merchantId, v.MerchantID)
+		require.Equal(s.T(), "TEE-M", v.SKU)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateProductVariant(rr, newMerchantRequest(http.MethodPost, "/products/"+product.ID+"/variants", variantBody, merchantId, product.ID))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Product_Variant_Of_Another_Merchant() {
+	product := ownedProduct(uuid.New().String())
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateProductVariant(rr, newMerchantRequest(http.MethodPost, "/products/"+product.ID+"/variants", variantBody, uuid.New().String(), product.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Product_Variant() {
+	merchantId := uuid.New().String()
+	product := ownedProduct(merchantId)
+	variant := &model.ProductVariant{Model: model.Model{ID: uuid.New().String()}, ProductID: product.ID, MerchantID: merchantId}
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+	s.db.EXPECT().ReadProductVariantById(variant.ID).Return(variant, nil)
+	s.db.EXPECT().UpdateProductVariantById(variant.ID, gomock.Any()).DoAndReturn(func(_ string, v *model.ProductVariant) error {
+		require.Equal(s.T(), product.ID, v.ProductID)
+		require.Equal(s.T(), int64(2500), v.Price)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateProductVariant(rr, newProductVariantRequest(http.MethodPut, variantBody, merchantId, product.ID, variant.ID))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Product_Variant_Of_Another_Product() {
+	merchantId := uuid.New().String()
+	product := ownedProduct(merchantId)
+	variant := &model.ProductVariant{Model: model.Model{ID: uuid.New().String()}, ProductID: uuid.New().String(), MerchantID: uuid.New().String()}
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+	s.db.EXPECT().ReadProductVariantById(variant.ID).Return(variant, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateProductVariant(rr, newProductVariantRequest(http.MethodPut, variantBody, merchantId, product.ID, variant.ID))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Product_Variant_Duplicate_Sku() {
+	merchantId := uuid.New().String()
+	product := ownedProduct(merchantId)
+	variant := &model.ProductVariant{Model: model.Model{ID: uuid.New().String()}, ProductID: product.ID, MerchantID: merchantId}
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+	s.db.EXPECT().ReadProductVariantById(variant.ID).Return(variant, nil)
+	s.db.EXPECT().UpdateProductVariantById(variant.ID, gomock.Any()).Return(repository.ErrDuplicate)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateProductVariant(rr, newProductVariantRequest(http.MethodPut, variantBody, merchantId, product.ID, variant.ID))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Delete_Product_Variant_Of_Another_Merchant() {
+	product := ownedProduct(uuid.New().String())
+	variantId := uuid.New().String()
+
+	s.db.EXPECT().ReadProductById(product.ID).Return(product, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDeleteProductVariant(rr, newProductVariantRequest(http.MethodDelete, "", uuid.New().String(), product.ID, variantId))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"merchant/model"
+	"merchant/payment"
+)
+
+// ListRefund godoc
+// @Summary List refunds
+// @Description get the refunds of a payment, newest first
+// @tags payments
+// @Produce  json
+// @Param id path string true "Payment ID"
+// @Success 200 {array} model.RefundDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /payments/{id}/refunds [get]
+func (srv *Server) HandleListRefund(w http.ResponseWriter, r *http.Request) {
+	intent, ok := srv.readOwnedPaymentIntent(w, r)
+	if !ok {
+		return
+	}
+
+	refunds, err := srv.DB.ListRefundsByPaymentIntentId(intent.ID)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(refunds) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := refunds.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreateRefund godoc
+// @Summary Refund payment
+// @Description refund part or, by default, all of what remains of a captured payment; once it is refunded in full its order is marked refunded
+// @tags payments
+// @Accept  json
+// @Produce  json
+// @Param body body model.RefundForm true "Refund"
+// @Param id path string true "Payment ID"
+// @Success 201 {object} model.RefundDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,403,404 {object} httputil.HTTPError
+// @Failure 402,409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500,502 {object} httputil.HTTPError
+// @Router /payments/{id}/refunds [post]
+func (srv *Server) HandleCreateRefund(w http.ResponseWriter, r *http.Request) {
+	form := &model.RefundForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	intent, ok := srv.readOwnedPaymentIntent(w, r)
+	if !ok {
+		return
+	}
+
+	if !intent.IsRefundable() {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "cannot refund a payment that is %s"}`, intent.Status)
+		return
+	}
+
+	refund := form.ToModel(intent)
+
+	if refund.Amount <= 0 {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrRefundExceedsBalance)
+		return
+	}
+
+	if err := srv.DB.ReserveRefund(refund); err != nil {
+		if err == model.ErrRefundExceedsBalance {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrRefundExceedsBalance)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	// When the outcome at the provider is unknown the refund stays pending
+	// and keeps its amount set aside until it is reconciled.
+	res, err := srv.Payments.Refund(r.Context(), &payment.RefundRequest{
+		IdempotencyKey: refund.ID,
+		Reference:      intent.ProviderReference,
+		Amount:         refund.Amount,
+	})
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrPaymentGatewayFailure)
+		return
+	}
+
+	if !res.Approved {
+		if err := srv.DB.FailRefund(refund.ID, res.DeclineReason); err != nil {
+			srv.Logger.Warn(err.Error())
+		}
+
+		w.WriteHeader(http.StatusPaymentRequired)
+		fmt.Fprintf(w, `{"error": "%v: %s"}`, srvErrPaymentDeclined, res.DeclineReason)
+		return
+	}
+
+	if err := srv.DB.CompleteRefund(refund.ID, res.Reference); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	refund.Status = model.RefundStatusSucceeded
+	refund.ProviderReference = res.Reference
+
+	srv.Logger.Info(fmt.Sprintf("New Refund created: %s", refund.ID))
+	w.WriteHeader(http.StatusCreated)
+
+	dto := refund.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+		return
+	}
+}
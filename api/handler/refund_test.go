@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/payment/fakepay"
+)
+
+func capturedPayment(merchantId string) *model.PaymentIntent {
+	return &model.PaymentIntent{
+		Model:             model.Model{ID: uuid.New().String()},
+		MerchantID:        merchantId,
+		OrderID:           uuid.New().String(),
+		ProviderReference: "fake_pay_9",
+		Amount:            2500,
+		CapturedAmount:    2500,
+		RefundedAmount:    1000,
+		Currency:          "EUR",
+		Status:            model.PaymentIntentStatusPartiallyRefunded,
+	}
+}
+
+func (s *Suite) Test_handler_Create_Refund() {
+	gateway := fakepay.New("")
+	s.server.Payments = gateway
+
+	merchantId := uuid.New().String()
+	intent := capturedPayment(merchantId)
+
+	s.db.EXPECT().ReadPaymentIntentById(intent.ID).Return(intent, nil)
+	s.db.EXPECT().ReserveRefund(gomock.Any()).DoAndReturn(func(rf *model.Refund) error {
+		require.Equal(s.T(), int64(1500), rf.Amount)
+		return nil
+	})
+	s.db.EXPECT().CompleteRefund(gomock.Any(), "fake_refund_1").Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateRefund(rr, newMerchantRequest(http.MethodPost, "/payments/"+intent.ID+"/refunds", `{"reasonCode": "requested_by_customer"}`, merchantId, intent.ID))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	dto := &model.RefundDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), model.RefundStatusSucceeded, dto.Status)
+	require.Equal(s.T(), []fakepay.Call{{Op: "refund", Reference: "fake_pay_9", Amount: 1500}}, gateway.Calls())
+}
+
+func (s *Suite) Test_handler_Create_Refund_Exceeding_Balance() {
+	s.server.Payments = fakepay.New("")
+
+	merchantId := uuid.New().String()
+	intent := capturedPayment(merchantId)
+
+	s.db.EXPECT().ReadPaymentIntentById(intent.ID).Return(intent, nil)
+	s.db.EXPECT().ReserveRefund(gomock.Any()).Return(model.ErrRefundExceedsBalance)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateRefund(rr, newMerchantRequest(http.MethodPost, "/payments/"+intent.ID+"/refunds", `{"amount": 1500, "reasonCode": "duplicate"}`, merchantId, intent.ID))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Refund_Declined() {
+	gateway := fakepay.New("")
+	gateway.Script(fakepay.Decline("refund_window_closed"))
+	s.server.Payments = gateway
+
+	merchantId := uuid.New().String()
+	intent := capturedPayment(merchantId)
+
+	s.db.EXPECT().ReadPaymentIntentById(intent.ID).Return(intent, nil)
+	s.db.EXPECT().ReserveRefund(gomock.Any()).Return(nil)
+	s.db.EXPECT().FailRefund(gomock.Any(), "refund_window_closed").Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateRefund(rr, newMerchantRequest(http.MethodPost, "/payments/"+intent.ID+"/refunds", `{"amount": 500, "reasonCode": "other"}`, merchantId, intent.ID))
+
+	require.Equal(s.T(), http.StatusPaymentRequired, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Refund_Of_Uncaptured_Payment() {
+	merchantId := uuid.New().String()
+	intent := capturedPayment(merchantId)
+	intent.Status = model.PaymentIntentStatusAuthorized
+
+	s.db.EXPECT().ReadPaymentIntentById(intent.ID).Return(intent, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateRefund(rr, newMerchantRequest(http.MethodPost, "/payments/"+intent.ID+"/refunds", `{"reasonCode": "other"}`, merchantId, intent.ID))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
@@ -1,43 +1,115 @@
 package handler
 
 import (
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"merchant/blob"
+	"merchant/dataexport"
+	"merchant/keyring"
 	"merchant/mock/mock_repository"
+	"merchant/model"
+	"merchant/payment"
 	"merchant/repository"
+	"merchant/teamimport"
 )
 
+// DefaultRequestTimeout is how long handling a request may take unless
+// configured otherwise.
+const DefaultRequestTimeout = 30 * time.Second
+
 var (
 	srvErrDataCreationFailure    = "data creation failure"
 	srvErrDataAccessFailure      = "data access failure"
 	srvErrDataDuplicateInsertion = "data duplicate insertion"
 	srvErrDataUpdateFailure      = "data update failure"
 	srvErrDataDeleteFailure      = "data delete failure"
+	srvErrDataConcurrentUpdate   = "data was changed concurrently"
+	srvErrPreconditionFailed     = "data was changed since it was read"
+	srvErrPreconditionRequired   = "if-match header required"
+
+	srvErrIdempotentRequestInFlight = "request with this idempotency key is in progress"
 
 	srvErrFormDecodingFailure   = "form decoding failure"
 	srvErrHashGenerationFailure = "hash generation failure"
 	srvErrJsonCreationFailure   = "json creation failure"
 
 	srvErrAuthenticationFailure = "authentication failure"
+	srvErrAccessForbidden       = "access forbidden"
+	srvErrMerchantNotVerified   = "merchant not verified"
+	srvErrVerificationLocked    = "verification is under review or approved"
+
+	srvErrClosureGracePeriodOver = "closure grace period is over"
+
+	srvErrInsufficientStock = "insufficient stock"
+	srvErrInvalidQuantity   = "invalid quantity"
+
+	srvErrOrderNotEditable = "order is not a draft"
+	srvErrOrderNotPayable  = "order is not awaiting payment"
+	srvErrOrderHasCoupon   = "order has a coupon already"
+
+	srvErrOrderNotInvoiceable   = "order is a draft or cancelled"
+	srvErrOrderAlreadyInvoiced  = "order already has an invoice"
+	srvErrDocumentRenderFailure = "document rendering failure"
+
+	srvErrPaymentDeclined       = "payment declined"
+	srvErrPaymentGatewayFailure = "payment gateway failure"
+	srvErrInvalidCallback       = "invalid callback"
+	srvErrPaymentInProgress     = "order has a payment in progress"
+	srvErrRefundExceedsBalance  = "refund exceeds refundable balance"
+
+	srvErrFileTooLarge         = "file too large"
+	srvErrUnsupportedMediaType = "unsupported media type"
+	srvErrNotAcceptable        = "requested media type is not available"
+	srvErrImageDecodingFailure = "image decoding failure"
+	srvErrBlobStorageFailure   = "blob storage failure"
+
+	srvErrInvalidDownloadLink = "invalid download link"
+	srvErrDownloadLinkExpired = "download link expired"
 )
 
 type Server struct {
-	DB        repository.Repository
-	Validator *validator.Validate
-	Logger    *zap.Logger
+	DB          repository.Repository
+	Blob        blob.Storage
+	Payments    payment.Gateway
+	Imports     *teamimport.Importer
+	DataExports *dataexport.Exporter
+	Keys        *keyring.Manager
+	Validator   *validator.Validate
+	Logger      *zap.Logger
+	// RequireIfMatch makes updates and deletes of merchants and team members
+	// fail with precondition required unless they carry an If-Match header.
+	RequireIfMatch bool
+	// ClosureGracePeriod is how long a merchant may cancel the closure of
+	// its account before its data is erased.
+	ClosureGracePeriod time.Duration
+	// RequestTimeout bounds handling a request sent with an idempotency key.
+	RequestTimeout time.Duration
 }
 
 func New(
 	db *gorm.DB,
+	storage blob.Storage,
+	gateway payment.Gateway,
 	validator *validator.Validate,
 	logger *zap.Logger,
 ) *Server {
+	repo := repository.New(db)
+
 	return &Server{
-		DB:        repository.New(db),
-		Validator: validator,
-		Logger:    logger,
+		DB:          repo,
+		Blob:        storage,
+		Payments:    gateway,
+		Imports:     teamimport.NewImporter(repo, storage, validator, logger),
+		DataExports: dataexport.NewExporter(repo, storage, logger),
+		Validator:   validator,
+		Logger:      logger,
+
+		ClosureGracePeriod: model.DefaultClosureGracePeriod,
+		RequestTimeout:     DefaultRequestTimeout,
 	}
 }
 
@@ -47,8 +119,13 @@ func NewMockServer(
 	logger *zap.Logger,
 ) *Server {
 	return &Server{
-		DB:        db,
-		Validator: validator,
-		Logger:    logger,
+		DB:          db,
+		Imports:     teamimport.NewImporter(db, nil, validator, logger),
+		DataExports: dataexport.NewExporter(db, nil, logger),
+		Validator:   validator,
+		Logger:      logger,
+
+		ClosureGracePeriod: model.DefaultClosureGracePeriod,
+		RequestTimeout:     DefaultRequestTimeout,
 	}
 }
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// ReadSettings godoc
+// @Summary Read settings
+// @Description get the settings of the merchant
+// @tags settings
+// @Produce  json
+// @Success 200 {object} model.MerchantSettingsDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /settings [get]
+func (srv *Server) HandleReadSettings(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	settings, err := srv.DB.ReadMerchantSettingsByMerchantId(merchantId)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+			return
+		}
+
+		// Merchants registered before settings existed use the defaults.
+		settings = model.DefaultMerchantSettings(merchantId)
+	}
+
+	dto := settings.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// UpdateSettings godoc
+// @Summary Update settings
+// @Description replace the settings of the merchant
+// @tags settings
+// @Accept  json
+// @Param body body model.MerchantSettingsForm true "Merchant settings"
+// @Success 202 {string} string "accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /settings [put]
+func (srv *Server) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	form := &model.MerchantSettingsForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	if err := srv.DB.SaveMerchantSettings(form.ToModel(merchantId)); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
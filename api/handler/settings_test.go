@@ -0,0 +1,78 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_handler_Read_Settings() {
+	merchantId := uuid.New().String()
+	settings := &model.MerchantSettings{MerchantID: merchantId, Currency: "GBP", Locale: "en-GB", Timezone: "Europe/London"}
+
+	s.db.EXPECT().ReadMerchantSettingsByMerchantId(merchantId).Return(settings, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadSettings(rr, newMerchantRequest(http.MethodGet, "/settings", "", merchantId, ""))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dto := &model.MerchantSettingsDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), "GBP", dto.Currency)
+	require.Equal(s.T(), "Europe/London", dto.Timezone)
+}
+
+func (s *Suite) Test_handler_Read_Settings_Defaults() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().ReadMerchantSettingsByMerchantId(merchantId).Return(nil, gorm.ErrRecordNotFound)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadSettings(rr, newMerchantRequest(http.MethodGet, "/settings", "", merchantId, ""))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+
+	dto := &model.MerchantSettingsDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), model.DefaultMerchantSettings(merchantId).ToDto(), dto)
+}
+
+func (s *Suite) Test_handler_Update_Settings() {
+	merchantId := uuid.New().String()
+	body := `{"merchantID": "` + uuid.New().String() + `", "currency": "GBP", "locale": "en-GB", "timezone": "Europe/London", "notifications": {"weeklySummary": true}}`
+
+	s.db.EXPECT().SaveMerchantSettings(gomock.Any()).DoAndReturn(func(settings *model.MerchantSettings) error {
+		require.Equal(s.T(), merchantId, settings.MerchantID)
+		require.Equal(s.T(), "GBP", settings.Currency)
+		require.True(s.T(), settings.Notifications.WeeklySummary)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateSettings(rr, newMerchantRequest(http.MethodPut, "/settings", body, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Settings_Invalid() {
+	bodies := []string{
+		`{"currency": "XXY", "locale": "en-GB", "timezone": "Europe/London"}`,
+		`{"currency": "GBP", "locale": "not a locale", "timezone": "Europe/London"}`,
+		`{"currency": "GBP", "locale": "en-GB", "timezone": "Europe/Soho"}`,
+	}
+
+	for _, body := range bodies {
+		rr := httptest.NewRecorder()
+		s.server.HandleUpdateSettings(rr, newMerchantRequest(http.MethodPut, "/settings", body, uuid.New().String(), ""))
+
+		require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code, body)
+	}
+}
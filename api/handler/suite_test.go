@@ -3,13 +3,13 @@ package handler_test
 import (
 	"testing"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 
 	"merchant/api/handler"
 	"merchant/mock/mock_repository"
+	"merchant/util/validator"
 )
 
 type Suite struct {
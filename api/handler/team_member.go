@@ -9,22 +9,30 @@ import (
 	"gorm.io/gorm"
 
 	"merchant/model"
+	"merchant/repository"
 )
 
 // ListTeamMember godoc
 // @Summary List team members
 // @Description get team members list
 // @Produce  json
+// @Param metadata[key] query string false "only members whose metadata holds the value at the key; repeatable with other keys"
 // @Success 200 {array} model.TeamMemberDtos
 // @Header 200 {string} Token "qwerty"
 // @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /team-members [get]
 func (srv *Server) HandleListTeamMember(w http.ResponseWriter, r *http.Request) {
 	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
 	merchantId := userDetails.UserId.String()
 
-	members, err := srv.DB.ListTeamMembersByMerchantId(merchantId)
+	metadata, ok := parseMetadataFilter(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := srv.DB.ListTeamMembersByMerchantId(merchantId, metadata)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
@@ -50,6 +58,7 @@ func (srv *Server) HandleListTeamMember(w http.ResponseWriter, r *http.Request)
 // @Description add a member to merchant team
 // @Accept  json
 // @Param body body model.TeamMemberCreateForm true "Create a team member"
+// @Param Idempotency-Key header string false "Key making retries of the request safe"
 // @Success 200 {string} string "ok"
 // @Header 200 {string} Token "qwerty"
 // @Failure 400,404 {object} httputil.HTTPError
@@ -86,7 +95,14 @@ func (srv *Server) HandleCreateTeamMember(w http.ResponseWriter, r *http.Request
 
 	member := form.ToModel(merchantId)
 
-	if err := srv.DB.CreateTeamMember(member); err != nil {
+	err := srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.CreateTeamMember(member); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewTeamMemberEvent(model.EventTeamMemberCreated, member))
+	})
+	if err != nil {
 		srv.Logger.Warn(err.Error())
 
 		w.WriteHeader(http.StatusInternalServerError)
@@ -105,6 +121,7 @@ func (srv *Server) HandleCreateTeamMember(w http.ResponseWriter, r *http.Request
 // @Param id path string true "Team Member ID"
 // @Success 200 {object} model.TeamMemberDto
 // @Header 200 {string} Token "qwerty"
+// @Header 200 {string} ETag "version of the member"
 // @Failure 400,404 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /team-members/{id} [get]
@@ -125,6 +142,8 @@ func (srv *Server) HandleReadTeamMember(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	w.Header().Set("ETag", etag(member.Version))
+
 	dto := member.ToDto()
 	if err := json.NewEncoder(w).Encode(dto); err != nil {
 		srv.Logger.Warn(err.Error())
@@ -141,9 +160,10 @@ func (srv *Server) HandleReadTeamMember(w http.ResponseWriter, r *http.Request)
 // @Produce  json
 // @Param body body model.TeamMemberUpdateForm true "Update a team member"
 // @Param id path string true "Team Member ID"
+// @Param If-Match header string false "ETag of the member as read"
 // @Success 200 {string} string	"accepted"
 // @Header 200 {string} Token "qwerty"
-// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 400,404,409,412,428 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /team-members/{id} [put]
 func (srv *Server) HandleUpdateTeamMember(w http.ResponseWriter, r *http.Request) {
@@ -161,7 +181,8 @@ func (srv *Server) HandleUpdateTeamMember(w http.ResponseWriter, r *http.Request
 
 	id := chi.URLParam(r, "id")
 
-	if _, err := srv.DB.ReadTeamMemberById(id); err != nil {
+	current, err := srv.DB.ReadTeamMemberById(id)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			return
@@ -174,9 +195,32 @@ func (srv *Server) HandleUpdateTeamMember(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if !srv.checkIfMatch(w, r, current.Version) {
+		return
+	}
+
 	member := form.ToModel(id)
 
-	if err := srv.DB.UpdateTeamMemberById(id, member); err != nil {
+	err = srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.UpdateTeamMemberById(id, current.Version, member); err != nil {
+			return err
+		}
+
+		// The form only carries the changed fields, so the event reports the
+		// member as stored.
+		updated, err := tx.ReadTeamMemberById(id)
+		if err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewTeamMemberEvent(model.EventTeamMemberUpdated, updated))
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			writeVersionConflict(w, r)
+			return
+		}
+
 		srv.Logger.Warn(err.Error())
 
 		w.WriteHeader(http.StatusInternalServerError)
@@ -191,15 +235,17 @@ func (srv *Server) HandleUpdateTeamMember(w http.ResponseWriter, r *http.Request
 // @Summary Remove a team member from merchant
 // @Description remove a member
 // @Param id path string true "Team Member ID"
+// @Param If-Match header string false "ETag of the member as read"
 // @Success 200 {string} string	"ok"
 // @Header 200 {string} Token "qwerty"
-// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 400,404,409,412,428 {object} httputil.HTTPError
 // @Failure 500 {object} httputil.HTTPError
 // @Router /team-members/{id} [delete]
 func (srv *Server) HandleDeleteTeamMember(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	if _, err := srv.DB.ReadMerchantById(id); err != nil {
+	member, err := srv.DB.ReadTeamMemberById(id)
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			return
@@ -212,12 +258,27 @@ func (srv *Server) HandleDeleteTeamMember(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := srv.DB.DeleteMerchant(id); err != nil {
+	if !srv.checkIfMatch(w, r, member.Version) {
+		return
+	}
+
+	err = srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.DeleteTeamMember(id, member.Version); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewTeamMemberEvent(model.EventTeamMemberDeleted, member))
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			writeVersionConflict(w, r)
+			return
+		}
+
 		srv.Logger.Warn(err.Error())
 
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDeleteFailure)
 		return
 	}
-
 }
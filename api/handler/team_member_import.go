@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/teamimport"
+)
+
+const maxTeamMemberImportBytes = 5 << 20
+
+// MaxTeamMemberImportRequestBytes bounds the body of an import request.
+const MaxTeamMemberImportRequestBytes = maxTeamMemberImportBytes + multipartOverhead
+
+// ImportTeamMembers godoc
+// @Summary Import team members
+// @Description add the team members listed in a CSV or XLSX file of up to 5MB, whose first line names the givenName, familyName, email and optional isOwner columns. Files of up to 100 rows are imported right away and answered with 201; larger files are imported in the background and answered with 202 and the import to poll.
+// @tags team-members
+// @Accept  mpfd
+// @Produce  json
+// @Param mode query string false "Import nothing unless every row is valid, or the valid rows" Enums(all_or_nothing, best_effort)
+// @Param dryRun query bool false "Validate the rows without importing any"
+// @Param file formData file true "CSV or XLSX file"
+// @Success 201,202 {object} model.TeamMemberImportDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,413 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /team-members/import [post]
+func (srv *Server) HandleImportTeamMembers(w http.ResponseWriter, r *http.Request) {
+	form := &model.TeamMemberImportForm{
+		Mode: r.URL.Query().Get("mode"),
+	}
+	if form.Mode == "" {
+		form.Mode = model.TeamMemberImportModeAllOrNothing
+	}
+	if v := r.URL.Query().Get("dryRun"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrInvalidDryRun)
+			return
+		}
+		form.DryRun = dryRun
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxTeamMemberImportRequestBytes)
+	data, fileName, ok := srv.readMultipartFile(w, r, "file", maxTeamMemberImportBytes)
+	if !ok {
+		return
+	}
+
+	format := teamimport.DetectFormat(fileName, data)
+	rows, err := teamimport.Read(format, data)
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		switch err {
+		case teamimport.ErrMissingColumns:
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrImportColumns)
+		case teamimport.ErrTooManyRows:
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrImportTooManyRows)
+		default:
+			fmt.Fprintf(w, `{"error": "%v"}`, valErrImportUnreadable)
+		}
+		return
+	}
+
+	id := uuid.New().String()
+	job := &model.TeamMemberImport{
+		Model: model.Model{
+			ID: id,
+		},
+		MerchantID: merchantId,
+		Format:     format,
+		FileName:   fileName,
+		Mode:       form.Mode,
+		DryRun:     form.DryRun,
+		Status:     model.TeamMemberImportStatusPending,
+		TotalRows:  len(rows),
+	}
+
+	status := http.StatusCreated
+	if len(rows) <= teamimport.SyncRows {
+		now := time.Now()
+		job.StartedAt = &now
+		srv.Imports.Import(job, rows)
+	} else {
+		job.BlobKey = fmt.Sprintf("imports/%s/%s.%s", merchantId, id, format)
+		if err := srv.Blob.Put(r.Context(), job.BlobKey, bytes.NewReader(data)); err != nil {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrBlobStorageFailure)
+			return
+		}
+		status = http.StatusAccepted
+	}
+
+	if err := srv.DB.CreateTeamMemberImport(job); err != nil {
+		srv.Logger.Warn(err.Error())
+		srv.deleteBlobs(r, job.BlobKey)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/team-member-imports/"+id)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(job.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+// ReadTeamMemberImport godoc
+// @Summary Read team member import
+// @Description get the progress and outcome of an import, with the problems of every invalid row
+// @tags team-members
+// @Produce  json
+// @Param id path string true "Import ID"
+// @Success 200 {object} model.TeamMemberImportDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /team-member-imports/{id} [get]
+func (srv *Server) HandleReadTeamMemberImport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	job, err := srv.DB.ReadTeamMemberImportById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if job.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(job.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
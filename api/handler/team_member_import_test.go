@@ -0,0 +1,67 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func newImportRequest(s *Suite, merchantId, query, content string) *http.Request {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, err := mw.CreateFormFile("file", "staff.csv")
+	require.NoError(s.T(), err)
+	_, err = fw.Write([]byte(content))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), mw.Close())
+
+	r := newMerchantRequest(http.MethodPost, "/team-members/import"+query, "", merchantId, "")
+	r.Body = httptest.NewRequest(http.MethodPost, "/", body).Body
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return r
+}
+
+func (s *Suite) Test_handler_Import_Team_Members_Dry_Run() {
+	merchantId := uuid.New().String()
+	content := "givenName,familyName,email\nAda,Lovelace,ada@example.com\nGrace,Hopper,grace@example.com\n"
+
+	s.db.EXPECT().ListTeamMembersByMerchantId(merchantId, gomock.Nil()).
+		Return(model.TeamMembers{{Email: "grace@example.com", MerchantID: merchantId}}, nil)
+	s.db.EXPECT().CreateTeamMemberImport(gomock.Any()).DoAndReturn(func(i *model.TeamMemberImport) error {
+		require.Equal(s.T(), merchantId, i.MerchantID)
+		require.True(s.T(), i.DryRun)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleImportTeamMembers(rr, newImportRequest(s, merchantId, "?mode=best_effort&dryRun=true", content))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	dto := &model.TeamMemberImportDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), model.TeamMemberImportStatusSucceeded, dto.Status)
+	require.Equal(s.T(), 2, dto.TotalRows)
+	require.Equal(s.T(), 1, dto.ValidRows)
+	require.Equal(s.T(), 0, dto.ImportedRows)
+	require.Equal(s.T(), []*model.TeamMemberImportRowError{{Line: 3, Errors: []string{"email is a team member already"}}}, dto.RowErrors)
+	require.Equal(s.T(), "/api/v1/team-member-imports/"+dto.ID, rr.Header().Get("Location"))
+}
+
+func (s *Suite) Test_handler_Import_Team_Members_Missing_Columns() {
+	merchantId := uuid.New().String()
+
+	rr := httptest.NewRecorder()
+	s.server.HandleImportTeamMembers(rr, newImportRequest(s, merchantId, "", "name,email\nAda,ada@example.com\n"))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
@@ -0,0 +1,185 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// expectTransaction runs transactions of the handler against the mock
+// repository itself.
+func (s *Suite) expectTransaction() {
+	s.db.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(repository.Repository) error) error {
+		return fn(s.db)
+	})
+}
+
+func newCreateTeamMemberRequest(merchantId string) *http.Request {
+	body := `{"isOwner": false, "givenName": "Ada", "familyName": "Lovelace", "email": "ada@example.com"}`
+
+	r := httptest.NewRequest(http.MethodPost, "/team-members", strings.NewReader(body))
+	return r.WithContext(context.WithValue(r.Context(), model.CtxKeyXUser, model.CtxUser{UserId: uuid.MustParse(merchantId)}))
+}
+
+func (s *Suite) Test_handler_Create_Team_Member_Records_Event() {
+	merchantId := uuid.New().String()
+
+	var member *model.TeamMember
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(&model.Merchant{Model: model.Model{ID: merchantId}}, nil)
+	s.expectTransaction()
+	s.db.EXPECT().CreateTeamMember(gomock.Any()).DoAndReturn(func(t *model.TeamMember) error {
+		member = t
+		return nil
+	})
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).DoAndReturn(func(e *model.OutboxEvent) error {
+		require.Equal(s.T(), model.EventTeamMemberCreated, e.Type)
+		require.Equal(s.T(), model.AggregateTeamMember, e.AggregateType)
+		require.Equal(s.T(), member.ID, e.AggregateID)
+		require.Equal(s.T(), merchantId, e.MerchantID)
+		require.Contains(s.T(), e.Payload, `"email":"ada@example.com"`)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateTeamMember(rr, newCreateTeamMemberRequest(merchantId))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Team_Member_Outbox_Failure() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().ReadMerchantById(merchantId).Return(&model.Merchant{Model: model.Model{ID: merchantId}}, nil)
+	s.expectTransaction()
+	s.db.EXPECT().CreateTeamMember(gomock.Any()).Return(nil)
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).Return(errors.New("connection lost"))
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateTeamMember(rr, newCreateTeamMemberRequest(merchantId))
+
+	require.Equal(s.T(), http.StatusInternalServerError, rr.Code)
+}
+
+func (s *Suite) Test_handler_Read_Team_Member_ETag() {
+	merchantId := uuid.New().String()
+	member := &model.TeamMember{Model: model.Model{ID: uuid.New().String(), Version: 4}, MerchantID: merchantId}
+
+	s.db.EXPECT().ReadTeamMemberById(member.ID).Return(member, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadTeamMember(rr, newMerchantRequest(http.MethodGet, "/team-members/"+member.ID, "", merchantId, member.ID))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Equal(s.T(), `"4"`, rr.Header().Get("ETag"))
+}
+
+func (s *Suite) Test_handler_Update_Team_Member_If_Match() {
+	merchantId := uuid.New().String()
+	member := &model.TeamMember{Model: model.Model{ID: uuid.New().String(), Version: 4}, MerchantID: merchantId}
+	body := `{"isOwner": true, "givenName": "Ada", "familyName": "King"}`
+
+	s.db.EXPECT().ReadTeamMemberById(member.ID).Return(member, nil).Times(2)
+	s.expectTransaction()
+	s.db.EXPECT().UpdateTeamMemberById(member.ID, int64(4), gomock.Any()).Return(nil)
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).Return(nil)
+
+	r := newMerchantRequest(http.MethodPut, "/team-members/"+member.ID, body, merchantId, member.ID)
+	r.Header.Set("If-Match", `"3", "4"`)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateTeamMember(rr, r)
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Team_Member_Stale_If_Match() {
+	merchantId := uuid.New().String()
+	member := &model.TeamMember{Model: model.Model{ID: uuid.New().String(), Version: 5}, MerchantID: merchantId}
+	body := `{"isOwner": true, "givenName": "Ada", "familyName": "King"}`
+
+	s.db.EXPECT().ReadTeamMemberById(member.ID).Return(member, nil)
+
+	r := newMerchantRequest(http.MethodPut, "/team-members/"+member.ID, body, merchantId, member.ID)
+	r.Header.Set("If-Match", `"4"`)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateTeamMember(rr, r)
+
+	require.Equal(s.T(), http.StatusPreconditionFailed, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Team_Member_Lost_Race() {
+	merchantId := uuid.New().String()
+	member := &model.TeamMember{Model: model.Model{ID: uuid.New().String(), Version: 4}, MerchantID: merchantId}
+	body := `{"isOwner": true, "givenName": "Ada", "familyName": "King"}`
+
+	s.db.EXPECT().ReadTeamMemberById(member.ID).Return(member, nil)
+	s.expectTransaction()
+	s.db.EXPECT().UpdateTeamMemberById(member.ID, int64(4), gomock.Any()).Return(repository.ErrConflict)
+
+	r := newMerchantRequest(http.MethodPut, "/team-members/"+member.ID, body, merchantId, member.ID)
+	r.Header.Set("If-Match", `"4"`)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateTeamMember(rr, r)
+
+	require.Equal(s.T(), http.StatusPreconditionFailed, rr.Code)
+}
+
+func (s *Suite) Test_handler_Delete_Team_Member_If_Match_Required() {
+	s.server.RequireIfMatch = true
+	defer func() { s.server.RequireIfMatch = false }()
+
+	merchantId := uuid.New().String()
+	member := &model.TeamMember{Model: model.Model{ID: uuid.New().String(), Version: 4}, MerchantID: merchantId}
+
+	s.db.EXPECT().ReadTeamMemberById(member.ID).Return(member, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleDeleteTeamMember(rr, newMerchantRequest(http.MethodDelete, "/team-members/"+member.ID, "", merchantId, member.ID))
+
+	require.Equal(s.T(), http.StatusPreconditionRequired, rr.Code)
+}
+
+func (s *Suite) Test_handler_List_Team_Member_Metadata_Filter() {
+	merchantId := uuid.New().String()
+	member := &model.TeamMember{Model: model.Model{ID: uuid.New().String()}, MerchantID: merchantId, Metadata: model.Metadata{"employeeId": "E-42"}}
+
+	s.db.EXPECT().ListTeamMembersByMerchantId(merchantId, model.Metadata{"employeeId": "E-42"}).Return(model.TeamMembers{member}, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleListTeamMember(rr, newMerchantRequest(http.MethodGet, "/team-members?metadata[employeeId]=E-42", "", merchantId, ""))
+
+	require.Equal(s.T(), http.StatusOK, rr.Code)
+	require.Contains(s.T(), rr.Body.String(), `"metadata":{"employeeId":"E-42"}`)
+}
+
+func (s *Suite) Test_handler_List_Team_Member_Invalid_Metadata_Key() {
+	merchantId := uuid.New().String()
+
+	rr := httptest.NewRecorder()
+	s.server.HandleListTeamMember(rr, newMerchantRequest(http.MethodGet, "/team-members?metadata[employee%20id]=E-42", "", merchantId, ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Update_Team_Member_Metadata_Too_Long() {
+	merchantId := uuid.New().String()
+	id := uuid.New().String()
+	body := `{"isOwner": true, "givenName": "Ada", "familyName": "King", "metadata": {"notes": "` + strings.Repeat("a", 501) + `"}}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleUpdateTeamMember(rr, newMerchantRequest(http.MethodPut, "/team-members/"+id, body, merchantId, id))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
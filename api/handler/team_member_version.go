@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// ListTeamMemberVersion godoc
+// @Summary List team member versions
+// @Description get the versions of the member, newest first. Each write of the member records a version.
+// @Produce  json
+// @Param id path string true "Team Member ID"
+// @Param at query string false "only the versions before this date or RFC 3339 date-time, the first being the member as it was then"
+// @Param limit query int false "page size, 20 by default and at most 100"
+// @Param offset query int false "number of versions to skip"
+// @Success 200 {array} model.TeamMemberVersionDtos
+// @Header 200 {string} X-Total-Count "number of matching versions"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /team-members/{id}/versions [get]
+func (srv *Server) HandleListTeamMemberVersion(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	at, err := parseDateParam(r, "at", true)
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrInvalidDate)
+		return
+	}
+
+	member, ok := srv.readOwnedTeamMember(w, r)
+	if !ok {
+		return
+	}
+
+	versions, total, err := srv.DB.ListTeamMemberVersions(member.ID, at, limit, offset)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	if len(versions) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := versions.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// ReadTeamMemberVersion godoc
+// @Summary Read team member version
+// @Description get the member as it was at a version
+// @Produce  json
+// @Param id path string true "Team Member ID"
+// @Param version path int true "Version"
+// @Success 200 {object} model.TeamMemberVersionDto
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /team-members/{id}/versions/{version} [get]
+func (srv *Server) HandleReadTeamMemberVersion(w http.ResponseWriter, r *http.Request) {
+	member, ok := srv.readOwnedTeamMember(w, r)
+	if !ok {
+		return
+	}
+
+	version, ok := srv.readTeamMemberVersion(w, r, member.ID)
+	if !ok {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(version.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// RestoreTeamMemberVersion godoc
+// @Summary Restore team member version
+// @Description set the owner flag, names and metadata of the member back to those of a version, which records another version
+// @Produce  json
+// @Param id path string true "Team Member ID"
+// @Param version path int true "Version to restore"
+// @Param If-Match header string false "ETag of the member as read"
+// @Success 202 {object} model.TeamMemberDto
+// @Header 202 {string} ETag "version of the member"
+// @Failure 400,404,409,412,428 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /team-members/{id}/versions/{version}/restore [post]
+func (srv *Server) HandleRestoreTeamMemberVersion(w http.ResponseWriter, r *http.Request) {
+	current, ok := srv.readOwnedTeamMember(w, r)
+	if !ok {
+		return
+	}
+
+	version, ok := srv.readTeamMemberVersion(w, r, current.ID)
+	if !ok {
+		return
+	}
+
+	if !srv.checkIfMatch(w, r, current.Version) {
+		return
+	}
+
+	member := version.ToTeamMember()
+	member.Metadata = member.Metadata.ToDto()
+
+	var restored *model.TeamMember
+	err := srv.DB.Transaction(func(tx repository.Repository) error {
+		if err := tx.UpdateTeamMemberById(current.ID, current.Version, member); err != nil {
+			return err
+		}
+
+		var err error
+		if restored, err = tx.ReadTeamMemberById(current.ID); err != nil {
+			return err
+		}
+
+		return tx.CreateOutboxEvent(model.NewTeamMemberEvent(model.EventTeamMemberUpdated, restored))
+	})
+	if err != nil {
+		if err == repository.ErrConflict {
+			writeVersionConflict(w, r)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.Header().Set("ETag", etag(restored.Version))
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(restored.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+// readOwnedTeamMember reads the team member in the URL and writes a not
+// found response when it does not exist or belongs to another merchant.
+func (srv *Server) readOwnedTeamMember(w http.ResponseWriter, r *http.Request) (*model.TeamMember, bool) {
+	member, err := srv.DB.ReadTeamMemberById(chi.URLParam(r, "id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if member.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return member, true
+}
+
+// readTeamMemberVersion reads the version in the URL of the member and
+// writes a not found response when it does not exist.
+func (srv *Server) readTeamMemberVersion(w http.ResponseWriter, r *http.Request, teamMemberId string) (*model.TeamMemberVersion, bool) {
+	n, err := strconv.ParseInt(chi.URLParam(r, "version"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	version, err := srv.DB.ReadTeamMemberVersion(teamMemberId, n)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	return version, true
+}
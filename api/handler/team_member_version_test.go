@@ -0,0 +1,53 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/util/cryptoutil"
+)
+
+func (s *Suite) Test_handler_Read_Team_Member_Version_Of_Another_Merchant() {
+	member := &model.TeamMember{Model: model.Model{ID: uuid.New().String(), Version: 4}, MerchantID: uuid.New().String()}
+
+	s.db.EXPECT().ReadTeamMemberById(member.ID).Return(member, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReadTeamMemberVersion(rr, newVersionRequest(http.MethodGet, "/team-members/"+member.ID+"/versions/1", uuid.New().String(), member.ID, "1"))
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
+
+func (s *Suite) Test_handler_Restore_Team_Member_Version() {
+	merchantId := uuid.New().String()
+	member := &model.TeamMember{Model: model.Model{ID: uuid.New().String(), Version: 4}, MerchantID: merchantId, GivenName: cryptoutil.EncryptedString("Ada")}
+	version := &model.TeamMemberVersion{TeamMemberID: member.ID, MerchantID: merchantId, Version: 1, GivenName: cryptoutil.EncryptedString("Augusta")}
+	restored := &model.TeamMember{Model: model.Model{ID: member.ID, Version: 5}, MerchantID: merchantId, GivenName: cryptoutil.EncryptedString("Augusta")}
+
+	s.db.EXPECT().ReadTeamMemberById(member.ID).Return(member, nil)
+	s.db.EXPECT().ReadTeamMemberVersion(member.ID, int64(1)).Return(version, nil)
+	s.expectTransaction()
+	s.db.EXPECT().UpdateTeamMemberById(member.ID, int64(4), gomock.Any()).DoAndReturn(func(_ string, _ int64, t *model.TeamMember) error {
+		require.Equal(s.T(), "Augusta", t.GivenName.String())
+		require.Equal(s.T(), model.Metadata{}, t.Metadata)
+		return nil
+	})
+	s.db.EXPECT().ReadTeamMemberById(member.ID).Return(restored, nil)
+	s.db.EXPECT().CreateOutboxEvent(gomock.Any()).Return(nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRestoreTeamMemberVersion(rr, newVersionRequest(http.MethodPost, "/team-members/"+member.ID+"/versions/1/restore", merchantId, member.ID, "1"))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+	require.Equal(s.T(), `"5"`, rr.Header().Get("ETag"))
+
+	dto := &model.TeamMemberDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.Equal(s.T(), "Augusta", dto.GivenName)
+}
@@ -0,0 +1,457 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"merchant/blob"
+	"merchant/model"
+	"merchant/repository"
+)
+
+const maxVerificationDocumentBytes = 10 << 20
+
+// MaxVerificationDocumentRequestBytes bounds the body of a document upload.
+const MaxVerificationDocumentRequestBytes = maxVerificationDocumentBytes + multipartOverhead
+
+var documentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+}
+
+// ReadVerification godoc
+// @Summary Read verification
+// @Description get the business verification case of the merchant
+// @tags verification
+// @Produce  json
+// @Success 200 {object} model.VerificationDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /verification [get]
+func (srv *Server) HandleReadVerification(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	verification, err := srv.DB.ReadVerificationByMerchantId(merchantId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	dto := verification.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// SubmitVerification godoc
+// @Summary Submit verification
+// @Description submit the business details of the merchant for verification, or resubmit them before a review starts or after a rejection
+// @tags verification
+// @Accept  json
+// @Param body body model.VerificationForm true "Business details"
+// @Success 201 {string} string "created"
+// @Success 202 {string} string "accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404,409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /verification [put]
+func (srv *Server) HandleSubmitVerification(w http.ResponseWriter, r *http.Request) {
+	form := &model.VerificationForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	existing, err := srv.DB.ReadVerificationByMerchantId(merchantId)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	verification := form.ToModel(merchantId)
+
+	if existing == nil {
+		if err := srv.DB.CreateVerification(verification); err != nil {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+			return
+		}
+
+		srv.Logger.Info(fmt.Sprintf("New Verification submitted: %s", verification.ID))
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	if !existing.CanBeResubmitted() {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrVerificationLocked)
+		return
+	}
+
+	if err := srv.DB.ResubmitVerificationById(existing.ID, verification); err != nil {
+		if err == repository.ErrConflict {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrVerificationLocked)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// UploadVerificationDocument godoc
+// @Summary Upload verification document
+// @Description attach a PDF, PNG or JPEG document of up to 10MB to the verification case
+// @tags verification
+// @Accept  mpfd
+// @Produce  json
+// @Param kind query string true "Document kind" Enums(BusinessRegistration, ProofOfAddress, Identity)
+// @Param file formData file true "Document"
+// @Success 201 {object} model.VerificationDocumentDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404,409,413,415 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /verification/documents [post]
+func (srv *Server) HandleUploadVerificationDocument(w http.ResponseWriter, r *http.Request) {
+	form := &model.VerificationDocumentForm{
+		Kind: r.URL.Query().Get("kind"),
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	verification, err := srv.DB.ReadVerificationByMerchantId(merchantId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if !verification.CanBeResubmitted() {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrVerificationLocked)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxVerificationDocumentRequestBytes)
+	data, fileName, ok := srv.readMultipartFile(w, r, "file", maxVerificationDocumentBytes)
+	if !ok {
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := documentExtensions[contentType]
+	if !ok {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrUnsupportedMediaType)
+		return
+	}
+
+	id := uuid.New().String()
+	document := &model.VerificationDocument{
+		Model: model.Model{
+			ID: id,
+		},
+		VerificationID: verification.ID,
+		Kind:           form.Kind,
+		FileName:       fileName,
+		ContentType:    contentType,
+		Size:           int64(len(data)),
+		BlobKey:        fmt.Sprintf("verifications/%s/%s%s", verification.ID, id, ext),
+	}
+
+	if err := srv.Blob.Put(r.Context(), document.BlobKey, bytes.NewReader(data)); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrBlobStorageFailure)
+		return
+	}
+
+	if err := srv.DB.CreateVerificationDocument(document); err != nil {
+		srv.Logger.Warn(err.Error())
+		srv.deleteBlobs(r, document.BlobKey)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(document.ToDto()); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+// ListVerificationAdmin godoc
+// @Summary List verifications
+// @Description get the verification cases, oldest submission first
+// @tags admin
+// @Produce  json
+// @Param status query string false "Filter by status" Enums(Submitted, InReview, Approved, Rejected)
+// @Success 200 {array} model.VerificationDtos
+// @Header 200 {string} X-Admin-Token "qwerty"
+// @Failure 400,401 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /admin/v1/verifications [get]
+func (srv *Server) HandleListVerificationAdmin(w http.ResponseWriter, r *http.Request) {
+	verifications, err := srv.DB.ListVerificationsByStatus(r.URL.Query().Get("status"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(verifications) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := verifications.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// ReadVerificationAdmin godoc
+// @Summary Read verification
+// @Description get a verification case
+// @tags admin
+// @Produce  json
+// @Param id path string true "Verification ID"
+// @Success 200 {object} model.VerificationDto
+// @Header 200 {string} X-Admin-Token "qwerty"
+// @Failure 400,401,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /admin/v1/verifications/{id} [get]
+func (srv *Server) HandleReadVerificationAdmin(w http.ResponseWriter, r *http.Request) {
+	verification, ok := srv.readVerification(w, r)
+	if !ok {
+		return
+	}
+
+	dto := verification.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// ReadVerificationDocumentAdmin godoc
+// @Summary Download verification document
+// @Description download a document attached to a verification case
+// @tags admin
+// @Param id path string true "Verification ID"
+// @Param documentId path string true "Document ID"
+// @Success 200 {file} file
+// @Header 200 {string} X-Admin-Token "qwerty"
+// @Failure 400,401,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /admin/v1/verifications/{id}/documents/{documentId} [get]
+func (srv *Server) HandleReadVerificationDocumentAdmin(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	documentId := chi.URLParam(r, "documentId")
+
+	document, err := srv.DB.ReadVerificationDocumentById(documentId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if document.VerificationID != id {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	rc, err := srv.Blob.Get(r.Context(), document.BlobKey)
+	if err != nil {
+		if err == blob.ErrNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrBlobStorageFailure)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", document.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", document.FileName))
+
+	if _, err := io.Copy(w, rc); err != nil {
+		srv.Logger.Warn(err.Error())
+	}
+}
+
+// ReviewVerificationAdmin godoc
+// @Summary Review verification
+// @Description move a verification case to in review, approved or rejected, with reviewer notes
+// @tags admin
+// @Accept  json
+// @Param id path string true "Verification ID"
+// @Param body body model.VerificationReviewForm true "Review outcome"
+// @Success 202 {string} string "accepted"
+// @Header 200 {string} X-Admin-Token "qwerty"
+// @Failure 400,401,404,409 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /admin/v1/verifications/{id}/status [put]
+func (srv *Server) HandleReviewVerificationAdmin(w http.ResponseWriter, r *http.Request) {
+	form := &model.VerificationReviewForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+
+	verification, ok := srv.readVerification(w, r)
+	if !ok {
+		return
+	}
+
+	if !verification.CanTransitionTo(form.Status) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error": "cannot move verification from %s to %s"}`, verification.Status, form.Status)
+		return
+	}
+
+	err := srv.DB.UpdateVerificationStatusById(verification.ID, verification.Status, form.Status, form.ReviewerNotes, form.ReviewedBy)
+	if err != nil {
+		if err == repository.ErrConflict {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataConcurrentUpdate)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("Verification %s moved to %s by %s", verification.ID, form.Status, form.ReviewedBy))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// RequireVerifiedMerchant only lets requests of merchants whose business
+// verification was approved through to payment related features.
+func (srv *Server) RequireVerifiedMerchant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+		merchantId := userDetails.UserId.String()
+
+		verification, err := srv.DB.ReadVerificationByMerchantId(merchantId)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			srv.Logger.Warn(err.Error())
+
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+			return
+		}
+
+		if verification == nil || !verification.IsApproved() {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, `{"error": "%v"}`, srvErrMerchantNotVerified)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (srv *Server) readVerification(w http.ResponseWriter, r *http.Request) (*model.Verification, bool) {
+	id := chi.URLParam(r, "id")
+
+	verification, err := srv.DB.ReadVerificationById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	return verification, true
+}
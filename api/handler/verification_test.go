@@ -0,0 +1,197 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+const verificationBody = `{"legalName": "Acme Ltd", "registrationNumber": "12345678", "addressLine1": "1 High Street", "city": "London", "countryCode": "GB"}`
+
+func (s *Suite) requireVerifiedMerchant(merchantId string) *httptest.ResponseRecorder {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.RequireVerifiedMerchant(next).ServeHTTP(rr, newMerchantRequest(http.MethodPost, "/payment-intents", "", merchantId, ""))
+
+	return rr
+}
+
+func (s *Suite) Test_handler_Require_Verified_Merchant_Without_Verification() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().ReadVerificationByMerchantId(merchantId).Return(nil, gorm.ErrRecordNotFound)
+
+	require.Equal(s.T(), http.StatusForbidden, s.requireVerifiedMerchant(merchantId).Code)
+}
+
+func (s *Suite) Test_handler_Require_Verified_Merchant_Not_Approved() {
+	for _, status := range []string{model.VerificationStatusSubmitted, model.VerificationStatusInReview, model.VerificationStatusRejected} {
+		merchantId := uuid.New().String()
+
+		s.db.EXPECT().ReadVerificationByMerchantId(merchantId).Return(&model.Verification{MerchantID: merchantId, Status: status}, nil)
+
+		require.Equal(s.T(), http.StatusForbidden, s.requireVerifiedMerchant(merchantId).Code, status)
+	}
+}
+
+func (s *Suite) Test_handler_Require_Verified_Merchant_Approved() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().ReadVerificationByMerchantId(merchantId).Return(&model.Verification{MerchantID: merchantId, Status: model.VerificationStatusApproved}, nil)
+
+	require.Equal(s.T(), http.StatusOK, s.requireVerifiedMerchant(merchantId).Code)
+}
+
+func (s *Suite) Test_handler_Submit_Verification() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().ReadVerificationByMerchantId(merchantId).Return(nil, gorm.ErrRecordNotFound)
+	s.db.EXPECT().CreateVerification(gomock.Any()).DoAndReturn(func(v *model.Verification) error {
+		require.Equal(s.T(), merchantId, v.MerchantID)
+		require.Equal(s.T(), model.VerificationStatusSubmitted, v.Status)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleSubmitVerification(rr, newMerchantRequest(http.MethodPut, "/verification", verificationBody, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+}
+
+func (s *Suite) Test_handler_Submit_Verification_Invalid() {
+	merchantId := uuid.New().String()
+
+	rr := httptest.NewRecorder()
+	s.server.HandleSubmitVerification(rr, newMerchantRequest(http.MethodPut, "/verification", `{"legalName": "Acme Ltd", "countryCode": "XX"}`, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Resubmit_Rejected_Verification() {
+	merchantId := uuid.New().String()
+	existing := &model.Verification{Model: model.Model{ID: uuid.New().String()}, MerchantID: merchantId, Status: model.VerificationStatusRejected}
+
+	s.db.EXPECT().ReadVerificationByMerchantId(merchantId).Return(existing, nil)
+	s.db.EXPECT().ResubmitVerificationById(existing.ID, gomock.Any()).DoAndReturn(func(_ string, v *model.Verification) error {
+		require.Equal(s.T(), model.VerificationStatusSubmitted, v.Status)
+		return nil
+	})
+
+	rr := httptest.NewRecorder()
+	s.server.HandleSubmitVerification(rr, newMerchantRequest(http.MethodPut, "/verification", verificationBody, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusAccepted, rr.Code)
+}
+
+func (s *Suite) Test_handler_Resubmit_Verification_In_Review() {
+	merchantId := uuid.New().String()
+	existing := &model.Verification{Model: model.Model{ID: uuid.New().String()}, MerchantID: merchantId, Status: model.VerificationStatusInReview}
+
+	s.db.EXPECT().ReadVerificationByMerchantId(merchantId).Return(existing, nil)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleSubmitVerification(rr, newMerchantRequest(http.MethodPut, "/verification", verificationBody, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) Test_handler_Resubmit_Verification_Review_Started_Concurrently() {
+	merchantId := uuid.New().String()
+	existing := &model.Verification{Model: model.Model{ID: uuid.New().String()}, MerchantID: merchantId, Status: model.VerificationStatusSubmitted}
+
+	s.db.EXPECT().ReadVerificationByMerchantId(merchantId).Return(existing, nil)
+	s.db.EXPECT().ResubmitVerificationById(existing.ID, gomock.Any()).Return(repository.ErrConflict)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleSubmitVerification(rr, newMerchantRequest(http.MethodPut, "/verification", verificationBody, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusConflict, rr.Code)
+}
+
+func (s *Suite) reviewVerification(v *model.Verification, status string) *httptest.ResponseRecorder {
+	body := `{"status": "` + status + `", "reviewerNotes": "documents match", "reviewedBy": "ops@example.com"}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleReviewVerificationAdmin(rr, newMerchantRequest(http.MethodPut, "/admin/v1/verifications/"+v.ID+"/status", body, uuid.New().String(), v.ID))
+
+	return rr
+}
+
+func (s *Suite) Test_handler_Review_Verification() {
+	v := &model.Verification{Model: model.Model{ID: uuid.New().String()}, Status: model.VerificationStatusSubmitted}
+
+	s.db.EXPECT().ReadVerificationById(v.ID).Return(v, nil)
+	s.db.EXPECT().UpdateVerificationStatusById(v.ID, model.VerificationStatusSubmitted, model.VerificationStatusInReview, "documents match", "ops@example.com").Return(nil)
+
+	require.Equal(s.T(), http.StatusAccepted, s.reviewVerification(v, model.VerificationStatusInReview).Code)
+}
+
+func (s *Suite) Test_handler_Approve_Verification() {
+	v := &model.Verification{Model: model.Model{ID: uuid.New().String()}, Status: model.VerificationStatusInReview}
+
+	s.db.EXPECT().ReadVerificationById(v.ID).Return(v, nil)
+	s.db.EXPECT().UpdateVerificationStatusById(v.ID, model.VerificationStatusInReview, model.VerificationStatusApproved, "documents match", "ops@example.com").Return(nil)
+
+	require.Equal(s.T(), http.StatusAccepted, s.reviewVerification(v, model.VerificationStatusApproved).Code)
+}
+
+func (s *Suite) Test_handler_Reject_Verification() {
+	v := &model.Verification{Model: model.Model{ID: uuid.New().String()}, Status: model.VerificationStatusInReview}
+
+	s.db.EXPECT().ReadVerificationById(v.ID).Return(v, nil)
+	s.db.EXPECT().UpdateVerificationStatusById(v.ID, model.VerificationStatusInReview, model.VerificationStatusRejected, "documents match", "ops@example.com").Return(nil)
+
+	require.Equal(s.T(), http.StatusAccepted, s.reviewVerification(v, model.VerificationStatusRejected).Code)
+}
+
+func (s *Suite) Test_handler_Review_Verification_Invalid_Transition() {
+	cases := []struct {
+		from string
+		to   string
+	}{
+		{model.VerificationStatusSubmitted, model.VerificationStatusApproved},
+		{model.VerificationStatusApproved, model.VerificationStatusRejected},
+		{model.VerificationStatusRejected, model.VerificationStatusInReview},
+	}
+
+	for _, c := range cases {
+		v := &model.Verification{Model: model.Model{ID: uuid.New().String()}, Status: c.from}
+
+		s.db.EXPECT().ReadVerificationById(v.ID).Return(v, nil)
+
+		require.Equal(s.T(), http.StatusConflict, s.reviewVerification(v, c.to).Code, c.from+" to "+c.to)
+	}
+}
+
+func (s *Suite) Test_handler_Review_Verification_Changed_Concurrently() {
+	v := &model.Verification{Model: model.Model{ID: uuid.New().String()}, Status: model.VerificationStatusSubmitted}
+
+	s.db.EXPECT().ReadVerificationById(v.ID).Return(v, nil)
+	s.db.EXPECT().UpdateVerificationStatusById(v.ID, model.VerificationStatusSubmitted, model.VerificationStatusInReview, gomock.Any(), gomock.Any()).Return(repository.ErrConflict)
+
+	require.Equal(s.T(), http.StatusConflict, s.reviewVerification(v, model.VerificationStatusInReview).Code)
+}
+
+func (s *Suite) Test_handler_Review_Verification_Not_Found() {
+	v := &model.Verification{Model: model.Model{ID: uuid.New().String()}}
+
+	s.db.EXPECT().ReadVerificationById(v.ID).Return(nil, gorm.ErrRecordNotFound)
+
+	require.Equal(s.T(), http.StatusNotFound, s.reviewVerification(v, model.VerificationStatusInReview).Code)
+}
+
+func (s *Suite) Test_handler_Review_Verification_Invalid() {
+	v := &model.Verification{Model: model.Model{ID: uuid.New().String()}}
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, s.reviewVerification(v, "Pending").Code)
+}
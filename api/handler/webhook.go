@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// ListWebhook godoc
+// @Summary List webhook subscriptions
+// @Description get the webhook subscriptions of the merchant
+// @tags webhooks
+// @Produce  json
+// @Success 200 {array} model.WebhookSubscriptionDtos
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /webhooks [get]
+func (srv *Server) HandleListWebhook(w http.ResponseWriter, r *http.Request) {
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	subscriptions, err := srv.DB.ListWebhookSubscriptionsByMerchantId(merchantId)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if len(subscriptions) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := subscriptions.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Create webhook subscription
+// @Description subscribe an https URL of a public host to events of the merchant; deliveries are signed with the secret, which is generated when not given and only returned here
+// @tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param body body model.WebhookSubscriptionForm true "Create a webhook subscription"
+// @Success 201 {object} model.WebhookSubscriptionDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /webhooks [post]
+func (srv *Server) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	form := &model.WebhookSubscriptionForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+	if !form.HasPublicHttpsURL() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrWebhookUrl)
+		return
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	merchantId := userDetails.UserId.String()
+
+	subscription, err := form.ToModel(merchantId)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	if err := srv.DB.CreateWebhookSubscription(subscription); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataCreationFailure)
+		return
+	}
+
+	srv.Logger.Info(fmt.Sprintf("New Webhook Subscription created: %s", subscription.ID))
+	w.WriteHeader(http.StatusCreated)
+
+	dto := subscription.ToDto()
+	dto.Secret = subscription.Secret.String()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+		return
+	}
+}
+
+// ReadWebhook godoc
+// @Summary Read webhook subscription
+// @Description get a webhook subscription
+// @tags webhooks
+// @Produce  json
+// @Param id path string true "Webhook Subscription ID"
+// @Success 200 {object} model.WebhookSubscriptionDto
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /webhooks/{id} [get]
+func (srv *Server) HandleReadWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := srv.readOwnedWebhookSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	dto := subscription.ToDto()
+	if err := json.NewEncoder(w).Encode(dto); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// UpdateWebhook godoc
+// @Summary Update webhook subscription
+// @Description change the URL, event types or active flag of a webhook subscription; the secret is kept
+// @tags webhooks
+// @Accept  json
+// @Param body body model.WebhookSubscriptionForm true "Update a webhook subscription"
+// @Param id path string true "Webhook Subscription ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} validator.ErrResponse
+// @Failure 500 {object} httputil.HTTPError
+// @Router /webhooks/{id} [put]
+func (srv *Server) HandleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	form := &model.WebhookSubscriptionForm{}
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrFormDecodingFailure)
+		return
+	}
+	if srv.handleValidationErrors(w, form) {
+		return
+	}
+	if !form.HasPublicHttpsURL() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprintf(w, `{"error": "%v"}`, valErrWebhookUrl)
+		return
+	}
+
+	existing, ok := srv.readOwnedWebhookSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	subscription := form.ToModelWithId(existing.ID, existing.MerchantID, existing.Secret.String())
+
+	if err := srv.DB.UpdateWebhookSubscriptionById(existing.ID, subscription); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook subscription
+// @Description remove a webhook subscription and its queued and logged deliveries
+// @tags webhooks
+// @Param id path string true "Webhook Subscription ID"
+// @Success 200 {string} string	"ok"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /webhooks/{id} [delete]
+func (srv *Server) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := srv.readOwnedWebhookSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	if err := srv.DB.DeleteWebhookSubscription(subscription.ID); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataDeleteFailure)
+		return
+	}
+}
+
+// ListWebhookDelivery godoc
+// @Summary List webhook deliveries
+// @Description get the delivery log of a webhook subscription, newest first; the total number of matches is in the X-Total-Count header
+// @tags webhooks
+// @Produce  json
+// @Param id path string true "Webhook Subscription ID"
+// @Param status query string false "Filter by status" Enums(pending, succeeded, dead)
+// @Param limit query integer false "Page size, 1 to 100" default(20)
+// @Param offset query integer false "Number of deliveries to skip" default(0)
+// @Success 200 {array} model.WebhookDeliveryDtos
+// @Header 200 {string} Token "qwerty"
+// @Header 200 {integer} X-Total-Count "Number of matching deliveries"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 422 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /webhooks/{id}/deliveries [get]
+func (srv *Server) HandleListWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, ok := srv.readOwnedWebhookSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, total, err := srv.DB.ListWebhookDeliveries(subscription.ID, r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	if len(deliveries) == 0 {
+		fmt.Fprint(w, "[]")
+		return
+	}
+
+	dtos := deliveries.ToDto()
+
+	if err := json.NewEncoder(w).Encode(dtos); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrJsonCreationFailure)
+		return
+	}
+}
+
+// RedeliverWebhookDelivery godoc
+// @Summary Redeliver webhook delivery
+// @Description queue a delivery again right away with a fresh set of attempts, including succeeded and dead-lettered ones
+// @tags webhooks
+// @Param id path string true "Webhook Subscription ID"
+// @Param deliveryId path string true "Webhook Delivery ID"
+// @Success 202 {string} string	"accepted"
+// @Header 200 {string} Token "qwerty"
+// @Failure 400,404 {object} httputil.HTTPError
+// @Failure 500 {object} httputil.HTTPError
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (srv *Server) HandleRedeliverWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := srv.readOwnedWebhookSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := srv.DB.ReadWebhookDeliveryById(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return
+	}
+
+	if delivery.SubscriptionID != subscription.ID {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err := srv.DB.RedeliverWebhookDelivery(delivery.ID, time.Now()); err != nil {
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataUpdateFailure)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// readOwnedWebhookSubscription reads the webhook subscription in the URL and
+// writes a not found response when it does not exist or belongs to another
+// merchant.
+func (srv *Server) readOwnedWebhookSubscription(w http.ResponseWriter, r *http.Request) (*model.WebhookSubscription, bool) {
+	id := chi.URLParam(r, "id")
+
+	subscription, err := srv.DB.ReadWebhookSubscriptionById(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		srv.Logger.Warn(err.Error())
+
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `{"error": "%v"}`, srvErrDataAccessFailure)
+		return nil, false
+	}
+
+	userDetails, _ := r.Context().Value(model.CtxKeyXUser).(model.CtxUser)
+	if subscription.MerchantID != userDetails.UserId.String() {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return subscription, true
+}
@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_handler_Create_Webhook() {
+	merchantId := uuid.New().String()
+
+	s.db.EXPECT().CreateWebhookSubscription(gomock.Any()).DoAndReturn(func(sub *model.WebhookSubscription) error {
+		require.Equal(s.T(), merchantId, sub.MerchantID)
+		require.Equal(s.T(), "team_member.created,team_member.deleted", sub.EventTypes)
+		require.True(s.T(), sub.Active)
+		return nil
+	})
+
+	body := `{"url": "https://example.com/hooks", "eventTypes": ["team_member.created", "team_member.deleted"]}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateWebhook(rr, newMerchantRequest(http.MethodPost, "/webhooks", body, merchantId, ""))
+
+	require.Equal(s.T(), http.StatusCreated, rr.Code)
+
+	dto := &model.WebhookSubscriptionDto{}
+	require.NoError(s.T(), json.NewDecoder(rr.Body).Decode(dto))
+	require.True(s.T(), strings.HasPrefix(dto.Secret, "whsec_"))
+}
+
+func (s *Suite) Test_handler_Create_Webhook_Non_Web_Url() {
+	body := `{"url": "ftp://example.com/hooks", "eventTypes": ["merchant.updated"]}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateWebhook(rr, newMerchantRequest(http.MethodPost, "/webhooks", body, uuid.New().String(), ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Create_Webhook_Internal_Url() {
+	body := `{"url": "https://169.254.169.254/latest/meta-data", "eventTypes": ["merchant.updated"]}`
+
+	rr := httptest.NewRecorder()
+	s.server.HandleCreateWebhook(rr, newMerchantRequest(http.MethodPost, "/webhooks", body, uuid.New().String(), ""))
+
+	require.Equal(s.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (s *Suite) Test_handler_Redeliver_Webhook_Delivery_Of_Other_Subscription() {
+	merchantId := uuid.New().String()
+	subscription := &model.WebhookSubscription{Model: model.Model{ID: uuid.New().String()}, MerchantID: merchantId}
+	delivery := &model.WebhookDelivery{Model: model.Model{ID: uuid.New().String()}, SubscriptionID: uuid.New().String()}
+
+	s.db.EXPECT().ReadWebhookSubscriptionById(subscription.ID).Return(subscription, nil)
+	s.db.EXPECT().ReadWebhookDeliveryById(delivery.ID).Return(delivery, nil)
+
+	r := newMerchantRequest(http.MethodPost, "/webhooks/"+subscription.ID+"/deliveries/"+delivery.ID+"/redeliver", "", merchantId, subscription.ID)
+	chi.RouteContext(r.Context()).URLParams.Add("deliveryId", delivery.ID)
+
+	rr := httptest.NewRecorder()
+	s.server.HandleRedeliverWebhookDelivery(rr, r)
+
+	require.Equal(s.T(), http.StatusNotFound, rr.Code)
+}
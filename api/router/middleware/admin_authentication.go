@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+)
+
+const (
+	adminTokenHeader = "X-Admin-Token"
+
+	adminErrInvalidToken = "invalid admin token"
+)
+
+// AdminAuthentication protects the back office API with a shared token
+// sent in the X-Admin-Token header. An empty token disables the API.
+func AdminAuthentication(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := r.Header.Get(adminTokenHeader)
+			if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprintf(w, `{"error": "%v"}`, adminErrInvalidToken)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
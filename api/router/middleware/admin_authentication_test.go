@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"merchant/api/router/middleware"
+)
+
+type adminAuthTestCase struct {
+	name         string
+	configured   string
+	token        string
+	expectedResp int
+}
+
+var adminAuthTests = []*adminAuthTestCase{
+	{
+		name:         "with no token",
+		configured:   "s3cret",
+		expectedResp: http.StatusUnauthorized,
+	}, {
+		name:         "with a wrong token",
+		configured:   "s3cret",
+		token:        "guess",
+		expectedResp: http.StatusUnauthorized,
+	}, {
+		name:         "with the admin API disabled",
+		expectedResp: http.StatusUnauthorized,
+	}, {
+		name:         "with a valid token",
+		configured:   "s3cret",
+		token:        "s3cret",
+		expectedResp: http.StatusOK,
+	},
+}
+
+func TestAdminAuthentication(t *testing.T) {
+	for _, tc := range adminAuthTests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest("GET", "/", nil)
+			rr := httptest.NewRecorder()
+
+			if tc.token != "" {
+				r.Header.Set("X-Admin-Token", tc.token)
+			}
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, testHandlerRespBody)
+			})
+			middleware.AdminAuthentication(tc.configured)(handler).ServeHTTP(rr, r)
+
+			if resp := rr.Result().StatusCode; tc.expectedResp != resp {
+				t.Errorf("Wrong response code: want %d, got %d ", tc.expectedResp, resp)
+			}
+		})
+	}
+}
@@ -8,29 +8,52 @@ import (
 
 	"merchant/api/handler"
 	"merchant/api/router/middleware"
+	"merchant/config"
 )
 
-func New(srv *handler.Server) *chi.Mux {
+func New(srv *handler.Server, conf *config.Config) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Prepare CORS.
 	cors := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "pragma", "X-Organization"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "pragma", "X-Organization", "X-Admin-Token", "If-Match", "Idempotency-Key"},
 		AllowCredentials: true,
-		ExposedHeaders:   []string{"X-Total-Count"},
+		ExposedHeaders:   []string{"X-Total-Count", "ETag", "Idempotent-Replayed", "Content-Disposition"},
 		MaxAge:           300,
 	})
 
 	// Route to swagger specification
 
+	// Routes for uploaded assets
+	r.Route("/assets", func(r chi.Router) {
+		r.Use(cors.Handler)
+
+		r.MethodFunc(http.MethodGet, "/*", srv.HandleReadAsset)
+	})
+
+	// Routes for downloads authorized by signed links
+	r.Route("/downloads", func(r chi.Router) {
+		r.Use(cors.Handler)
+
+		r.MethodFunc(http.MethodGet, "/data-exports/{id}", srv.HandleDownloadDataExport)
+	})
+
+	// Routes for payment provider callbacks
+	r.Route("/callbacks", func(r chi.Router) {
+		r.Use(middleware.ContentTypeJson)
+
+		r.MethodFunc(http.MethodPost, "/payments", srv.HandlePaymentCallback)
+	})
+
 	r.Route("/auth", func(r chi.Router) {
 		r.Use(cors.Handler)
 		r.Use(middleware.ContentTypeJson)
 
+		// Logins are not idempotent, so that no token is ever stored.
 		r.MethodFunc(http.MethodPost, "/login", srv.HandleLogin)
-		r.MethodFunc(http.MethodPost, "/register", srv.HandleRegister)
+		r.With(srv.Idempotent).MethodFunc(http.MethodPost, "/register", srv.HandleRegister)
 	})
 
 	// Routes for APIs
@@ -38,12 +61,29 @@ func New(srv *handler.Server) *chi.Mux {
 		r.Use(cors.Handler)
 		r.Use(middleware.ContentTypeJson)
 		r.Use(middleware.JwtAuthentication)
+		r.Use(srv.ActingMerchant)
+		r.Use(srv.Idempotent)
 
 		// Routes for merchants
 		r.MethodFunc(http.MethodGet, "/merchants", srv.HandleListMerchant)
 		r.MethodFunc(http.MethodGet, "/merchants/{id}", srv.HandleReadMerchant)
 		r.MethodFunc(http.MethodPut, "/merchants/{id}", srv.HandleUpdateMerchant)
 		r.MethodFunc(http.MethodDelete, "/merchants/{id}", srv.HandleDeleteMerchant)
+		r.MethodFunc(http.MethodPut, "/merchants/{id}/logo", srv.HandleUploadMerchantLogo)
+		r.MethodFunc(http.MethodDelete, "/merchants/{id}/logo", srv.HandleDeleteMerchantLogo)
+		r.MethodFunc(http.MethodPut, "/merchants/{id}/banner", srv.HandleUploadMerchantBanner)
+		r.MethodFunc(http.MethodDelete, "/merchants/{id}/banner", srv.HandleDeleteMerchantBanner)
+		r.MethodFunc(http.MethodPost, "/merchants/{id}/closure", srv.HandleRequestMerchantClosure)
+		r.MethodFunc(http.MethodGet, "/merchants/{id}/closure", srv.HandleReadMerchantClosure)
+		r.MethodFunc(http.MethodDelete, "/merchants/{id}/closure", srv.HandleCancelMerchantClosure)
+		r.MethodFunc(http.MethodGet, "/merchants/{id}/versions", srv.HandleListMerchantVersion)
+		r.MethodFunc(http.MethodGet, "/merchants/{id}/versions/{version}", srv.HandleReadMerchantVersion)
+		r.MethodFunc(http.MethodPost, "/merchants/{id}/versions/{version}/restore", srv.HandleRestoreMerchantVersion)
+
+		// Routes for the merchants under the merchant
+		r.MethodFunc(http.MethodGet, "/organization/merchants", srv.HandleListOrganizationMerchant)
+		r.MethodFunc(http.MethodPost, "/organization/merchants", srv.HandleCreateOrganizationMerchant)
+		r.MethodFunc(http.MethodGet, "/organization/team-members", srv.HandleListOrganizationTeamMember)
 
 		// Routes for team members
 		r.MethodFunc(http.MethodGet, "/team-members", srv.HandleListTeamMember)
@@ -51,6 +91,147 @@ func New(srv *handler.Server) *chi.Mux {
 		r.MethodFunc(http.MethodGet, "/team-members/{id}", srv.HandleReadTeamMember)
 		r.MethodFunc(http.MethodPut, "/team-members/{id}", srv.HandleUpdateTeamMember)
 		r.MethodFunc(http.MethodDelete, "/team-members/{id}", srv.HandleDeleteTeamMember)
+		r.MethodFunc(http.MethodGet, "/team-members/{id}/versions", srv.HandleListTeamMemberVersion)
+		r.MethodFunc(http.MethodGet, "/team-members/{id}/versions/{version}", srv.HandleReadTeamMemberVersion)
+		r.MethodFunc(http.MethodPost, "/team-members/{id}/versions/{version}/restore", srv.HandleRestoreTeamMemberVersion)
+		r.MethodFunc(http.MethodGet, "/team-member-imports/{id}", srv.HandleReadTeamMemberImport)
+
+		// Routes for exports of all the data of the merchant
+		r.MethodFunc(http.MethodPost, "/data-exports", srv.HandleCreateDataExport)
+		r.MethodFunc(http.MethodGet, "/data-exports/{id}", srv.HandleReadDataExport)
+
+		// Routes for locations
+		r.MethodFunc(http.MethodGet, "/locations", srv.HandleListLocation)
+		r.MethodFunc(http.MethodPost, "/locations", srv.HandleCreateLocation)
+		r.MethodFunc(http.MethodGet, "/locations/{id}", srv.HandleReadLocation)
+		r.MethodFunc(http.MethodPut, "/locations/{id}", srv.HandleUpdateLocation)
+		r.MethodFunc(http.MethodDelete, "/locations/{id}", srv.HandleDeleteLocation)
+
+		// Routes for products
+		r.MethodFunc(http.MethodGet, "/products", srv.HandleListProduct)
+		r.MethodFunc(http.MethodPost, "/products", srv.HandleCreateProduct)
+		r.MethodFunc(http.MethodGet, "/products/{id}", srv.HandleReadProduct)
+		r.MethodFunc(http.MethodPut, "/products/{id}", srv.HandleUpdateProduct)
+		r.MethodFunc(http.MethodDelete, "/products/{id}", srv.HandleDeleteProduct)
+		r.MethodFunc(http.MethodPost, "/products/{id}/variants", srv.HandleCreateProductVariant)
+		r.MethodFunc(http.MethodPut, "/products/{id}/variants/{variantId}", srv.HandleUpdateProductVariant)
+		r.MethodFunc(http.MethodDelete, "/products/{id}/variants/{variantId}", srv.HandleDeleteProductVariant)
+
+		// Routes for inventory
+		r.MethodFunc(http.MethodGet, "/inventory", srv.HandleListInventory)
+		r.MethodFunc(http.MethodPost, "/inventory/adjustments", srv.HandleAdjustInventory)
+		r.MethodFunc(http.MethodPost, "/inventory/reservations", srv.HandleReserveInventory)
+		r.MethodFunc(http.MethodPost, "/inventory/releases", srv.HandleReleaseInventory)
+		r.MethodFunc(http.MethodPut, "/inventory/thresholds", srv.HandleUpdateInventoryThreshold)
+		r.MethodFunc(http.MethodGet, "/inventory/movements", srv.HandleListStockMovement)
+		r.MethodFunc(http.MethodGet, "/inventory/alerts", srv.HandleListStockAlert)
+
+		// Routes for orders
+		r.MethodFunc(http.MethodGet, "/orders", srv.HandleListOrder)
+		r.MethodFunc(http.MethodPost, "/orders", srv.HandleCreateOrder)
+		r.MethodFunc(http.MethodGet, "/orders/{id}", srv.HandleReadOrder)
+		r.MethodFunc(http.MethodPut, "/orders/{id}", srv.HandleUpdateOrder)
+		r.MethodFunc(http.MethodPut, "/orders/{id}/state", srv.HandleUpdateOrderState)
+
+		// Routes for coupons
+		r.MethodFunc(http.MethodGet, "/coupons", srv.HandleListCoupon)
+		r.MethodFunc(http.MethodPost, "/coupons", srv.HandleCreateCoupon)
+		r.MethodFunc(http.MethodGet, "/coupons/{id}", srv.HandleReadCoupon)
+		r.MethodFunc(http.MethodPut, "/coupons/{id}", srv.HandleUpdateCoupon)
+		r.MethodFunc(http.MethodPost, "/orders/{id}/coupon", srv.HandleRedeemCoupon)
+		r.MethodFunc(http.MethodDelete, "/orders/{id}/coupon", srv.HandleRemoveCoupon)
+
+		// Routes for invoices and receipts
+		r.MethodFunc(http.MethodPost, "/orders/{id}/invoice", srv.HandleCreateInvoice)
+		r.MethodFunc(http.MethodGet, "/invoices", srv.HandleListInvoice)
+		r.MethodFunc(http.MethodGet, "/invoices/{id}", srv.HandleReadInvoice)
+		r.MethodFunc(http.MethodGet, "/invoices/{id}.pdf", srv.HandleReadInvoicePdf)
+		r.MethodFunc(http.MethodGet, "/invoices/{id}.html", srv.HandleReadInvoiceHtml)
+
+		// Routes for webhooks
+		r.MethodFunc(http.MethodGet, "/webhooks", srv.HandleListWebhook)
+		r.MethodFunc(http.MethodPost, "/webhooks", srv.HandleCreateWebhook)
+		r.MethodFunc(http.MethodGet, "/webhooks/{id}", srv.HandleReadWebhook)
+		r.MethodFunc(http.MethodPut, "/webhooks/{id}", srv.HandleUpdateWebhook)
+		r.MethodFunc(http.MethodDelete, "/webhooks/{id}", srv.HandleDeleteWebhook)
+		r.MethodFunc(http.MethodGet, "/webhooks/{id}/deliveries", srv.HandleListWebhookDelivery)
+		r.MethodFunc(http.MethodPost, "/webhooks/{id}/deliveries/{deliveryId}/redeliver", srv.HandleRedeliverWebhookDelivery)
+
+		// Routes for settings
+		r.MethodFunc(http.MethodGet, "/settings", srv.HandleReadSettings)
+		r.MethodFunc(http.MethodPut, "/settings", srv.HandleUpdateSettings)
+
+		// Routes for business verification
+		r.MethodFunc(http.MethodGet, "/verification", srv.HandleReadVerification)
+		r.MethodFunc(http.MethodPut, "/verification", srv.HandleSubmitVerification)
+
+		// Routes for moving money, open to verified merchants only
+		r.Group(func(r chi.Router) {
+			r.Use(srv.RequireVerifiedMerchant)
+
+			r.MethodFunc(http.MethodGet, "/payout-accounts", srv.HandleListPayoutAccount)
+			r.MethodFunc(http.MethodPost, "/payout-accounts", srv.HandleCreatePayoutAccount)
+			r.MethodFunc(http.MethodGet, "/payout-accounts/{id}", srv.HandleReadPayoutAccount)
+			r.MethodFunc(http.MethodDelete, "/payout-accounts/{id}", srv.HandleDeletePayoutAccount)
+
+			r.MethodFunc(http.MethodPost, "/orders/{id}/payments", srv.HandleCreatePaymentIntent)
+			r.MethodFunc(http.MethodGet, "/payments", srv.HandleListPaymentIntent)
+			r.MethodFunc(http.MethodGet, "/payments/{id}", srv.HandleReadPaymentIntent)
+			r.MethodFunc(http.MethodPost, "/payments/{id}/capture", srv.HandleCapturePaymentIntent)
+			r.MethodFunc(http.MethodPost, "/payments/{id}/void", srv.HandleVoidPaymentIntent)
+			r.MethodFunc(http.MethodGet, "/payments/{id}/refunds", srv.HandleListRefund)
+			r.MethodFunc(http.MethodPost, "/payments/{id}/refunds", srv.HandleCreateRefund)
+		})
+	})
+
+	// Routes for uploads, whose bodies are larger than those of the other
+	// APIs; idempotent retries of them are buffered up to their own limits
+	r.Route("/api/v1/team-members/import", func(r chi.Router) {
+		r.Use(cors.Handler)
+		r.Use(middleware.ContentTypeJson)
+		r.Use(middleware.JwtAuthentication)
+		r.Use(srv.ActingMerchant)
+		r.Use(srv.IdempotentWithLimit(handler.MaxTeamMemberImportRequestBytes))
+
+		r.MethodFunc(http.MethodPost, "/", srv.HandleImportTeamMembers)
+	})
+	r.Route("/api/v1/verification/documents", func(r chi.Router) {
+		r.Use(cors.Handler)
+		r.Use(middleware.ContentTypeJson)
+		r.Use(middleware.JwtAuthentication)
+		r.Use(srv.ActingMerchant)
+		r.Use(srv.IdempotentWithLimit(handler.MaxVerificationDocumentRequestBytes))
+
+		r.MethodFunc(http.MethodPost, "/", srv.HandleUploadVerificationDocument)
+	})
+
+	// Routes for exports, which stream CSV or NDJSON rather than JSON
+	r.Route("/api/v1/exports", func(r chi.Router) {
+		r.Use(cors.Handler)
+		r.Use(middleware.JwtAuthentication)
+		r.Use(srv.ActingMerchant)
+
+		r.MethodFunc(http.MethodGet, "/merchants", srv.HandleExportMerchants)
+		r.MethodFunc(http.MethodGet, "/team-members", srv.HandleExportTeamMembers)
+	})
+
+	// Routes for the back office
+	r.Route("/admin/v1", func(r chi.Router) {
+		r.Use(cors.Handler)
+		r.Use(middleware.ContentTypeJson)
+		r.Use(middleware.AdminAuthentication(conf.AdminToken))
+
+		r.MethodFunc(http.MethodGet, "/verifications", srv.HandleListVerificationAdmin)
+		r.MethodFunc(http.MethodGet, "/verifications/{id}", srv.HandleReadVerificationAdmin)
+		r.MethodFunc(http.MethodGet, "/verifications/{id}/documents/{documentId}", srv.HandleReadVerificationDocumentAdmin)
+		r.MethodFunc(http.MethodPut, "/verifications/{id}/status", srv.HandleReviewVerificationAdmin)
+
+		r.MethodFunc(http.MethodGet, "/payout-accounts", srv.HandleListPayoutAccountAdmin)
+		r.MethodFunc(http.MethodPut, "/payout-accounts/{id}/status", srv.HandleUpdatePayoutAccountStatusAdmin)
+
+		r.MethodFunc(http.MethodPut, "/merchants/{id}/parent", srv.HandleUpdateMerchantParentAdmin)
+
+		r.MethodFunc(http.MethodPost, "/data-keys", srv.HandleRotateDataKeyAdmin)
 	})
 
 	return r
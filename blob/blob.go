@@ -0,0 +1,21 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// PrivatePrefix starts the keys of blobs which are only ever handed out to
+// their owners, such as invoices and data exports, and never served publicly.
+const PrivatePrefix = "private/"
+
+// Storage keeps binary objects, such as uploaded images and rendered
+// documents, under slash separated keys.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
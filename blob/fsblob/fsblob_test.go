@@ -0,0 +1,56 @@
+package fsblob_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/blob"
+	"merchant/blob/fsblob"
+)
+
+func newStorage(t *testing.T) *fsblob.Storage {
+	dir, err := ioutil.TempDir("", "fsblob")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	s, err := fsblob.New(dir)
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestStoragePutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	s := newStorage(t)
+
+	require.NoError(t, s.Put(ctx, "merchants/1/logo.png", bytes.NewBufferString("logo")))
+
+	r, err := s.Get(ctx, "merchants/1/logo.png")
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "logo", string(content))
+
+	require.NoError(t, s.Delete(ctx, "merchants/1/logo.png"))
+
+	_, err = s.Get(ctx, "merchants/1/logo.png")
+	assert.Equal(t, blob.ErrNotFound, err)
+
+	assert.NoError(t, s.Delete(ctx, "merchants/1/logo.png"))
+}
+
+func TestStorageRejectsEscapingKeys(t *testing.T) {
+	ctx := context.Background()
+	s := newStorage(t)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "merchants/../../outside", "merchants//logo.png"} {
+		assert.Errorf(t, s.Put(ctx, key, bytes.NewBufferString("x")), "key %q", key)
+	}
+}
@@ -0,0 +1,120 @@
+package closure
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"merchant/blob"
+	"merchant/model"
+	"merchant/repository"
+)
+
+// Closer erases the merchants whose closure grace period has ended. Several
+// closers may run at once; a merchant is erased by whichever locks it
+// first.
+type Closer struct {
+	db     repository.Repository
+	blob   blob.Storage
+	logger *zap.Logger
+
+	// BatchSize is the number of merchants erased at a time.
+	BatchSize int
+}
+
+func NewCloser(db repository.Repository, storage blob.Storage, logger *zap.Logger) *Closer {
+	return &Closer{
+		db:        db,
+		blob:      storage,
+		logger:    logger,
+		BatchSize: 10,
+	}
+}
+
+// Run erases the merchants due for closure every interval until the context
+// is done. Batches are erased one after another while they are full; a batch
+// in which a merchant failed to be erased ends the run until the next
+// interval, so merchants failing over and over are not retried in a loop.
+func (c *Closer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := c.CloseDue(ctx)
+			if err != nil {
+				c.logger.Warn(err.Error())
+			}
+			if err != nil || n < c.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CloseDue erases one batch of merchants due for closure and returns the
+// number of merchants no longer due: those erased, and those whose closure
+// was cancelled or taken by another closer meanwhile. Merchants which failed
+// to be erased are logged and left due.
+func (c *Closer) CloseDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	merchants, err := c.db.ListMerchantsDueForClosure(now, c.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, m := range merchants {
+		if ctx.Err() != nil {
+			return closed, ctx.Err()
+		}
+
+		if err := c.close(ctx, m, now); err != nil && err != repository.ErrConflict {
+			c.logger.Warn(err.Error())
+			continue
+		}
+		closed++
+	}
+
+	return closed, nil
+}
+
+// close erases the merchant, records its tombstone and the event of its
+// deletion in one transaction, then deletes the blobs of the erased records.
+// Blobs left behind by a failure past the commit are not retried.
+func (c *Closer) close(ctx context.Context, m *model.Merchant, now time.Time) error {
+	var keys []string
+	err := c.db.Transaction(func(tx repository.Repository) error {
+		erased, ks, err := tx.EraseMerchant(m.ID, now)
+		if err != nil {
+			return err
+		}
+		keys = ks
+
+		if err := tx.CreateMerchantTombstone(model.NewMerchantTombstone(m, erased, time.Now())); err != nil {
+			return err
+		}
+
+		// The event carries nothing but the id of the erased merchant.
+		deleted := &model.Merchant{Model: model.Model{ID: m.ID}}
+		return tx.CreateOutboxEvent(model.NewMerchantEvent(model.EventMerchantDeleted, deleted))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := c.blob.Delete(ctx, key); err != nil {
+			c.logger.Warn(err.Error())
+		}
+	}
+
+	return nil
+}
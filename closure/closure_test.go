@@ -0,0 +1,86 @@
+package closure_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"merchant/blob/fsblob"
+	"merchant/closure"
+	"merchant/mock/mock_repository"
+	"merchant/model"
+	"merchant/repository"
+)
+
+func TestCloseDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "closure")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storage, err := fsblob.New(dir)
+	require.NoError(t, err)
+
+	logo := filepath.Join(dir, "merchants", "m1", "logo.png")
+	require.NoError(t, os.MkdirAll(filepath.Dir(logo), 0755))
+	require.NoError(t, ioutil.WriteFile(logo, []byte("png"), 0644))
+
+	requestedAt := time.Now().Add(-31 * 24 * time.Hour)
+	closing := &model.Merchant{Model: model.Model{ID: "m1"}, Email: "shop@example.com", ClosureRequestedAt: &requestedAt}
+	cancelled := &model.Merchant{Model: model.Model{ID: "m2"}}
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListMerchantsDueForClosure(gomock.Any(), 10).Return(model.Merchants{closing, cancelled}, nil)
+	db.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(repository.Repository) error) error {
+		return fn(db)
+	}).Times(2)
+	db.EXPECT().EraseMerchant("m1", gomock.Any()).Return(map[string]int64{"merchants": 1, "team_members": 2}, []string{"merchants/m1/logo.png"}, nil)
+	db.EXPECT().CreateMerchantTombstone(gomock.Any()).DoAndReturn(func(ts *model.MerchantTombstone) error {
+		assert.Equal(t, "m1", ts.MerchantID)
+		assert.Equal(t, &requestedAt, ts.ClosureRequestedAt)
+		assert.JSONEq(t, `{"merchants": 1, "team_members": 2}`, ts.Erased)
+		return nil
+	})
+	db.EXPECT().CreateOutboxEvent(gomock.Any()).DoAndReturn(func(e *model.OutboxEvent) error {
+		assert.Equal(t, model.EventMerchantDeleted, e.Type)
+		assert.Equal(t, "m1", e.AggregateID)
+		assert.NotContains(t, e.Payload, "shop@example.com")
+		return nil
+	})
+	db.EXPECT().EraseMerchant("m2", gomock.Any()).Return(nil, nil, repository.ErrConflict)
+
+	n, err := closure.NewCloser(db, storage, zap.NewNop()).CloseDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = os.Stat(logo)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunStopsOnFailingBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failing := &model.Merchant{Model: model.Model{ID: "m1"}}
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListMerchantsDueForClosure(gomock.Any(), 1).Return(model.Merchants{failing}, nil).Times(1)
+	db.EXPECT().Transaction(gomock.Any()).Return(errors.New("erase failed")).Times(1)
+
+	closer := closure.NewCloser(db, nil, zap.NewNop())
+	closer.BatchSize = 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	closer.Run(ctx, time.Hour)
+}
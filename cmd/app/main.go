@@ -12,17 +12,31 @@ import (
 
 	"github.com/spf13/viper"
 	"go.opencensus.io/trace"
+	"go.uber.org/zap"
 
 	"merchant/api/handler"
 	"merchant/api/router"
+	"merchant/blob/fsblob"
+	"merchant/closure"
 	c "merchant/config"
+	"merchant/events"
+	"merchant/events/fileevents"
+	"merchant/events/memevents"
+	"merchant/events/natsevents"
+	"merchant/keyring"
 	"merchant/model"
 	"merchant/mysql"
+	"merchant/outbox"
+	"merchant/payment/fakepay"
+	"merchant/reconcile"
+	"merchant/repository"
 	"merchant/server"
 	"merchant/server/health"
 	"merchant/server/requestlog"
+	"merchant/util/cryptoutil"
 	"merchant/util/logutil"
 	"merchant/util/validator"
+	"merchant/webhook"
 )
 
 type customHealthCheck struct {
@@ -84,16 +98,149 @@ func main() {
 		return
 	}
 
-	_ = db.AutoMigrate(&model.Merchant{}, &model.TeamMember{})
+	_ = db.AutoMigrate(
+		&model.Merchant{},
+		&model.TeamMember{},
+		&model.MerchantVersion{},
+		&model.TeamMemberVersion{},
+		&model.Location{},
+		&model.OpeningHours{},
+		&model.HolidayException{},
+		&model.Verification{},
+		&model.VerificationDocument{},
+		&model.MerchantSettings{},
+		&model.PayoutAccount{},
+		&model.Product{},
+		&model.ProductVariant{},
+		&model.InventoryLevel{},
+		&model.StockMovement{},
+		&model.StockAlert{},
+		&model.Order{},
+		&model.OrderItem{},
+		&model.PaymentIntent{},
+		&model.ProcessedCallback{},
+		&model.Refund{},
+		&model.Coupon{},
+		&model.CouponRedemption{},
+		&model.Invoice{},
+		&model.InvoiceSequence{},
+		&model.WebhookSubscription{},
+		&model.WebhookDelivery{},
+		&model.OutboxEvent{},
+		&model.IdempotencyKey{},
+		&model.TeamMemberImport{},
+		&model.DataExport{},
+		&model.MerchantTombstone{},
+		&model.DataKey{},
+	)
+
+	ring, err := newKeyring(&cfg)
+	if err != nil {
+		logger.Fatal(err.Error())
+		return
+	}
+	cryptoutil.SetKeyring(ring)
 
 	var exporter trace.Exporter
 
 	// Get validator
 	appValidator := validator.New()
 
-	srv := handler.New(db, appValidator, logger)
+	storage, err := fsblob.New(cfg.Storage.Path)
+	if err != nil {
+		logger.Fatal(err.Error())
+		return
+	}
+
+	if cfg.Payment.Provider != "fake" {
+		logger.Fatal(fmt.Sprintf("Unknown payment provider %q", cfg.Payment.Provider))
+		return
+	}
+	if cfg.Payment.CallbackSecret == "" {
+		logger.Warn("No payment callback secret configured, payment callbacks are rejected")
+	}
+	gateway := fakepay.New(cfg.Payment.CallbackSecret)
+
+	srv := handler.New(db, storage, gateway, appValidator, logger)
+	srv.RequireIfMatch = cfg.Server.RequireIfMatch
+	if cfg.Server.RequestTimeout > 0 {
+		srv.RequestTimeout = cfg.Server.RequestTimeout
+	}
+	srv.Keys = keyring.NewManager(srv.DB, ring, logger)
+	if err := srv.Keys.Load(); err != nil {
+		logger.Fatal(err.Error())
+		return
+	}
+	if cfg.Closure.GracePeriod > 0 {
+		srv.ClosureGracePeriod = cfg.Closure.GracePeriod
+	}
+
+	if cfg.Webhook.Interval <= 0 {
+		cfg.Webhook.Interval = 5 * time.Second
+	}
+	if cfg.Webhook.Timeout <= 0 {
+		cfg.Webhook.Timeout = 10 * time.Second
+	}
+	dispatcher := webhook.NewDispatcher(srv.DB, webhook.NewClient(cfg.Webhook.Timeout), logger)
+
+	if cfg.Outbox.Interval <= 0 {
+		cfg.Outbox.Interval = time.Second
+	}
+	publishers := []outbox.EventPublisher{webhook.NewPublisher(srv.DB)}
+	if cfg.Events.Driver != "" {
+		bus, err := newEventPublisher(&cfg.Events)
+		if err != nil {
+			logger.Fatal(err.Error())
+			return
+		}
+		defer bus.Close()
+
+		if cfg.Events.Source == "" {
+			cfg.Events.Source = "/merchant"
+		}
+		publishers = append(publishers, events.NewOutboxPublisher(bus, cfg.Events.Source))
+	}
+	if cfg.Import.Interval <= 0 {
+		cfg.Import.Interval = 5 * time.Second
+	}
+	if cfg.Encryption.Interval <= 0 {
+		cfg.Encryption.Interval = time.Minute
+	}
+	if cfg.Closure.Interval <= 0 {
+		cfg.Closure.Interval = time.Hour
+	}
+	if cfg.Payment.ReconcileInterval <= 0 {
+		cfg.Payment.ReconcileInterval = time.Minute
+	}
+	if cfg.Export.Interval <= 0 {
+		cfg.Export.Interval = 5 * time.Second
+	}
+	if cfg.Export.LinkSecret != "" {
+		srv.DataExports.LinkSecret = []byte(cfg.Export.LinkSecret)
+	}
+	if cfg.Export.LinkTTL > 0 {
+		srv.DataExports.LinkTTL = cfg.Export.LinkTTL
+	}
+
+	relay := outbox.NewRelay(srv.DB, outbox.MultiPublisher(publishers...), logger)
+	closer := closure.NewCloser(srv.DB, storage, logger)
+	reconciler := reconcile.NewReconciler(srv.DB, gateway, logger)
+	if cfg.Payment.ReconcileAfter > 0 {
+		reconciler.After = cfg.Payment.ReconcileAfter
+	}
 
-	mux := router.New(srv)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx, cfg.Webhook.Interval)
+	go relay.Run(ctx, cfg.Outbox.Interval)
+	go sweepIdempotencyKeys(ctx, srv.DB, time.Hour, logger)
+	go srv.Imports.Run(ctx, cfg.Import.Interval)
+	go srv.DataExports.Run(ctx, cfg.Export.Interval)
+	go closer.Run(ctx, cfg.Closure.Interval)
+	go reconciler.Run(ctx, cfg.Payment.ReconcileInterval)
+	go srv.Keys.Run(ctx, cfg.Encryption.Interval)
+
+	mux := router.New(srv, &cfg)
 
 	// healthCheck will report the server is unhealthy for 10 seconds after
 	// startup, and as healthy henceforth. Check the /healthz/readiness
@@ -105,12 +252,17 @@ func main() {
 		healthCheck.healthy = true
 	})
 
+	driver := server.NewDefaultDriver()
+	// There is no write timeout, which would cut off streamed exports and
+	// downloads; idempotent requests are bounded by their own deadline.
+	driver.Server.ReadTimeout = srv.RequestTimeout
+
 	options := &server.Options{
 		RequestLogger:         requestlog.NewNCSALogger(os.Stdout, func(error) {}),
 		HealthChecks:          []health.Checker{healthCheck},
 		TraceExporter:         exporter,
 		DefaultSamplingPolicy: trace.AlwaysSample(),
-		Driver:                &server.DefaultDriver{},
+		Driver:                driver,
 	}
 
 	s := server.New(mux, options)
@@ -121,6 +273,7 @@ func main() {
 		signal.Notify(interrupt, os.Interrupt)
 		for {
 			<-interrupt
+			cancel()
 			_ = s.Shutdown(context.Background())
 			if sqlDB, err := db.DB(); err != nil {
 				if err = sqlDB.Close(); err != nil {
@@ -134,3 +287,65 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// sweepIdempotencyKeys deletes expired idempotency keys every interval until
+// the context is cancelled.
+func sweepIdempotencyKeys(ctx context.Context, db repository.Repository, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := db.DeleteExpiredIdempotencyKeys(now); err != nil {
+				logger.Warn(err.Error())
+			}
+		}
+	}
+}
+
+// newEventPublisher opens the event bus driver selected in the config.
+func newEventPublisher(cfg *c.EventsConfig) (events.Publisher, error) {
+	switch cfg.Driver {
+	case "memory":
+		return memevents.New(), nil
+	case "file":
+		return fileevents.New(cfg.Path)
+	case "nats":
+		if cfg.Timeout <= 0 {
+			cfg.Timeout = 5 * time.Second
+		}
+		return natsevents.New(cfg.URL, cfg.Subject, cfg.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown events driver %q", cfg.Driver)
+	}
+}
+
+// newKeyring reads the master key, from the config or from a key file, and
+// the master keys it replaced.
+func newKeyring(cfg *c.Config) (*cryptoutil.Keyring, error) {
+	var master cryptoutil.Cipher
+	var err error
+	switch {
+	case cfg.EncryptionKey != "":
+		master, err = cryptoutil.ParseKey(cfg.EncryptionKey)
+	case cfg.Encryption.KeyFile != "":
+		master, err = cryptoutil.ReadKeyFile(cfg.Encryption.KeyFile)
+	default:
+		return nil, errors.New("no encryption key configured, set ENCRYPTIONKEY or encryption.keyfile")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read encryption key, %v", err)
+	}
+
+	retired := make([]cryptoutil.Cipher, len(cfg.Encryption.RetiredKeys))
+	for i, key := range cfg.Encryption.RetiredKeys {
+		if retired[i], err = cryptoutil.ParseKey(key); err != nil {
+			return nil, fmt.Errorf("unable to read retired encryption key, %v", err)
+		}
+	}
+
+	return cryptoutil.NewKeyring(master, retired...), nil
+}
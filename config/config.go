@@ -2,17 +2,125 @@ package config
 
 import (
 	"fmt"
+	"time"
 )
 
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	JwtSecret string
-	Debug     bool
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Storage    StorageConfig
+	Payment    PaymentConfig
+	Webhook    WebhookConfig
+	Outbox     OutboxConfig
+	Events     EventsConfig
+	Import     ImportConfig
+	Export     ExportConfig
+	Closure    ClosureConfig
+	Encryption EncryptionConfig
+	JwtSecret  string
+	// AdminToken authenticates the back office API; leaving it empty
+	// disables that API.
+	AdminToken string
+	// EncryptionKey is the base64 encoded 256 bit master key. It wraps the
+	// data keys sensitive columns, such as bank account numbers and the
+	// names and emails of merchants and team members, are encrypted with.
+	// It has no default; the service does not start without it or a key
+	// file.
+	EncryptionKey string
+	Debug         bool
 }
 
 type ServerConfig struct {
 	Port string
+	// RequireIfMatch rejects updates and deletes of merchants and team
+	// members that do not say which version they change with If-Match.
+	RequireIfMatch bool
+	// RequestTimeout bounds reading a request, and handling one sent with
+	// an idempotency key.
+	RequestTimeout time.Duration
+}
+
+type StorageConfig struct {
+	Path string
+}
+
+type PaymentConfig struct {
+	// Provider selects the payment gateway; only "fake" is available.
+	Provider string
+	// CallbackSecret verifies the signatures of provider callbacks. Leaving
+	// it empty rejects all callbacks.
+	CallbackSecret string
+	// ReconcileAfter is how long a refund whose outcome at the provider is
+	// unknown stays pending before it is requested again.
+	ReconcileAfter time.Duration
+	// ReconcileInterval is how often pending refunds are reconciled.
+	ReconcileInterval time.Duration
+}
+
+type WebhookConfig struct {
+	// Interval is how often the delivery queue is polled.
+	Interval time.Duration
+	// Timeout bounds each delivery attempt.
+	Timeout time.Duration
+}
+
+type OutboxConfig struct {
+	// Interval is how often the outbox is polled for events to relay.
+	Interval time.Duration
+}
+
+type ImportConfig struct {
+	// Interval is how often the queue of background team member imports is
+	// polled.
+	Interval time.Duration
+}
+
+type ExportConfig struct {
+	// Interval is how often the queue of data exports is polled.
+	Interval time.Duration
+	// LinkSecret signs the download links of data exports. Leaving it empty
+	// signs them with a random secret, which only the running instance
+	// knows.
+	LinkSecret string
+	// LinkTTL is how long a finished export may be downloaded before it is
+	// purged along with its archive.
+	LinkTTL time.Duration
+}
+
+type ClosureConfig struct {
+	// GracePeriod is how long a merchant may cancel the closure of its
+	// account before its data is erased.
+	GracePeriod time.Duration
+	// Interval is how often merchants due for closure are looked for.
+	Interval time.Duration
+}
+
+type EncryptionConfig struct {
+	// KeyFile is a file holding the base64 encoded master key, read when
+	// no EncryptionKey is configured.
+	KeyFile string
+	// RetiredKeys are the base64 encoded master keys replaced by the
+	// current one. Data keys they wrapped are wrapped again on start.
+	RetiredKeys []string
+	// Interval is how often data keys rotated by other instances are looked
+	// for.
+	Interval time.Duration
+}
+
+type EventsConfig struct {
+	// Driver selects where merchant events are published: "memory", "file"
+	// or "nats". Leaving it empty publishes them nowhere.
+	Driver string
+	// Source is the CloudEvents source attribute of the events.
+	Source string
+	// Path is the NDJSON file the file driver appends events to.
+	Path string
+	// URL is the nats:// URL of the server the nats driver publishes to.
+	URL string
+	// Subject prefixes the event type in the subjects of the nats driver.
+	Subject string
+	// Timeout bounds connecting and publishing for the nats driver.
+	Timeout time.Duration
 }
 
 type DatabaseConfig struct {
@@ -0,0 +1,209 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// pageSize is the number of records read at a time from paged lists.
+const pageSize = 500
+
+// file is a JSON file of an archive along with the function reading its
+// content.
+type file struct {
+	name string
+	read func(db repository.Repository, merchantId string, now time.Time) (interface{}, error)
+}
+
+// files lists the files of an archive in order. Files of single records hold
+// null when the merchant has none.
+var files = []file{
+	{"merchant.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		m, err := db.ReadMerchantById(merchantId)
+		if err != nil {
+			return nil, err
+		}
+		return m.ToDto(), nil
+	}},
+	{"merchant_versions.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		all := make(model.MerchantVersions, 0)
+		err := readPages(func(limit, offset int) (int, error) {
+			vs, _, err := db.ListMerchantVersions(merchantId, nil, limit, offset)
+			all = append(all, vs...)
+			return len(vs), err
+		})
+		return all.ToDto(), err
+	}},
+	{"settings.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		s, err := db.ReadMerchantSettingsByMerchantId(merchantId)
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		return s.ToDto(), nil
+	}},
+	{"verification.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		v, err := db.ReadVerificationByMerchantId(merchantId)
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		return v.ToDto(), nil
+	}},
+	{"team_members.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		ts, err := db.ListTeamMembersByMerchantId(merchantId, nil)
+		return ts.ToDto(), err
+	}},
+	{"team_member_versions.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		all := make(model.TeamMemberVersions, 0)
+		err := readPages(func(limit, offset int) (int, error) {
+			vs, err := db.ListTeamMemberVersionsByMerchantId(merchantId, limit, offset)
+			all = append(all, vs...)
+			return len(vs), err
+		})
+		return all.ToDto(), err
+	}},
+	{"locations.json", func(db repository.Repository, merchantId string, now time.Time) (interface{}, error) {
+		ls, err := db.ListLocationsByMerchantId(merchantId)
+		return ls.ToDto(now), err
+	}},
+	{"payout_accounts.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		ps, err := db.ListPayoutAccountsByMerchantId(merchantId)
+		return ps.ToDto(), err
+	}},
+	{"products.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		all := make(model.Products, 0)
+		err := readPages(func(limit, offset int) (int, error) {
+			ps, _, err := db.SearchProducts(merchantId, &model.ProductSearch{Limit: limit, Offset: offset})
+			all = append(all, ps...)
+			return len(ps), err
+		})
+		return all.ToDto(), err
+	}},
+	{"inventory_levels.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		ls, err := db.ListInventoryLevels(merchantId, "", "")
+		return ls.ToDto(), err
+	}},
+	{"stock_movements.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		all := make(model.StockMovements, 0)
+		err := readPages(func(limit, offset int) (int, error) {
+			ms, err := db.ListStockMovements(merchantId, "", "", limit, offset)
+			all = append(all, ms...)
+			return len(ms), err
+		})
+		return all.ToDto(), err
+	}},
+	{"stock_alerts.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		as, err := db.ListStockAlertsByMerchantId(merchantId)
+		return as.ToDto(), err
+	}},
+	{"orders.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		all := make(model.Orders, 0)
+		err := readPages(func(limit, offset int) (int, error) {
+			os, _, err := db.SearchOrders(merchantId, &model.OrderSearch{Limit: limit, Offset: offset})
+			all = append(all, os...)
+			return len(os), err
+		})
+		return all.ToDto(), err
+	}},
+	{"payment_intents.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		ps, err := db.ListPaymentIntents(merchantId, "")
+		return ps.ToDto(), err
+	}},
+	{"refunds.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		ps, err := db.ListPaymentIntents(merchantId, "")
+		if err != nil {
+			return nil, err
+		}
+
+		all := make(model.Refunds, 0)
+		for _, p := range ps {
+			rs, err := db.ListRefundsByPaymentIntentId(p.ID)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, rs...)
+		}
+		return all.ToDto(), nil
+	}},
+	{"coupons.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		cs, err := db.ListCouponsByMerchantId(merchantId)
+		return cs.ToDto(), err
+	}},
+	{"invoices.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		all := make(model.Invoices, 0)
+		err := readPages(func(limit, offset int) (int, error) {
+			is, _, err := db.ListInvoicesByMerchantId(merchantId, limit, offset)
+			all = append(all, is...)
+			return len(is), err
+		})
+		return all.ToDto(), err
+	}},
+	{"webhook_subscriptions.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		ss, err := db.ListWebhookSubscriptionsByMerchantId(merchantId)
+		return ss.ToDto(), err
+	}},
+	// The events recorded for the merchant and its team members are the
+	// audit trail of the account.
+	{"events.json", func(db repository.Repository, merchantId string, _ time.Time) (interface{}, error) {
+		all := make(model.OutboxEvents, 0)
+		err := readPages(func(limit, offset int) (int, error) {
+			es, err := db.ListOutboxEventsByMerchantId(merchantId, limit, offset)
+			all = append(all, es...)
+			return len(es), err
+		})
+		return all.ToDto(), err
+	}},
+}
+
+// WriteArchive writes a ZIP archive of the data of the merchant, one JSON
+// file per kind of record, as it stands at the given time.
+func WriteArchive(w io.Writer, db repository.Repository, merchantId string, now time.Time) error {
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		data, err := f.read(db, merchantId, now)
+		if err != nil {
+			return err
+		}
+
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// readPages calls read with pages of increasing offset until it returns a
+// page which is not full.
+func readPages(read func(limit, offset int) (int, error)) error {
+	for offset := 0; ; offset += pageSize {
+		n, err := read(pageSize, offset)
+		if err != nil || n < pageSize {
+			return err
+		}
+	}
+}
+
+func ignoreNotFound(err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,205 @@
+package dataexport
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"merchant/blob"
+	"merchant/model"
+	"merchant/repository"
+)
+
+var ErrExportFailed = errors.New("data could not be exported")
+
+// Exporter assembles the archives of data exports in the background and
+// signs the links they are downloaded with. Several exporters may share the
+// queue of exports; every export is claimed before it runs.
+type Exporter struct {
+	db     repository.Repository
+	blob   blob.Storage
+	logger *zap.Logger
+
+	// BatchSize is the number of exports taken at a time.
+	BatchSize int
+	// Lease is how long a claimed export is left alone by other exporters.
+	// An export running longer is taken to have been cut off and is run
+	// again.
+	Lease time.Duration
+	// LinkSecret signs download links. NewExporter picks a random secret,
+	// with which links only work on the exporter that made them; set it to
+	// share links between exporters.
+	LinkSecret []byte
+	// LinkTTL is how long a finished export may be downloaded. The export
+	// expires then, and its archive is purged, so no download link works
+	// longer.
+	LinkTTL time.Duration
+}
+
+func NewExporter(db repository.Repository, storage blob.Storage, logger *zap.Logger) *Exporter {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+
+	return &Exporter{
+		db:         db,
+		blob:       storage,
+		logger:     logger,
+		BatchSize:  10,
+		Lease:      30 * time.Minute,
+		LinkSecret: secret,
+		LinkTTL:    24 * time.Hour,
+	}
+}
+
+// Link returns the download link of the archive of the export and when it
+// stops working.
+func (ex *Exporter) Link(e *model.DataExport, now time.Time) (string, time.Time) {
+	expires := now.Add(ex.LinkTTL)
+	if e.ExpiresAt != nil && e.ExpiresAt.Before(expires) {
+		expires = *e.ExpiresAt
+	}
+	expires = expires.Truncate(time.Second)
+
+	return "/downloads/data-exports/" + e.ID + "?" + SignLink(ex.LinkSecret, e.ID, expires).Encode(), expires
+}
+
+// VerifyLink checks the query of a download link of the export.
+func (ex *Exporter) VerifyLink(id string, q url.Values, now time.Time) error {
+	return VerifyLink(ex.LinkSecret, id, q, now)
+}
+
+// Run assembles queued exports, and purges expired ones, every interval
+// until the context is done.
+func (ex *Exporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := ex.ExportPending(ctx)
+			if err != nil {
+				ex.logger.Warn(err.Error())
+			}
+			if err != nil || n < ex.BatchSize {
+				break
+			}
+		}
+
+		for {
+			n, err := ex.PurgeExpired(ctx)
+			if err != nil {
+				ex.logger.Warn(err.Error())
+			}
+			if err != nil || n < ex.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExportPending runs one batch of queued exports and returns the number of
+// exports taken from the queue.
+func (ex *Exporter) ExportPending(ctx context.Context) (int, error) {
+	now := time.Now()
+	exports, err := ex.db.ListPendingDataExports(now.Add(-ex.Lease), ex.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range exports {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		if err := ex.run(ctx, e); err != nil && err != repository.ErrConflict {
+			ex.logger.Warn(err.Error())
+		}
+	}
+
+	return len(exports), nil
+}
+
+func (ex *Exporter) run(ctx context.Context, e *model.DataExport) error {
+	now := time.Now()
+	if err := ex.db.ClaimDataExport(e.ID, now, now.Add(-ex.Lease)); err != nil {
+		return err
+	}
+	e.Status = model.DataExportStatusRunning
+	e.StartedAt = &now
+
+	e.BlobKey = fmt.Sprintf("%sexports/%s/%s.zip", blob.PrivatePrefix, e.MerchantID, e.ID)
+	size, err := ex.write(ctx, e.BlobKey, e.MerchantID, now)
+	if err != nil {
+		ex.logger.Warn(err.Error())
+		e.BlobKey = ""
+		e.Finish(time.Now(), ex.LinkTTL, ErrExportFailed)
+	} else {
+		e.Size = size
+		e.Finish(time.Now(), ex.LinkTTL, nil)
+	}
+
+	return ex.db.FinishDataExport(e)
+}
+
+// write streams the archive of the merchant to blob storage and returns its
+// size.
+func (ex *Exporter) write(ctx context.Context, key, merchantId string, now time.Time) (int64, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(WriteArchive(pw, ex.db, merchantId, now))
+	}()
+
+	cr := &countingReader{r: pr}
+	err := ex.blob.Put(ctx, key, cr)
+	// Unblock the archive writer should the storage give up early.
+	pr.CloseWithError(err)
+
+	return cr.n, err
+}
+
+// PurgeExpired deletes one batch of expired exports along with their
+// archives and returns the number of exports deleted.
+func (ex *Exporter) PurgeExpired(ctx context.Context) (int, error) {
+	exports, err := ex.db.ListExpiredDataExports(time.Now(), ex.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, e := range exports {
+		if e.BlobKey != "" {
+			if err := ex.blob.Delete(ctx, e.BlobKey); err != nil {
+				return i, err
+			}
+		}
+		if err := ex.db.DeleteDataExport(e.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(exports), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
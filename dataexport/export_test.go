@@ -0,0 +1,191 @@
+package dataexport_test
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"merchant/blob/fsblob"
+	"merchant/dataexport"
+	"merchant/mock/mock_repository"
+	"merchant/model"
+)
+
+func TestLink(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1700000000, 0)
+
+	q := dataexport.SignLink(secret, "e1", now.Add(time.Hour))
+	assert.NoError(t, dataexport.VerifyLink(secret, "e1", q, now))
+	assert.Equal(t, dataexport.ErrInvalidLink, dataexport.VerifyLink(secret, "e2", q, now))
+	assert.Equal(t, dataexport.ErrInvalidLink, dataexport.VerifyLink([]byte("other"), "e1", q, now))
+	assert.Equal(t, dataexport.ErrLinkExpired, dataexport.VerifyLink(secret, "e1", q, now.Add(time.Hour)))
+
+	tampered := url.Values{"expires": {"1800000000"}, "signature": q["signature"]}
+	assert.Equal(t, dataexport.ErrInvalidLink, dataexport.VerifyLink(secret, "e1", tampered, now))
+}
+
+// expectArchive sets the repository up to hold a merchant with a version, a
+// team member, the version of a deleted team member and an event, and nothing
+// else.
+func expectArchive(db *mock_repository.MockRepository, merchantId string) {
+	db.EXPECT().ReadMerchantById(merchantId).Return(&model.Merchant{Model: model.Model{ID: merchantId}, Email: "shop@example.com"}, nil)
+	db.EXPECT().ListMerchantVersions(merchantId, gomock.Nil(), 500, 0).Return(model.MerchantVersions{{MerchantID: merchantId, Version: 1, Email: "old@example.com"}}, int64(1), nil)
+	db.EXPECT().ReadMerchantSettingsByMerchantId(merchantId).Return(nil, gorm.ErrRecordNotFound)
+	db.EXPECT().ReadVerificationByMerchantId(merchantId).Return(nil, gorm.ErrRecordNotFound)
+	db.EXPECT().ListTeamMembersByMerchantId(merchantId, gomock.Nil()).Return(model.TeamMembers{{Model: model.Model{ID: "t1"}, Email: "ada@example.com", MerchantID: merchantId}}, nil)
+	db.EXPECT().ListTeamMemberVersionsByMerchantId(merchantId, 500, 0).Return(model.TeamMemberVersions{{TeamMemberID: "t2", MerchantID: merchantId, Version: 2, Email: "grace@example.com"}}, nil)
+	db.EXPECT().ListLocationsByMerchantId(merchantId).Return(model.Locations{}, nil)
+	db.EXPECT().ListPayoutAccountsByMerchantId(merchantId).Return(model.PayoutAccounts{}, nil)
+	db.EXPECT().SearchProducts(merchantId, gomock.Any()).Return(model.Products{}, int64(0), nil)
+	db.EXPECT().ListInventoryLevels(merchantId, "", "").Return(model.InventoryLevels{}, nil)
+	db.EXPECT().ListStockMovements(merchantId, "", "", 500, 0).Return(model.StockMovements{}, nil)
+	db.EXPECT().ListStockAlertsByMerchantId(merchantId).Return(model.StockAlerts{}, nil)
+	db.EXPECT().SearchOrders(merchantId, gomock.Any()).Return(model.Orders{}, int64(0), nil)
+	db.EXPECT().ListPaymentIntents(merchantId, "").Return(model.PaymentIntents{}, nil).Times(2)
+	db.EXPECT().ListCouponsByMerchantId(merchantId).Return(model.Coupons{}, nil)
+	db.EXPECT().ListInvoicesByMerchantId(merchantId, 500, 0).Return(model.Invoices{}, int64(0), nil)
+	db.EXPECT().ListWebhookSubscriptionsByMerchantId(merchantId).Return(model.WebhookSubscriptions{}, nil)
+	db.EXPECT().ListOutboxEventsByMerchantId(merchantId, 500, 0).Return(model.OutboxEvents{
+		{ID: "ev1", Type: model.EventMerchantRegistered, AggregateType: model.AggregateMerchant, AggregateID: merchantId, Payload: `{"id":"m1"}`},
+	}, nil)
+}
+
+func readZipFile(t *testing.T, zr *zip.ReadCloser, name string) string {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		require.NoError(t, err)
+		defer rc.Close()
+
+		b, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Fatalf("archive has no %s", name)
+	return ""
+}
+
+func TestExportPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "exports")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storage, err := fsblob.New(dir)
+	require.NoError(t, err)
+
+	db := mock_repository.NewMockRepository(ctrl)
+	ex := dataexport.NewExporter(db, storage, zap.NewNop())
+
+	e := &model.DataExport{Model: model.Model{ID: "e1"}, MerchantID: "m1", Status: model.DataExportStatusPending}
+	db.EXPECT().ListPendingDataExports(gomock.Any(), 10).Return(model.DataExports{e}, nil)
+	db.EXPECT().ClaimDataExport("e1", gomock.Any(), gomock.Any()).Return(nil)
+	expectArchive(db, "m1")
+	db.EXPECT().FinishDataExport(e).Return(nil)
+
+	n, err := ex.ExportPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, model.DataExportStatusSucceeded, e.Status)
+	assert.Equal(t, "private/exports/m1/e1.zip", e.BlobKey)
+	require.NotNil(t, e.ExpiresAt)
+	assert.Equal(t, ex.LinkTTL, e.ExpiresAt.Sub(*e.FinishedAt))
+
+	name := filepath.Join(dir, "private", "exports", "m1", "e1.zip")
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), e.Size)
+
+	zr, err := zip.OpenReader(name)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	assert.Len(t, zr.File, 19)
+	assert.Contains(t, readZipFile(t, zr, "merchant.json"), `"email": "shop@example.com"`)
+	assert.Contains(t, readZipFile(t, zr, "merchant_versions.json"), `"email": "old@example.com"`)
+	assert.Equal(t, "null\n", readZipFile(t, zr, "settings.json"))
+	assert.Contains(t, readZipFile(t, zr, "team_members.json"), `"email": "ada@example.com"`)
+	assert.Contains(t, readZipFile(t, zr, "team_member_versions.json"), `"email": "grace@example.com"`)
+	assert.Equal(t, "[]\n", readZipFile(t, zr, "refunds.json"))
+	assert.Contains(t, readZipFile(t, zr, "events.json"), `"type": "merchant.registered"`)
+}
+
+func TestExportPendingFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "exports")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storage, err := fsblob.New(dir)
+	require.NoError(t, err)
+
+	db := mock_repository.NewMockRepository(ctrl)
+	ex := dataexport.NewExporter(db, storage, zap.NewNop())
+
+	e := &model.DataExport{Model: model.Model{ID: "e1"}, MerchantID: "m1", Status: model.DataExportStatusPending}
+	db.EXPECT().ListPendingDataExports(gomock.Any(), 10).Return(model.DataExports{e}, nil)
+	db.EXPECT().ClaimDataExport("e1", gomock.Any(), gomock.Any()).Return(nil)
+	db.EXPECT().ReadMerchantById("m1").Return(nil, errors.New("connection lost"))
+	db.EXPECT().FinishDataExport(e).Return(nil)
+
+	_, err = ex.ExportPending(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, model.DataExportStatusFailed, e.Status)
+	assert.Equal(t, dataexport.ErrExportFailed.Error(), e.Error)
+	assert.Empty(t, e.BlobKey)
+
+	_, err = os.Stat(filepath.Join(dir, "private", "exports", "m1", "e1.zip"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPurgeExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "exports")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	storage, err := fsblob.New(dir)
+	require.NoError(t, err)
+
+	name := filepath.Join(dir, "private", "exports", "m1", "e1.zip")
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+	require.NoError(t, ioutil.WriteFile(name, []byte("zip"), 0644))
+
+	db := mock_repository.NewMockRepository(ctrl)
+	ex := dataexport.NewExporter(db, storage, zap.NewNop())
+
+	db.EXPECT().ListExpiredDataExports(gomock.Any(), 10).Return(model.DataExports{
+		{Model: model.Model{ID: "e1"}, BlobKey: "private/exports/m1/e1.zip"},
+		{Model: model.Model{ID: "e2"}},
+	}, nil)
+	db.EXPECT().DeleteDataExport("e1").Return(nil)
+	db.EXPECT().DeleteDataExport("e2").Return(nil)
+
+	n, err := ex.PurgeExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = os.Stat(name)
+	assert.True(t, os.IsNotExist(err))
+}
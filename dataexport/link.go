@@ -0,0 +1,55 @@
+package dataexport
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidLink = errors.New("invalid download link")
+	ErrLinkExpired = errors.New("download link expired")
+)
+
+// SignLink returns the query of a download link of the export which is
+// valid until expires.
+func SignLink(secret []byte, id string, expires time.Time) url.Values {
+	timestamp := strconv.FormatInt(expires.Unix(), 10)
+
+	q := url.Values{}
+	q.Set("expires", timestamp)
+	q.Set("signature", hex.EncodeToString(mac(secret, id, timestamp)))
+	return q
+}
+
+// VerifyLink checks the query of a download link of the export followed at
+// now.
+func VerifyLink(secret []byte, id string, q url.Values, now time.Time) error {
+	timestamp := q.Get("expires")
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidLink
+	}
+	signature, err := hex.DecodeString(q.Get("signature"))
+	if err != nil || !hmac.Equal(signature, mac(secret, id, timestamp)) {
+		return ErrInvalidLink
+	}
+
+	if !now.Before(time.Unix(unix, 0)) {
+		return ErrLinkExpired
+	}
+
+	return nil
+}
+
+func mac(secret []byte, id, timestamp string) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(id))
+	m.Write([]byte("."))
+	m.Write([]byte(timestamp))
+	return m.Sum(nil)
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"merchant/model"
+)
+
+// SpecVersion is the CloudEvents specification version of the envelopes.
+const SpecVersion = "1.0"
+
+// Envelope is a merchant event in the CloudEvents 1.0 JSON format. The
+// merchant the event belongs to is carried in the merchantid extension
+// attribute, so consumers can route events without decoding the data.
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	MerchantID      string          `json:"merchantid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// Publisher sends envelopes to the consumers of merchant events. Envelopes
+// may be sent more than once; consumers tell duplicates apart by source and
+// ID, as CloudEvents prescribes.
+type Publisher interface {
+	Publish(ctx context.Context, e *Envelope) error
+	Close() error
+}
+
+// FromOutbox wraps a domain event recorded in the outbox. The subject names
+// the aggregate the event is about, as in team_member/<id>.
+func FromOutbox(source string, e *model.OutboxEvent) *Envelope {
+	return &Envelope{
+		SpecVersion:     SpecVersion,
+		ID:              e.ID,
+		Source:          source,
+		Type:            e.Type,
+		Subject:         e.AggregateType + "/" + e.AggregateID,
+		Time:            e.OccurredAt,
+		DataContentType: "application/json",
+		MerchantID:      e.MerchantID,
+		Data:            json.RawMessage(e.Payload),
+	}
+}
+
+// OutboxPublisher publishes the domain events relayed from the outbox to
+// the event bus.
+type OutboxPublisher struct {
+	publisher Publisher
+	source    string
+}
+
+func NewOutboxPublisher(publisher Publisher, source string) *OutboxPublisher {
+	return &OutboxPublisher{
+		publisher: publisher,
+		source:    source,
+	}
+}
+
+func (p *OutboxPublisher) Publish(ctx context.Context, e *model.OutboxEvent) error {
+	return p.publisher.Publish(ctx, FromOutbox(p.source, e))
+}
@@ -0,0 +1,43 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/events"
+	"merchant/model"
+)
+
+func TestFromOutbox(t *testing.T) {
+	t.Parallel()
+
+	occurred := time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)
+	e := events.FromOutbox("/merchant", &model.OutboxEvent{
+		ID:            "e1",
+		Type:          model.EventTeamMemberCreated,
+		AggregateType: model.AggregateTeamMember,
+		AggregateID:   "t1",
+		MerchantID:    "m1",
+		Payload:       `{"email":"ada@example.com"}`,
+		OccurredAt:    occurred,
+	})
+
+	body, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"specversion": "1.0",
+		"id": "e1",
+		"source": "/merchant",
+		"type": "team_member.created",
+		"subject": "team_member/t1",
+		"time": "2021-03-04T10:00:00Z",
+		"datacontenttype": "application/json",
+		"merchantid": "m1",
+		"data": {"email": "ada@example.com"}
+	}`, string(body))
+}
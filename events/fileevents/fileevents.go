@@ -0,0 +1,58 @@
+package fileevents
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"merchant/events"
+)
+
+// Publisher is an events.Publisher appending envelopes to a file as
+// newline delimited JSON, one envelope per line. The file is synced after
+// every envelope, so a published envelope survives a crash.
+type Publisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func New(name string) (*Publisher, error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Publisher{file: f}, nil
+}
+
+func (p *Publisher) Publish(_ context.Context, e *events.Envelope) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A single write of the whole line keeps lines intact when several
+	// processes append to the file.
+	if _, err := p.file.Write(line); err != nil {
+		return err
+	}
+
+	return p.file.Sync()
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.file.Close()
+}
@@ -0,0 +1,53 @@
+package fileevents_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/events"
+	"merchant/events/fileevents"
+)
+
+func TestPublisherAppendsLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileevents")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "events", "merchant.ndjson")
+
+	// Reopening the file appends to what was published before.
+	for _, id := range []string{"e1", "e2"} {
+		p, err := fileevents.New(name)
+		require.NoError(t, err)
+		require.NoError(t, p.Publish(context.Background(), &events.Envelope{
+			SpecVersion: events.SpecVersion,
+			ID:          id,
+			Type:        "merchant.updated",
+			Data:        json.RawMessage(`{"id":"m1"}`),
+		}))
+		require.NoError(t, p.Close())
+	}
+
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e := &events.Envelope{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), e))
+		ids = append(ids, e.ID)
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, []string{"e1", "e2"}, ids)
+}
@@ -0,0 +1,49 @@
+package memevents
+
+import (
+	"context"
+	"sync"
+
+	"merchant/events"
+)
+
+// Publisher is an events.Publisher keeping envelopes in memory, for
+// development and tests.
+type Publisher struct {
+	mu        sync.Mutex
+	envelopes []*events.Envelope
+}
+
+func New() *Publisher {
+	return &Publisher{}
+}
+
+func (p *Publisher) Publish(_ context.Context, e *events.Envelope) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.envelopes = append(p.envelopes, e)
+	return nil
+}
+
+// Envelopes returns the envelopes published so far, oldest first.
+func (p *Publisher) Envelopes() []*events.Envelope {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]*events.Envelope, len(p.envelopes))
+	copy(result, p.envelopes)
+	return result
+}
+
+// Reset forgets the envelopes published so far.
+func (p *Publisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.envelopes = nil
+}
+
+func (p *Publisher) Close() error {
+	return nil
+}
@@ -0,0 +1,214 @@
+package natsevents
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"merchant/events"
+)
+
+const defaultPort = "4222"
+
+var errPayloadTooLarge = errors.New("event exceeds the maximum payload of the server")
+
+// Publisher is an events.Publisher sending envelopes to a NATS server, or
+// anything speaking its client protocol, on the subject <prefix>.<type>.
+// Envelopes are sent in structured mode, the message body being the JSON
+// envelope.
+//
+// Every publish is followed by a PING, and only counts as done once the
+// server answered PONG: servers handle the messages of a connection in
+// order, so the answer confirms the server accepted the envelope.
+type Publisher struct {
+	addr     string
+	user     string
+	password string
+	prefix   string
+	timeout  time.Duration
+
+	mu         sync.Mutex
+	conn       net.Conn
+	r          *bufio.Reader
+	maxPayload int
+}
+
+// New returns a publisher for the server at the nats:// URL, which may
+// carry a user and password. The connection is made on the first publish
+// and made again after any failure.
+func New(rawURL, prefix string, timeout time.Duration) (*Publisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", rawURL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	p := &Publisher{
+		addr:    net.JoinHostPort(u.Hostname(), port),
+		prefix:  strings.TrimSuffix(prefix, "."),
+		timeout: timeout,
+	}
+	if u.User != nil {
+		p.user = u.User.Username()
+		p.password, _ = u.User.Password()
+	}
+
+	return p, nil
+}
+
+// Subject returns the subject envelopes of the event type are sent on.
+func (p *Publisher) Subject(eventType string) string {
+	if p.prefix == "" {
+		return eventType
+	}
+
+	return p.prefix + "." + eventType
+}
+
+func (p *Publisher) Publish(ctx context.Context, e *events.Envelope) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, p.Subject(e.Type), body); err != nil {
+		p.disconnect()
+		return err
+	}
+
+	return nil
+}
+
+func (p *Publisher) publish(ctx context.Context, subject string, body []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if p.maxPayload > 0 && len(body) > p.maxPayload {
+		return errPayloadTooLarge
+	}
+
+	if err := p.conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body)
+	if _, err := p.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	return p.awaitPong()
+}
+
+// awaitPong reads what the server sent until the answer to our PING.
+func (p *Publisher) awaitPong() error {
+	for {
+		line, err := p.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *Publisher) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	p.r = bufio.NewReader(conn)
+
+	if err := conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	line, err := p.r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(line))
+	}
+
+	var info struct {
+		MaxPayload int `json:"max_payload"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		return err
+	}
+	p.maxPayload = info.MaxPayload
+
+	options, err := json.Marshal(map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "merchant",
+		"lang":     "go",
+		"version":  "1.0",
+		"user":     p.user,
+		"pass":     p.password,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(conn, "CONNECT %s\r\n", options)
+	return err
+}
+
+func (p *Publisher) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		return d
+	}
+
+	return deadline
+}
+
+func (p *Publisher) disconnect() error {
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn = nil
+	p.r = nil
+	return err
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.disconnect()
+}
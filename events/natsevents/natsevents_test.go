@@ -0,0 +1,191 @@
+package natsevents_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/events"
+	"merchant/events/natsevents"
+)
+
+type message struct {
+	subject string
+	body    []byte
+}
+
+// standIn is a local stand-in for a NATS server, speaking just enough of
+// the protocol to take published messages. It rejects publishes while
+// reject is set.
+type standIn struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	messages []message
+	connects []string
+	reject   string
+}
+
+func newStandIn(t *testing.T) *standIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &standIn{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *standIn) URL(userinfo string) string {
+	return "nats://" + userinfo + s.ln.Addr().String()
+}
+
+func (s *standIn) Messages() []message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]message(nil), s.messages...)
+}
+
+func (s *standIn) serve(conn net.Conn) {
+	defer conn.Close()
+
+	fmt.Fprint(conn, `INFO {"server_id":"stand-in","max_payload":1048576}`+"\r\n")
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "CONNECT":
+			s.mu.Lock()
+			s.connects = append(s.connects, strings.TrimSpace(strings.TrimPrefix(line, "CONNECT")))
+			s.mu.Unlock()
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			body := make([]byte, size+2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			reject := s.reject
+			if reject == "" {
+				s.messages = append(s.messages, message{subject: fields[1], body: body[:size]})
+			}
+			s.mu.Unlock()
+
+			if reject != "" {
+				fmt.Fprintf(conn, "-ERR '%s'\r\n", reject)
+				return
+			}
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		}
+	}
+}
+
+func envelope(id, eventType string) *events.Envelope {
+	return &events.Envelope{
+		SpecVersion: events.SpecVersion,
+		ID:          id,
+		Source:      "/merchant",
+		Type:        eventType,
+		Data:        json.RawMessage(`{"id":"m1"}`),
+	}
+}
+
+func TestPublisherPublishes(t *testing.T) {
+	s := newStandIn(t)
+	defer s.ln.Close()
+
+	p, err := natsevents.New(s.URL("svc:secret@"), "merchant.events", time.Second)
+	require.NoError(t, err)
+	defer p.Close()
+
+	require.NoError(t, p.Publish(context.Background(), envelope("e1", "merchant.registered")))
+	require.NoError(t, p.Publish(context.Background(), envelope("e2", "team_member.created")))
+
+	messages := s.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "merchant.events.merchant.registered", messages[0].subject)
+	assert.Equal(t, "merchant.events.team_member.created", messages[1].subject)
+
+	received := &events.Envelope{}
+	require.NoError(t, json.Unmarshal(messages[1].body, received))
+	assert.Equal(t, "e2", received.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Len(t, s.connects, 1, "one connection for both publishes")
+	assert.Contains(t, s.connects[0], `"user":"svc"`)
+	assert.Contains(t, s.connects[0], `"pass":"secret"`)
+}
+
+func TestPublisherReportsRejectionAndReconnects(t *testing.T) {
+	s := newStandIn(t)
+	defer s.ln.Close()
+
+	p, err := natsevents.New(s.URL(""), "merchant.events", time.Second)
+	require.NoError(t, err)
+	defer p.Close()
+
+	s.mu.Lock()
+	s.reject = "Permissions Violation for Publish"
+	s.mu.Unlock()
+
+	err = p.Publish(context.Background(), envelope("e1", "merchant.updated"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Permissions Violation")
+
+	s.mu.Lock()
+	s.reject = ""
+	s.mu.Unlock()
+
+	require.NoError(t, p.Publish(context.Background(), envelope("e1", "merchant.updated")))
+	assert.Len(t, s.Messages(), 1)
+}
+
+func TestPublisherUnreachableServer(t *testing.T) {
+	s := newStandIn(t)
+	url := s.URL("")
+	s.ln.Close()
+
+	p, err := natsevents.New(url, "merchant.events", time.Second)
+	require.NoError(t, err)
+
+	assert.Error(t, p.Publish(context.Background(), envelope("e1", "merchant.updated")))
+}
+
+func TestNewRejectsInvalidURL(t *testing.T) {
+	t.Parallel()
+
+	_, err := natsevents.New("http://localhost:4222", "merchant.events", time.Second)
+	assert.Error(t, err)
+}
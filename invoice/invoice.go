@@ -0,0 +1,273 @@
+package invoice
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+
+	"merchant/util/imageutil"
+	"merchant/util/pdfutil"
+	"merchant/util/validator"
+)
+
+// Document holds what is printed on an invoice or receipt. Amounts are in
+// the minor unit of the currency.
+type Document struct {
+	Receipt       bool
+	Number        string
+	IssuedAt      time.Time
+	BusinessName  string
+	Logo          image.Image
+	CustomerName  string
+	CustomerEmail string
+	Currency      string
+	Note          string
+	Lines         []*Line
+	Subtotal      int64
+	DiscountTotal int64
+	TaxTotal      int64
+	Total         int64
+}
+
+// Line is a billed order line.
+type Line struct {
+	Description string
+	SKU         string
+	Quantity    int64
+	UnitPrice   int64
+	Discount    int64
+	Tax         int64
+	Total       int64
+}
+
+// Title is the heading of the document.
+func (d *Document) Title() string {
+	if d.Receipt {
+		return "Receipt"
+	}
+
+	return "Invoice"
+}
+
+// Amount formats an amount of the document currency.
+func (d *Document) Amount(amount int64) string {
+	return FormatAmount(amount, d.Currency)
+}
+
+// FormatAmount formats an amount given in the minor unit of the currency,
+// e.g. 123456 EUR as "1,234.56 EUR".
+func FormatAmount(amount int64, currency string) string {
+	digits, ok := validator.CurrencyMinorUnits(currency)
+	if !ok {
+		digits = 2
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	unit := int64(1)
+	for k := 0; k < digits; k++ {
+		unit *= 10
+	}
+
+	major := fmt.Sprintf("%d", amount/unit)
+	for k := len(major) - 3; k > 0; k -= 3 {
+		major = major[:k] + "," + major[k:]
+	}
+
+	if digits == 0 {
+		return fmt.Sprintf("%s%s %s", sign, major, currency)
+	}
+
+	return fmt.Sprintf("%s%s.%0*d %s", sign, major, digits, amount%unit, currency)
+}
+
+var htmlTemplate = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Number}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 40px; }
+header { display: flex; justify-content: space-between; align-items: flex-start; }
+header img { max-width: 96px; max-height: 96px; }
+table { width: 100%; border-collapse: collapse; margin-top: 24px; }
+th, td { padding: 6px 4px; border-bottom: 1px solid #ccc; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+tfoot td { border: none; }
+.total td { font-weight: bold; }
+</style>
+</head>
+<body>
+<header>
+<div>{{if .LogoURL}}<img src="{{.LogoURL}}" alt="{{.BusinessName}}">{{end}}
+<h2>{{.BusinessName}}</h2></div>
+<div><h1>{{.Title}}</h1>
+<p>{{.Number}}<br>{{.IssuedAt.Format "2 January 2006"}}</p></div>
+</header>
+<section>
+<h3>Billed to</h3>
+<p>{{.CustomerName}}<br>{{.CustomerEmail}}</p>
+</section>
+<table>
+<thead><tr><th>Description</th><th>Quantity</th><th>Unit price</th><th>Discount</th><th>Tax</th><th>Total</th></tr></thead>
+<tbody>
+{{- range .Lines}}
+<tr><td>{{.Description}}{{if .SKU}} ({{.SKU}}){{end}}</td><td>{{.Quantity}}</td><td>{{$.Amount .UnitPrice}}</td><td>{{$.Amount .Discount}}</td><td>{{$.Amount .Tax}}</td><td>{{$.Amount .Total}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr><td colspan="5">Subtotal</td><td>{{.Amount .Subtotal}}</td></tr>
+{{- if .DiscountTotal}}
+<tr><td colspan="5">Discount</td><td>-{{.Amount .DiscountTotal}}</td></tr>
+{{- end}}
+<tr><td colspan="5">Tax</td><td>{{.Amount .TaxTotal}}</td></tr>
+<tr class="total"><td colspan="5">{{if .Receipt}}Paid{{else}}Amount due{{end}}</td><td>{{.Amount .Total}}</td></tr>
+</tfoot>
+</table>
+{{- if .Note}}
+<p>{{.Note}}</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTML writes the document as a self-contained HTML page; the logo is
+// inlined as a data URL.
+func RenderHTML(w io.Writer, d *Document) error {
+	data := struct {
+		*Document
+		LogoURL template.URL
+	}{Document: d}
+
+	if d.Logo != nil {
+		buf := &bytes.Buffer{}
+		if err := png.Encode(buf, d.Logo); err != nil {
+			return err
+		}
+		data.LogoURL = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+	}
+
+	return htmlTemplate.Execute(w, data)
+}
+
+// Layout of the PDF rendering in points.
+const (
+	margin     = 50.0
+	logoSize   = 64.0
+	lineHeight = 16.0
+	fontSize   = 10.0
+)
+
+// columns are the right edges of the amount columns of the lines table.
+var columns = []float64{330, 400, 455, 500, pdfutil.PageWidth - margin}
+
+// RenderPDF writes the document as a PDF file of one or more A4 pages.
+func RenderPDF(w io.Writer, d *Document) error {
+	pdf := pdfutil.New()
+	right := pdfutil.PageWidth - margin
+
+	y := pdfutil.PageHeight - margin
+	if d.Logo != nil {
+		b := d.Logo.Bounds()
+		lw, lh := imageutil.Fit(b.Dx(), b.Dy(), int(logoSize), int(logoSize))
+		pdf.Image(d.Logo, margin, y-float64(lh), float64(lw), float64(lh))
+	}
+	pdf.TextRight(right, y-20, 20, true, d.Title())
+	pdf.TextRight(right, y-38, fontSize, false, d.Number)
+	pdf.TextRight(right, y-52, fontSize, false, d.IssuedAt.Format("2 January 2006"))
+
+	y -= logoSize + 24
+	pdf.Text(margin, y, 14, true, d.BusinessName)
+
+	y -= 32
+	pdf.Text(margin, y, fontSize, true, "Billed to")
+	pdf.Text(margin, y-lineHeight, fontSize, false, d.CustomerName)
+	pdf.Text(margin, y-2*lineHeight, fontSize, false, d.CustomerEmail)
+
+	y -= 3*lineHeight + 16
+	header := func() {
+		pdf.Text(margin, y, fontSize, true, "Description")
+		for k, title := range []string{"Quantity", "Unit price", "Discount", "Tax", "Total"} {
+			pdf.TextRight(columns[k], y, fontSize, true, title)
+		}
+		pdf.Line(margin, y-5, right, y-5)
+		y -= lineHeight + 4
+	}
+	header()
+
+	for _, line := range d.Lines {
+		if y < margin+lineHeight {
+			pdf.AddPage()
+			y = pdfutil.PageHeight - margin
+			header()
+		}
+
+		description := line.Description
+		if line.SKU != "" {
+			description += " (" + line.SKU + ")"
+		}
+		pdf.Text(margin, y, fontSize, false, truncate(description, 46))
+		for k, value := range []string{
+			fmt.Sprintf("%d", line.Quantity),
+			d.Amount(line.UnitPrice),
+			d.Amount(line.Discount),
+			d.Amount(line.Tax),
+			d.Amount(line.Total),
+		} {
+			pdf.TextRight(columns[k], y, fontSize, false, value)
+		}
+		y -= lineHeight
+	}
+
+	totals := [][2]string{{"Subtotal", d.Amount(d.Subtotal)}}
+	if d.DiscountTotal != 0 {
+		totals = append(totals, [2]string{"Discount", "-" + d.Amount(d.DiscountTotal)})
+	}
+	totals = append(totals, [2]string{"Tax", d.Amount(d.TaxTotal)})
+	if d.Receipt {
+		totals = append(totals, [2]string{"Paid", d.Amount(d.Total)})
+	} else {
+		totals = append(totals, [2]string{"Amount due", d.Amount(d.Total)})
+	}
+
+	if y < margin+float64(len(totals)+2)*lineHeight {
+		pdf.AddPage()
+		y = pdfutil.PageHeight - margin
+	}
+
+	pdf.Line(margin, y+lineHeight-5, right, y+lineHeight-5)
+	y -= 4
+	for k, total := range totals {
+		bold := k == len(totals)-1
+		pdf.TextRight(columns[3], y, fontSize, bold, total[0])
+		pdf.TextRight(columns[4], y, fontSize, bold, total[1])
+		y -= lineHeight
+	}
+
+	if d.Note != "" {
+		y -= lineHeight
+		pdf.Text(margin, y, fontSize, false, truncate(d.Note, 90))
+	}
+
+	_, err := pdf.WriteTo(w)
+	return err
+}
+
+// truncate shortens s to at most n characters so it fits its column.
+func truncate(s string, n int) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) <= n {
+		return string(runes)
+	}
+
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,106 @@
+package invoice_test
+
+import (
+	"bytes"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/invoice"
+)
+
+type formatAmountTestCase struct {
+	amount   int64
+	currency string
+	exp      string
+}
+
+var formatAmountTests = []*formatAmountTestCase{
+	{amount: 0, currency: "EUR", exp: "0.00 EUR"},
+	{amount: 5, currency: "EUR", exp: "0.05 EUR"},
+	{amount: 123456, currency: "EUR", exp: "1,234.56 EUR"},
+	{amount: 100000000, currency: "USD", exp: "1,000,000.00 USD"},
+	{amount: 1500, currency: "JPY", exp: "1,500 JPY"},
+	{amount: 1234, currency: "KWD", exp: "1.234 KWD"},
+	{amount: -250, currency: "GBP", exp: "-2.50 GBP"},
+}
+
+func TestFormatAmount(t *testing.T) {
+	for _, tc := range formatAmountTests {
+		tc := tc
+		t.Run(tc.exp, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.exp, invoice.FormatAmount(tc.amount, tc.currency))
+		})
+	}
+}
+
+func document() *invoice.Document {
+	return &invoice.Document{
+		Number:        "INV-000042",
+		IssuedAt:      time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC),
+		BusinessName:  "Corner <Shop>",
+		Logo:          image.NewRGBA(image.Rect(0, 0, 8, 8)),
+		CustomerName:  "Ada Lovelace",
+		CustomerEmail: "ada@example.com",
+		Currency:      "EUR",
+		Lines: []*invoice.Line{
+			{Description: "T-shirt", SKU: "TS-M", Quantity: 2, UnitPrice: 1999, Discount: 616, Tax: 676, Total: 4058},
+		},
+		Subtotal:      3998,
+		DiscountTotal: 616,
+		TaxTotal:      676,
+		Total:         4058,
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, invoice.RenderHTML(buf, document()))
+
+	html := buf.String()
+	assert.Contains(t, html, "<title>Invoice INV-000042</title>")
+	assert.Contains(t, html, "Corner &lt;Shop&gt;")
+	assert.Contains(t, html, `src="data:image/png;base64,`)
+	assert.Contains(t, html, "4 March 2021")
+	assert.Contains(t, html, "T-shirt (TS-M)")
+	assert.Contains(t, html, "-6.16 EUR")
+	assert.Contains(t, html, "Amount due</td><td>40.58 EUR")
+}
+
+func TestRenderHTMLReceipt(t *testing.T) {
+	t.Parallel()
+
+	doc := document()
+	doc.Receipt = true
+	doc.Logo = nil
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, invoice.RenderHTML(buf, doc))
+
+	html := buf.String()
+	assert.Contains(t, html, "<h1>Receipt</h1>")
+	assert.Contains(t, html, "Paid</td><td>40.58 EUR")
+	assert.NotContains(t, html, "<img")
+}
+
+func TestRenderPDFPaginates(t *testing.T) {
+	t.Parallel()
+
+	doc := document()
+	for len(doc.Lines) < 100 {
+		doc.Lines = append(doc.Lines, doc.Lines[0])
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, invoice.RenderPDF(buf, doc))
+
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, buf.String(), "/Count 3")
+}
@@ -0,0 +1,227 @@
+package keyring
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"merchant/model"
+	"merchant/repository"
+	"merchant/util/cryptoutil"
+)
+
+// Manager keeps the data keys of a keyring in the database. It loads them,
+// rotates them, and encrypts values again with the active key. Several
+// managers may share the keys; every one activates the newest.
+type Manager struct {
+	db     repository.Repository
+	ring   *cryptoutil.Keyring
+	logger *zap.Logger
+
+	// BatchSize is the number of rows encrypted again at a time.
+	BatchSize int
+
+	// reencrypted is the id of the key the last complete pass of
+	// re-encryption encrypted with.
+	reencrypted string
+}
+
+// NewManager returns a manager of the keys of the keyring, which fetches
+// from the database the keys it does not hold.
+func NewManager(db repository.Repository, ring *cryptoutil.Keyring, logger *zap.Logger) *Manager {
+	m := &Manager{
+		db:        db,
+		ring:      ring,
+		logger:    logger,
+		BatchSize: 100,
+	}
+	ring.Fetch = m.fetch
+
+	return m
+}
+
+// Load adds the stored keys to the keyring and activates the newest data
+// key. It creates the blind index key and a first data key when there are
+// none, and wraps again with the master key the keys wrapped by a retired
+// one.
+func (m *Manager) Load() error {
+	keys, err := m.db.ListDataKeys()
+	if err != nil {
+		return err
+	}
+
+	var index bool
+	var active string
+	for _, k := range keys {
+		key, err := m.unwrap(k)
+		if err != nil {
+			return err
+		}
+
+		if k.Purpose == model.DataKeyPurposeIndex {
+			m.ring.SetIndexKey(key)
+			index = true
+			continue
+		}
+		if err := m.ring.AddKey(k.ID, key); err != nil {
+			return err
+		}
+		active = k.ID
+	}
+
+	if !index {
+		_, key, err := m.create(model.DataKeyIndexID, model.DataKeyPurposeIndex)
+		if err == repository.ErrDuplicate {
+			// Another manager created it first.
+			key, err = m.fetch(model.DataKeyIndexID)
+		}
+		if err != nil {
+			return err
+		}
+		m.ring.SetIndexKey(key)
+	}
+
+	if active == "" {
+		_, err := m.Rotate()
+		return err
+	}
+
+	return m.ring.Activate(active)
+}
+
+// Rotate creates a data key and activates it. Values encrypted with the
+// keys before stay readable until they are encrypted again.
+func (m *Manager) Rotate() (*model.DataKey, error) {
+	k, key, err := m.create(uuid.New().String(), model.DataKeyPurposeEncryption)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ring.AddKey(k.ID, key); err != nil {
+		return nil, err
+	}
+	if err := m.ring.Activate(k.ID); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Run reloads the keys every interval until the context is done, and
+// encrypts again the values of every encrypted table whenever another key
+// was activated since the last complete pass, as well as once on start to
+// take in values written before.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Load(); err != nil {
+			m.logger.Warn(err.Error())
+		} else if active := m.ring.ActiveKeyID(); active != m.reencrypted {
+			n, err := m.Reencrypt(ctx)
+			if err != nil {
+				m.logger.Warn(err.Error())
+			} else {
+				m.reencrypted = active
+				m.logger.Info(fmt.Sprintf("%d rows encrypted again with data key %s", n, active))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reencrypt encrypts again with the active key the stale values of every
+// encrypted table and returns the number of rows written.
+func (m *Manager) Reencrypt(ctx context.Context) (int, error) {
+	total := 0
+	for _, table := range repository.EncryptedTables {
+		after := ""
+		for {
+			if ctx.Err() != nil {
+				return total, ctx.Err()
+			}
+
+			last, n, err := m.db.ReencryptRows(table, after, m.BatchSize)
+			if err != nil {
+				return total, err
+			}
+			total += n
+			if last == "" {
+				break
+			}
+			after = last
+		}
+	}
+
+	return total, nil
+}
+
+// create stores a random key of the purpose, wrapped by the master key, and
+// returns the record along with the key.
+func (m *Manager) create(id, purpose string) (*model.DataKey, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, err := m.ring.Wrap(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := &model.DataKey{
+		ID:         id,
+		Purpose:    purpose,
+		WrappedKey: base64.StdEncoding.EncodeToString(wrapped),
+	}
+	if err := m.db.CreateDataKey(k); err != nil {
+		return nil, nil, err
+	}
+
+	return k, key, nil
+}
+
+// unwrap returns the key, which it wraps again when a retired master key
+// wrapped it.
+func (m *Manager) unwrap(k *model.DataKey) ([]byte, error) {
+	wrapped, err := base64.StdEncoding.DecodeString(k.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, retired, err := m.ring.Unwrap(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if !retired {
+		return key, nil
+	}
+
+	if wrapped, err = m.ring.Wrap(key); err != nil {
+		return nil, err
+	}
+	if err := m.db.UpdateDataKeyWrappedKey(k.ID, base64.StdEncoding.EncodeToString(wrapped)); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (m *Manager) fetch(id string) ([]byte, error) {
+	k, err := m.db.ReadDataKeyById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.unwrap(k)
+}
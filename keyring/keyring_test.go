@@ -0,0 +1,168 @@
+package keyring_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"merchant/keyring"
+	"merchant/mock/mock_repository"
+	"merchant/model"
+	"merchant/repository"
+	"merchant/util/cryptoutil"
+)
+
+func cipher(t *testing.T, b byte) cryptoutil.Cipher {
+	c, err := cryptoutil.NewAESGCM(bytes.Repeat([]byte{b}, 32))
+	require.NoError(t, err)
+	return c
+}
+
+func wrap(t *testing.T, master cryptoutil.Cipher, key []byte) string {
+	wrapped, err := master.Encrypt(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(wrapped)
+}
+
+func TestLoadCreatesKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ring := cryptoutil.NewKeyring(cipher(t, 7))
+
+	var created []*model.DataKey
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListDataKeys().Return(model.DataKeys{}, nil)
+	db.EXPECT().CreateDataKey(gomock.Any()).DoAndReturn(func(k *model.DataKey) error {
+		created = append(created, k)
+		return nil
+	}).Times(2)
+
+	require.NoError(t, keyring.NewManager(db, ring, zap.NewNop()).Load())
+
+	require.Len(t, created, 2)
+	assert.Equal(t, model.DataKeyIndexID, created[0].ID)
+	assert.Equal(t, model.DataKeyPurposeIndex, created[0].Purpose)
+	assert.Equal(t, model.DataKeyPurposeEncryption, created[1].Purpose)
+	assert.Equal(t, created[1].ID, ring.ActiveKeyID())
+
+	_, err := ring.BlindIndex("jane@example.com")
+	assert.NoError(t, err)
+}
+
+func TestLoadRewrapsRetiredKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	retired, master := cipher(t, 7), cipher(t, 8)
+	ring := cryptoutil.NewKeyring(master, retired)
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListDataKeys().Return(model.DataKeys{
+		{ID: model.DataKeyIndexID, Purpose: model.DataKeyPurposeIndex, WrappedKey: wrap(t, master, bytes.Repeat([]byte{3}, 32))},
+		{ID: "old", Purpose: model.DataKeyPurposeEncryption, WrappedKey: wrap(t, master, bytes.Repeat([]byte{1}, 32))},
+		{ID: "new", Purpose: model.DataKeyPurposeEncryption, WrappedKey: wrap(t, retired, bytes.Repeat([]byte{2}, 32))},
+	}, nil)
+	db.EXPECT().UpdateDataKeyWrappedKey("new", gomock.Any()).DoAndReturn(func(_, wrapped string) error {
+		b, err := base64.StdEncoding.DecodeString(wrapped)
+		require.NoError(t, err)
+		key, err := master.Decrypt(b)
+		require.NoError(t, err)
+		assert.Equal(t, bytes.Repeat([]byte{2}, 32), key)
+		return nil
+	})
+
+	require.NoError(t, keyring.NewManager(db, ring, zap.NewNop()).Load())
+
+	assert.Equal(t, "new", ring.ActiveKeyID())
+}
+
+func TestLoadIndexKeyCreatedConcurrently(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	master := cipher(t, 7)
+	ring := cryptoutil.NewKeyring(master)
+	index := &model.DataKey{ID: model.DataKeyIndexID, Purpose: model.DataKeyPurposeIndex, WrappedKey: wrap(t, master, bytes.Repeat([]byte{3}, 32))}
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListDataKeys().Return(model.DataKeys{
+		{ID: "key", Purpose: model.DataKeyPurposeEncryption, WrappedKey: wrap(t, master, bytes.Repeat([]byte{1}, 32))},
+	}, nil)
+	db.EXPECT().CreateDataKey(gomock.Any()).Return(repository.ErrDuplicate)
+	db.EXPECT().ReadDataKeyById(model.DataKeyIndexID).Return(index, nil)
+
+	require.NoError(t, keyring.NewManager(db, ring, zap.NewNop()).Load())
+
+	expected := cryptoutil.NewKeyring(master)
+	expected.SetIndexKey(bytes.Repeat([]byte{3}, 32))
+	want, err := expected.BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	got, err := ring.BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestRotateFetchesKeysOfOtherManagers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	master := cipher(t, 7)
+	var created *model.DataKey
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().CreateDataKey(gomock.Any()).DoAndReturn(func(k *model.DataKey) error {
+		created = k
+		return nil
+	})
+
+	rotating := cryptoutil.NewKeyring(master)
+	k, err := keyring.NewManager(db, rotating, zap.NewNop()).Rotate()
+	require.NoError(t, err)
+	assert.Equal(t, k.ID, rotating.ActiveKeyID())
+
+	cryptoutil.SetKeyring(rotating)
+	value, err := cryptoutil.EncryptedString("jane@example.com").Value()
+	require.NoError(t, err)
+
+	db.EXPECT().ReadDataKeyById(k.ID).Return(created, nil)
+	other := cryptoutil.NewKeyring(master)
+	keyring.NewManager(db, other, zap.NewNop())
+	cryptoutil.SetKeyring(other)
+
+	var scanned cryptoutil.EncryptedString
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, cryptoutil.EncryptedString("jane@example.com"), scanned)
+}
+
+func TestReencrypt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := mock_repository.NewMockRepository(ctrl)
+	m := keyring.NewManager(db, cryptoutil.NewKeyring(cipher(t, 7)), zap.NewNop())
+	m.BatchSize = 2
+
+	db.EXPECT().ReencryptRows("merchants", "", 2).Return("b", 2, nil)
+	db.EXPECT().ReencryptRows("merchants", "b", 2).Return("c", 0, nil)
+	db.EXPECT().ReencryptRows("merchants", "c", 2).Return("", 0, nil)
+	db.EXPECT().ReencryptRows("merchant_versions", "", 2).Return("", 0, nil)
+	db.EXPECT().ReencryptRows("team_members", "", 2).Return("", 0, nil)
+	db.EXPECT().ReencryptRows("team_member_versions", "", 2).Return("", 0, nil)
+	db.EXPECT().ReencryptRows("payout_accounts", "", 2).Return("x", 1, nil)
+	db.EXPECT().ReencryptRows("payout_accounts", "x", 2).Return("", 0, nil)
+	db.EXPECT().ReencryptRows("webhook_subscriptions", "", 2).Return("", 0, nil)
+	db.EXPECT().ReencryptRows("webhook_deliveries", "", 2).Return("", 0, nil)
+	db.EXPECT().ReencryptRows("outbox_events", "", 2).Return("", 0, nil)
+	db.EXPECT().ReencryptRows("idempotency_keys", "", 2).Return("", 0, nil)
+
+	n, err := m.Reencrypt(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
@@ -6,7 +6,9 @@ package mock_repository
 
 import (
 	model "merchant/model"
+	repository "merchant/repository"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,6 +36,204 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ApplyStockMovement mocks base method.
+func (m_2 *MockRepository) ApplyStockMovement(m *model.StockMovement) (*model.InventoryLevel, *model.StockAlert, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "ApplyStockMovement", m)
+	ret0, _ := ret[0].(*model.InventoryLevel)
+	ret1, _ := ret[1].(*model.StockAlert)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ApplyStockMovement indicates an expected call of ApplyStockMovement.
+func (mr *MockRepositoryMockRecorder) ApplyStockMovement(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyStockMovement", reflect.TypeOf((*MockRepository)(nil).ApplyStockMovement), m)
+}
+
+// CancelMerchantClosure mocks base method.
+func (m *MockRepository) CancelMerchantClosure(id string, version int64, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelMerchantClosure", id, version, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelMerchantClosure indicates an expected call of CancelMerchantClosure.
+func (mr *MockRepositoryMockRecorder) CancelMerchantClosure(id, version, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelMerchantClosure", reflect.TypeOf((*MockRepository)(nil).CancelMerchantClosure), id, version, now)
+}
+
+// ClaimDataExport mocks base method.
+func (m *MockRepository) ClaimDataExport(id string, at, staleBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDataExport", id, at, staleBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimDataExport indicates an expected call of ClaimDataExport.
+func (mr *MockRepositoryMockRecorder) ClaimDataExport(id, at, staleBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDataExport", reflect.TypeOf((*MockRepository)(nil).ClaimDataExport), id, at, staleBefore)
+}
+
+// ClaimOutboxEvent mocks base method.
+func (m *MockRepository) ClaimOutboxEvent(sequence uint64, attempts int, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEvent", sequence, attempts, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimOutboxEvent indicates an expected call of ClaimOutboxEvent.
+func (mr *MockRepositoryMockRecorder) ClaimOutboxEvent(sequence, attempts, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEvent", reflect.TypeOf((*MockRepository)(nil).ClaimOutboxEvent), sequence, attempts, until)
+}
+
+// ClaimTeamMemberImport mocks base method.
+func (m *MockRepository) ClaimTeamMemberImport(id string, at, staleBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimTeamMemberImport", id, at, staleBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimTeamMemberImport indicates an expected call of ClaimTeamMemberImport.
+func (mr *MockRepositoryMockRecorder) ClaimTeamMemberImport(id, at, staleBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimTeamMemberImport", reflect.TypeOf((*MockRepository)(nil).ClaimTeamMemberImport), id, at, staleBefore)
+}
+
+// ClaimWebhookDelivery mocks base method.
+func (m *MockRepository) ClaimWebhookDelivery(id string, attempts int, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDelivery", id, attempts, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimWebhookDelivery indicates an expected call of ClaimWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) ClaimWebhookDelivery(id, attempts, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).ClaimWebhookDelivery), id, attempts, until)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockRepository) CompleteIdempotencyKey(id string, statusCode int, headers, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", id, statusCode, headers, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) CompleteIdempotencyKey(id, statusCode, headers, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).CompleteIdempotencyKey), id, statusCode, headers, body)
+}
+
+// CompleteRefund mocks base method.
+func (m *MockRepository) CompleteRefund(id, providerReference string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteRefund", id, providerReference)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteRefund indicates an expected call of CompleteRefund.
+func (mr *MockRepositoryMockRecorder) CompleteRefund(id, providerReference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteRefund", reflect.TypeOf((*MockRepository)(nil).CompleteRefund), id, providerReference)
+}
+
+// CreateCoupon mocks base method.
+func (m *MockRepository) CreateCoupon(c *model.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCoupon", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCoupon indicates an expected call of CreateCoupon.
+func (mr *MockRepositoryMockRecorder) CreateCoupon(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCoupon", reflect.TypeOf((*MockRepository)(nil).CreateCoupon), c)
+}
+
+// CreateDataExport mocks base method.
+func (m *MockRepository) CreateDataExport(e *model.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataExport", e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDataExport indicates an expected call of CreateDataExport.
+func (mr *MockRepositoryMockRecorder) CreateDataExport(e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataExport", reflect.TypeOf((*MockRepository)(nil).CreateDataExport), e)
+}
+
+// CreateDataKey mocks base method.
+func (m *MockRepository) CreateDataKey(k *model.DataKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataKey", k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDataKey indicates an expected call of CreateDataKey.
+func (mr *MockRepositoryMockRecorder) CreateDataKey(k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataKey", reflect.TypeOf((*MockRepository)(nil).CreateDataKey), k)
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockRepository) CreateIdempotencyKey(k *model.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) CreateIdempotencyKey(k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).CreateIdempotencyKey), k)
+}
+
+// CreateInvoice mocks base method.
+func (m *MockRepository) CreateInvoice(i *model.Invoice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvoice", i)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateInvoice indicates an expected call of CreateInvoice.
+func (mr *MockRepositoryMockRecorder) CreateInvoice(i interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoice", reflect.TypeOf((*MockRepository)(nil).CreateInvoice), i)
+}
+
+// CreateLocation mocks base method.
+func (m *MockRepository) CreateLocation(l *model.Location) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLocation", l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLocation indicates an expected call of CreateLocation.
+func (mr *MockRepositoryMockRecorder) CreateLocation(l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLocation", reflect.TypeOf((*MockRepository)(nil).CreateLocation), l)
+}
+
 // CreateMerchant mocks base method.
 func (m *MockRepository) CreateMerchant(u *model.Merchant) error {
 	m.ctrl.T.Helper()
@@ -48,6 +248,104 @@ func (mr *MockRepositoryMockRecorder) CreateMerchant(u interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMerchant", reflect.TypeOf((*MockRepository)(nil).CreateMerchant), u)
 }
 
+// CreateMerchantTombstone mocks base method.
+func (m *MockRepository) CreateMerchantTombstone(t *model.MerchantTombstone) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMerchantTombstone", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMerchantTombstone indicates an expected call of CreateMerchantTombstone.
+func (mr *MockRepositoryMockRecorder) CreateMerchantTombstone(t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMerchantTombstone", reflect.TypeOf((*MockRepository)(nil).CreateMerchantTombstone), t)
+}
+
+// CreateOrder mocks base method.
+func (m *MockRepository) CreateOrder(o *model.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", o)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockRepositoryMockRecorder) CreateOrder(o interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockRepository)(nil).CreateOrder), o)
+}
+
+// CreateOutboxEvent mocks base method.
+func (m *MockRepository) CreateOutboxEvent(e *model.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockRepositoryMockRecorder) CreateOutboxEvent(e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockRepository)(nil).CreateOutboxEvent), e)
+}
+
+// CreatePaymentIntent mocks base method.
+func (m *MockRepository) CreatePaymentIntent(p *model.PaymentIntent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentIntent", p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePaymentIntent indicates an expected call of CreatePaymentIntent.
+func (mr *MockRepositoryMockRecorder) CreatePaymentIntent(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentIntent", reflect.TypeOf((*MockRepository)(nil).CreatePaymentIntent), p)
+}
+
+// CreatePayoutAccount mocks base method.
+func (m *MockRepository) CreatePayoutAccount(p *model.PayoutAccount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePayoutAccount", p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePayoutAccount indicates an expected call of CreatePayoutAccount.
+func (mr *MockRepositoryMockRecorder) CreatePayoutAccount(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayoutAccount", reflect.TypeOf((*MockRepository)(nil).CreatePayoutAccount), p)
+}
+
+// CreateProduct mocks base method.
+func (m *MockRepository) CreateProduct(p *model.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockRepositoryMockRecorder) CreateProduct(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockRepository)(nil).CreateProduct), p)
+}
+
+// CreateProductVariant mocks base method.
+func (m *MockRepository) CreateProductVariant(v *model.ProductVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductVariant", v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProductVariant indicates an expected call of CreateProductVariant.
+func (mr *MockRepositoryMockRecorder) CreateProductVariant(v interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductVariant", reflect.TypeOf((*MockRepository)(nil).CreateProductVariant), v)
+}
+
 // CreateTeamMember mocks base method.
 func (m *MockRepository) CreateTeamMember(t *model.TeamMember) error {
 	m.ctrl.T.Helper()
@@ -62,148 +360,1657 @@ func (mr *MockRepositoryMockRecorder) CreateTeamMember(t interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTeamMember", reflect.TypeOf((*MockRepository)(nil).CreateTeamMember), t)
 }
 
-// DeleteMerchant mocks base method.
-func (m *MockRepository) DeleteMerchant(id string) error {
+// CreateTeamMemberImport mocks base method.
+func (m *MockRepository) CreateTeamMemberImport(i *model.TeamMemberImport) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteMerchant", id)
+	ret := m.ctrl.Call(m, "CreateTeamMemberImport", i)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteMerchant indicates an expected call of DeleteMerchant.
-func (mr *MockRepositoryMockRecorder) DeleteMerchant(id interface{}) *gomock.Call {
+// CreateTeamMemberImport indicates an expected call of CreateTeamMemberImport.
+func (mr *MockRepositoryMockRecorder) CreateTeamMemberImport(i interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMerchant", reflect.TypeOf((*MockRepository)(nil).DeleteMerchant), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTeamMemberImport", reflect.TypeOf((*MockRepository)(nil).CreateTeamMemberImport), i)
 }
 
-// DeleteTeamMember mocks base method.
-func (m *MockRepository) DeleteTeamMember(id string) error {
+// CreateVerification mocks base method.
+func (m *MockRepository) CreateVerification(v *model.Verification) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTeamMember", id)
+	ret := m.ctrl.Call(m, "CreateVerification", v)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTeamMember indicates an expected call of DeleteTeamMember.
-func (mr *MockRepositoryMockRecorder) DeleteTeamMember(id interface{}) *gomock.Call {
+// CreateVerification indicates an expected call of CreateVerification.
+func (mr *MockRepositoryMockRecorder) CreateVerification(v interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTeamMember", reflect.TypeOf((*MockRepository)(nil).DeleteTeamMember), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerification", reflect.TypeOf((*MockRepository)(nil).CreateVerification), v)
 }
 
-// ListMerchants mocks base method.
-func (m *MockRepository) ListMerchants() (model.Merchants, error) {
+// CreateVerificationDocument mocks base method.
+func (m *MockRepository) CreateVerificationDocument(d *model.VerificationDocument) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListMerchants")
-	ret0, _ := ret[0].(model.Merchants)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateVerificationDocument", d)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ListMerchants indicates an expected call of ListMerchants.
-func (mr *MockRepositoryMockRecorder) ListMerchants() *gomock.Call {
+// CreateVerificationDocument indicates an expected call of CreateVerificationDocument.
+func (mr *MockRepositoryMockRecorder) CreateVerificationDocument(d interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchants", reflect.TypeOf((*MockRepository)(nil).ListMerchants))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVerificationDocument", reflect.TypeOf((*MockRepository)(nil).CreateVerificationDocument), d)
 }
 
-// ListTeamMembersByMerchantId mocks base method.
-func (m *MockRepository) ListTeamMembersByMerchantId(merchantId string) (model.TeamMembers, error) {
+// CreateWebhookDeliveries mocks base method.
+func (m *MockRepository) CreateWebhookDeliveries(ds model.WebhookDeliveries) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTeamMembersByMerchantId", merchantId)
-	ret0, _ := ret[0].(model.TeamMembers)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateWebhookDeliveries", ds)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ListTeamMembersByMerchantId indicates an expected call of ListTeamMembersByMerchantId.
-func (mr *MockRepositoryMockRecorder) ListTeamMembersByMerchantId(merchantId interface{}) *gomock.Call {
+// CreateWebhookDeliveries indicates an expected call of CreateWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) CreateWebhookDeliveries(ds interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMembersByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListTeamMembersByMerchantId), merchantId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).CreateWebhookDeliveries), ds)
 }
 
-// ReadMerchantByEmail mocks base method.
-func (m *MockRepository) ReadMerchantByEmail(email string) (*model.Merchant, error) {
+// CreateWebhookSubscription mocks base method.
+func (m *MockRepository) CreateWebhookSubscription(s *model.WebhookSubscription) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadMerchantByEmail", email)
-	ret0, _ := ret[0].(*model.Merchant)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", s)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ReadMerchantByEmail indicates an expected call of ReadMerchantByEmail.
-func (mr *MockRepositoryMockRecorder) ReadMerchantByEmail(email interface{}) *gomock.Call {
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) CreateWebhookSubscription(s interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMerchantByEmail", reflect.TypeOf((*MockRepository)(nil).ReadMerchantByEmail), email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).CreateWebhookSubscription), s)
 }
 
-// ReadMerchantById mocks base method.
-func (m *MockRepository) ReadMerchantById(id string) (*model.Merchant, error) {
+// DeleteDataExport mocks base method.
+func (m *MockRepository) DeleteDataExport(id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadMerchantById", id)
-	ret0, _ := ret[0].(*model.Merchant)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteDataExport", id)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ReadMerchantById indicates an expected call of ReadMerchantById.
-func (mr *MockRepositoryMockRecorder) ReadMerchantById(id interface{}) *gomock.Call {
+// DeleteDataExport indicates an expected call of DeleteDataExport.
+func (mr *MockRepositoryMockRecorder) DeleteDataExport(id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMerchantById", reflect.TypeOf((*MockRepository)(nil).ReadMerchantById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDataExport", reflect.TypeOf((*MockRepository)(nil).DeleteDataExport), id)
 }
 
-// ReadTeamMemberByEmail mocks base method.
-func (m *MockRepository) ReadTeamMemberByEmail(email string) (*model.TeamMember, error) {
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockRepository) DeleteExpiredIdempotencyKeys(now time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadTeamMemberByEmail", email)
-	ret0, _ := ret[0].(*model.TeamMember)
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", now)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ReadTeamMemberByEmail indicates an expected call of ReadTeamMemberByEmail.
-func (mr *MockRepositoryMockRecorder) ReadTeamMemberByEmail(email interface{}) *gomock.Call {
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredIdempotencyKeys(now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTeamMemberByEmail", reflect.TypeOf((*MockRepository)(nil).ReadTeamMemberByEmail), email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredIdempotencyKeys), now)
 }
 
-// ReadTeamMemberById mocks base method.
-func (m *MockRepository) ReadTeamMemberById(id string) (*model.TeamMember, error) {
+// DeleteIdempotencyKey mocks base method.
+func (m *MockRepository) DeleteIdempotencyKey(id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadTeamMemberById", id)
-	ret0, _ := ret[0].(*model.TeamMember)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", id)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ReadTeamMemberById indicates an expected call of ReadTeamMemberById.
-func (mr *MockRepositoryMockRecorder) ReadTeamMemberById(id interface{}) *gomock.Call {
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) DeleteIdempotencyKey(id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTeamMemberById", reflect.TypeOf((*MockRepository)(nil).ReadTeamMemberById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).DeleteIdempotencyKey), id)
 }
 
-// UpdateMerchantDescriptionById mocks base method.
-func (m *MockRepository) UpdateMerchantDescriptionById(id, d string) error {
+// DeleteLocation mocks base method.
+func (m *MockRepository) DeleteLocation(id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateMerchantDescriptionById", id, d)
+	ret := m.ctrl.Call(m, "DeleteLocation", id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateMerchantDescriptionById indicates an expected call of UpdateMerchantDescriptionById.
-func (mr *MockRepositoryMockRecorder) UpdateMerchantDescriptionById(id, d interface{}) *gomock.Call {
+// DeleteLocation indicates an expected call of DeleteLocation.
+func (mr *MockRepositoryMockRecorder) DeleteLocation(id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchantDescriptionById", reflect.TypeOf((*MockRepository)(nil).UpdateMerchantDescriptionById), id, d)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLocation", reflect.TypeOf((*MockRepository)(nil).DeleteLocation), id)
 }
 
-// UpdateTeamMemberById mocks base method.
-func (m *MockRepository) UpdateTeamMemberById(id string, t *model.TeamMember) error {
+// DeletePayoutAccount mocks base method.
+func (m *MockRepository) DeletePayoutAccount(id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateTeamMemberById", id, t)
+	ret := m.ctrl.Call(m, "DeletePayoutAccount", id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateTeamMemberById indicates an expected call of UpdateTeamMemberById.
-func (mr *MockRepositoryMockRecorder) UpdateTeamMemberById(id, t interface{}) *gomock.Call {
+// DeletePayoutAccount indicates an expected call of DeletePayoutAccount.
+func (mr *MockRepositoryMockRecorder) DeletePayoutAccount(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePayoutAccount", reflect.TypeOf((*MockRepository)(nil).DeletePayoutAccount), id)
+}
+
+// DeleteProduct mocks base method.
+func (m *MockRepository) DeleteProduct(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockRepositoryMockRecorder) DeleteProduct(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockRepository)(nil).DeleteProduct), id)
+}
+
+// DeleteProductVariant mocks base method.
+func (m *MockRepository) DeleteProductVariant(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductVariant", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductVariant indicates an expected call of DeleteProductVariant.
+func (mr *MockRepositoryMockRecorder) DeleteProductVariant(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductVariant", reflect.TypeOf((*MockRepository)(nil).DeleteProductVariant), id)
+}
+
+// DeleteTeamMember mocks base method.
+func (m *MockRepository) DeleteTeamMember(id string, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTeamMember", id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTeamMember indicates an expected call of DeleteTeamMember.
+func (mr *MockRepositoryMockRecorder) DeleteTeamMember(id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTeamMember", reflect.TypeOf((*MockRepository)(nil).DeleteTeamMember), id, version)
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockRepository) DeleteWebhookSubscription(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) DeleteWebhookSubscription(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).DeleteWebhookSubscription), id)
+}
+
+// EraseMerchant mocks base method.
+func (m *MockRepository) EraseMerchant(id string, now time.Time) (map[string]int64, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseMerchant", id, now)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EraseMerchant indicates an expected call of EraseMerchant.
+func (mr *MockRepositoryMockRecorder) EraseMerchant(id, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseMerchant", reflect.TypeOf((*MockRepository)(nil).EraseMerchant), id, now)
+}
+
+// FailRefund mocks base method.
+func (m *MockRepository) FailRefund(id, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailRefund", id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailRefund indicates an expected call of FailRefund.
+func (mr *MockRepositoryMockRecorder) FailRefund(id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailRefund", reflect.TypeOf((*MockRepository)(nil).FailRefund), id, reason)
+}
+
+// FinishDataExport mocks base method.
+func (m *MockRepository) FinishDataExport(e *model.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishDataExport", e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishDataExport indicates an expected call of FinishDataExport.
+func (mr *MockRepositoryMockRecorder) FinishDataExport(e interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishDataExport", reflect.TypeOf((*MockRepository)(nil).FinishDataExport), e)
+}
+
+// FinishTeamMemberImport mocks base method.
+func (m *MockRepository) FinishTeamMemberImport(i *model.TeamMemberImport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishTeamMemberImport", i)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishTeamMemberImport indicates an expected call of FinishTeamMemberImport.
+func (mr *MockRepositoryMockRecorder) FinishTeamMemberImport(i interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishTeamMemberImport", reflect.TypeOf((*MockRepository)(nil).FinishTeamMemberImport), i)
+}
+
+// ListCouponsByMerchantId mocks base method.
+func (m *MockRepository) ListCouponsByMerchantId(merchantId string) (model.Coupons, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCouponsByMerchantId", merchantId)
+	ret0, _ := ret[0].(model.Coupons)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCouponsByMerchantId indicates an expected call of ListCouponsByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListCouponsByMerchantId(merchantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCouponsByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListCouponsByMerchantId), merchantId)
+}
+
+// ListDataKeys mocks base method.
+func (m *MockRepository) ListDataKeys() (model.DataKeys, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDataKeys")
+	ret0, _ := ret[0].(model.DataKeys)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDataKeys indicates an expected call of ListDataKeys.
+func (mr *MockRepositoryMockRecorder) ListDataKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDataKeys", reflect.TypeOf((*MockRepository)(nil).ListDataKeys))
+}
+
+// ListDueWebhookDeliveries mocks base method.
+func (m *MockRepository) ListDueWebhookDeliveries(now time.Time, limit int) (model.WebhookDeliveries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueWebhookDeliveries", now, limit)
+	ret0, _ := ret[0].(model.WebhookDeliveries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueWebhookDeliveries indicates an expected call of ListDueWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) ListDueWebhookDeliveries(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).ListDueWebhookDeliveries), now, limit)
+}
+
+// ListExpiredDataExports mocks base method.
+func (m *MockRepository) ListExpiredDataExports(now time.Time, limit int) (model.DataExports, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredDataExports", now, limit)
+	ret0, _ := ret[0].(model.DataExports)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredDataExports indicates an expected call of ListExpiredDataExports.
+func (mr *MockRepositoryMockRecorder) ListExpiredDataExports(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredDataExports", reflect.TypeOf((*MockRepository)(nil).ListExpiredDataExports), now, limit)
+}
+
+// ListInventoryLevels mocks base method.
+func (m *MockRepository) ListInventoryLevels(merchantId, variantId, locationId string) (model.InventoryLevels, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInventoryLevels", merchantId, variantId, locationId)
+	ret0, _ := ret[0].(model.InventoryLevels)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInventoryLevels indicates an expected call of ListInventoryLevels.
+func (mr *MockRepositoryMockRecorder) ListInventoryLevels(merchantId, variantId, locationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInventoryLevels", reflect.TypeOf((*MockRepository)(nil).ListInventoryLevels), merchantId, variantId, locationId)
+}
+
+// ListInvoicesByMerchantId mocks base method.
+func (m *MockRepository) ListInvoicesByMerchantId(merchantId string, limit, offset int) (model.Invoices, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvoicesByMerchantId", merchantId, limit, offset)
+	ret0, _ := ret[0].(model.Invoices)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListInvoicesByMerchantId indicates an expected call of ListInvoicesByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListInvoicesByMerchantId(merchantId, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvoicesByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListInvoicesByMerchantId), merchantId, limit, offset)
+}
+
+// ListLocationsByMerchantId mocks base method.
+func (m *MockRepository) ListLocationsByMerchantId(merchantId string) (model.Locations, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLocationsByMerchantId", merchantId)
+	ret0, _ := ret[0].(model.Locations)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLocationsByMerchantId indicates an expected call of ListLocationsByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListLocationsByMerchantId(merchantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLocationsByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListLocationsByMerchantId), merchantId)
+}
+
+// ListMerchantDescendantIds mocks base method.
+func (m *MockRepository) ListMerchantDescendantIds(id string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantDescendantIds", id)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantDescendantIds indicates an expected call of ListMerchantDescendantIds.
+func (mr *MockRepositoryMockRecorder) ListMerchantDescendantIds(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantDescendantIds", reflect.TypeOf((*MockRepository)(nil).ListMerchantDescendantIds), id)
+}
+
+// ListMerchantVersions mocks base method.
+func (m *MockRepository) ListMerchantVersions(merchantId string, at *time.Time, limit, offset int) (model.MerchantVersions, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantVersions", merchantId, at, limit, offset)
+	ret0, _ := ret[0].(model.MerchantVersions)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListMerchantVersions indicates an expected call of ListMerchantVersions.
+func (mr *MockRepositoryMockRecorder) ListMerchantVersions(merchantId, at, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantVersions", reflect.TypeOf((*MockRepository)(nil).ListMerchantVersions), merchantId, at, limit, offset)
+}
+
+// ListMerchants mocks base method.
+func (m *MockRepository) ListMerchants(metadata model.Metadata) (model.Merchants, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchants", metadata)
+	ret0, _ := ret[0].(model.Merchants)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchants indicates an expected call of ListMerchants.
+func (mr *MockRepositoryMockRecorder) ListMerchants(metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchants", reflect.TypeOf((*MockRepository)(nil).ListMerchants), metadata)
+}
+
+// ListMerchantsByIds mocks base method.
+func (m *MockRepository) ListMerchantsByIds(ids []string, metadata model.Metadata) (model.Merchants, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantsByIds", ids, metadata)
+	ret0, _ := ret[0].(model.Merchants)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantsByIds indicates an expected call of ListMerchantsByIds.
+func (mr *MockRepositoryMockRecorder) ListMerchantsByIds(ids, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantsByIds", reflect.TypeOf((*MockRepository)(nil).ListMerchantsByIds), ids, metadata)
+}
+
+// ListMerchantsByIdsAfter mocks base method.
+func (m *MockRepository) ListMerchantsByIdsAfter(ids []string, afterId string, limit int) (model.Merchants, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantsByIdsAfter", ids, afterId, limit)
+	ret0, _ := ret[0].(model.Merchants)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantsByIdsAfter indicates an expected call of ListMerchantsByIdsAfter.
+func (mr *MockRepositoryMockRecorder) ListMerchantsByIdsAfter(ids, afterId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantsByIdsAfter", reflect.TypeOf((*MockRepository)(nil).ListMerchantsByIdsAfter), ids, afterId, limit)
+}
+
+// ListMerchantsDueForClosure mocks base method.
+func (m *MockRepository) ListMerchantsDueForClosure(now time.Time, limit int) (model.Merchants, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantsDueForClosure", now, limit)
+	ret0, _ := ret[0].(model.Merchants)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantsDueForClosure indicates an expected call of ListMerchantsDueForClosure.
+func (mr *MockRepositoryMockRecorder) ListMerchantsDueForClosure(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantsDueForClosure", reflect.TypeOf((*MockRepository)(nil).ListMerchantsDueForClosure), now, limit)
+}
+
+// ListOutboxEventsByMerchantId mocks base method.
+func (m *MockRepository) ListOutboxEventsByMerchantId(merchantId string, limit, offset int) (model.OutboxEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutboxEventsByMerchantId", merchantId, limit, offset)
+	ret0, _ := ret[0].(model.OutboxEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutboxEventsByMerchantId indicates an expected call of ListOutboxEventsByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListOutboxEventsByMerchantId(merchantId, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutboxEventsByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListOutboxEventsByMerchantId), merchantId, limit, offset)
+}
+
+// ListPaymentIntents mocks base method.
+func (m *MockRepository) ListPaymentIntents(merchantId, orderId string) (model.PaymentIntents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentIntents", merchantId, orderId)
+	ret0, _ := ret[0].(model.PaymentIntents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentIntents indicates an expected call of ListPaymentIntents.
+func (mr *MockRepositoryMockRecorder) ListPaymentIntents(merchantId, orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentIntents", reflect.TypeOf((*MockRepository)(nil).ListPaymentIntents), merchantId, orderId)
+}
+
+// ListPayoutAccountsByMerchantId mocks base method.
+func (m *MockRepository) ListPayoutAccountsByMerchantId(merchantId string) (model.PayoutAccounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPayoutAccountsByMerchantId", merchantId)
+	ret0, _ := ret[0].(model.PayoutAccounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPayoutAccountsByMerchantId indicates an expected call of ListPayoutAccountsByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListPayoutAccountsByMerchantId(merchantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPayoutAccountsByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListPayoutAccountsByMerchantId), merchantId)
+}
+
+// ListPayoutAccountsByStatus mocks base method.
+func (m *MockRepository) ListPayoutAccountsByStatus(status string) (model.PayoutAccounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPayoutAccountsByStatus", status)
+	ret0, _ := ret[0].(model.PayoutAccounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPayoutAccountsByStatus indicates an expected call of ListPayoutAccountsByStatus.
+func (mr *MockRepositoryMockRecorder) ListPayoutAccountsByStatus(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPayoutAccountsByStatus", reflect.TypeOf((*MockRepository)(nil).ListPayoutAccountsByStatus), status)
+}
+
+// ListPendingDataExports mocks base method.
+func (m *MockRepository) ListPendingDataExports(staleBefore time.Time, limit int) (model.DataExports, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingDataExports", staleBefore, limit)
+	ret0, _ := ret[0].(model.DataExports)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingDataExports indicates an expected call of ListPendingDataExports.
+func (mr *MockRepositoryMockRecorder) ListPendingDataExports(staleBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingDataExports", reflect.TypeOf((*MockRepository)(nil).ListPendingDataExports), staleBefore, limit)
+}
+
+// ListPendingOutboxEvents mocks base method.
+func (m *MockRepository) ListPendingOutboxEvents(now time.Time, limit int) (model.OutboxEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingOutboxEvents", now, limit)
+	ret0, _ := ret[0].(model.OutboxEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingOutboxEvents indicates an expected call of ListPendingOutboxEvents.
+func (mr *MockRepositoryMockRecorder) ListPendingOutboxEvents(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingOutboxEvents", reflect.TypeOf((*MockRepository)(nil).ListPendingOutboxEvents), now, limit)
+}
+
+// ListPendingRefunds mocks base method.
+func (m *MockRepository) ListPendingRefunds(before time.Time, limit int) (model.Refunds, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingRefunds", before, limit)
+	ret0, _ := ret[0].(model.Refunds)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingRefunds indicates an expected call of ListPendingRefunds.
+func (mr *MockRepositoryMockRecorder) ListPendingRefunds(before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingRefunds", reflect.TypeOf((*MockRepository)(nil).ListPendingRefunds), before, limit)
+}
+
+// ListPendingTeamMemberImports mocks base method.
+func (m *MockRepository) ListPendingTeamMemberImports(staleBefore time.Time, limit int) (model.TeamMemberImports, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingTeamMemberImports", staleBefore, limit)
+	ret0, _ := ret[0].(model.TeamMemberImports)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingTeamMemberImports indicates an expected call of ListPendingTeamMemberImports.
+func (mr *MockRepositoryMockRecorder) ListPendingTeamMemberImports(staleBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingTeamMemberImports", reflect.TypeOf((*MockRepository)(nil).ListPendingTeamMemberImports), staleBefore, limit)
+}
+
+// ListProductsByVariantIds mocks base method.
+func (m *MockRepository) ListProductsByVariantIds(variantIds []string) (model.Products, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductsByVariantIds", variantIds)
+	ret0, _ := ret[0].(model.Products)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductsByVariantIds indicates an expected call of ListProductsByVariantIds.
+func (mr *MockRepositoryMockRecorder) ListProductsByVariantIds(variantIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductsByVariantIds", reflect.TypeOf((*MockRepository)(nil).ListProductsByVariantIds), variantIds)
+}
+
+// ListRefundsByPaymentIntentId mocks base method.
+func (m *MockRepository) ListRefundsByPaymentIntentId(paymentIntentId string) (model.Refunds, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRefundsByPaymentIntentId", paymentIntentId)
+	ret0, _ := ret[0].(model.Refunds)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRefundsByPaymentIntentId indicates an expected call of ListRefundsByPaymentIntentId.
+func (mr *MockRepositoryMockRecorder) ListRefundsByPaymentIntentId(paymentIntentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRefundsByPaymentIntentId", reflect.TypeOf((*MockRepository)(nil).ListRefundsByPaymentIntentId), paymentIntentId)
+}
+
+// ListStockAlertsByMerchantId mocks base method.
+func (m *MockRepository) ListStockAlertsByMerchantId(merchantId string) (model.StockAlerts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStockAlertsByMerchantId", merchantId)
+	ret0, _ := ret[0].(model.StockAlerts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStockAlertsByMerchantId indicates an expected call of ListStockAlertsByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListStockAlertsByMerchantId(merchantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStockAlertsByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListStockAlertsByMerchantId), merchantId)
+}
+
+// ListStockMovements mocks base method.
+func (m *MockRepository) ListStockMovements(merchantId, variantId, locationId string, limit, offset int) (model.StockMovements, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStockMovements", merchantId, variantId, locationId, limit, offset)
+	ret0, _ := ret[0].(model.StockMovements)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStockMovements indicates an expected call of ListStockMovements.
+func (mr *MockRepositoryMockRecorder) ListStockMovements(merchantId, variantId, locationId, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStockMovements", reflect.TypeOf((*MockRepository)(nil).ListStockMovements), merchantId, variantId, locationId, limit, offset)
+}
+
+// ListTeamMemberVersions mocks base method.
+func (m *MockRepository) ListTeamMemberVersions(teamMemberId string, at *time.Time, limit, offset int) (model.TeamMemberVersions, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeamMemberVersions", teamMemberId, at, limit, offset)
+	ret0, _ := ret[0].(model.TeamMemberVersions)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTeamMemberVersions indicates an expected call of ListTeamMemberVersions.
+func (mr *MockRepositoryMockRecorder) ListTeamMemberVersions(teamMemberId, at, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMemberVersions", reflect.TypeOf((*MockRepository)(nil).ListTeamMemberVersions), teamMemberId, at, limit, offset)
+}
+
+// ListTeamMemberVersionsByMerchantId mocks base method.
+func (m *MockRepository) ListTeamMemberVersionsByMerchantId(merchantId string, limit, offset int) (model.TeamMemberVersions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeamMemberVersionsByMerchantId", merchantId, limit, offset)
+	ret0, _ := ret[0].(model.TeamMemberVersions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeamMemberVersionsByMerchantId indicates an expected call of ListTeamMemberVersionsByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListTeamMemberVersionsByMerchantId(merchantId, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMemberVersionsByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListTeamMemberVersionsByMerchantId), merchantId, limit, offset)
+}
+
+// ListTeamMembersByMerchantId mocks base method.
+func (m *MockRepository) ListTeamMembersByMerchantId(merchantId string, metadata model.Metadata) (model.TeamMembers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeamMembersByMerchantId", merchantId, metadata)
+	ret0, _ := ret[0].(model.TeamMembers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeamMembersByMerchantId indicates an expected call of ListTeamMembersByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListTeamMembersByMerchantId(merchantId, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMembersByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListTeamMembersByMerchantId), merchantId, metadata)
+}
+
+// ListTeamMembersByMerchantIdAfter mocks base method.
+func (m *MockRepository) ListTeamMembersByMerchantIdAfter(merchantId, afterId string, limit int) (model.TeamMembers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeamMembersByMerchantIdAfter", merchantId, afterId, limit)
+	ret0, _ := ret[0].(model.TeamMembers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeamMembersByMerchantIdAfter indicates an expected call of ListTeamMembersByMerchantIdAfter.
+func (mr *MockRepositoryMockRecorder) ListTeamMembersByMerchantIdAfter(merchantId, afterId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMembersByMerchantIdAfter", reflect.TypeOf((*MockRepository)(nil).ListTeamMembersByMerchantIdAfter), merchantId, afterId, limit)
+}
+
+// ListTeamMembersByMerchantIds mocks base method.
+func (m *MockRepository) ListTeamMembersByMerchantIds(merchantIds []string, metadata model.Metadata) (model.TeamMembers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeamMembersByMerchantIds", merchantIds, metadata)
+	ret0, _ := ret[0].(model.TeamMembers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeamMembersByMerchantIds indicates an expected call of ListTeamMembersByMerchantIds.
+func (mr *MockRepositoryMockRecorder) ListTeamMembersByMerchantIds(merchantIds, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMembersByMerchantIds", reflect.TypeOf((*MockRepository)(nil).ListTeamMembersByMerchantIds), merchantIds, metadata)
+}
+
+// ListVerificationsByStatus mocks base method.
+func (m *MockRepository) ListVerificationsByStatus(status string) (model.Verifications, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVerificationsByStatus", status)
+	ret0, _ := ret[0].(model.Verifications)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVerificationsByStatus indicates an expected call of ListVerificationsByStatus.
+func (mr *MockRepositoryMockRecorder) ListVerificationsByStatus(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVerificationsByStatus", reflect.TypeOf((*MockRepository)(nil).ListVerificationsByStatus), status)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockRepository) ListWebhookDeliveries(subscriptionId, status string, limit, offset int) (model.WebhookDeliveries, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", subscriptionId, status, limit, offset)
+	ret0, _ := ret[0].(model.WebhookDeliveries)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) ListWebhookDeliveries(subscriptionId, status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).ListWebhookDeliveries), subscriptionId, status, limit, offset)
+}
+
+// ListWebhookSubscriptionsByMerchantId mocks base method.
+func (m *MockRepository) ListWebhookSubscriptionsByMerchantId(merchantId string) (model.WebhookSubscriptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptionsByMerchantId", merchantId)
+	ret0, _ := ret[0].(model.WebhookSubscriptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptionsByMerchantId indicates an expected call of ListWebhookSubscriptionsByMerchantId.
+func (mr *MockRepositoryMockRecorder) ListWebhookSubscriptionsByMerchantId(merchantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptionsByMerchantId", reflect.TypeOf((*MockRepository)(nil).ListWebhookSubscriptionsByMerchantId), merchantId)
+}
+
+// MarkOutboxEventPublished mocks base method.
+func (m *MockRepository) MarkOutboxEventPublished(sequence uint64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventPublished", sequence, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventPublished indicates an expected call of MarkOutboxEventPublished.
+func (mr *MockRepositoryMockRecorder) MarkOutboxEventPublished(sequence, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventPublished", reflect.TypeOf((*MockRepository)(nil).MarkOutboxEventPublished), sequence, at)
+}
+
+// ProcessPaymentCallback mocks base method.
+func (m *MockRepository) ProcessPaymentCallback(cb *model.ProcessedCallback, id, from string, p *model.PaymentIntent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessPaymentCallback", cb, id, from, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProcessPaymentCallback indicates an expected call of ProcessPaymentCallback.
+func (mr *MockRepositoryMockRecorder) ProcessPaymentCallback(cb, id, from, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPaymentCallback", reflect.TypeOf((*MockRepository)(nil).ProcessPaymentCallback), cb, id, from, p)
+}
+
+// ReadCouponByCode mocks base method.
+func (m *MockRepository) ReadCouponByCode(merchantId, code string) (*model.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCouponByCode", merchantId, code)
+	ret0, _ := ret[0].(*model.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadCouponByCode indicates an expected call of ReadCouponByCode.
+func (mr *MockRepositoryMockRecorder) ReadCouponByCode(merchantId, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCouponByCode", reflect.TypeOf((*MockRepository)(nil).ReadCouponByCode), merchantId, code)
+}
+
+// ReadCouponById mocks base method.
+func (m *MockRepository) ReadCouponById(id string) (*model.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCouponById", id)
+	ret0, _ := ret[0].(*model.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadCouponById indicates an expected call of ReadCouponById.
+func (mr *MockRepositoryMockRecorder) ReadCouponById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCouponById", reflect.TypeOf((*MockRepository)(nil).ReadCouponById), id)
+}
+
+// ReadDataExportById mocks base method.
+func (m *MockRepository) ReadDataExportById(id string) (*model.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadDataExportById", id)
+	ret0, _ := ret[0].(*model.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDataExportById indicates an expected call of ReadDataExportById.
+func (mr *MockRepositoryMockRecorder) ReadDataExportById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDataExportById", reflect.TypeOf((*MockRepository)(nil).ReadDataExportById), id)
+}
+
+// ReadDataKeyById mocks base method.
+func (m *MockRepository) ReadDataKeyById(id string) (*model.DataKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadDataKeyById", id)
+	ret0, _ := ret[0].(*model.DataKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDataKeyById indicates an expected call of ReadDataKeyById.
+func (mr *MockRepositoryMockRecorder) ReadDataKeyById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDataKeyById", reflect.TypeOf((*MockRepository)(nil).ReadDataKeyById), id)
+}
+
+// ReadIdempotencyKey mocks base method.
+func (m *MockRepository) ReadIdempotencyKey(scope, key string) (*model.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadIdempotencyKey", scope, key)
+	ret0, _ := ret[0].(*model.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadIdempotencyKey indicates an expected call of ReadIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) ReadIdempotencyKey(scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).ReadIdempotencyKey), scope, key)
+}
+
+// ReadInvoiceById mocks base method.
+func (m *MockRepository) ReadInvoiceById(id string) (*model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadInvoiceById", id)
+	ret0, _ := ret[0].(*model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadInvoiceById indicates an expected call of ReadInvoiceById.
+func (mr *MockRepositoryMockRecorder) ReadInvoiceById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadInvoiceById", reflect.TypeOf((*MockRepository)(nil).ReadInvoiceById), id)
+}
+
+// ReadLocationById mocks base method.
+func (m *MockRepository) ReadLocationById(id string) (*model.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadLocationById", id)
+	ret0, _ := ret[0].(*model.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLocationById indicates an expected call of ReadLocationById.
+func (mr *MockRepositoryMockRecorder) ReadLocationById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLocationById", reflect.TypeOf((*MockRepository)(nil).ReadLocationById), id)
+}
+
+// ReadMerchantByEmail mocks base method.
+func (m *MockRepository) ReadMerchantByEmail(email string) (*model.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMerchantByEmail", email)
+	ret0, _ := ret[0].(*model.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadMerchantByEmail indicates an expected call of ReadMerchantByEmail.
+func (mr *MockRepositoryMockRecorder) ReadMerchantByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMerchantByEmail", reflect.TypeOf((*MockRepository)(nil).ReadMerchantByEmail), email)
+}
+
+// ReadMerchantById mocks base method.
+func (m *MockRepository) ReadMerchantById(id string) (*model.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMerchantById", id)
+	ret0, _ := ret[0].(*model.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadMerchantById indicates an expected call of ReadMerchantById.
+func (mr *MockRepositoryMockRecorder) ReadMerchantById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMerchantById", reflect.TypeOf((*MockRepository)(nil).ReadMerchantById), id)
+}
+
+// ReadMerchantSettingsByMerchantId mocks base method.
+func (m *MockRepository) ReadMerchantSettingsByMerchantId(merchantId string) (*model.MerchantSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMerchantSettingsByMerchantId", merchantId)
+	ret0, _ := ret[0].(*model.MerchantSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadMerchantSettingsByMerchantId indicates an expected call of ReadMerchantSettingsByMerchantId.
+func (mr *MockRepositoryMockRecorder) ReadMerchantSettingsByMerchantId(merchantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMerchantSettingsByMerchantId", reflect.TypeOf((*MockRepository)(nil).ReadMerchantSettingsByMerchantId), merchantId)
+}
+
+// ReadMerchantVersion mocks base method.
+func (m *MockRepository) ReadMerchantVersion(merchantId string, version int64) (*model.MerchantVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadMerchantVersion", merchantId, version)
+	ret0, _ := ret[0].(*model.MerchantVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadMerchantVersion indicates an expected call of ReadMerchantVersion.
+func (mr *MockRepositoryMockRecorder) ReadMerchantVersion(merchantId, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMerchantVersion", reflect.TypeOf((*MockRepository)(nil).ReadMerchantVersion), merchantId, version)
+}
+
+// ReadOrderById mocks base method.
+func (m *MockRepository) ReadOrderById(id string) (*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOrderById", id)
+	ret0, _ := ret[0].(*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOrderById indicates an expected call of ReadOrderById.
+func (mr *MockRepositoryMockRecorder) ReadOrderById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOrderById", reflect.TypeOf((*MockRepository)(nil).ReadOrderById), id)
+}
+
+// ReadPaymentIntentById mocks base method.
+func (m *MockRepository) ReadPaymentIntentById(id string) (*model.PaymentIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPaymentIntentById", id)
+	ret0, _ := ret[0].(*model.PaymentIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadPaymentIntentById indicates an expected call of ReadPaymentIntentById.
+func (mr *MockRepositoryMockRecorder) ReadPaymentIntentById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPaymentIntentById", reflect.TypeOf((*MockRepository)(nil).ReadPaymentIntentById), id)
+}
+
+// ReadPaymentIntentByProviderReference mocks base method.
+func (m *MockRepository) ReadPaymentIntentByProviderReference(provider, reference string) (*model.PaymentIntent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPaymentIntentByProviderReference", provider, reference)
+	ret0, _ := ret[0].(*model.PaymentIntent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadPaymentIntentByProviderReference indicates an expected call of ReadPaymentIntentByProviderReference.
+func (mr *MockRepositoryMockRecorder) ReadPaymentIntentByProviderReference(provider, reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPaymentIntentByProviderReference", reflect.TypeOf((*MockRepository)(nil).ReadPaymentIntentByProviderReference), provider, reference)
+}
+
+// ReadPayoutAccountById mocks base method.
+func (m *MockRepository) ReadPayoutAccountById(id string) (*model.PayoutAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadPayoutAccountById", id)
+	ret0, _ := ret[0].(*model.PayoutAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadPayoutAccountById indicates an expected call of ReadPayoutAccountById.
+func (mr *MockRepositoryMockRecorder) ReadPayoutAccountById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadPayoutAccountById", reflect.TypeOf((*MockRepository)(nil).ReadPayoutAccountById), id)
+}
+
+// ReadProductById mocks base method.
+func (m *MockRepository) ReadProductById(id string) (*model.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadProductById", id)
+	ret0, _ := ret[0].(*model.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadProductById indicates an expected call of ReadProductById.
+func (mr *MockRepositoryMockRecorder) ReadProductById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadProductById", reflect.TypeOf((*MockRepository)(nil).ReadProductById), id)
+}
+
+// ReadProductVariantById mocks base method.
+func (m *MockRepository) ReadProductVariantById(id string) (*model.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadProductVariantById", id)
+	ret0, _ := ret[0].(*model.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadProductVariantById indicates an expected call of ReadProductVariantById.
+func (mr *MockRepositoryMockRecorder) ReadProductVariantById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadProductVariantById", reflect.TypeOf((*MockRepository)(nil).ReadProductVariantById), id)
+}
+
+// ReadRefundById mocks base method.
+func (m *MockRepository) ReadRefundById(id string) (*model.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRefundById", id)
+	ret0, _ := ret[0].(*model.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadRefundById indicates an expected call of ReadRefundById.
+func (mr *MockRepositoryMockRecorder) ReadRefundById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRefundById", reflect.TypeOf((*MockRepository)(nil).ReadRefundById), id)
+}
+
+// ReadTeamMemberByEmail mocks base method.
+func (m *MockRepository) ReadTeamMemberByEmail(email string) (*model.TeamMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTeamMemberByEmail", email)
+	ret0, _ := ret[0].(*model.TeamMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTeamMemberByEmail indicates an expected call of ReadTeamMemberByEmail.
+func (mr *MockRepositoryMockRecorder) ReadTeamMemberByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTeamMemberByEmail", reflect.TypeOf((*MockRepository)(nil).ReadTeamMemberByEmail), email)
+}
+
+// ReadTeamMemberById mocks base method.
+func (m *MockRepository) ReadTeamMemberById(id string) (*model.TeamMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTeamMemberById", id)
+	ret0, _ := ret[0].(*model.TeamMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTeamMemberById indicates an expected call of ReadTeamMemberById.
+func (mr *MockRepositoryMockRecorder) ReadTeamMemberById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTeamMemberById", reflect.TypeOf((*MockRepository)(nil).ReadTeamMemberById), id)
+}
+
+// ReadTeamMemberImportById mocks base method.
+func (m *MockRepository) ReadTeamMemberImportById(id string) (*model.TeamMemberImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTeamMemberImportById", id)
+	ret0, _ := ret[0].(*model.TeamMemberImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTeamMemberImportById indicates an expected call of ReadTeamMemberImportById.
+func (mr *MockRepositoryMockRecorder) ReadTeamMemberImportById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTeamMemberImportById", reflect.TypeOf((*MockRepository)(nil).ReadTeamMemberImportById), id)
+}
+
+// ReadTeamMemberVersion mocks base method.
+func (m *MockRepository) ReadTeamMemberVersion(teamMemberId string, version int64) (*model.TeamMemberVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTeamMemberVersion", teamMemberId, version)
+	ret0, _ := ret[0].(*model.TeamMemberVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTeamMemberVersion indicates an expected call of ReadTeamMemberVersion.
+func (mr *MockRepositoryMockRecorder) ReadTeamMemberVersion(teamMemberId, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTeamMemberVersion", reflect.TypeOf((*MockRepository)(nil).ReadTeamMemberVersion), teamMemberId, version)
+}
+
+// ReadVerificationById mocks base method.
+func (m *MockRepository) ReadVerificationById(id string) (*model.Verification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadVerificationById", id)
+	ret0, _ := ret[0].(*model.Verification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadVerificationById indicates an expected call of ReadVerificationById.
+func (mr *MockRepositoryMockRecorder) ReadVerificationById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadVerificationById", reflect.TypeOf((*MockRepository)(nil).ReadVerificationById), id)
+}
+
+// ReadVerificationByMerchantId mocks base method.
+func (m *MockRepository) ReadVerificationByMerchantId(merchantId string) (*model.Verification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadVerificationByMerchantId", merchantId)
+	ret0, _ := ret[0].(*model.Verification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadVerificationByMerchantId indicates an expected call of ReadVerificationByMerchantId.
+func (mr *MockRepositoryMockRecorder) ReadVerificationByMerchantId(merchantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadVerificationByMerchantId", reflect.TypeOf((*MockRepository)(nil).ReadVerificationByMerchantId), merchantId)
+}
+
+// ReadVerificationDocumentById mocks base method.
+func (m *MockRepository) ReadVerificationDocumentById(id string) (*model.VerificationDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadVerificationDocumentById", id)
+	ret0, _ := ret[0].(*model.VerificationDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadVerificationDocumentById indicates an expected call of ReadVerificationDocumentById.
+func (mr *MockRepositoryMockRecorder) ReadVerificationDocumentById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadVerificationDocumentById", reflect.TypeOf((*MockRepository)(nil).ReadVerificationDocumentById), id)
+}
+
+// ReadWebhookDeliveryById mocks base method.
+func (m *MockRepository) ReadWebhookDeliveryById(id string) (*model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadWebhookDeliveryById", id)
+	ret0, _ := ret[0].(*model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadWebhookDeliveryById indicates an expected call of ReadWebhookDeliveryById.
+func (mr *MockRepositoryMockRecorder) ReadWebhookDeliveryById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWebhookDeliveryById", reflect.TypeOf((*MockRepository)(nil).ReadWebhookDeliveryById), id)
+}
+
+// ReadWebhookSubscriptionById mocks base method.
+func (m *MockRepository) ReadWebhookSubscriptionById(id string) (*model.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadWebhookSubscriptionById", id)
+	ret0, _ := ret[0].(*model.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadWebhookSubscriptionById indicates an expected call of ReadWebhookSubscriptionById.
+func (mr *MockRepositoryMockRecorder) ReadWebhookSubscriptionById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWebhookSubscriptionById", reflect.TypeOf((*MockRepository)(nil).ReadWebhookSubscriptionById), id)
+}
+
+// RecordOutboxEventFailure mocks base method.
+func (m *MockRepository) RecordOutboxEventFailure(sequence uint64, retryAt time.Time, errMessage string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordOutboxEventFailure", sequence, retryAt, errMessage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordOutboxEventFailure indicates an expected call of RecordOutboxEventFailure.
+func (mr *MockRepositoryMockRecorder) RecordOutboxEventFailure(sequence, retryAt, errMessage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordOutboxEventFailure", reflect.TypeOf((*MockRepository)(nil).RecordOutboxEventFailure), sequence, retryAt, errMessage)
+}
+
+// RedeemCoupon mocks base method.
+func (m *MockRepository) RedeemCoupon(rd *model.CouponRedemption, o *model.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemCoupon", rd, o)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedeemCoupon indicates an expected call of RedeemCoupon.
+func (mr *MockRepositoryMockRecorder) RedeemCoupon(rd, o interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemCoupon", reflect.TypeOf((*MockRepository)(nil).RedeemCoupon), rd, o)
+}
+
+// RedeliverWebhookDelivery mocks base method.
+func (m *MockRepository) RedeliverWebhookDelivery(id string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverWebhookDelivery", id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedeliverWebhookDelivery indicates an expected call of RedeliverWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) RedeliverWebhookDelivery(id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).RedeliverWebhookDelivery), id, at)
+}
+
+// ReencryptRows mocks base method.
+func (m *MockRepository) ReencryptRows(table, afterId string, limit int) (string, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReencryptRows", table, afterId, limit)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReencryptRows indicates an expected call of ReencryptRows.
+func (mr *MockRepositoryMockRecorder) ReencryptRows(table, afterId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReencryptRows", reflect.TypeOf((*MockRepository)(nil).ReencryptRows), table, afterId, limit)
+}
+
+// ReleaseCoupon mocks base method.
+func (m *MockRepository) ReleaseCoupon(couponId string, o *model.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseCoupon", couponId, o)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseCoupon indicates an expected call of ReleaseCoupon.
+func (mr *MockRepositoryMockRecorder) ReleaseCoupon(couponId, o interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseCoupon", reflect.TypeOf((*MockRepository)(nil).ReleaseCoupon), couponId, o)
+}
+
+// RequestMerchantClosure mocks base method.
+func (m *MockRepository) RequestMerchantClosure(id string, version int64, requestedAt, closesAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestMerchantClosure", id, version, requestedAt, closesAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestMerchantClosure indicates an expected call of RequestMerchantClosure.
+func (mr *MockRepositoryMockRecorder) RequestMerchantClosure(id, version, requestedAt, closesAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestMerchantClosure", reflect.TypeOf((*MockRepository)(nil).RequestMerchantClosure), id, version, requestedAt, closesAt)
+}
+
+// ReserveRefund mocks base method.
+func (m *MockRepository) ReserveRefund(rf *model.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveRefund", rf)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveRefund indicates an expected call of ReserveRefund.
+func (mr *MockRepositoryMockRecorder) ReserveRefund(rf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveRefund", reflect.TypeOf((*MockRepository)(nil).ReserveRefund), rf)
+}
+
+// RestoreMerchantById mocks base method.
+func (m *MockRepository) RestoreMerchantById(id string, version int64, v *model.MerchantVersion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreMerchantById", id, version, v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreMerchantById indicates an expected call of RestoreMerchantById.
+func (mr *MockRepositoryMockRecorder) RestoreMerchantById(id, version, v interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreMerchantById", reflect.TypeOf((*MockRepository)(nil).RestoreMerchantById), id, version, v)
+}
+
+// ResubmitVerificationById mocks base method.
+func (m *MockRepository) ResubmitVerificationById(id string, v *model.Verification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResubmitVerificationById", id, v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResubmitVerificationById indicates an expected call of ResubmitVerificationById.
+func (mr *MockRepositoryMockRecorder) ResubmitVerificationById(id, v interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResubmitVerificationById", reflect.TypeOf((*MockRepository)(nil).ResubmitVerificationById), id, v)
+}
+
+// SaveMerchantSettings mocks base method.
+func (m *MockRepository) SaveMerchantSettings(s *model.MerchantSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMerchantSettings", s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMerchantSettings indicates an expected call of SaveMerchantSettings.
+func (mr *MockRepositoryMockRecorder) SaveMerchantSettings(s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMerchantSettings", reflect.TypeOf((*MockRepository)(nil).SaveMerchantSettings), s)
+}
+
+// SearchOrders mocks base method.
+func (m *MockRepository) SearchOrders(merchantId string, search *model.OrderSearch) (model.Orders, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchOrders", merchantId, search)
+	ret0, _ := ret[0].(model.Orders)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchOrders indicates an expected call of SearchOrders.
+func (mr *MockRepositoryMockRecorder) SearchOrders(merchantId, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchOrders", reflect.TypeOf((*MockRepository)(nil).SearchOrders), merchantId, search)
+}
+
+// SearchProducts mocks base method.
+func (m *MockRepository) SearchProducts(merchantId string, search *model.ProductSearch) (model.Products, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", merchantId, search)
+	ret0, _ := ret[0].(model.Products)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockRepositoryMockRecorder) SearchProducts(merchantId, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockRepository)(nil).SearchProducts), merchantId, search)
+}
+
+// Transaction mocks base method.
+func (m *MockRepository) Transaction(fn func(repository.Repository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockRepositoryMockRecorder) Transaction(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockRepository)(nil).Transaction), fn)
+}
+
+// UpdateCouponById mocks base method.
+func (m *MockRepository) UpdateCouponById(id string, c *model.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCouponById", id, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCouponById indicates an expected call of UpdateCouponById.
+func (mr *MockRepositoryMockRecorder) UpdateCouponById(id, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCouponById", reflect.TypeOf((*MockRepository)(nil).UpdateCouponById), id, c)
+}
+
+// UpdateDataKeyWrappedKey mocks base method.
+func (m *MockRepository) UpdateDataKeyWrappedKey(id, wrappedKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDataKeyWrappedKey", id, wrappedKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDataKeyWrappedKey indicates an expected call of UpdateDataKeyWrappedKey.
+func (mr *MockRepositoryMockRecorder) UpdateDataKeyWrappedKey(id, wrappedKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDataKeyWrappedKey", reflect.TypeOf((*MockRepository)(nil).UpdateDataKeyWrappedKey), id, wrappedKey)
+}
+
+// UpdateDraftOrderById mocks base method.
+func (m *MockRepository) UpdateDraftOrderById(id string, o *model.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDraftOrderById", id, o)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDraftOrderById indicates an expected call of UpdateDraftOrderById.
+func (mr *MockRepositoryMockRecorder) UpdateDraftOrderById(id, o interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraftOrderById", reflect.TypeOf((*MockRepository)(nil).UpdateDraftOrderById), id, o)
+}
+
+// UpdateInventoryThreshold mocks base method.
+func (m *MockRepository) UpdateInventoryThreshold(merchantId, variantId, locationId string, threshold int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInventoryThreshold", merchantId, variantId, locationId, threshold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInventoryThreshold indicates an expected call of UpdateInventoryThreshold.
+func (mr *MockRepositoryMockRecorder) UpdateInventoryThreshold(merchantId, variantId, locationId, threshold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInventoryThreshold", reflect.TypeOf((*MockRepository)(nil).UpdateInventoryThreshold), merchantId, variantId, locationId, threshold)
+}
+
+// UpdateInvoiceDocumentsById mocks base method.
+func (m *MockRepository) UpdateInvoiceDocumentsById(id, pdfKey, htmlKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInvoiceDocumentsById", id, pdfKey, htmlKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInvoiceDocumentsById indicates an expected call of UpdateInvoiceDocumentsById.
+func (mr *MockRepositoryMockRecorder) UpdateInvoiceDocumentsById(id, pdfKey, htmlKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInvoiceDocumentsById", reflect.TypeOf((*MockRepository)(nil).UpdateInvoiceDocumentsById), id, pdfKey, htmlKey)
+}
+
+// UpdateLocationById mocks base method.
+func (m *MockRepository) UpdateLocationById(id string, l *model.Location) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLocationById", id, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLocationById indicates an expected call of UpdateLocationById.
+func (mr *MockRepositoryMockRecorder) UpdateLocationById(id, l interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLocationById", reflect.TypeOf((*MockRepository)(nil).UpdateLocationById), id, l)
+}
+
+// UpdateMerchantBannerById mocks base method.
+func (m *MockRepository) UpdateMerchantBannerById(id string, version int64, key, thumbnailKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMerchantBannerById", id, version, key, thumbnailKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMerchantBannerById indicates an expected call of UpdateMerchantBannerById.
+func (mr *MockRepositoryMockRecorder) UpdateMerchantBannerById(id, version, key, thumbnailKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchantBannerById", reflect.TypeOf((*MockRepository)(nil).UpdateMerchantBannerById), id, version, key, thumbnailKey)
+}
+
+// UpdateMerchantById mocks base method.
+func (m_2 *MockRepository) UpdateMerchantById(id string, version int64, m *model.Merchant) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "UpdateMerchantById", id, version, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMerchantById indicates an expected call of UpdateMerchantById.
+func (mr *MockRepositoryMockRecorder) UpdateMerchantById(id, version, m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchantById", reflect.TypeOf((*MockRepository)(nil).UpdateMerchantById), id, version, m)
+}
+
+// UpdateMerchantLogoById mocks base method.
+func (m *MockRepository) UpdateMerchantLogoById(id string, version int64, key, thumbnailKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMerchantLogoById", id, version, key, thumbnailKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMerchantLogoById indicates an expected call of UpdateMerchantLogoById.
+func (mr *MockRepositoryMockRecorder) UpdateMerchantLogoById(id, version, key, thumbnailKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchantLogoById", reflect.TypeOf((*MockRepository)(nil).UpdateMerchantLogoById), id, version, key, thumbnailKey)
+}
+
+// UpdateMerchantParentById mocks base method.
+func (m *MockRepository) UpdateMerchantParentById(id string, version int64, parentId *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMerchantParentById", id, version, parentId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMerchantParentById indicates an expected call of UpdateMerchantParentById.
+func (mr *MockRepositoryMockRecorder) UpdateMerchantParentById(id, version, parentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchantParentById", reflect.TypeOf((*MockRepository)(nil).UpdateMerchantParentById), id, version, parentId)
+}
+
+// UpdateOrderStateById mocks base method.
+func (m *MockRepository) UpdateOrderStateById(id, from, to string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStateById", id, from, to, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStateById indicates an expected call of UpdateOrderStateById.
+func (mr *MockRepositoryMockRecorder) UpdateOrderStateById(id, from, to, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStateById", reflect.TypeOf((*MockRepository)(nil).UpdateOrderStateById), id, from, to, at)
+}
+
+// UpdatePaymentIntentById mocks base method.
+func (m *MockRepository) UpdatePaymentIntentById(id, from string, p *model.PaymentIntent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentIntentById", id, from, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePaymentIntentById indicates an expected call of UpdatePaymentIntentById.
+func (mr *MockRepositoryMockRecorder) UpdatePaymentIntentById(id, from, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentIntentById", reflect.TypeOf((*MockRepository)(nil).UpdatePaymentIntentById), id, from, p)
+}
+
+// UpdatePayoutAccountStatusById mocks base method.
+func (m *MockRepository) UpdatePayoutAccountStatusById(id, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePayoutAccountStatusById", id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePayoutAccountStatusById indicates an expected call of UpdatePayoutAccountStatusById.
+func (mr *MockRepositoryMockRecorder) UpdatePayoutAccountStatusById(id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayoutAccountStatusById", reflect.TypeOf((*MockRepository)(nil).UpdatePayoutAccountStatusById), id, status)
+}
+
+// UpdateProductById mocks base method.
+func (m *MockRepository) UpdateProductById(id string, p *model.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductById", id, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProductById indicates an expected call of UpdateProductById.
+func (mr *MockRepositoryMockRecorder) UpdateProductById(id, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductById", reflect.TypeOf((*MockRepository)(nil).UpdateProductById), id, p)
+}
+
+// UpdateProductVariantById mocks base method.
+func (m *MockRepository) UpdateProductVariantById(id string, v *model.ProductVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductVariantById", id, v)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProductVariantById indicates an expected call of UpdateProductVariantById.
+func (mr *MockRepositoryMockRecorder) UpdateProductVariantById(id, v interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductVariantById", reflect.TypeOf((*MockRepository)(nil).UpdateProductVariantById), id, v)
+}
+
+// UpdateRedeemedDraftOrder mocks base method.
+func (m *MockRepository) UpdateRedeemedDraftOrder(rd *model.CouponRedemption, o *model.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRedeemedDraftOrder", rd, o)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRedeemedDraftOrder indicates an expected call of UpdateRedeemedDraftOrder.
+func (mr *MockRepositoryMockRecorder) UpdateRedeemedDraftOrder(rd, o interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRedeemedDraftOrder", reflect.TypeOf((*MockRepository)(nil).UpdateRedeemedDraftOrder), rd, o)
+}
+
+// UpdateTeamMemberById mocks base method.
+func (m *MockRepository) UpdateTeamMemberById(id string, version int64, t *model.TeamMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTeamMemberById", id, version, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTeamMemberById indicates an expected call of UpdateTeamMemberById.
+func (mr *MockRepositoryMockRecorder) UpdateTeamMemberById(id, version, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTeamMemberById", reflect.TypeOf((*MockRepository)(nil).UpdateTeamMemberById), id, version, t)
+}
+
+// UpdateVerificationStatusById mocks base method.
+func (m *MockRepository) UpdateVerificationStatusById(id, from, to, notes, reviewedBy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVerificationStatusById", id, from, to, notes, reviewedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVerificationStatusById indicates an expected call of UpdateVerificationStatusById.
+func (mr *MockRepositoryMockRecorder) UpdateVerificationStatusById(id, from, to, notes, reviewedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVerificationStatusById", reflect.TypeOf((*MockRepository)(nil).UpdateVerificationStatusById), id, from, to, notes, reviewedBy)
+}
+
+// UpdateWebhookDeliveryById mocks base method.
+func (m *MockRepository) UpdateWebhookDeliveryById(id string, attempts int, d *model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDeliveryById", id, attempts, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDeliveryById indicates an expected call of UpdateWebhookDeliveryById.
+func (mr *MockRepositoryMockRecorder) UpdateWebhookDeliveryById(id, attempts, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDeliveryById", reflect.TypeOf((*MockRepository)(nil).UpdateWebhookDeliveryById), id, attempts, d)
+}
+
+// UpdateWebhookSubscriptionById mocks base method.
+func (m *MockRepository) UpdateWebhookSubscriptionById(id string, s *model.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookSubscriptionById", id, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookSubscriptionById indicates an expected call of UpdateWebhookSubscriptionById.
+func (mr *MockRepositoryMockRecorder) UpdateWebhookSubscriptionById(id, s interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTeamMemberById", reflect.TypeOf((*MockRepository)(nil).UpdateTeamMemberById), id, t)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookSubscriptionById", reflect.TypeOf((*MockRepository)(nil).UpdateWebhookSubscriptionById), id, s)
 }
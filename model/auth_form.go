@@ -1,12 +1,17 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+
+	"merchant/util/cryptoutil"
+)
 
 type RegistrationForm struct {
-	Email           string `json:"email" form:"required,email"`
-	Password        string `json:"password" form:"required"`
-	ConfirmPassword string `json:"confirmPassword" form:"required,eqfield=Password"`
-	BusinessName    string `json:"businessName" form:"required"`
+	Email           string   `json:"email" form:"required,email"`
+	Password        string   `json:"password" form:"required"`
+	ConfirmPassword string   `json:"confirmPassword" form:"required,eqfield=Password"`
+	BusinessName    string   `json:"businessName" form:"required"`
+	Metadata        Metadata `json:"metadata" form:"omitempty,metadata"`
 }
 
 type LoginForm struct {
@@ -26,9 +31,10 @@ func (f *RegistrationForm) ToMerchantModel() *Merchant {
 		Model: Model{
 			ID: id,
 		},
-		Email:        f.Email,
+		Email:        cryptoutil.EncryptedString(f.Email),
 		Password:     f.Password,
-		BusinessName: f.BusinessName,
+		BusinessName: cryptoutil.EncryptedString(f.BusinessName),
 		Status:       "Active",
+		Metadata:     f.Metadata,
 	}
 }
@@ -8,6 +8,10 @@ type Model struct {
 	ID        string `gorm:"primaryKey"`
 	CreatedAt *time.Time
 	UpdatedAt *time.Time
+	// Version counts the updates of the record. The repository bumps it on
+	// every update, so clients can tell whether a record changed since they
+	// read it.
+	Version int64 `gorm:"not null;default:0"`
 }
 
 type SrvError struct {
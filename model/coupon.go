@@ -0,0 +1,223 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	CouponTypePercentage = "percentage"
+	CouponTypeFixed      = "fixed"
+)
+
+var (
+	ErrCouponInactive         = errors.New("coupon is not active")
+	ErrCouponNotStarted       = errors.New("coupon is not valid yet")
+	ErrCouponExpired          = errors.New("coupon has expired")
+	ErrCouponCurrencyMismatch = errors.New("coupon is for another currency")
+	ErrCouponMinOrderValue    = errors.New("order subtotal is below the coupon minimum")
+	ErrCouponExhausted        = errors.New("coupon has been redeemed the maximum number of times")
+	ErrCouponCustomerLimit    = errors.New("customer has redeemed the coupon the maximum number of times")
+	ErrCouponCustomerRequired = errors.New("coupon is limited per customer and the order has no customer email")
+)
+
+// Coupon is a promotion of a merchant, redeemed by customers with its code.
+// Amounts are in the minor unit of the coupon currency, which fixed amount
+// coupons and coupons with a minimum order value are bound to. A zero limit
+// leaves the number of redemptions unlimited.
+type Coupon struct {
+	Model
+	MerchantID string `gorm:"uniqueIndex:idx_coupons_merchant_code"`
+	// Code is stored upper case; codes are matched case-insensitively.
+	Code string `gorm:"size:64;uniqueIndex:idx_coupons_merchant_code"`
+	Type string
+	// PercentOff is the discount of percentage coupons in basis points, so
+	// 1000 is 10%.
+	PercentOff                int64
+	AmountOff                 int64
+	Currency                  string
+	MinOrderValue             int64
+	StartsAt                  *time.Time
+	EndsAt                    *time.Time
+	MaxRedemptions            int64
+	MaxRedemptionsPerCustomer int64
+	// Redemptions counts the redemptions of the coupon. It only changes
+	// while the coupon is locked, along with the redemptions themselves.
+	Redemptions int64
+	Active      bool
+}
+
+type Coupons []*Coupon
+
+// CouponRedemption records a coupon applied to an order. An order redeems at
+// most one coupon.
+type CouponRedemption struct {
+	Model
+	CouponID   string `gorm:"index:idx_coupon_redemptions_customer"`
+	MerchantID string `gorm:"index"`
+	OrderID    string `gorm:"size:36;uniqueIndex"`
+	// CustomerEmail is the lower case email of the customer of the order,
+	// which identifies customers for the per customer limit.
+	CustomerEmail string `gorm:"index:idx_coupon_redemptions_customer"`
+	Amount        int64
+}
+
+// NormalizeCouponCode returns the form codes are stored and looked up in.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CheckValidAt returns why the coupon can not be redeemed at the given time,
+// or nil when it can.
+func (c Coupon) CheckValidAt(at time.Time) error {
+	switch {
+	case !c.Active:
+		return ErrCouponInactive
+	case c.StartsAt != nil && at.Before(*c.StartsAt):
+		return ErrCouponNotStarted
+	case c.EndsAt != nil && !at.Before(*c.EndsAt):
+		return ErrCouponExpired
+	}
+
+	return nil
+}
+
+// CheckLimits returns why the coupon can not be redeemed once more by a
+// customer who redeemed it the given number of times before, or nil when it
+// can.
+func (c Coupon) CheckLimits(customerRedemptions int64) error {
+	if c.MaxRedemptions > 0 && c.Redemptions >= c.MaxRedemptions {
+		return ErrCouponExhausted
+	}
+
+	return c.CheckCustomerLimit(customerRedemptions)
+}
+
+// CheckCustomerLimit returns why the coupon can not be redeemed once more by
+// a customer who redeemed it the given number of times before, or nil when
+// it can.
+func (c Coupon) CheckCustomerLimit(customerRedemptions int64) error {
+	if c.MaxRedemptionsPerCustomer > 0 && customerRedemptions >= c.MaxRedemptionsPerCustomer {
+		return ErrCouponCustomerLimit
+	}
+
+	return nil
+}
+
+// CheckCustomer returns ErrCouponCustomerRequired when the coupon is limited
+// per customer and the customer is unknown, since the limit could not be
+// told apart from one customer to the next.
+func (c Coupon) CheckCustomer(customerEmail string) error {
+	if c.MaxRedemptionsPerCustomer > 0 && customerEmail == "" {
+		return ErrCouponCustomerRequired
+	}
+
+	return nil
+}
+
+// Discount returns the discount of the coupon on an order subtotal.
+// Percentages are rounded down and neither kind exceeds the subtotal.
+func (c Coupon) Discount(subtotal int64) int64 {
+	discount := c.AmountOff
+	if c.Type == CouponTypePercentage {
+		discount = subtotal * c.PercentOff / 10000
+	}
+
+	switch {
+	case discount < 0:
+		return 0
+	case discount > subtotal:
+		return subtotal
+	}
+
+	return discount
+}
+
+// Apply prices the order with the discount of the coupon, which replaces any
+// other discount of the order.
+func (c Coupon) Apply(o *Order) error {
+	if c.Currency != "" && c.Currency != o.Currency {
+		return ErrCouponCurrencyMismatch
+	}
+
+	var subtotal int64
+	for _, item := range o.Items {
+		if item.Quantity > 0 && item.UnitPrice > (MaxOrderSubtotal-subtotal)/item.Quantity {
+			return ErrOrderTooLarge
+		}
+		subtotal += item.UnitPrice * item.Quantity
+	}
+	if subtotal < c.MinOrderValue {
+		return ErrCouponMinOrderValue
+	}
+
+	o.CouponID = c.ID
+	o.CouponCode = c.Code
+	o.DiscountTotal = c.Discount(subtotal)
+
+	return o.CalculateTotals()
+}
+
+// NewRedemption records the coupon as applied to the order.
+func (c Coupon) NewRedemption(id string, o *Order) *CouponRedemption {
+	return &CouponRedemption{
+		Model: Model{
+			ID: id,
+		},
+		CouponID:      c.ID,
+		MerchantID:    c.MerchantID,
+		OrderID:       o.ID,
+		CustomerEmail: strings.ToLower(o.CustomerEmail),
+		Amount:        o.DiscountTotal,
+	}
+}
+
+type CouponDto struct {
+	ID                        string     `json:"id"`
+	MerchantID                string     `json:"merchantID"`
+	Code                      string     `json:"code"`
+	Type                      string     `json:"type"`
+	PercentOff                int64      `json:"percentOff,omitempty"`
+	AmountOff                 int64      `json:"amountOff,omitempty"`
+	Currency                  string     `json:"currency,omitempty"`
+	MinOrderValue             int64      `json:"minOrderValue"`
+	StartsAt                  *time.Time `json:"startsAt,omitempty"`
+	EndsAt                    *time.Time `json:"endsAt,omitempty"`
+	MaxRedemptions            int64      `json:"maxRedemptions"`
+	MaxRedemptionsPerCustomer int64      `json:"maxRedemptionsPerCustomer"`
+	Redemptions               int64      `json:"redemptions"`
+	Active                    bool       `json:"active"`
+	CreatedAt                 *time.Time `json:"createdAt"`
+}
+
+func (c Coupon) ToDto() *CouponDto {
+	return &CouponDto{
+		ID:                        c.ID,
+		MerchantID:                c.MerchantID,
+		Code:                      c.Code,
+		Type:                      c.Type,
+		PercentOff:                c.PercentOff,
+		AmountOff:                 c.AmountOff,
+		Currency:                  c.Currency,
+		MinOrderValue:             c.MinOrderValue,
+		StartsAt:                  c.StartsAt,
+		EndsAt:                    c.EndsAt,
+		MaxRedemptions:            c.MaxRedemptions,
+		MaxRedemptionsPerCustomer: c.MaxRedemptionsPerCustomer,
+		Redemptions:               c.Redemptions,
+		Active:                    c.Active,
+		CreatedAt:                 c.CreatedAt,
+	}
+}
+
+type CouponDtos []*CouponDto
+
+func (cs Coupons) ToDto() CouponDtos {
+	result := make([]*CouponDto, len(cs))
+	for k, v := range cs {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
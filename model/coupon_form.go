@@ -0,0 +1,102 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CouponForm struct {
+	Code string `json:"code" form:"required,min=3,max=64,couponcode"`
+	Type string `json:"type" form:"required,oneof=percentage fixed"`
+	// PercentOff is in basis points, so 1000 is 10%.
+	PercentOff                int64      `json:"percentOff" form:"required_if=Type percentage,min=0,max=10000"`
+	AmountOff                 int64      `json:"amountOff" form:"required_if=Type fixed,min=0"`
+	Currency                  string     `json:"currency" form:"required_if=Type fixed,required_with=MinOrderValue,omitempty,iso4217"`
+	MinOrderValue             int64      `json:"minOrderValue" form:"min=0"`
+	StartsAt                  *time.Time `json:"startsAt"`
+	EndsAt                    *time.Time `json:"endsAt"`
+	MaxRedemptions            int64      `json:"maxRedemptions" form:"min=0"`
+	MaxRedemptionsPerCustomer int64      `json:"maxRedemptionsPerCustomer" form:"min=0"`
+	// Active defaults to true when left out.
+	Active *bool `json:"active"`
+}
+
+// CouponUpdateForm changes when and how often a coupon may be redeemed. The
+// code and the discount are fixed once customers may have seen them.
+type CouponUpdateForm struct {
+	StartsAt                  *time.Time `json:"startsAt"`
+	EndsAt                    *time.Time `json:"endsAt"`
+	MaxRedemptions            int64      `json:"maxRedemptions" form:"min=0"`
+	MaxRedemptionsPerCustomer int64      `json:"maxRedemptionsPerCustomer" form:"min=0"`
+	Active                    bool       `json:"active"`
+}
+
+type CouponRedemptionForm struct {
+	Code string `json:"code" form:"required,max=64"`
+}
+
+// Normalize upper-cases the code and the currency ahead of validation.
+func (f *CouponForm) Normalize() {
+	f.Code = NormalizeCouponCode(f.Code)
+	f.Currency = strings.ToUpper(f.Currency)
+}
+
+// HasValidWindow reports whether the coupon ends after it starts.
+func (f *CouponForm) HasValidWindow() bool {
+	return validWindow(f.StartsAt, f.EndsAt)
+}
+
+// HasValidWindow reports whether the coupon ends after it starts.
+func (f *CouponUpdateForm) HasValidWindow() bool {
+	return validWindow(f.StartsAt, f.EndsAt)
+}
+
+func validWindow(startsAt, endsAt *time.Time) bool {
+	return startsAt == nil || endsAt == nil || endsAt.After(*startsAt)
+}
+
+func (f *CouponForm) ToModel(merchantId string) *Coupon {
+	active := true
+	if f.Active != nil {
+		active = *f.Active
+	}
+
+	c := &Coupon{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID:                merchantId,
+		Code:                      NormalizeCouponCode(f.Code),
+		Type:                      f.Type,
+		Currency:                  f.Currency,
+		MinOrderValue:             f.MinOrderValue,
+		StartsAt:                  f.StartsAt,
+		EndsAt:                    f.EndsAt,
+		MaxRedemptions:            f.MaxRedemptions,
+		MaxRedemptionsPerCustomer: f.MaxRedemptionsPerCustomer,
+		Active:                    active,
+	}
+
+	if f.Type == CouponTypePercentage {
+		c.PercentOff = f.PercentOff
+	} else {
+		c.AmountOff = f.AmountOff
+	}
+
+	return c
+}
+
+func (f *CouponUpdateForm) ToModel(id string) *Coupon {
+	return &Coupon{
+		Model: Model{
+			ID: id,
+		},
+		StartsAt:                  f.StartsAt,
+		EndsAt:                    f.EndsAt,
+		MaxRedemptions:            f.MaxRedemptions,
+		MaxRedemptionsPerCustomer: f.MaxRedemptionsPerCustomer,
+		Active:                    f.Active,
+	}
+}
@@ -0,0 +1,173 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/util/validator"
+)
+
+func couponOrder(lines ...int64) *model.Order {
+	o := &model.Order{Model: model.Model{ID: "o1"}, Currency: "EUR", CustomerEmail: "Ada@Example.com"}
+	for _, price := range lines {
+		o.Items = append(o.Items, &model.OrderItem{UnitPrice: price, Quantity: 1, TaxCategory: model.TaxCategoryStandard})
+	}
+
+	return o
+}
+
+func TestCouponApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		coupon   model.Coupon
+		lines    []int64
+		discount int64
+		err      error
+	}{
+		{
+			name:     "percentage rounds down",
+			coupon:   model.Coupon{Type: model.CouponTypePercentage, PercentOff: 1250},
+			lines:    []int64{999, 1},
+			discount: 125,
+		},
+		{
+			name:     "fixed amount",
+			coupon:   model.Coupon{Type: model.CouponTypeFixed, AmountOff: 500, Currency: "EUR"},
+			lines:    []int64{2000},
+			discount: 500,
+		},
+		{
+			name:     "fixed amount capped at subtotal",
+			coupon:   model.Coupon{Type: model.CouponTypeFixed, AmountOff: 5000, Currency: "EUR"},
+			lines:    []int64{1500, 500},
+			discount: 2000,
+		},
+		{
+			name:     "negative percentage is no surcharge",
+			coupon:   model.Coupon{Type: model.CouponTypePercentage, PercentOff: -1000},
+			lines:    []int64{2000},
+			discount: 0,
+		},
+		{
+			name:   "below minimum order value",
+			coupon: model.Coupon{Type: model.CouponTypeFixed, AmountOff: 500, Currency: "EUR", MinOrderValue: 3000},
+			lines:  []int64{2999},
+			err:    model.ErrCouponMinOrderValue,
+		},
+		{
+			name:   "other currency",
+			coupon: model.Coupon{Type: model.CouponTypeFixed, AmountOff: 500, Currency: "USD"},
+			lines:  []int64{2000},
+			err:    model.ErrCouponCurrencyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o := couponOrder(tt.lines...)
+			o.DiscountTotal = 100
+
+			err := tt.coupon.Apply(o)
+			require.Equal(t, tt.err, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.discount, o.DiscountTotal)
+
+			var discounts int64
+			for _, item := range o.Items {
+				discounts += item.Discount
+			}
+			assert.Equal(t, tt.discount, discounts)
+		})
+	}
+}
+
+func TestCouponCheckValidAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, 6, 15, 12, 0, 0, 0, time.UTC)
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+
+	assert.NoError(t, model.Coupon{Active: true}.CheckValidAt(now))
+	assert.NoError(t, model.Coupon{Active: true, StartsAt: &before, EndsAt: &after}.CheckValidAt(now))
+	assert.Equal(t, model.ErrCouponInactive, model.Coupon{}.CheckValidAt(now))
+	assert.Equal(t, model.ErrCouponNotStarted, model.Coupon{Active: true, StartsAt: &after}.CheckValidAt(now))
+	assert.Equal(t, model.ErrCouponExpired, model.Coupon{Active: true, EndsAt: &now}.CheckValidAt(now))
+}
+
+func TestCouponCheckLimits(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, model.Coupon{Redemptions: 1000}.CheckLimits(50))
+	assert.NoError(t, model.Coupon{MaxRedemptions: 10, Redemptions: 9, MaxRedemptionsPerCustomer: 2}.CheckLimits(1))
+	assert.Equal(t, model.ErrCouponExhausted, model.Coupon{MaxRedemptions: 10, Redemptions: 10}.CheckLimits(0))
+	assert.Equal(t, model.ErrCouponCustomerLimit, model.Coupon{MaxRedemptionsPerCustomer: 1}.CheckLimits(1))
+	assert.Equal(t, model.ErrCouponCustomerLimit, model.Coupon{MaxRedemptions: 1, Redemptions: 1, MaxRedemptionsPerCustomer: 1}.CheckCustomerLimit(1))
+	assert.NoError(t, model.Coupon{MaxRedemptions: 1, Redemptions: 1, MaxRedemptionsPerCustomer: 1}.CheckCustomerLimit(0))
+}
+
+func TestCouponCheckCustomer(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, model.Coupon{}.CheckCustomer(""))
+	assert.NoError(t, model.Coupon{MaxRedemptionsPerCustomer: 1}.CheckCustomer("ada@example.com"))
+	assert.Equal(t, model.ErrCouponCustomerRequired, model.Coupon{MaxRedemptionsPerCustomer: 1}.CheckCustomer(""))
+}
+
+func TestCouponNewRedemption(t *testing.T) {
+	t.Parallel()
+
+	coupon := model.Coupon{Model: model.Model{ID: "c1"}, MerchantID: "m1", Type: model.CouponTypeFixed, AmountOff: 300}
+	o := couponOrder(1000)
+	require.NoError(t, coupon.Apply(o))
+
+	rd := coupon.NewRedemption("r1", o)
+	assert.Equal(t, "c1", rd.CouponID)
+	assert.Equal(t, "o1", rd.OrderID)
+	assert.Equal(t, "ada@example.com", rd.CustomerEmail)
+	assert.Equal(t, int64(300), rd.Amount)
+	assert.Equal(t, "c1", o.CouponID)
+
+	require.NoError(t, o.RemoveCoupon())
+	assert.Equal(t, "", o.CouponID)
+	assert.Equal(t, int64(0), o.DiscountTotal)
+}
+
+func TestCouponFormValidation(t *testing.T) {
+	t.Parallel()
+
+	vr := validator.New()
+
+	valid := []*model.CouponForm{
+		{Code: "SUMMER-10", Type: model.CouponTypePercentage, PercentOff: 1000},
+		{Code: "WELCOME5", Type: model.CouponTypeFixed, AmountOff: 500, Currency: "EUR"},
+	}
+	invalid := []*model.CouponForm{
+		{Code: "SUMMER 10", Type: model.CouponTypePercentage, PercentOff: 1000},
+		{Code: "SUMMER10", Type: model.CouponTypePercentage},
+		{Code: "SUMMER10", Type: model.CouponTypePercentage, PercentOff: 10001},
+		{Code: "SUMMER10", Type: model.CouponTypePercentage, PercentOff: -1000},
+		{Code: "WELCOME5", Type: model.CouponTypeFixed, AmountOff: 500},
+		{Code: "BIGSPEND", Type: model.CouponTypePercentage, PercentOff: 1000, MinOrderValue: 5000},
+	}
+
+	for _, f := range valid {
+		f.Normalize()
+		assert.NoError(t, vr.Struct(f), f.Code)
+	}
+	for _, f := range invalid {
+		f.Normalize()
+		assert.Error(t, vr.Struct(f), f.Code)
+	}
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+const (
+	DataExportStatusPending   = "pending"
+	DataExportStatusRunning   = "running"
+	DataExportStatusSucceeded = "succeeded"
+	DataExportStatusFailed    = "failed"
+)
+
+// DataExport is a copy of all the data of a merchant, assembled in the
+// background into a ZIP archive of JSON files kept in blob storage.
+type DataExport struct {
+	Model
+	MerchantID string `gorm:"index"`
+	Status     string `gorm:"index"`
+	BlobKey    string
+	Size       int64
+	Error      string
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	// ExpiresAt is when the export is purged along with its archive.
+	ExpiresAt *time.Time `gorm:"index"`
+}
+
+type DataExports []*DataExport
+
+// Finish records the outcome of the export, which is kept until the
+// retention period has passed.
+func (e *DataExport) Finish(at time.Time, retention time.Duration, err error) {
+	expiresAt := at.Add(retention)
+	e.FinishedAt = &at
+	e.ExpiresAt = &expiresAt
+	e.Status = DataExportStatusSucceeded
+	if err != nil {
+		e.Status = DataExportStatusFailed
+		e.Error = err.Error()
+	}
+}
+
+// Downloadable reports whether the archive of the export may be downloaded
+// at the given time.
+func (e DataExport) Downloadable(now time.Time) bool {
+	return e.Status == DataExportStatusSucceeded && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
+
+type DataExportDto struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Size       int64      `json:"size,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	// DownloadURL is a link to the archive, which anyone holding it may
+	// follow until DownloadExpiresAt.
+	DownloadURL       string     `json:"downloadUrl,omitempty"`
+	DownloadExpiresAt *time.Time `json:"downloadExpiresAt,omitempty"`
+}
+
+func (e DataExport) ToDto() *DataExportDto {
+	return &DataExportDto{
+		ID:         e.ID,
+		Status:     e.Status,
+		Size:       e.Size,
+		Error:      e.Error,
+		CreatedAt:  e.CreatedAt,
+		StartedAt:  e.StartedAt,
+		FinishedAt: e.FinishedAt,
+		ExpiresAt:  e.ExpiresAt,
+	}
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+const (
+	DataKeyPurposeEncryption = "encryption"
+	DataKeyPurposeIndex      = "index"
+)
+
+// DataKeyIndexID is the id of the one key of blind indexes, which makes
+// concurrent attempts at creating it collide.
+const DataKeyIndexID = "index"
+
+// DataKey is a key of field encryption, stored wrapped by the master key.
+// Encryption keys are rotated by adding a newer one; the older ones are kept
+// to decrypt the values not encrypted again yet.
+type DataKey struct {
+	ID      string `gorm:"primaryKey;size:36"`
+	Purpose string `gorm:"size:16"`
+	// WrappedKey is the base64 encoded key encrypted with the master key.
+	WrappedKey string `gorm:"type:text"`
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+}
+
+type DataKeys []*DataKey
+
+type DataKeyDto struct {
+	ID        string     `json:"id"`
+	Purpose   string     `json:"purpose"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+func (k DataKey) ToDto() *DataKeyDto {
+	return &DataKeyDto{
+		ID:        k.ID,
+		Purpose:   k.Purpose,
+		CreatedAt: k.CreatedAt,
+	}
+}
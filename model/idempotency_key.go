@@ -0,0 +1,70 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"merchant/util/cryptoutil"
+)
+
+// IdempotencyKeyTTL is how long a key, and the response to the request which
+// used it first, is kept for retries.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyReplayedHeaders are the response headers stored along with the
+// body of a response, to be replayed with it.
+var IdempotencyReplayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// IdempotencyKey records a request sent with an Idempotency-Key header, so a
+// retry of the request is answered with the response of the first one.
+type IdempotencyKey struct {
+	Model
+	// Scope is the merchant who sent the request. Requests sent before
+	// signing in are scoped to their client instead.
+	Scope string `gorm:"size:64;uniqueIndex:idx_idempotency_keys_scope_key"`
+	Key   string `gorm:"size:255;uniqueIndex:idx_idempotency_keys_scope_key"`
+	// Fingerprint identifies the method, path and body of the request.
+	Fingerprint string `gorm:"size:64"`
+	// StatusCode is zero while the request is in flight.
+	StatusCode int
+	// ResponseHeaders is the JSON encoded object of the replayed headers of
+	// the response.
+	ResponseHeaders string `gorm:"type:text"`
+	// ResponseBody holds personal data, such as the merchant or team member
+	// created by the request, so it is stored encrypted.
+	ResponseBody cryptoutil.EncryptedString `gorm:"type:mediumtext"`
+	ExpiresAt    time.Time                  `gorm:"index"`
+}
+
+// IdempotencyFingerprint returns the fingerprint of a request.
+func IdempotencyFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AnonymousIdempotencyScope returns the scope of a request sent before
+// signing in, so that clients picking the same key never share it. A key a
+// client reuses for another request shares the scope, and is rejected.
+func AnonymousIdempotencyScope(client string) string {
+	h := sha256.Sum256([]byte(client))
+
+	return "anonymous:" + hex.EncodeToString(h[:])[:48]
+}
+
+// InFlight reports whether the request which used the key has not finished.
+func (k IdempotencyKey) InFlight() bool {
+	return k.StatusCode == 0
+}
+
+// ReusableAt reports whether the key may be taken by a new request at the
+// given time, because it expired. A key in flight is never taken over before
+// then, even when its request looks abandoned, since the request may still
+// be running; a request cut off by a restart leaves its key in flight until
+// it expires.
+func (k IdempotencyKey) ReusableAt(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
@@ -0,0 +1,58 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/model"
+)
+
+func TestIdempotencyKeyReusableAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, 6, 15, 12, 0, 0, 0, time.UTC)
+	justNow, aMinuteAgo, anHourAgo := now.Add(-time.Second), now.Add(-time.Minute), now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		key      model.IdempotencyKey
+		reusable bool
+	}{
+		{
+			name:     "completed",
+			key:      model.IdempotencyKey{Model: model.Model{CreatedAt: &anHourAgo}, StatusCode: 201, ExpiresAt: now.Add(time.Hour)},
+			reusable: false,
+		},
+		{
+			name:     "in flight",
+			key:      model.IdempotencyKey{Model: model.Model{CreatedAt: &justNow}, ExpiresAt: now.Add(time.Hour)},
+			reusable: false,
+		},
+		{
+			name:     "slow",
+			key:      model.IdempotencyKey{Model: model.Model{CreatedAt: &aMinuteAgo}, ExpiresAt: now.Add(time.Hour)},
+			reusable: false,
+		},
+		{
+			name:     "in flight for long",
+			key:      model.IdempotencyKey{Model: model.Model{CreatedAt: &anHourAgo}, ExpiresAt: now.Add(time.Hour)},
+			reusable: false,
+		},
+		{
+			name:     "expired",
+			key:      model.IdempotencyKey{Model: model.Model{CreatedAt: &anHourAgo}, StatusCode: 201, ExpiresAt: now},
+			reusable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.reusable, tt.key.ReusableAt(now))
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// StockMovementAdjustment changes the quantity on hand, e.g. for a
+	// delivery, a stock count or breakage.
+	StockMovementAdjustment = "adjustment"
+	// StockMovementReservation sets available stock aside for an order.
+	StockMovementReservation = "reservation"
+	// StockMovementRelease returns reserved stock, e.g. for a cancelled order.
+	StockMovementRelease = "release"
+	// StockMovementFulfilment removes reserved stock that has been shipped.
+	StockMovementFulfilment = "fulfilment"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidQuantity   = errors.New("invalid quantity")
+)
+
+// InventoryLevel is the stock of a product variant at a location. Reserved
+// stock is part of the stock on hand but can not be sold again.
+type InventoryLevel struct {
+	Model
+	MerchantID        string `gorm:"index"`
+	VariantID         string `gorm:"uniqueIndex:idx_inventory_levels_variant_location;size:64"`
+	LocationID        string `gorm:"uniqueIndex:idx_inventory_levels_variant_location;size:64"`
+	OnHand            int64
+	Reserved          int64
+	LowStockThreshold int64
+}
+
+type InventoryLevels []*InventoryLevel
+
+// StockMovement is an entry in the stock ledger of a variant at a location,
+// recording the levels after the movement was applied.
+type StockMovement struct {
+	Model
+	MerchantID string `gorm:"index"`
+	VariantID  string `gorm:"index:idx_stock_movements_variant_location"`
+	LocationID string `gorm:"index:idx_stock_movements_variant_location"`
+	Kind       string
+	Quantity   int64
+	OnHand     int64
+	Reserved   int64
+	Reference  string
+	Reason     string
+}
+
+type StockMovements []*StockMovement
+
+// StockAlert records that the available stock of a variant at a location
+// fell to or below its low stock threshold.
+type StockAlert struct {
+	Model
+	MerchantID string `gorm:"index"`
+	VariantID  string
+	LocationID string
+	Available  int64
+	Threshold  int64
+}
+
+type StockAlerts []*StockAlert
+
+func NewInventoryLevel(merchantId, variantId, locationId string) *InventoryLevel {
+	return &InventoryLevel{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID: merchantId,
+		VariantID:  variantId,
+		LocationID: locationId,
+	}
+}
+
+func (l InventoryLevel) Available() int64 {
+	return l.OnHand - l.Reserved
+}
+
+// IsLowStock reports whether the available stock is at or below the
+// threshold. A threshold of zero disables the check.
+func (l InventoryLevel) IsLowStock() bool {
+	return l.LowStockThreshold > 0 && l.Available() <= l.LowStockThreshold
+}
+
+// Apply changes the levels by the movement, leaving them untouched when the
+// movement would take the stock on hand or the reserved stock below zero or
+// reserve more than is available.
+func (l *InventoryLevel) Apply(m *StockMovement) error {
+	q := m.Quantity
+
+	switch m.Kind {
+	case StockMovementAdjustment:
+		if q == 0 {
+			return ErrInvalidQuantity
+		}
+		if l.OnHand+q < l.Reserved {
+			return ErrInsufficientStock
+		}
+		l.OnHand += q
+	case StockMovementReservation:
+		if q <= 0 {
+			return ErrInvalidQuantity
+		}
+		if l.Available() < q {
+			return ErrInsufficientStock
+		}
+		l.Reserved += q
+	case StockMovementRelease:
+		if q <= 0 {
+			return ErrInvalidQuantity
+		}
+		if l.Reserved < q {
+			return ErrInsufficientStock
+		}
+		l.Reserved -= q
+	case StockMovementFulfilment:
+		if q <= 0 {
+			return ErrInvalidQuantity
+		}
+		if l.Reserved < q {
+			return ErrInsufficientStock
+		}
+		l.Reserved -= q
+		l.OnHand -= q
+	default:
+		return ErrInvalidQuantity
+	}
+
+	m.OnHand = l.OnHand
+	m.Reserved = l.Reserved
+	return nil
+}
+
+// NewStockAlert returns an alert for the current available stock of the level.
+func (l InventoryLevel) NewStockAlert() *StockAlert {
+	return &StockAlert{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID: l.MerchantID,
+		VariantID:  l.VariantID,
+		LocationID: l.LocationID,
+		Available:  l.Available(),
+		Threshold:  l.LowStockThreshold,
+	}
+}
+
+type InventoryLevelDto struct {
+	VariantID         string `json:"variantID"`
+	LocationID        string `json:"locationID"`
+	OnHand            int64  `json:"onHand"`
+	Reserved          int64  `json:"reserved"`
+	Available         int64  `json:"available"`
+	LowStockThreshold int64  `json:"lowStockThreshold"`
+	LowStock          bool   `json:"lowStock"`
+}
+
+func (l InventoryLevel) ToDto() *InventoryLevelDto {
+	return &InventoryLevelDto{
+		VariantID:         l.VariantID,
+		LocationID:        l.LocationID,
+		OnHand:            l.OnHand,
+		Reserved:          l.Reserved,
+		Available:         l.Available(),
+		LowStockThreshold: l.LowStockThreshold,
+		LowStock:          l.IsLowStock(),
+	}
+}
+
+type InventoryLevelDtos []*InventoryLevelDto
+
+func (ls InventoryLevels) ToDto() InventoryLevelDtos {
+	result := make([]*InventoryLevelDto, len(ls))
+	for k, v := range ls {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
+
+type StockMovementDto struct {
+	ID         string     `json:"id"`
+	VariantID  string     `json:"variantID"`
+	LocationID string     `json:"locationID"`
+	Kind       string     `json:"kind"`
+	Quantity   int64      `json:"quantity"`
+	OnHand     int64      `json:"onHand"`
+	Reserved   int64      `json:"reserved"`
+	Reference  string     `json:"reference,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt"`
+}
+
+func (m StockMovement) ToDto() *StockMovementDto {
+	return &StockMovementDto{
+		ID:         m.ID,
+		VariantID:  m.VariantID,
+		LocationID: m.LocationID,
+		Kind:       m.Kind,
+		Quantity:   m.Quantity,
+		OnHand:     m.OnHand,
+		Reserved:   m.Reserved,
+		Reference:  m.Reference,
+		Reason:     m.Reason,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+type StockMovementDtos []*StockMovementDto
+
+func (ms StockMovements) ToDto() StockMovementDtos {
+	result := make([]*StockMovementDto, len(ms))
+	for k, v := range ms {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
+
+type StockAlertDto struct {
+	ID         string     `json:"id"`
+	VariantID  string     `json:"variantID"`
+	LocationID string     `json:"locationID"`
+	Available  int64      `json:"available"`
+	Threshold  int64      `json:"threshold"`
+	CreatedAt  *time.Time `json:"createdAt"`
+}
+
+func (a StockAlert) ToDto() *StockAlertDto {
+	return &StockAlertDto{
+		ID:         a.ID,
+		VariantID:  a.VariantID,
+		LocationID: a.LocationID,
+		Available:  a.Available,
+		Threshold:  a.Threshold,
+		CreatedAt:  a.CreatedAt,
+	}
+}
+
+type StockAlertDtos []*StockAlertDto
+
+func (as StockAlerts) ToDto() StockAlertDtos {
+	result := make([]*StockAlertDto, len(as))
+	for k, v := range as {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
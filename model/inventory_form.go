@@ -0,0 +1,55 @@
+package model
+
+import "github.com/google/uuid"
+
+// StockAdjustmentForm changes the stock on hand; a negative quantity removes
+// stock.
+type StockAdjustmentForm struct {
+	VariantID  string `json:"variantID" form:"required,uuid4_rfc4122"`
+	LocationID string `json:"locationID" form:"required,uuid4_rfc4122"`
+	Quantity   int64  `json:"quantity" form:"required"`
+	Reason     string `json:"reason" form:"required,max=255"`
+}
+
+// StockReservationForm reserves or releases stock for a reference such as an
+// order number.
+type StockReservationForm struct {
+	VariantID  string `json:"variantID" form:"required,uuid4_rfc4122"`
+	LocationID string `json:"locationID" form:"required,uuid4_rfc4122"`
+	Quantity   int64  `json:"quantity" form:"required,min=1"`
+	Reference  string `json:"reference" form:"required,max=255"`
+}
+
+type StockThresholdForm struct {
+	VariantID  string `json:"variantID" form:"required,uuid4_rfc4122"`
+	LocationID string `json:"locationID" form:"required,uuid4_rfc4122"`
+	Threshold  int64  `json:"threshold" form:"min=0"`
+}
+
+func (f *StockAdjustmentForm) ToModel(merchantId string) *StockMovement {
+	return &StockMovement{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID: merchantId,
+		VariantID:  f.VariantID,
+		LocationID: f.LocationID,
+		Kind:       StockMovementAdjustment,
+		Quantity:   f.Quantity,
+		Reason:     f.Reason,
+	}
+}
+
+func (f *StockReservationForm) ToModel(merchantId, kind string) *StockMovement {
+	return &StockMovement{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID: merchantId,
+		VariantID:  f.VariantID,
+		LocationID: f.LocationID,
+		Kind:       kind,
+		Quantity:   f.Quantity,
+		Reference:  f.Reference,
+	}
+}
@@ -0,0 +1,66 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/model"
+)
+
+func TestInventoryLevelApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		kind     string
+		quantity int64
+		onHand   int64
+		reserved int64
+		err      error
+	}{
+		{"receive stock", model.StockMovementAdjustment, 5, 15, 3, nil},
+		{"write off stock", model.StockMovementAdjustment, -7, 3, 3, nil},
+		{"write off reserved stock", model.StockMovementAdjustment, -8, 10, 3, model.ErrInsufficientStock},
+		{"reserve available stock", model.StockMovementReservation, 7, 10, 10, nil},
+		{"reserve more than available", model.StockMovementReservation, 8, 10, 3, model.ErrInsufficientStock},
+		{"release reserved stock", model.StockMovementRelease, 3, 10, 0, nil},
+		{"release more than reserved", model.StockMovementRelease, 4, 10, 3, model.ErrInsufficientStock},
+		{"fulfil reserved stock", model.StockMovementFulfilment, 2, 8, 1, nil},
+		{"fulfil more than reserved", model.StockMovementFulfilment, 4, 10, 3, model.ErrInsufficientStock},
+		{"reserve nothing", model.StockMovementReservation, 0, 10, 3, model.ErrInvalidQuantity},
+		{"reserve negative stock", model.StockMovementReservation, -1, 10, 3, model.ErrInvalidQuantity},
+		{"unknown kind", "transfer", 1, 10, 3, model.ErrInvalidQuantity},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			level := &model.InventoryLevel{OnHand: 10, Reserved: 3}
+			movement := &model.StockMovement{Kind: tt.kind, Quantity: tt.quantity}
+
+			assert.Equal(t, tt.err, level.Apply(movement))
+			assert.Equal(t, tt.onHand, level.OnHand)
+			assert.Equal(t, tt.reserved, level.Reserved)
+			if tt.err == nil {
+				assert.Equal(t, tt.onHand, movement.OnHand)
+				assert.Equal(t, tt.reserved, movement.Reserved)
+			}
+		})
+	}
+}
+
+func TestInventoryLevelIsLowStock(t *testing.T) {
+	t.Parallel()
+
+	level := &model.InventoryLevel{OnHand: 10, Reserved: 5}
+	assert.False(t, level.IsLowStock())
+
+	level.LowStockThreshold = 5
+	assert.True(t, level.IsLowStock())
+
+	level.LowStockThreshold = 4
+	assert.False(t, level.IsLowStock())
+}
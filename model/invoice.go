@@ -0,0 +1,190 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"merchant/blob"
+)
+
+// Invoice is the billing document of an order. Invoices of a merchant are
+// numbered 1, 2, 3 and so on without gaps in the order they are issued. An
+// invoice issued for an order which is already paid is a receipt.
+type Invoice struct {
+	Model
+	MerchantID    string `gorm:"uniqueIndex:idx_invoices_merchant_number;size:64"`
+	Number        int64  `gorm:"uniqueIndex:idx_invoices_merchant_number"`
+	OrderID       string `gorm:"uniqueIndex;size:64"`
+	Receipt       bool
+	CustomerName  string
+	CustomerEmail string
+	Currency      string
+	Subtotal      int64
+	DiscountTotal int64
+	TaxTotal      int64
+	Total         int64
+	IssuedAt      time.Time
+	Snapshot      *InvoiceSnapshot `gorm:"type:mediumtext"`
+	PdfKey        string
+	HtmlKey       string
+}
+
+// InvoiceSnapshot holds what the documents of an invoice show beyond its
+// amounts, as it was when the invoice was issued, so that they render the
+// same however the merchant and the order change afterwards. It is stored as
+// a JSON object.
+type InvoiceSnapshot struct {
+	BusinessName string         `json:"businessName"`
+	Logo         []byte         `json:"logo,omitempty"`
+	Note         string         `json:"note,omitempty"`
+	Lines        []*InvoiceLine `json:"lines"`
+}
+
+// InvoiceLine is an order line as billed.
+type InvoiceLine struct {
+	Description string `json:"description"`
+	SKU         string `json:"sku,omitempty"`
+	Quantity    int64  `json:"quantity"`
+	UnitPrice   int64  `json:"unitPrice"`
+	Discount    int64  `json:"discount"`
+	Tax         int64  `json:"tax"`
+	Total       int64  `json:"total"`
+}
+
+type Invoices []*Invoice
+
+// InvoiceSequence holds the last invoice number issued by a merchant.
+type InvoiceSequence struct {
+	MerchantID string `gorm:"primaryKey;size:64"`
+	LastNumber int64
+}
+
+// NewInvoice returns the invoice of the order, issued by the merchant with
+// the given logo. It is numbered when it is stored.
+func NewInvoice(id string, order *Order, merchant *Merchant, logo []byte, issuedAt time.Time) *Invoice {
+	snapshot := &InvoiceSnapshot{
+		BusinessName: merchant.BusinessName.String(),
+		Logo:         logo,
+		Note:         order.Note,
+		Lines:        make([]*InvoiceLine, len(order.Items)),
+	}
+	for k, item := range order.Items {
+		snapshot.Lines[k] = &InvoiceLine{
+			Description: item.Name,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Discount:    item.Discount,
+			Tax:         item.Tax,
+			Total:       item.Total,
+		}
+	}
+
+	return &Invoice{
+		Model:         Model{ID: id},
+		MerchantID:    order.MerchantID,
+		OrderID:       order.ID,
+		Receipt:       order.PaidAt != nil,
+		CustomerName:  order.CustomerName,
+		CustomerEmail: order.CustomerEmail,
+		Currency:      order.Currency,
+		Subtotal:      order.Subtotal,
+		DiscountTotal: order.DiscountTotal,
+		TaxTotal:      order.TaxTotal,
+		Total:         order.Total,
+		IssuedAt:      issuedAt,
+		Snapshot:      snapshot,
+	}
+}
+
+func (s InvoiceSnapshot) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (s *InvoiceSnapshot) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into InvoiceSnapshot", src)
+	}
+
+	return json.Unmarshal(b, s)
+}
+
+// IsInvoiceable reports whether an invoice may be issued for the order.
+// Drafts may still change and cancelled orders are not billed.
+func (o Order) IsInvoiceable() bool {
+	return o.State != OrderStateDraft && o.State != OrderStateCancelled
+}
+
+// DisplayNumber is the invoice number as printed on the document.
+func (i Invoice) DisplayNumber() string {
+	return fmt.Sprintf("INV-%06d", i.Number)
+}
+
+// DocumentKey returns the blob storage key of the rendered document with the
+// given extension. Documents are private, and named after the id of the
+// invoice rather than its number so that their keys can not be guessed.
+func (i Invoice) DocumentKey(ext string) string {
+	return fmt.Sprintf("%sinvoices/%s/%s%s", blob.PrivatePrefix, i.MerchantID, i.ID, ext)
+}
+
+type InvoiceDto struct {
+	ID            string    `json:"id"`
+	MerchantID    string    `json:"merchantID"`
+	OrderID       string    `json:"orderID"`
+	Number        string    `json:"number"`
+	Receipt       bool      `json:"receipt"`
+	CustomerName  string    `json:"customerName"`
+	CustomerEmail string    `json:"customerEmail"`
+	Currency      string    `json:"currency"`
+	Subtotal      int64     `json:"subtotal"`
+	DiscountTotal int64     `json:"discountTotal"`
+	TaxTotal      int64     `json:"taxTotal"`
+	Total         int64     `json:"total"`
+	IssuedAt      time.Time `json:"issuedAt"`
+	PdfURL        string    `json:"pdfUrl"`
+	HtmlURL       string    `json:"htmlUrl"`
+}
+
+func (i Invoice) ToDto() *InvoiceDto {
+	return &InvoiceDto{
+		ID:            i.ID,
+		MerchantID:    i.MerchantID,
+		OrderID:       i.OrderID,
+		Number:        i.DisplayNumber(),
+		Receipt:       i.Receipt,
+		CustomerName:  i.CustomerName,
+		CustomerEmail: i.CustomerEmail,
+		Currency:      i.Currency,
+		Subtotal:      i.Subtotal,
+		DiscountTotal: i.DiscountTotal,
+		TaxTotal:      i.TaxTotal,
+		Total:         i.Total,
+		IssuedAt:      i.IssuedAt,
+		PdfURL:        fmt.Sprintf("/api/v1/invoices/%s.pdf", i.ID),
+		HtmlURL:       fmt.Sprintf("/api/v1/invoices/%s.html", i.ID),
+	}
+}
+
+type InvoiceDtos []*InvoiceDto
+
+func (is Invoices) ToDto() InvoiceDtos {
+	result := make([]*InvoiceDto, len(is))
+	for k, v := range is {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
@@ -14,7 +14,11 @@ const (
 type ctxKey string
 
 type CtxUser struct {
-	UserId         uuid.UUID
+	// UserId is the merchant acted on.
+	UserId uuid.UUID
+	// ActorId is the merchant signed in, which differs from UserId when an
+	// organisation acts on one of its descendants.
+	ActorId        uuid.UUID
 	TokenCreatedAt *time.Time
 }
 
@@ -31,6 +35,7 @@ func (t *Token) ToCtxUser() CtxUser {
 	id, _ := uuid.Parse(t.UserId)
 	return CtxUser{
 		UserId:         id,
+		ActorId:        id,
 		TokenCreatedAt: t.CreatedAt,
 	}
 }
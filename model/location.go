@@ -0,0 +1,237 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	dateLayout      = "2006-01-02"
+	clockTimeLayout = "15:04"
+)
+
+type Location struct {
+	Model
+	MerchantID        string `gorm:"index"`
+	Name              string
+	AddressLine1      string
+	AddressLine2      string
+	City              string
+	Region            string
+	PostalCode        string
+	CountryCode       string
+	Latitude          float64
+	Longitude         float64
+	Phone             string
+	Timezone          string
+	OpeningHours      []*OpeningHours     `gorm:"constraint:OnDelete:CASCADE"`
+	HolidayExceptions []*HolidayException `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type Locations []*Location
+
+// OpeningHours is a single opening period on a day of the week. A period
+// whose closing time is not after its opening time runs past midnight.
+type OpeningHours struct {
+	ID         uint   `gorm:"primaryKey"`
+	LocationID string `gorm:"index"`
+	Weekday    time.Weekday
+	Opens      string
+	Closes     string
+}
+
+// HolidayException replaces the regular opening hours of a location on a
+// specific date, either closing it for the day or setting special hours.
+type HolidayException struct {
+	ID         uint   `gorm:"primaryKey"`
+	LocationID string `gorm:"index"`
+	Date       string
+	Closed     bool
+	Opens      string
+	Closes     string
+	Note       string
+}
+
+type LocationDto struct {
+	ID                string                 `json:"id"`
+	MerchantID        string                 `json:"merchantID"`
+	Name              string                 `json:"name"`
+	AddressLine1      string                 `json:"addressLine1"`
+	AddressLine2      string                 `json:"addressLine2"`
+	City              string                 `json:"city"`
+	Region            string                 `json:"region"`
+	PostalCode        string                 `json:"postalCode"`
+	CountryCode       string                 `json:"countryCode"`
+	Latitude          float64                `json:"latitude"`
+	Longitude         float64                `json:"longitude"`
+	Phone             string                 `json:"phone"`
+	Timezone          string                 `json:"timezone"`
+	OpeningHours      []*OpeningHoursDto     `json:"openingHours"`
+	HolidayExceptions []*HolidayExceptionDto `json:"holidayExceptions"`
+	OpenNow           bool                   `json:"openNow"`
+}
+
+type OpeningHoursDto struct {
+	Weekday time.Weekday `json:"weekday" swaggertype:"integer"`
+	Opens   string       `json:"opens"`
+	Closes  string       `json:"closes"`
+}
+
+type HolidayExceptionDto struct {
+	Date   string `json:"date"`
+	Closed bool   `json:"closed"`
+	Opens  string `json:"opens,omitempty"`
+	Closes string `json:"closes,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// ToDto converts the location, reporting whether it is open at the given time.
+func (l Location) ToDto(now time.Time) *LocationDto {
+	hours := make([]*OpeningHoursDto, len(l.OpeningHours))
+	for k, v := range l.OpeningHours {
+		hours[k] = &OpeningHoursDto{
+			Weekday: v.Weekday,
+			Opens:   v.Opens,
+			Closes:  v.Closes,
+		}
+	}
+
+	exceptions := make([]*HolidayExceptionDto, len(l.HolidayExceptions))
+	for k, v := range l.HolidayExceptions {
+		exceptions[k] = &HolidayExceptionDto{
+			Date:   v.Date,
+			Closed: v.Closed,
+			Opens:  v.Opens,
+			Closes: v.Closes,
+			Note:   v.Note,
+		}
+	}
+
+	// A location with an unknown timezone is reported as closed.
+	openNow, _ := l.IsOpenAt(now)
+
+	return &LocationDto{
+		ID:                l.ID,
+		MerchantID:        l.MerchantID,
+		Name:              l.Name,
+		AddressLine1:      l.AddressLine1,
+		AddressLine2:      l.AddressLine2,
+		City:              l.City,
+		Region:            l.Region,
+		PostalCode:        l.PostalCode,
+		CountryCode:       l.CountryCode,
+		Latitude:          l.Latitude,
+		Longitude:         l.Longitude,
+		Phone:             l.Phone,
+		Timezone:          l.Timezone,
+		OpeningHours:      hours,
+		HolidayExceptions: exceptions,
+		OpenNow:           openNow,
+	}
+}
+
+type LocationDtos []*LocationDto
+
+func (ls Locations) ToDto(now time.Time) LocationDtos {
+	result := make([]*LocationDto, len(ls))
+	for k, v := range ls {
+		result[k] = v.ToDto(now)
+	}
+
+	return result
+}
+
+type openPeriod struct {
+	opens  int
+	closes int
+}
+
+// IsOpenAt reports whether the location is open at t, evaluated in the
+// location's own timezone. Holiday exceptions take precedence over the
+// weekly opening hours for their date.
+func (l Location) IsOpenAt(t time.Time) (bool, error) {
+	loc, err := time.LoadLocation(l.Timezone)
+	if err != nil {
+		return false, err
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	today, err := l.periodsOn(local)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range today {
+		if p.closes > p.opens {
+			if minute >= p.opens && minute < p.closes {
+				return true, nil
+			}
+		} else if minute >= p.opens {
+			return true, nil
+		}
+	}
+
+	// Periods running past midnight yesterday are still open this morning.
+	yesterday, err := l.periodsOn(local.AddDate(0, 0, -1))
+	if err != nil {
+		return false, err
+	}
+	for _, p := range yesterday {
+		if p.closes <= p.opens && minute < p.closes {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (l Location) periodsOn(day time.Time) ([]openPeriod, error) {
+	date := day.Format(dateLayout)
+	for _, e := range l.HolidayExceptions {
+		if e.Date != date {
+			continue
+		}
+		if e.Closed {
+			return nil, nil
+		}
+
+		p, err := newOpenPeriod(e.Opens, e.Closes)
+		if err != nil {
+			return nil, err
+		}
+		return []openPeriod{p}, nil
+	}
+
+	periods := make([]openPeriod, 0)
+	for _, h := range l.OpeningHours {
+		if h.Weekday != day.Weekday() {
+			continue
+		}
+
+		p, err := newOpenPeriod(h.Opens, h.Closes)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+
+	return periods, nil
+}
+
+func newOpenPeriod(opens, closes string) (openPeriod, error) {
+	o, err := time.Parse(clockTimeLayout, opens)
+	if err != nil {
+		return openPeriod{}, fmt.Errorf("invalid opening time %q: %w", opens, err)
+	}
+
+	c, err := time.Parse(clockTimeLayout, closes)
+	if err != nil {
+		return openPeriod{}, fmt.Errorf("invalid closing time %q: %w", closes, err)
+	}
+
+	return openPeriod{
+		opens:  o.Hour()*60 + o.Minute(),
+		closes: c.Hour()*60 + c.Minute(),
+	}, nil
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LocationForm struct {
+	Name              string                  `json:"name" form:"required,max=255"`
+	AddressLine1      string                  `json:"addressLine1" form:"required,max=255"`
+	AddressLine2      string                  `json:"addressLine2" form:"max=255"`
+	City              string                  `json:"city" form:"required,max=255"`
+	Region            string                  `json:"region" form:"max=255"`
+	PostalCode        string                  `json:"postalCode" form:"max=32"`
+	CountryCode       string                  `json:"countryCode" form:"required,iso3166_1_alpha2"`
+	Latitude          *float64                `json:"latitude" form:"required,latitude"`
+	Longitude         *float64                `json:"longitude" form:"required,longitude"`
+	Phone             string                  `json:"phone" form:"omitempty,e164"`
+	Timezone          string                  `json:"timezone" form:"required,timezone"`
+	OpeningHours      []*OpeningHoursForm     `json:"openingHours" form:"dive"`
+	HolidayExceptions []*HolidayExceptionForm `json:"holidayExceptions" form:"dive"`
+}
+
+type OpeningHoursForm struct {
+	Weekday time.Weekday `json:"weekday" form:"min=0,max=6" swaggertype:"integer"`
+	Opens   string       `json:"opens" form:"required,datetime=15:04"`
+	Closes  string       `json:"closes" form:"required,datetime=15:04"`
+}
+
+type HolidayExceptionForm struct {
+	Date   string `json:"date" form:"required,datetime=2006-01-02"`
+	Closed bool   `json:"closed"`
+	Opens  string `json:"opens" form:"omitempty,datetime=15:04"`
+	Closes string `json:"closes" form:"omitempty,datetime=15:04"`
+	Note   string `json:"note" form:"max=255"`
+}
+
+// HasCompleteHolidayHours reports whether every holiday exception either
+// closes the location or sets both an opening and a closing time.
+func (f *LocationForm) HasCompleteHolidayHours() bool {
+	for _, e := range f.HolidayExceptions {
+		if !e.Closed && (e.Opens == "" || e.Closes == "") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *LocationForm) ToModel(merchantId string) *Location {
+	return f.ToModelWithId(uuid.New().String(), merchantId)
+}
+
+func (f *LocationForm) ToModelWithId(id, merchantId string) *Location {
+	hours := make([]*OpeningHours, len(f.OpeningHours))
+	for k, v := range f.OpeningHours {
+		hours[k] = &OpeningHours{
+			LocationID: id,
+			Weekday:    v.Weekday,
+			Opens:      v.Opens,
+			Closes:     v.Closes,
+		}
+	}
+
+	exceptions := make([]*HolidayException, len(f.HolidayExceptions))
+	for k, v := range f.HolidayExceptions {
+		exceptions[k] = &HolidayException{
+			LocationID: id,
+			Date:       v.Date,
+			Closed:     v.Closed,
+			Opens:      v.Opens,
+			Closes:     v.Closes,
+			Note:       v.Note,
+		}
+	}
+
+	return &Location{
+		Model: Model{
+			ID: id,
+		},
+		MerchantID:        merchantId,
+		Name:              f.Name,
+		AddressLine1:      f.AddressLine1,
+		AddressLine2:      f.AddressLine2,
+		City:              f.City,
+		Region:            f.Region,
+		PostalCode:        f.PostalCode,
+		CountryCode:       f.CountryCode,
+		Latitude:          *f.Latitude,
+		Longitude:         *f.Longitude,
+		Phone:             f.Phone,
+		Timezone:          f.Timezone,
+		OpeningHours:      hours,
+		HolidayExceptions: exceptions,
+	}
+}
@@ -0,0 +1,66 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+var location = &model.Location{
+	Timezone: "Asia/Singapore",
+	OpeningHours: []*model.OpeningHours{
+		{Weekday: time.Monday, Opens: "09:00", Closes: "17:00"},
+		{Weekday: time.Friday, Opens: "18:00", Closes: "02:00"},
+	},
+	HolidayExceptions: []*model.HolidayException{
+		{Date: "2021-03-08", Closed: true},
+		{Date: "2021-03-15", Opens: "12:00", Closes: "14:00"},
+	},
+}
+
+type openAtTestCase struct {
+	name     string
+	at       string
+	expected bool
+}
+
+var openAtTests = []*openAtTestCase{
+	{name: "within regular hours", at: "2021-03-01T10:00:00+08:00", expected: true},
+	{name: "at closing time", at: "2021-03-01T17:00:00+08:00", expected: false},
+	{name: "on a day without hours", at: "2021-03-02T10:00:00+08:00", expected: false},
+	{name: "converted to location timezone", at: "2021-03-01T01:30:00Z", expected: true},
+	{name: "overnight before midnight", at: "2021-03-05T23:00:00+08:00", expected: true},
+	{name: "overnight after midnight", at: "2021-03-06T01:59:00+08:00", expected: true},
+	{name: "overnight after closing", at: "2021-03-06T02:00:00+08:00", expected: false},
+	{name: "closed holiday", at: "2021-03-08T10:00:00+08:00", expected: false},
+	{name: "special hours holiday", at: "2021-03-15T13:00:00+08:00", expected: true},
+	{name: "outside special hours", at: "2021-03-15T10:00:00+08:00", expected: false},
+}
+
+func TestLocationIsOpenAt(t *testing.T) {
+	for _, tc := range openAtTests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			at, err := time.Parse(time.RFC3339, tc.at)
+			require.NoError(t, err)
+
+			actual, err := location.IsOpenAt(at)
+			require.NoError(t, err)
+			assert.Equalf(t, tc.expected, actual, "Expected: %v, Actual: %v", tc.expected, actual)
+		})
+	}
+}
+
+func TestLocationIsOpenAtInvalidTimezone(t *testing.T) {
+	t.Parallel()
+
+	l := &model.Location{Timezone: "Mars/Olympus_Mons"}
+	_, err := l.IsOpenAt(time.Now())
+	assert.Error(t, err)
+}
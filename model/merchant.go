@@ -1,31 +1,89 @@
 package model
 
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"merchant/util/cryptoutil"
+)
+
+const AssetPathPrefix = "/assets/"
+
+var ErrMerchantParentCycle = errors.New("merchant can not be moved under itself or its descendants")
+
 type Merchant struct {
 	Model
-	Email        string
-	Password     string
-	BusinessName string
-	Description  string
-	Status       string
+	Email              cryptoutil.EncryptedString
+	Password           string
+	BusinessName       cryptoutil.EncryptedString
+	Description        string
+	Status             string
+	LogoKey            string
+	LogoThumbnailKey   string
+	BannerKey          string
+	BannerThumbnailKey string
+	// ClosureRequestedAt is when the merchant asked for the account to be
+	// closed. Its data is erased at ClosesAt unless the closure is cancelled
+	// before.
+	ClosureRequestedAt *time.Time
+	ClosesAt           *time.Time `gorm:"index"`
+	// EmailIndex is the blind index of the email, which merchants are looked
+	// up by.
+	EmailIndex string `gorm:"size:64;index"`
+	// ParentID is the merchant this one trades under: organisations group
+	// brands, which group merchants. Merchants at the top have none.
+	ParentID *string  `gorm:"size:36;index"`
+	Metadata Metadata `gorm:"type:json"`
+}
+
+// BeforeSave keeps the blind index of the email in step with the email.
+func (m *Merchant) BeforeSave(tx *gorm.DB) error {
+	if m.Email == "" {
+		return nil
+	}
+
+	index, err := EmailIndex(m.Email.String())
+	if err != nil {
+		return err
+	}
+	m.EmailIndex = index
+	return nil
 }
 
 type Merchants []*Merchant
 
 type MerchantDto struct {
-	ID           string `json:"id"`
-	Email        string `json:"email"`
-	BusinessName string `json:"businessName"`
-	Description  string `json:"description"`
-	Status       string `json:"status"`
+	ID                 string     `json:"id"`
+	Email              string     `json:"email"`
+	BusinessName       string     `json:"businessName"`
+	Description        string     `json:"description"`
+	Status             string     `json:"status"`
+	LogoURL            string     `json:"logoUrl"`
+	LogoThumbnailURL   string     `json:"logoThumbnailUrl"`
+	BannerURL          string     `json:"bannerUrl"`
+	BannerThumbnailURL string     `json:"bannerThumbnailUrl"`
+	ClosesAt           *time.Time `json:"closesAt,omitempty"`
+	ParentID           *string    `json:"parentId,omitempty"`
+	Metadata           Metadata   `json:"metadata"`
 }
 
 func (m Merchant) ToDto() *MerchantDto {
 	return &MerchantDto{
-		ID:           m.ID,
-		Email:        m.Email,
-		BusinessName: m.BusinessName,
-		Description:  m.Description,
-		Status:       m.Status,
+		ID:                 m.ID,
+		Email:              m.Email.String(),
+		BusinessName:       m.BusinessName.String(),
+		Description:        m.Description,
+		Status:             m.Status,
+		LogoURL:            AssetURL(m.LogoKey),
+		LogoThumbnailURL:   AssetURL(m.LogoThumbnailKey),
+		BannerURL:          AssetURL(m.BannerKey),
+		BannerThumbnailURL: AssetURL(m.BannerThumbnailKey),
+		ClosesAt:           m.ClosesAt,
+		ParentID:           m.ParentID,
+		Metadata:           m.Metadata.ToDto(),
 	}
 }
 
@@ -39,3 +97,19 @@ func (ms Merchants) ToDto() MerchantDtos {
 
 	return result
 }
+
+// AssetURL returns the path the asset stored under key is served at, or an
+// empty string when there is no asset.
+func AssetURL(key string) string {
+	if key == "" {
+		return ""
+	}
+
+	return AssetPathPrefix + key
+}
+
+// EmailIndex returns the blind index of the email, which ignores case and
+// surrounding spaces.
+func EmailIndex(email string) (string, error) {
+	return cryptoutil.BlindIndex(strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DefaultClosureGracePeriod is how long a closure may be cancelled before
+// the data of the merchant is erased, unless configured otherwise.
+const DefaultClosureGracePeriod = 30 * 24 * time.Hour
+
+var ErrClosureGracePeriodOver = errors.New("closure grace period is over")
+
+// MerchantTombstone records that the account of a merchant was closed and
+// its data erased. It keeps nothing which identifies the merchant but the
+// id its records were kept under.
+type MerchantTombstone struct {
+	MerchantID         string `gorm:"primaryKey;size:36"`
+	ClosureRequestedAt *time.Time
+	ErasedAt           time.Time
+	// Erased is the JSON encoded number of records erased or anonymised, by
+	// table.
+	Erased string `gorm:"type:text"`
+}
+
+func NewMerchantTombstone(m *Merchant, erased map[string]int64, at time.Time) *MerchantTombstone {
+	// Maps of counts always marshal.
+	b, _ := json.Marshal(erased)
+
+	return &MerchantTombstone{
+		MerchantID:         m.ID,
+		ClosureRequestedAt: m.ClosureRequestedAt,
+		ErasedAt:           at,
+		Erased:             string(b),
+	}
+}
+
+type MerchantClosureDto struct {
+	RequestedAt *time.Time `json:"requestedAt"`
+	ClosesAt    *time.Time `json:"closesAt"`
+}
+
+// ClosureToDto returns the pending closure of the merchant, or nil when no
+// closure was requested.
+func (m Merchant) ClosureToDto() *MerchantClosureDto {
+	if m.ClosesAt == nil {
+		return nil
+	}
+
+	return &MerchantClosureDto{
+		RequestedAt: m.ClosureRequestedAt,
+		ClosesAt:    m.ClosesAt,
+	}
+}
@@ -2,4 +2,13 @@ package model
 
 type MerchantForm struct {
 	Description string `json:"description"`
+	// Metadata replaces the metadata of the merchant; leaving it out keeps
+	// the metadata as it is.
+	Metadata Metadata `json:"metadata" form:"omitempty,metadata"`
+}
+
+type MerchantParentForm struct {
+	// ParentID is the merchant to move under. Leaving it out moves the
+	// merchant to the top of the hierarchy.
+	ParentID *string `json:"parentId" form:"omitempty,uuid"`
 }
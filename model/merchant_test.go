@@ -14,6 +14,7 @@ var merchantDto = &model.MerchantDto{
 	BusinessName: "PaceNow",
 	Description:  "",
 	Status:       "Active",
+	Metadata:     model.Metadata{},
 }
 var merchant = &model.Merchant{
 	Model: model.Model{
@@ -41,6 +42,18 @@ func TestMerchantToDto(t *testing.T) {
 	assert.Equalf(t, expected, actual, "Expected: %q, Actual: %q", expected, actual)
 }
 
+func TestMerchantToDtoWithAssets(t *testing.T) {
+	t.Parallel()
+
+	m := *merchant
+	m.LogoKey = "merchants/8336fc00-43b5-40f7-83e3-27c018058054/logo.png"
+
+	expected := "/assets/merchants/8336fc00-43b5-40f7-83e3-27c018058054/logo.png"
+	actual := m.ToDto()
+	assert.Equal(t, expected, actual.LogoURL)
+	assert.Equal(t, "", actual.BannerURL)
+}
+
 func TestCountriesToDto(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"merchant/util/cryptoutil"
+)
+
+// MerchantVersion is the merchant as it was at one of its versions. The
+// repository records one on every write of the merchant.
+type MerchantVersion struct {
+	ID                 string `gorm:"primaryKey;size:36"`
+	MerchantID         string `gorm:"size:36;index"`
+	Version            int64
+	Email              cryptoutil.EncryptedString
+	BusinessName       cryptoutil.EncryptedString
+	Description        string
+	Status             string
+	LogoKey            string
+	LogoThumbnailKey   string
+	BannerKey          string
+	BannerThumbnailKey string
+	ParentID           *string  `gorm:"size:36"`
+	Metadata           Metadata `gorm:"type:json"`
+	// CreatedAt is when the merchant took on the version.
+	CreatedAt *time.Time `gorm:"index"`
+}
+
+type MerchantVersions []*MerchantVersion
+
+// ToMerchant returns the merchant at the version.
+func (v MerchantVersion) ToMerchant() *Merchant {
+	return &Merchant{
+		Model: Model{
+			ID:        v.MerchantID,
+			UpdatedAt: v.CreatedAt,
+			Version:   v.Version,
+		},
+		Email:              v.Email,
+		BusinessName:       v.BusinessName,
+		Description:        v.Description,
+		Status:             v.Status,
+		LogoKey:            v.LogoKey,
+		LogoThumbnailKey:   v.LogoThumbnailKey,
+		BannerKey:          v.BannerKey,
+		BannerThumbnailKey: v.BannerThumbnailKey,
+		ParentID:           v.ParentID,
+		Metadata:           v.Metadata,
+	}
+}
+
+type MerchantVersionDto struct {
+	Version   int64        `json:"version"`
+	CreatedAt *time.Time   `json:"createdAt"`
+	Merchant  *MerchantDto `json:"merchant"`
+}
+
+func (v MerchantVersion) ToDto() *MerchantVersionDto {
+	return &MerchantVersionDto{
+		Version:   v.Version,
+		CreatedAt: v.CreatedAt,
+		Merchant:  v.ToMerchant().ToDto(),
+	}
+}
+
+type MerchantVersionDtos []*MerchantVersionDto
+
+func (vs MerchantVersions) ToDto() MerchantVersionDtos {
+	result := make([]*MerchantVersionDto, len(vs))
+	for k, v := range vs {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Metadata holds the key-value pairs integrators attach to records, such as
+// their own identifiers. It is stored as a JSON object; forms limit it with
+// the metadata validation.
+type Metadata map[string]string
+
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (m *Metadata) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*m = Metadata{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into Metadata", src)
+	}
+
+	metadata := Metadata{}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &metadata); err != nil {
+			return err
+		}
+	}
+
+	*m = metadata
+	return nil
+}
+
+// ToDto returns the metadata, empty rather than nil.
+func (m Metadata) ToDto() Metadata {
+	if m == nil {
+		return Metadata{}
+	}
+
+	return m
+}
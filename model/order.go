@@ -0,0 +1,289 @@
+package model
+
+import (
+	"errors"
+	"math/bits"
+	"time"
+)
+
+const (
+	OrderStateDraft     = "draft"
+	OrderStatePlaced    = "placed"
+	OrderStatePaid      = "paid"
+	OrderStateFulfilled = "fulfilled"
+	OrderStateCancelled = "cancelled"
+	OrderStateRefunded  = "refunded"
+)
+
+// orderTransitions lists the states an order in a given state can move to.
+var orderTransitions = map[string][]string{
+	OrderStateDraft:     {OrderStatePlaced, OrderStateCancelled},
+	OrderStatePlaced:    {OrderStatePaid, OrderStateCancelled},
+	OrderStatePaid:      {OrderStateFulfilled, OrderStateRefunded},
+	OrderStateFulfilled: {OrderStateRefunded},
+}
+
+// TaxRates are the tax rates of the product tax categories in basis points,
+// so 2000 is 20%. Prices are exclusive of tax.
+var TaxRates = map[string]int64{
+	TaxCategoryStandard: 2000,
+	TaxCategoryReduced:  500,
+	TaxCategoryZero:     0,
+	TaxCategoryExempt:   0,
+}
+
+var (
+	ErrUnknownVariant   = errors.New("unknown or inactive product variant")
+	ErrCurrencyMismatch = errors.New("order lines must share one currency")
+	ErrDiscountTooLarge = errors.New("discount exceeds order subtotal")
+	ErrNegativeDiscount = errors.New("discount is negative")
+	ErrOrderTooLarge    = errors.New("order subtotal exceeds the maximum")
+)
+
+// MaxOrderSubtotal is the largest order subtotal in minor units. It keeps the
+// discount and tax arithmetic of an order within int64.
+const MaxOrderSubtotal int64 = 100000000000000
+
+// Order is a customer order of a merchant. All amounts are in the minor
+// unit of the order currency.
+type Order struct {
+	Model
+	MerchantID    string `gorm:"index"`
+	CustomerName  string
+	CustomerEmail string
+	Currency      string
+	State         string `gorm:"index"`
+	Note          string
+	// CouponID is the coupon redeemed on the order, whose discount is the
+	// order discount.
+	CouponID      string
+	CouponCode    string
+	Subtotal      int64
+	DiscountTotal int64
+	TaxTotal      int64
+	Total         int64
+	PlacedAt      *time.Time
+	PaidAt        *time.Time
+	FulfilledAt   *time.Time
+	CancelledAt   *time.Time
+	RefundedAt    *time.Time
+	Items         []*OrderItem `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type Orders []*Order
+
+// OrderItem is an order line. Product details are copied onto the line so
+// later catalog changes do not alter the order.
+type OrderItem struct {
+	ID          uint   `gorm:"primaryKey"`
+	OrderID     string `gorm:"index"`
+	ProductID   string
+	VariantID   string
+	SKU         string
+	Name        string
+	TaxCategory string
+	UnitPrice   int64
+	Quantity    int64
+	Subtotal    int64
+	Discount    int64
+	Tax         int64
+	Total       int64
+}
+
+// OrderSearch narrows down the orders of a merchant. From and To bound the
+// creation time of the orders, To being exclusive.
+type OrderSearch struct {
+	State  string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+// CanTransitionTo reports whether the order may move to the given state.
+func (o Order) CanTransitionTo(state string) bool {
+	for _, s := range orderTransitions[o.State] {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsEditable reports whether the lines of the order may still change.
+func (o Order) IsEditable() bool {
+	return o.State == OrderStateDraft
+}
+
+// RemoveCoupon prices the order without the coupon redeemed on it, and
+// without a discount.
+func (o *Order) RemoveCoupon() error {
+	o.CouponID = ""
+	o.CouponCode = ""
+	o.DiscountTotal = 0
+
+	return o.CalculateTotals()
+}
+
+// CalculateTotals prices the lines of the order. The order discount is
+// spread over the lines in proportion to their subtotals before tax is
+// calculated on what remains of each line.
+func (o *Order) CalculateTotals() error {
+	var subtotal int64
+	for _, item := range o.Items {
+		if item.Quantity > 0 && item.UnitPrice > (MaxOrderSubtotal-subtotal)/item.Quantity {
+			return ErrOrderTooLarge
+		}
+		item.Subtotal = item.UnitPrice * item.Quantity
+		subtotal += item.Subtotal
+	}
+
+	if o.DiscountTotal < 0 {
+		return ErrNegativeDiscount
+	}
+	if o.DiscountTotal > subtotal {
+		return ErrDiscountTooLarge
+	}
+
+	discounts := allocate(o.DiscountTotal, o.Items)
+
+	o.Subtotal = subtotal
+	o.TaxTotal = 0
+	o.Total = 0
+	for k, item := range o.Items {
+		item.Discount = discounts[k]
+		item.Tax = tax(item.Subtotal-item.Discount, TaxRates[item.TaxCategory])
+		item.Total = item.Subtotal - item.Discount + item.Tax
+
+		o.TaxTotal += item.Tax
+		o.Total += item.Total
+	}
+
+	return nil
+}
+
+// allocate spreads amount over the items in proportion to their subtotals,
+// handing the minor units lost to rounding to the first items so the shares
+// add up to amount. amount must not exceed the sum of the subtotals, which
+// keeps the 128 bit quotient of each share within 64 bits.
+func allocate(amount int64, items []*OrderItem) []int64 {
+	shares := make([]int64, len(items))
+
+	var subtotal int64
+	for _, item := range items {
+		subtotal += item.Subtotal
+	}
+	if amount == 0 || subtotal == 0 {
+		return shares
+	}
+
+	remaining := amount
+	for k, item := range items {
+		hi, lo := bits.Mul64(uint64(amount), uint64(item.Subtotal))
+		share, _ := bits.Div64(hi, lo, uint64(subtotal))
+		shares[k] = int64(share)
+		remaining -= shares[k]
+	}
+	for k := 0; remaining > 0; k = (k + 1) % len(items) {
+		if shares[k] < items[k].Subtotal {
+			shares[k]++
+			remaining--
+		}
+	}
+
+	return shares
+}
+
+// tax returns the tax on amount at rate basis points, rounded half up.
+func tax(amount, rate int64) int64 {
+	return (amount*rate + 5000) / 10000
+}
+
+type OrderDto struct {
+	ID            string          `json:"id"`
+	MerchantID    string          `json:"merchantID"`
+	CustomerName  string          `json:"customerName"`
+	CustomerEmail string          `json:"customerEmail"`
+	Currency      string          `json:"currency"`
+	State         string          `json:"state"`
+	Note          string          `json:"note,omitempty"`
+	CouponCode    string          `json:"couponCode,omitempty"`
+	Subtotal      int64           `json:"subtotal"`
+	DiscountTotal int64           `json:"discountTotal"`
+	TaxTotal      int64           `json:"taxTotal"`
+	Total         int64           `json:"total"`
+	Items         []*OrderItemDto `json:"items"`
+	CreatedAt     *time.Time      `json:"createdAt"`
+	PlacedAt      *time.Time      `json:"placedAt,omitempty"`
+	PaidAt        *time.Time      `json:"paidAt,omitempty"`
+	FulfilledAt   *time.Time      `json:"fulfilledAt,omitempty"`
+	CancelledAt   *time.Time      `json:"cancelledAt,omitempty"`
+	RefundedAt    *time.Time      `json:"refundedAt,omitempty"`
+}
+
+type OrderItemDto struct {
+	ProductID   string `json:"productID"`
+	VariantID   string `json:"variantID"`
+	SKU         string `json:"sku"`
+	Name        string `json:"name"`
+	TaxCategory string `json:"taxCategory"`
+	UnitPrice   int64  `json:"unitPrice"`
+	Quantity    int64  `json:"quantity"`
+	Subtotal    int64  `json:"subtotal"`
+	Discount    int64  `json:"discount"`
+	Tax         int64  `json:"tax"`
+	Total       int64  `json:"total"`
+}
+
+func (o Order) ToDto() *OrderDto {
+	items := make([]*OrderItemDto, len(o.Items))
+	for k, v := range o.Items {
+		items[k] = &OrderItemDto{
+			ProductID:   v.ProductID,
+			VariantID:   v.VariantID,
+			SKU:         v.SKU,
+			Name:        v.Name,
+			TaxCategory: v.TaxCategory,
+			UnitPrice:   v.UnitPrice,
+			Quantity:    v.Quantity,
+			Subtotal:    v.Subtotal,
+			Discount:    v.Discount,
+			Tax:         v.Tax,
+			Total:       v.Total,
+		}
+	}
+
+	return &OrderDto{
+		ID:            o.ID,
+		MerchantID:    o.MerchantID,
+		CustomerName:  o.CustomerName,
+		CustomerEmail: o.CustomerEmail,
+		Currency:      o.Currency,
+		State:         o.State,
+		Note:          o.Note,
+		CouponCode:    o.CouponCode,
+		Subtotal:      o.Subtotal,
+		DiscountTotal: o.DiscountTotal,
+		TaxTotal:      o.TaxTotal,
+		Total:         o.Total,
+		Items:         items,
+		CreatedAt:     o.CreatedAt,
+		PlacedAt:      o.PlacedAt,
+		PaidAt:        o.PaidAt,
+		FulfilledAt:   o.FulfilledAt,
+		CancelledAt:   o.CancelledAt,
+		RefundedAt:    o.RefundedAt,
+	}
+}
+
+type OrderDtos []*OrderDto
+
+func (os Orders) ToDto() OrderDtos {
+	result := make([]*OrderDto, len(os))
+	for k, v := range os {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
@@ -0,0 +1,105 @@
+package model
+
+import "github.com/google/uuid"
+
+type OrderForm struct {
+	CustomerName  string           `json:"customerName" form:"max=255"`
+	CustomerEmail string           `json:"customerEmail" form:"required,email,max=255"`
+	Note          string           `json:"note" form:"max=1024"`
+	Discount      int64            `json:"discount" form:"min=0"`
+	Items         []*OrderItemForm `json:"items" form:"required,min=1,max=100,dive"`
+}
+
+type OrderItemForm struct {
+	VariantID string `json:"variantID" form:"required,uuid4_rfc4122"`
+	Quantity  int64  `json:"quantity" form:"min=1,max=10000"`
+}
+
+// OrderStateForm moves an order by hand. Orders are only paid by capturing
+// their payments and refunded by refunding them.
+type OrderStateForm struct {
+	State string `json:"state" form:"required,oneof=placed fulfilled cancelled"`
+}
+
+// VariantIds returns the variants ordered.
+func (f *OrderForm) VariantIds() []string {
+	ids := make([]string, len(f.Items))
+	for k, v := range f.Items {
+		ids[k] = v.VariantID
+	}
+
+	return ids
+}
+
+func (f *OrderForm) ToModel(merchantId string, products Products) (*Order, error) {
+	return f.ToModelWithId(uuid.New().String(), merchantId, products)
+}
+
+// ToModelWithId returns a priced draft order, copying the details of the
+// ordered variants from the active products of the merchant given.
+func (f *OrderForm) ToModelWithId(id, merchantId string, products Products) (*Order, error) {
+	type line struct {
+		product *Product
+		variant *ProductVariant
+	}
+
+	lines := make(map[string]line)
+	for _, p := range products {
+		if p.MerchantID != merchantId || !p.Active {
+			continue
+		}
+		for _, v := range p.Variants {
+			if v.Active {
+				lines[v.ID] = line{product: p, variant: v}
+			}
+		}
+	}
+
+	order := &Order{
+		Model: Model{
+			ID: id,
+		},
+		MerchantID:    merchantId,
+		CustomerName:  f.CustomerName,
+		CustomerEmail: f.CustomerEmail,
+		State:         OrderStateDraft,
+		Note:          f.Note,
+		DiscountTotal: f.Discount,
+		Items:         make([]*OrderItem, len(f.Items)),
+	}
+
+	for k, v := range f.Items {
+		l, ok := lines[v.VariantID]
+		if !ok {
+			return nil, ErrUnknownVariant
+		}
+
+		if order.Currency == "" {
+			order.Currency = l.variant.Currency
+		} else if order.Currency != l.variant.Currency {
+			return nil, ErrCurrencyMismatch
+		}
+
+		name := l.product.Name
+		if l.variant.Name != "" {
+			name += " - " + l.variant.Name
+		}
+
+		order.Items[k] = &OrderItem{
+			OrderID:     id,
+			ProductID:   l.product.ID,
+			VariantID:   l.variant.ID,
+			SKU:         l.variant.SKU,
+			Name:        name,
+			TaxCategory: l.product.TaxCategory,
+			UnitPrice:   l.variant.Price,
+			Quantity:    v.Quantity,
+		}
+	}
+
+	if err := order.CalculateTotals(); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
@@ -0,0 +1,189 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func testCatalog(merchantId string) model.Products {
+	return model.Products{
+		{
+			Model:       model.Model{ID: "p-shirt"},
+			MerchantID:  merchantId,
+			Name:        "T-shirt",
+			TaxCategory: model.TaxCategoryStandard,
+			Active:      true,
+			Variants: []*model.ProductVariant{
+				{Model: model.Model{ID: "v-shirt-m"}, SKU: "TS-M", Name: "M", Price: 1999, Currency: "EUR", Active: true},
+				{Model: model.Model{ID: "v-shirt-xl"}, SKU: "TS-XL", Name: "XL", Price: 1999, Currency: "EUR"},
+			},
+		},
+		{
+			Model:       model.Model{ID: "p-book"},
+			MerchantID:  merchantId,
+			Name:        "Cookbook",
+			TaxCategory: model.TaxCategoryReduced,
+			Active:      true,
+			Variants: []*model.ProductVariant{
+				{Model: model.Model{ID: "v-book"}, SKU: "CB-1", Price: 2500, Currency: "EUR", Active: true},
+				{Model: model.Model{ID: "v-book-usd"}, SKU: "CB-1-US", Price: 2900, Currency: "USD", Active: true},
+			},
+		},
+	}
+}
+
+func TestOrderFormToModelCalculatesTotals(t *testing.T) {
+	t.Parallel()
+
+	form := &model.OrderForm{
+		CustomerEmail: "jane@example.com",
+		Discount:      1000,
+		Items: []*model.OrderItemForm{
+			{VariantID: "v-shirt-m", Quantity: 2},
+			{VariantID: "v-book", Quantity: 1},
+		},
+	}
+
+	order, err := form.ToModel("merchant-1", testCatalog("merchant-1"))
+	require.NoError(t, err)
+
+	assert.Equal(t, model.OrderStateDraft, order.State)
+	assert.Equal(t, "EUR", order.Currency)
+	assert.Equal(t, "T-shirt - M", order.Items[0].Name)
+
+	// 3998 + 2500 = 6498, the discount of 1000 splits 615 / 384 and the
+	// unit lost to rounding goes to the first line.
+	assert.Equal(t, int64(6498), order.Subtotal)
+	assert.Equal(t, int64(616), order.Items[0].Discount)
+	assert.Equal(t, int64(384), order.Items[1].Discount)
+
+	// 20% of 3382 and 5% of 2116, rounded half up.
+	assert.Equal(t, int64(676), order.Items[0].Tax)
+	assert.Equal(t, int64(106), order.Items[1].Tax)
+	assert.Equal(t, int64(782), order.TaxTotal)
+	assert.Equal(t, int64(6498-1000+782), order.Total)
+}
+
+func TestOrderFormToModelRejectsInvalidLines(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		merchant string
+		discount int64
+		items    []*model.OrderItemForm
+		err      error
+	}{
+		{"inactive variant", "merchant-1", 0, []*model.OrderItemForm{{VariantID: "v-shirt-xl", Quantity: 1}}, model.ErrUnknownVariant},
+		{"other merchant", "merchant-2", 0, []*model.OrderItemForm{{VariantID: "v-book", Quantity: 1}}, model.ErrUnknownVariant},
+		{"mixed currencies", "merchant-1", 0, []*model.OrderItemForm{{VariantID: "v-book", Quantity: 1}, {VariantID: "v-book-usd", Quantity: 1}}, model.ErrCurrencyMismatch},
+		{"discount too large", "merchant-1", 2501, []*model.OrderItemForm{{VariantID: "v-book", Quantity: 1}}, model.ErrDiscountTooLarge},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			form := &model.OrderForm{Discount: tt.discount, Items: tt.items}
+
+			_, err := form.ToModel(tt.merchant, testCatalog("merchant-1"))
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestOrderCalculateTotalsAllocatesWholeDiscount(t *testing.T) {
+	t.Parallel()
+
+	order := &model.Order{
+		DiscountTotal: 100,
+		Items: []*model.OrderItem{
+			{UnitPrice: 1, Quantity: 1, TaxCategory: model.TaxCategoryZero},
+			{UnitPrice: 100, Quantity: 1, TaxCategory: model.TaxCategoryZero},
+			{UnitPrice: 100, Quantity: 1, TaxCategory: model.TaxCategoryZero},
+		},
+	}
+
+	require.NoError(t, order.CalculateTotals())
+
+	var discount int64
+	for _, item := range order.Items {
+		assert.LessOrEqual(t, item.Discount, item.Subtotal)
+		discount += item.Discount
+	}
+	assert.Equal(t, int64(100), discount)
+	assert.Equal(t, int64(101), order.Total)
+}
+
+func TestOrderCalculateTotalsRejectsOverflow(t *testing.T) {
+	t.Parallel()
+
+	order := &model.Order{
+		Items: []*model.OrderItem{
+			{UnitPrice: 1000000000000, Quantity: 10000, TaxCategory: model.TaxCategoryStandard},
+		},
+	}
+
+	assert.Equal(t, model.ErrOrderTooLarge, order.CalculateTotals())
+}
+
+func TestOrderCalculateTotalsRejectsNegativeDiscount(t *testing.T) {
+	t.Parallel()
+
+	order := &model.Order{
+		DiscountTotal: -100,
+		Items: []*model.OrderItem{
+			{UnitPrice: 1000, Quantity: 1, TaxCategory: model.TaxCategoryZero},
+		},
+	}
+
+	assert.Equal(t, model.ErrNegativeDiscount, order.CalculateTotals())
+}
+
+func TestOrderCalculateTotalsAllocatesLargeDiscount(t *testing.T) {
+	t.Parallel()
+
+	order := &model.Order{
+		DiscountTotal: model.MaxOrderSubtotal / 2,
+		Items: []*model.OrderItem{
+			{UnitPrice: model.MaxOrderSubtotal / 4, Quantity: 2, TaxCategory: model.TaxCategoryStandard},
+			{UnitPrice: model.MaxOrderSubtotal / 2, Quantity: 1, TaxCategory: model.TaxCategoryStandard},
+		},
+	}
+
+	require.NoError(t, order.CalculateTotals())
+
+	assert.Equal(t, model.MaxOrderSubtotal/4, order.Items[0].Discount)
+	assert.Equal(t, model.MaxOrderSubtotal/4, order.Items[1].Discount)
+	assert.Equal(t, model.MaxOrderSubtotal/10, order.TaxTotal)
+}
+
+func TestOrderCanTransitionTo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		from string
+		to   string
+		ok   bool
+	}{
+		{model.OrderStateDraft, model.OrderStatePlaced, true},
+		{model.OrderStateDraft, model.OrderStatePaid, false},
+		{model.OrderStatePlaced, model.OrderStatePaid, true},
+		{model.OrderStatePlaced, model.OrderStateCancelled, true},
+		{model.OrderStatePaid, model.OrderStateCancelled, false},
+		{model.OrderStatePaid, model.OrderStateFulfilled, true},
+		{model.OrderStateFulfilled, model.OrderStateRefunded, true},
+		{model.OrderStateCancelled, model.OrderStatePlaced, false},
+		{model.OrderStateRefunded, model.OrderStatePaid, false},
+	}
+
+	for _, tt := range tests {
+		order := &model.Order{State: tt.from}
+		assert.Equal(t, tt.ok, order.CanTransitionTo(tt.to), "%s to %s", tt.from, tt.to)
+	}
+}
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"merchant/util/cryptoutil"
+)
+
+// Domain event types, recorded in the outbox along with the change they
+// report.
+const (
+	EventMerchantRegistered = "merchant.registered"
+	EventMerchantUpdated    = "merchant.updated"
+	EventMerchantDeleted    = "merchant.deleted"
+	EventTeamMemberCreated  = "team_member.created"
+	EventTeamMemberUpdated  = "team_member.updated"
+	EventTeamMemberDeleted  = "team_member.deleted"
+	EventInventoryLowStock  = "inventory.low_stock"
+)
+
+// Aggregate types events are ordered by.
+const (
+	AggregateMerchant       = "merchant"
+	AggregateTeamMember     = "team_member"
+	AggregateInventoryLevel = "inventory_level"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the
+// change it reports and relayed to the event publisher afterwards. Events
+// of an aggregate are relayed one at a time in sequence order.
+type OutboxEvent struct {
+	Sequence      uint64 `gorm:"primaryKey;autoIncrement"`
+	ID            string `gorm:"size:36;uniqueIndex"`
+	Type          string
+	AggregateType string `gorm:"index:idx_outbox_events_aggregate"`
+	AggregateID   string `gorm:"index:idx_outbox_events_aggregate"`
+	MerchantID    string
+	// Payload is the JSON encoded DTO of the aggregate. It holds personal
+	// data, such as emails and names, so it is stored encrypted.
+	Payload    cryptoutil.EncryptedString `gorm:"type:text"`
+	OccurredAt time.Time
+	Attempts   int
+	// AvailableAt is when the event may next be taken by a relay.
+	AvailableAt time.Time
+	PublishedAt *time.Time `gorm:"index"`
+	LastError   string
+}
+
+type OutboxEvents []*OutboxEvent
+
+// OutboxEventDto is an event as recorded, the data being the DTO of the
+// aggregate after the change.
+type OutboxEventDto struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   string          `json:"aggregateId"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+	Data          json.RawMessage `json:"data"`
+}
+
+type OutboxEventDtos []*OutboxEventDto
+
+func (e OutboxEvent) ToDto() *OutboxEventDto {
+	data := json.RawMessage(e.Payload)
+	if !json.Valid(data) {
+		data = json.RawMessage("null")
+	}
+
+	return &OutboxEventDto{
+		ID:            e.ID,
+		Type:          e.Type,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		OccurredAt:    e.OccurredAt,
+		Data:          data,
+	}
+}
+
+func (es OutboxEvents) ToDto() OutboxEventDtos {
+	result := make([]*OutboxEventDto, len(es))
+	for k, v := range es {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
+
+func newOutboxEvent(eventType, aggregateType, aggregateId, merchantId string, data interface{}) *OutboxEvent {
+	// The data is always a DTO, which marshals without error.
+	payload, _ := json.Marshal(data)
+	now := time.Now().UTC()
+
+	return &OutboxEvent{
+		ID:            uuid.New().String(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateId,
+		MerchantID:    merchantId,
+		Payload:       cryptoutil.EncryptedString(payload),
+		OccurredAt:    now,
+		AvailableAt:   now,
+	}
+}
+
+// NewMerchantEvent records an event of the given type about the merchant.
+func NewMerchantEvent(eventType string, m *Merchant) *OutboxEvent {
+	return newOutboxEvent(eventType, AggregateMerchant, m.ID, m.ID, m.ToDto())
+}
+
+// NewTeamMemberEvent records an event of the given type about the team
+// member.
+func NewTeamMemberEvent(eventType string, t *TeamMember) *OutboxEvent {
+	return newOutboxEvent(eventType, AggregateTeamMember, t.ID, t.MerchantID, t.ToDto())
+}
+
+// NewLowStockEvent records that the inventory level fell to or below its low
+// stock threshold, raising the alert.
+func NewLowStockEvent(l *InventoryLevel, a *StockAlert) *OutboxEvent {
+	return newOutboxEvent(EventInventoryLowStock, AggregateInventoryLevel, l.ID, l.MerchantID, a.ToDto())
+}
+
+// OutboxRetryBackoff returns how long to wait before relaying an event again
+// after the given number of failed attempts: a second, doubling with every
+// attempt up to 5 minutes. Events are retried until they are published.
+func OutboxRetryBackoff(attempts int) time.Duration {
+	const max = 5 * time.Minute
+
+	backoff := time.Second
+	for k := 1; k < attempts; k++ {
+		backoff *= 2
+		if backoff >= max {
+			return max
+		}
+	}
+
+	return backoff
+}
@@ -0,0 +1,46 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/model"
+)
+
+func TestNewTeamMemberEvent(t *testing.T) {
+	t.Parallel()
+
+	member := &model.TeamMember{Model: model.Model{ID: "t1"}, MerchantID: "m1", Email: "ada@example.com"}
+	e := model.NewTeamMemberEvent(model.EventTeamMemberUpdated, member)
+
+	assert.NotEmpty(t, e.ID)
+	assert.Equal(t, model.EventTeamMemberUpdated, e.Type)
+	assert.Equal(t, model.AggregateTeamMember, e.AggregateType)
+	assert.Equal(t, "t1", e.AggregateID)
+	assert.Equal(t, "m1", e.MerchantID)
+	assert.Contains(t, e.Payload, `"email":"ada@example.com"`)
+	assert.Equal(t, e.OccurredAt, e.AvailableAt)
+	assert.Nil(t, e.PublishedAt)
+}
+
+func TestOutboxRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attempts int
+		backoff  time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{5, 16 * time.Second},
+		{9, 256 * time.Second},
+		{10, 5 * time.Minute},
+		{50, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.backoff, model.OutboxRetryBackoff(tt.attempts), "after %d attempts", tt.attempts)
+	}
+}
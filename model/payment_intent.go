@@ -0,0 +1,153 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	PaymentIntentStatusPending    = "pending"
+	PaymentIntentStatusAuthorized = "authorized"
+	PaymentIntentStatusFailed     = "failed"
+	PaymentIntentStatusCaptured   = "captured"
+	PaymentIntentStatusVoided     = "voided"
+	// A payment is partially refunded while part of the captured amount
+	// has been refunded, and refunded once all of it has.
+	PaymentIntentStatusPartiallyRefunded = "partially_refunded"
+	PaymentIntentStatusRefunded          = "refunded"
+)
+
+// PaymentIntentDeclineGatewayError is the decline reason of a payment which
+// failed because the provider could not be reached.
+const PaymentIntentDeclineGatewayError = "gateway_error"
+
+// ErrPaymentInProgress is returned when a payment is made for an order which
+// already has a payment that is not failed or voided.
+var ErrPaymentInProgress = errors.New("order has a payment in progress")
+
+// PaymentIntentLiveStatuses lists the statuses of a payment which may still
+// be, or has been, charged to the customer. An order has at most one payment
+// in these statuses.
+var PaymentIntentLiveStatuses = []string{
+	PaymentIntentStatusPending,
+	PaymentIntentStatusAuthorized,
+	PaymentIntentStatusCaptured,
+	PaymentIntentStatusPartiallyRefunded,
+	PaymentIntentStatusRefunded,
+}
+
+// paymentIntentTransitions lists the statuses a payment intent in a given
+// status can move to.
+var paymentIntentTransitions = map[string][]string{
+	PaymentIntentStatusPending:    {PaymentIntentStatusAuthorized, PaymentIntentStatusFailed},
+	PaymentIntentStatusAuthorized: {PaymentIntentStatusCaptured, PaymentIntentStatusVoided, PaymentIntentStatusFailed},
+}
+
+// PaymentIntent is the payment of an order through a payment provider.
+// Amounts are in the minor unit of the currency.
+type PaymentIntent struct {
+	Model
+	MerchantID        string `gorm:"index"`
+	OrderID           string `gorm:"index"`
+	Provider          string
+	ProviderReference string `gorm:"index;size:255"`
+	Amount            int64
+	CapturedAmount    int64
+	// RefundedAmount includes refunds still pending at the provider, so it
+	// never lets refunds exceed the captured amount.
+	RefundedAmount int64
+	Currency       string
+	Status         string
+	DeclineReason  string
+}
+
+type PaymentIntents []*PaymentIntent
+
+// ProcessedCallback records a provider callback that has been applied, so
+// redeliveries of it are ignored.
+type ProcessedCallback struct {
+	Provider  string `gorm:"primaryKey;size:64"`
+	EventID   string `gorm:"primaryKey;size:255"`
+	Type      string
+	CreatedAt *time.Time
+}
+
+// CanTransitionTo reports whether the payment intent may move to the given
+// status.
+func (p PaymentIntent) CanTransitionTo(status string) bool {
+	for _, s := range paymentIntentTransitions[p.Status] {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRefundable reports whether refunds can be made against the payment.
+func (p PaymentIntent) IsRefundable() bool {
+	return p.Status == PaymentIntentStatusCaptured || p.Status == PaymentIntentStatusPartiallyRefunded
+}
+
+// RefundableAmount returns the part of the captured amount that has not been
+// refunded or set aside for a pending refund.
+func (p PaymentIntent) RefundableAmount() int64 {
+	return p.CapturedAmount - p.RefundedAmount
+}
+
+// RefundStatus returns the status of a captured payment given the amount of
+// its refunds which succeeded. Pending refunds do not count, since they may
+// still fail.
+func (p PaymentIntent) RefundStatus(refunded int64) string {
+	switch {
+	case refunded == 0:
+		return PaymentIntentStatusCaptured
+	case refunded < p.CapturedAmount:
+		return PaymentIntentStatusPartiallyRefunded
+	default:
+		return PaymentIntentStatusRefunded
+	}
+}
+
+type PaymentIntentDto struct {
+	ID                string     `json:"id"`
+	OrderID           string     `json:"orderID"`
+	Provider          string     `json:"provider"`
+	ProviderReference string     `json:"providerReference"`
+	Amount            int64      `json:"amount"`
+	CapturedAmount    int64      `json:"capturedAmount"`
+	RefundedAmount    int64      `json:"refundedAmount"`
+	Currency          string     `json:"currency"`
+	Status            string     `json:"status"`
+	DeclineReason     string     `json:"declineReason,omitempty"`
+	CreatedAt         *time.Time `json:"createdAt"`
+	UpdatedAt         *time.Time `json:"updatedAt"`
+}
+
+func (p PaymentIntent) ToDto() *PaymentIntentDto {
+	return &PaymentIntentDto{
+		ID:                p.ID,
+		OrderID:           p.OrderID,
+		Provider:          p.Provider,
+		ProviderReference: p.ProviderReference,
+		Amount:            p.Amount,
+		CapturedAmount:    p.CapturedAmount,
+		RefundedAmount:    p.RefundedAmount,
+		Currency:          p.Currency,
+		Status:            p.Status,
+		DeclineReason:     p.DeclineReason,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+	}
+}
+
+type PaymentIntentDtos []*PaymentIntentDto
+
+func (ps PaymentIntents) ToDto() PaymentIntentDtos {
+	result := make([]*PaymentIntentDto, len(ps))
+	for k, v := range ps {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
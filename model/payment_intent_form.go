@@ -0,0 +1,27 @@
+package model
+
+import "github.com/google/uuid"
+
+type PaymentIntentForm struct {
+	PaymentMethod string `json:"paymentMethod" form:"required,max=255"`
+}
+
+type PaymentCaptureForm struct {
+	// Amount defaults to the whole authorized amount when zero.
+	Amount int64 `json:"amount" form:"min=0"`
+}
+
+// ToModel returns a pending payment of the total of the order.
+func (f *PaymentIntentForm) ToModel(order *Order, provider string) *PaymentIntent {
+	return &PaymentIntent{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID: order.MerchantID,
+		OrderID:    order.ID,
+		Provider:   provider,
+		Amount:     order.Total,
+		Currency:   order.Currency,
+		Status:     PaymentIntentStatusPending,
+	}
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"strings"
+
+	"merchant/util/cryptoutil"
+)
+
+const (
+	PayoutAccountStatusPending  = "Pending"
+	PayoutAccountStatusVerified = "Verified"
+	PayoutAccountStatusFailed   = "Failed"
+)
+
+// PayoutAccount is a bank account payouts of a merchant are sent to. It is
+// identified either by an IBAN or by a local account number and sort code,
+// which are encrypted at rest.
+type PayoutAccount struct {
+	Model
+	MerchantID        string `gorm:"index"`
+	AccountHolderName string
+	Currency          string
+	IBAN              cryptoutil.EncryptedString `gorm:"size:255"`
+	AccountNumber     cryptoutil.EncryptedString `gorm:"size:255"`
+	SortCode          cryptoutil.EncryptedString `gorm:"size:255"`
+	BIC               string
+	Status            string `gorm:"index"`
+}
+
+type PayoutAccounts []*PayoutAccount
+
+type PayoutAccountDto struct {
+	ID                string `json:"id"`
+	MerchantID        string `json:"merchantID"`
+	AccountHolderName string `json:"accountHolderName"`
+	Currency          string `json:"currency"`
+	IBAN              string `json:"iban,omitempty"`
+	AccountNumber     string `json:"accountNumber,omitempty"`
+	SortCode          string `json:"sortCode,omitempty"`
+	BIC               string `json:"bic,omitempty"`
+	Status            string `json:"status"`
+}
+
+// ToDto converts the account, masking all but the last digits of the
+// account identifiers.
+func (p PayoutAccount) ToDto() *PayoutAccountDto {
+	iban := ""
+	if p.IBAN != "" {
+		iban = p.IBAN.String()[:2] + mask(p.IBAN.String()[2:], 4)
+	}
+
+	return &PayoutAccountDto{
+		ID:                p.ID,
+		MerchantID:        p.MerchantID,
+		AccountHolderName: p.AccountHolderName,
+		Currency:          p.Currency,
+		IBAN:              iban,
+		AccountNumber:     mask(p.AccountNumber.String(), 4),
+		SortCode:          mask(p.SortCode.String(), 2),
+		BIC:               p.BIC,
+		Status:            p.Status,
+	}
+}
+
+type PayoutAccountDtos []*PayoutAccountDto
+
+func (ps PayoutAccounts) ToDto() PayoutAccountDtos {
+	result := make([]*PayoutAccountDto, len(ps))
+	for k, v := range ps {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
+
+// mask replaces all but the last visible characters of s with asterisks.
+func mask(s string, visible int) string {
+	if len(s) <= visible {
+		return s
+	}
+
+	return strings.Repeat("*", len(s)-visible) + s[len(s)-visible:]
+}
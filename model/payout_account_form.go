@@ -0,0 +1,50 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"merchant/util/cryptoutil"
+)
+
+type PayoutAccountForm struct {
+	AccountHolderName string `json:"accountHolderName" form:"required,max=255"`
+	Currency          string `json:"currency" form:"required,iso4217"`
+	IBAN              string `json:"iban" form:"required_without=AccountNumber,omitempty,iban"`
+	AccountNumber     string `json:"accountNumber" form:"required_without=IBAN,omitempty,numeric,min=6,max=17"`
+	SortCode          string `json:"sortCode" form:"required_with=AccountNumber,omitempty,sortcode"`
+	BIC               string `json:"bic" form:"omitempty,bic"`
+}
+
+type PayoutAccountStatusForm struct {
+	Status string `json:"status" form:"required,oneof=Verified Failed"`
+}
+
+// Normalize removes the spaces and dashes people commonly write bank
+// details with and upper-cases the codes, ahead of validation.
+func (f *PayoutAccountForm) Normalize() {
+	strip := strings.NewReplacer(" ", "", "-", "")
+
+	f.IBAN = strings.ToUpper(strip.Replace(f.IBAN))
+	f.AccountNumber = strip.Replace(f.AccountNumber)
+	f.SortCode = strip.Replace(f.SortCode)
+	f.BIC = strings.ToUpper(strip.Replace(f.BIC))
+	f.Currency = strings.ToUpper(f.Currency)
+}
+
+func (f *PayoutAccountForm) ToModel(merchantId string) *PayoutAccount {
+	return &PayoutAccount{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID:        merchantId,
+		AccountHolderName: f.AccountHolderName,
+		Currency:          f.Currency,
+		IBAN:              cryptoutil.EncryptedString(f.IBAN),
+		AccountNumber:     cryptoutil.EncryptedString(f.AccountNumber),
+		SortCode:          cryptoutil.EncryptedString(f.SortCode),
+		BIC:               f.BIC,
+		Status:            PayoutAccountStatusPending,
+	}
+}
@@ -0,0 +1,22 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/model"
+)
+
+func TestPayoutAccountToDtoMasksIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	iban := &model.PayoutAccount{IBAN: "GB82WEST12345698765432", BIC: "NWBKGB2L"}
+	local := &model.PayoutAccount{AccountNumber: "31926819", SortCode: "401276"}
+
+	assert.Equal(t, "GB****************5432", iban.ToDto().IBAN)
+	assert.Equal(t, "", iban.ToDto().AccountNumber)
+	assert.Equal(t, "****6819", local.ToDto().AccountNumber)
+	assert.Equal(t, "****76", local.ToDto().SortCode)
+	assert.Equal(t, "", local.ToDto().IBAN)
+}
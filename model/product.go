@@ -0,0 +1,101 @@
+package model
+
+const (
+	TaxCategoryStandard = "standard"
+	TaxCategoryReduced  = "reduced"
+	TaxCategoryZero     = "zero"
+	TaxCategoryExempt   = "exempt"
+)
+
+type Product struct {
+	Model
+	MerchantID  string `gorm:"index"`
+	Name        string
+	Description string
+	TaxCategory string
+	Active      bool
+	Variants    []*ProductVariant `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type Products []*Product
+
+// ProductVariant is a sellable version of a product, such as a size or a
+// colour. SKUs are unique within a merchant.
+type ProductVariant struct {
+	Model
+	ProductID  string `gorm:"index"`
+	MerchantID string `gorm:"uniqueIndex:idx_product_variants_merchant_sku;size:64"`
+	SKU        string `gorm:"uniqueIndex:idx_product_variants_merchant_sku;size:64"`
+	Name       string
+	// Price is in the minor unit of the currency, e.g. cents.
+	Price    int64
+	Currency string
+	Active   bool
+}
+
+type ProductDto struct {
+	ID          string               `json:"id"`
+	MerchantID  string               `json:"merchantID"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	TaxCategory string               `json:"taxCategory"`
+	Active      bool                 `json:"active"`
+	Variants    []*ProductVariantDto `json:"variants"`
+}
+
+type ProductVariantDto struct {
+	ID       string `json:"id"`
+	SKU      string `json:"sku"`
+	Name     string `json:"name"`
+	Price    int64  `json:"price"`
+	Currency string `json:"currency"`
+	Active   bool   `json:"active"`
+}
+
+func (p Product) ToDto() *ProductDto {
+	variants := make([]*ProductVariantDto, len(p.Variants))
+	for k, v := range p.Variants {
+		variants[k] = v.ToDto()
+	}
+
+	return &ProductDto{
+		ID:          p.ID,
+		MerchantID:  p.MerchantID,
+		Name:        p.Name,
+		Description: p.Description,
+		TaxCategory: p.TaxCategory,
+		Active:      p.Active,
+		Variants:    variants,
+	}
+}
+
+func (v ProductVariant) ToDto() *ProductVariantDto {
+	return &ProductVariantDto{
+		ID:       v.ID,
+		SKU:      v.SKU,
+		Name:     v.Name,
+		Price:    v.Price,
+		Currency: v.Currency,
+		Active:   v.Active,
+	}
+}
+
+type ProductDtos []*ProductDto
+
+func (ps Products) ToDto() ProductDtos {
+	result := make([]*ProductDto, len(ps))
+	for k, v := range ps {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
+
+// ProductSearch narrows down the products of a merchant. Query matches the
+// product name or the exact SKU of one of its variants.
+type ProductSearch struct {
+	Query  string
+	Active *bool
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,91 @@
+package model
+
+import "github.com/google/uuid"
+
+type ProductCreateForm struct {
+	Name        string                `json:"name" form:"required,max=255"`
+	Description string                `json:"description" form:"max=4096"`
+	TaxCategory string                `json:"taxCategory" form:"required,oneof=standard reduced zero exempt"`
+	Active      bool                  `json:"active"`
+	Variants    []*ProductVariantForm `json:"variants" form:"dive"`
+}
+
+type ProductUpdateForm struct {
+	Name        string `json:"name" form:"required,max=255"`
+	Description string `json:"description" form:"max=4096"`
+	TaxCategory string `json:"taxCategory" form:"required,oneof=standard reduced zero exempt"`
+	Active      bool   `json:"active"`
+}
+
+type ProductVariantForm struct {
+	SKU      string `json:"sku" form:"required,max=64"`
+	Name     string `json:"name" form:"max=255"`
+	Price    int64  `json:"price" form:"min=0,max=1000000000000"`
+	Currency string `json:"currency" form:"required,iso4217"`
+	Active   bool   `json:"active"`
+}
+
+// HasUniqueSkus reports whether no two variants of the form share a SKU.
+func (f *ProductCreateForm) HasUniqueSkus() bool {
+	seen := make(map[string]bool, len(f.Variants))
+	for _, v := range f.Variants {
+		if seen[v.SKU] {
+			return false
+		}
+		seen[v.SKU] = true
+	}
+
+	return true
+}
+
+func (f *ProductCreateForm) ToModel(merchantId string) *Product {
+	id := uuid.New().String()
+
+	variants := make([]*ProductVariant, len(f.Variants))
+	for k, v := range f.Variants {
+		variants[k] = v.ToModel(id, merchantId)
+	}
+
+	return &Product{
+		Model: Model{
+			ID: id,
+		},
+		MerchantID:  merchantId,
+		Name:        f.Name,
+		Description: f.Description,
+		TaxCategory: f.TaxCategory,
+		Active:      f.Active,
+		Variants:    variants,
+	}
+}
+
+func (f *ProductUpdateForm) ToModel(id string) *Product {
+	return &Product{
+		Model: Model{
+			ID: id,
+		},
+		Name:        f.Name,
+		Description: f.Description,
+		TaxCategory: f.TaxCategory,
+		Active:      f.Active,
+	}
+}
+
+func (f *ProductVariantForm) ToModel(productId, merchantId string) *ProductVariant {
+	return f.ToModelWithId(uuid.New().String(), productId, merchantId)
+}
+
+func (f *ProductVariantForm) ToModelWithId(id, productId, merchantId string) *ProductVariant {
+	return &ProductVariant{
+		Model: Model{
+			ID: id,
+		},
+		ProductID:  productId,
+		MerchantID: merchantId,
+		SKU:        f.SKU,
+		Name:       f.Name,
+		Price:      f.Price,
+		Currency:   f.Currency,
+		Active:     f.Active,
+	}
+}
@@ -0,0 +1,46 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/model"
+)
+
+func TestProductCreateFormHasUniqueSkus(t *testing.T) {
+	t.Parallel()
+
+	form := &model.ProductCreateForm{
+		Variants: []*model.ProductVariantForm{{SKU: "TS-S"}, {SKU: "TS-M"}},
+	}
+	assert.True(t, form.HasUniqueSkus())
+
+	form.Variants = append(form.Variants, &model.ProductVariantForm{SKU: "TS-S"})
+	assert.False(t, form.HasUniqueSkus())
+}
+
+func TestProductCreateFormToModelLinksVariants(t *testing.T) {
+	t.Parallel()
+
+	form := &model.ProductCreateForm{
+		Name:        "T-shirt",
+		TaxCategory: model.TaxCategoryStandard,
+		Variants: []*model.ProductVariantForm{
+			{SKU: "TS-S", Price: 1999, Currency: "EUR"},
+			{SKU: "TS-M", Price: 2199, Currency: "EUR"},
+		},
+	}
+
+	product := form.ToModel("merchant-1")
+
+	assert.NotEmpty(t, product.ID)
+	assert.Len(t, product.Variants, 2)
+	for _, v := range product.Variants {
+		assert.NotEmpty(t, v.ID)
+		assert.Equal(t, product.ID, v.ProductID)
+		assert.Equal(t, "merchant-1", v.MerchantID)
+	}
+	assert.NotEqual(t, product.Variants[0].ID, product.Variants[1].ID)
+	assert.Equal(t, int64(2199), product.ToDto().Variants[1].Price)
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+const (
+	RefundReasonRequestedByCustomer = "requested_by_customer"
+	RefundReasonDuplicate           = "duplicate"
+	RefundReasonFraudulent          = "fraudulent"
+	RefundReasonProductUnavailable  = "product_unavailable"
+	RefundReasonOther               = "other"
+)
+
+// ErrRefundExceedsBalance is returned when a refund is larger than the part
+// of the payment that can still be refunded.
+var ErrRefundExceedsBalance = errors.New("refund exceeds refundable balance")
+
+// Refund returns part or all of a captured payment to the customer.
+type Refund struct {
+	Model
+	MerchantID        string `gorm:"index"`
+	PaymentIntentID   string `gorm:"index"`
+	OrderID           string
+	Amount            int64
+	Currency          string
+	ReasonCode        string
+	Note              string
+	Status            string
+	ProviderReference string
+	FailureReason     string
+}
+
+type Refunds []*Refund
+
+type RefundDto struct {
+	ID                string     `json:"id"`
+	PaymentIntentID   string     `json:"paymentID"`
+	OrderID           string     `json:"orderID"`
+	Amount            int64      `json:"amount"`
+	Currency          string     `json:"currency"`
+	ReasonCode        string     `json:"reasonCode"`
+	Note              string     `json:"note,omitempty"`
+	Status            string     `json:"status"`
+	ProviderReference string     `json:"providerReference,omitempty"`
+	FailureReason     string     `json:"failureReason,omitempty"`
+	CreatedAt         *time.Time `json:"createdAt"`
+}
+
+func (r Refund) ToDto() *RefundDto {
+	return &RefundDto{
+		ID:                r.ID,
+		PaymentIntentID:   r.PaymentIntentID,
+		OrderID:           r.OrderID,
+		Amount:            r.Amount,
+		Currency:          r.Currency,
+		ReasonCode:        r.ReasonCode,
+		Note:              r.Note,
+		Status:            r.Status,
+		ProviderReference: r.ProviderReference,
+		FailureReason:     r.FailureReason,
+		CreatedAt:         r.CreatedAt,
+	}
+}
+
+type RefundDtos []*RefundDto
+
+func (rs Refunds) ToDto() RefundDtos {
+	result := make([]*RefundDto, len(rs))
+	for k, v := range rs {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
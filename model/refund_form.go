@@ -0,0 +1,32 @@
+package model
+
+import "github.com/google/uuid"
+
+type RefundForm struct {
+	// Amount defaults to everything that can still be refunded when zero.
+	Amount     int64  `json:"amount" form:"min=0"`
+	ReasonCode string `json:"reasonCode" form:"required,oneof=requested_by_customer duplicate fraudulent product_unavailable other"`
+	Note       string `json:"note" form:"max=1024"`
+}
+
+// ToModel returns a pending refund against the payment.
+func (f *RefundForm) ToModel(p *PaymentIntent) *Refund {
+	amount := f.Amount
+	if amount == 0 {
+		amount = p.RefundableAmount()
+	}
+
+	return &Refund{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID:      p.MerchantID,
+		PaymentIntentID: p.ID,
+		OrderID:         p.OrderID,
+		Amount:          amount,
+		Currency:        p.Currency,
+		ReasonCode:      f.ReasonCode,
+		Note:            f.Note,
+		Status:          RefundStatusPending,
+	}
+}
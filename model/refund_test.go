@@ -0,0 +1,32 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/model"
+)
+
+func TestRefundFormToModelDefaultsToRefundableAmount(t *testing.T) {
+	t.Parallel()
+
+	payment := &model.PaymentIntent{CapturedAmount: 2500, RefundedAmount: 1000, Currency: "EUR"}
+
+	full := (&model.RefundForm{ReasonCode: model.RefundReasonDuplicate}).ToModel(payment)
+	assert.Equal(t, int64(1500), full.Amount)
+	assert.Equal(t, model.RefundStatusPending, full.Status)
+	assert.Equal(t, "EUR", full.Currency)
+
+	partial := (&model.RefundForm{Amount: 300, ReasonCode: model.RefundReasonOther}).ToModel(payment)
+	assert.Equal(t, int64(300), partial.Amount)
+}
+
+func TestPaymentIntentRefundStatus(t *testing.T) {
+	t.Parallel()
+
+	payment := &model.PaymentIntent{CapturedAmount: 2500, RefundedAmount: 2500}
+	assert.Equal(t, model.PaymentIntentStatusCaptured, payment.RefundStatus(0))
+	assert.Equal(t, model.PaymentIntentStatusPartiallyRefunded, payment.RefundStatus(1000))
+	assert.Equal(t, model.PaymentIntentStatusRefunded, payment.RefundStatus(2500))
+}
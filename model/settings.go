@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+const (
+	DefaultCurrency = "USD"
+	DefaultLocale   = "en-US"
+	DefaultTimezone = "UTC"
+)
+
+// MerchantSettings holds how the platform behaves for a merchant.
+type MerchantSettings struct {
+	MerchantID    string `gorm:"primaryKey;size:36"`
+	Currency      string
+	Locale        string
+	Timezone      string
+	Notifications NotificationPreferences `gorm:"embedded;embeddedPrefix:notify_"`
+	CreatedAt     *time.Time
+	UpdatedAt     *time.Time
+}
+
+type NotificationPreferences struct {
+	OrderPlaced     bool `json:"orderPlaced"`
+	PaymentReceived bool `json:"paymentReceived"`
+	PayoutSent      bool `json:"payoutSent"`
+	WeeklySummary   bool `json:"weeklySummary"`
+}
+
+// DefaultMerchantSettings returns the settings a merchant starts with.
+func DefaultMerchantSettings(merchantId string) *MerchantSettings {
+	return &MerchantSettings{
+		MerchantID: merchantId,
+		Currency:   DefaultCurrency,
+		Locale:     DefaultLocale,
+		Timezone:   DefaultTimezone,
+		Notifications: NotificationPreferences{
+			OrderPlaced:     true,
+			PaymentReceived: true,
+			PayoutSent:      true,
+			WeeklySummary:   false,
+		},
+	}
+}
+
+type MerchantSettingsDto struct {
+	Currency      string                  `json:"currency"`
+	Locale        string                  `json:"locale"`
+	Timezone      string                  `json:"timezone"`
+	Notifications NotificationPreferences `json:"notifications"`
+}
+
+func (s MerchantSettings) ToDto() *MerchantSettingsDto {
+	return &MerchantSettingsDto{
+		Currency:      s.Currency,
+		Locale:        s.Locale,
+		Timezone:      s.Timezone,
+		Notifications: s.Notifications,
+	}
+}
@@ -0,0 +1,18 @@
+package model
+
+type MerchantSettingsForm struct {
+	Currency      string                  `json:"currency" form:"required,iso4217"`
+	Locale        string                  `json:"locale" form:"required,locale"`
+	Timezone      string                  `json:"timezone" form:"required,timezone"`
+	Notifications NotificationPreferences `json:"notifications"`
+}
+
+func (f *MerchantSettingsForm) ToModel(merchantId string) *MerchantSettings {
+	return &MerchantSettings{
+		MerchantID:    merchantId,
+		Currency:      f.Currency,
+		Locale:        f.Locale,
+		Timezone:      f.Timezone,
+		Notifications: f.Notifications,
+	}
+}
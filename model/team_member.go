@@ -1,36 +1,62 @@
 package model
 
+import (
+	"gorm.io/gorm"
+
+	"merchant/util/cryptoutil"
+)
+
 type TeamMember struct {
 	Model
 	IsOwner    bool
-	GivenName  string
-	FamilyName string
-	Email      string
+	GivenName  cryptoutil.EncryptedString
+	FamilyName cryptoutil.EncryptedString
+	Email      cryptoutil.EncryptedString
+	// EmailIndex is the blind index of the email, which members are looked
+	// up by.
+	EmailIndex string `gorm:"size:64;index"`
 	Status     string
 	MerchantID string
+	Metadata   Metadata `gorm:"type:json"`
+}
+
+// BeforeSave keeps the blind index of the email in step with the email.
+func (t *TeamMember) BeforeSave(tx *gorm.DB) error {
+	if t.Email == "" {
+		return nil
+	}
+
+	index, err := EmailIndex(t.Email.String())
+	if err != nil {
+		return err
+	}
+	t.EmailIndex = index
+	return nil
 }
 
 type TeamMembers []*TeamMember
 
 type TeamMemberDto struct {
-	ID         string `json:"id"`
-	IsOwner    bool   `json:"isOwner"`
-	GivenName  string `json:"givenName"`
-	FamilyName string `json:"familyName"`
-	Email      string `json:"email"`
-	Status     string `json:"status"`
-	MerchantID string `json:"merchantID"`
+	ID         string   `json:"id"`
+	IsOwner    bool     `json:"isOwner"`
+	GivenName  string   `json:"givenName"`
+	FamilyName string   `json:"familyName"`
+	Email      string   `json:"email"`
+	Status     string   `json:"status"`
+	MerchantID string   `json:"merchantID"`
+	Metadata   Metadata `json:"metadata"`
 }
 
 func (t TeamMember) ToDto() *TeamMemberDto {
 	return &TeamMemberDto{
 		ID:         t.ID,
 		IsOwner:    t.IsOwner,
-		GivenName:  t.GivenName,
-		FamilyName: t.FamilyName,
-		Email:      t.Email,
+		GivenName:  t.GivenName.String(),
+		FamilyName: t.FamilyName.String(),
+		Email:      t.Email.String(),
 		Status:     t.Status,
 		MerchantID: t.MerchantID,
+		Metadata:   t.Metadata.ToDto(),
 	}
 }
 
@@ -1,18 +1,26 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+
+	"merchant/util/cryptoutil"
+)
 
 type TeamMemberCreateForm struct {
-	IsOwner    bool   `json:"isOwner" form:"required"`
-	GivenName  string `json:"givenName" form:"required"`
-	FamilyName string `json:"familyName" form:"required"`
-	Email      string `json:"email" form:"required"`
+	IsOwner    bool     `json:"isOwner"`
+	GivenName  string   `json:"givenName" form:"required,max=255"`
+	FamilyName string   `json:"familyName" form:"required,max=255"`
+	Email      string   `json:"email" form:"required,email,max=255"`
+	Metadata   Metadata `json:"metadata" form:"omitempty,metadata"`
 }
 
 type TeamMemberUpdateForm struct {
 	IsOwner    bool   `json:"isOwner" form:"required"`
 	GivenName  string `json:"givenName" form:"required"`
 	FamilyName string `json:"familyName" form:"required"`
+	// Metadata replaces the metadata of the member; leaving it out keeps
+	// the metadata as it is.
+	Metadata Metadata `json:"metadata" form:"omitempty,metadata"`
 }
 
 func (f *TeamMemberCreateForm) ToModel(merchantId string) *TeamMember {
@@ -22,11 +30,12 @@ func (f *TeamMemberCreateForm) ToModel(merchantId string) *TeamMember {
 			ID: id,
 		},
 		IsOwner:    f.IsOwner,
-		GivenName:  f.GivenName,
-		FamilyName: f.FamilyName,
-		Email:      f.Email,
+		GivenName:  cryptoutil.EncryptedString(f.GivenName),
+		FamilyName: cryptoutil.EncryptedString(f.FamilyName),
+		Email:      cryptoutil.EncryptedString(f.Email),
 		Status:     "Active",
 		MerchantID: merchantId,
+		Metadata:   f.Metadata,
 	}
 }
 
@@ -36,7 +45,8 @@ func (f *TeamMemberUpdateForm) ToModel(id string) *TeamMember {
 			ID: id,
 		},
 		IsOwner:    f.IsOwner,
-		GivenName:  f.GivenName,
-		FamilyName: f.FamilyName,
+		GivenName:  cryptoutil.EncryptedString(f.GivenName),
+		FamilyName: cryptoutil.EncryptedString(f.FamilyName),
+		Metadata:   f.Metadata,
 	}
 }
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	TeamMemberImportFormatCSV  = "csv"
+	TeamMemberImportFormatXLSX = "xlsx"
+
+	// TeamMemberImportModeAllOrNothing imports no member unless every row
+	// is valid.
+	TeamMemberImportModeAllOrNothing = "all_or_nothing"
+	// TeamMemberImportModeBestEffort imports the valid rows and reports the
+	// others.
+	TeamMemberImportModeBestEffort = "best_effort"
+
+	TeamMemberImportStatusPending   = "pending"
+	TeamMemberImportStatusRunning   = "running"
+	TeamMemberImportStatusSucceeded = "succeeded"
+	TeamMemberImportStatusFailed    = "failed"
+)
+
+// TeamMemberImport is a file of team members imported for a merchant. Small
+// files are imported while the upload waits; larger ones are kept in blob
+// storage and imported in the background.
+type TeamMemberImport struct {
+	Model
+	MerchantID string `gorm:"index"`
+	Format     string
+	FileName   string
+	BlobKey    string
+	Mode       string
+	// DryRun validates the rows without importing any.
+	DryRun       bool
+	Status       string `gorm:"index"`
+	TotalRows    int
+	ValidRows    int
+	ImportedRows int
+	// RowErrors is the JSON encoded list of the errors of invalid rows.
+	RowErrors  string `gorm:"type:mediumtext"`
+	Error      string
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+type TeamMemberImports []*TeamMemberImport
+
+// TeamMemberImportRowError lists the problems of a row of the file. Lines
+// are numbered as in the file, the header being line 1.
+type TeamMemberImportRowError struct {
+	Line   int      `json:"line"`
+	Errors []string `json:"errors"`
+}
+
+// Finish records the outcome of the import.
+func (i *TeamMemberImport) Finish(at time.Time, rowErrors []*TeamMemberImportRowError, err error) {
+	i.FinishedAt = &at
+	i.Status = TeamMemberImportStatusSucceeded
+	if err != nil {
+		i.Status = TeamMemberImportStatusFailed
+		i.Error = err.Error()
+	}
+
+	i.RowErrors = ""
+	if len(rowErrors) > 0 {
+		b, _ := json.Marshal(rowErrors)
+		i.RowErrors = string(b)
+	}
+}
+
+type TeamMemberImportDto struct {
+	ID           string                      `json:"id"`
+	FileName     string                      `json:"fileName"`
+	Format       string                      `json:"format"`
+	Mode         string                      `json:"mode"`
+	DryRun       bool                        `json:"dryRun"`
+	Status       string                      `json:"status"`
+	TotalRows    int                         `json:"totalRows"`
+	ValidRows    int                         `json:"validRows"`
+	ImportedRows int                         `json:"importedRows"`
+	RowErrors    []*TeamMemberImportRowError `json:"rowErrors"`
+	Error        string                      `json:"error,omitempty"`
+	CreatedAt    *time.Time                  `json:"createdAt"`
+	StartedAt    *time.Time                  `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time                  `json:"finishedAt,omitempty"`
+}
+
+func (i TeamMemberImport) ToDto() *TeamMemberImportDto {
+	rowErrors := make([]*TeamMemberImportRowError, 0)
+	if i.RowErrors != "" {
+		_ = json.Unmarshal([]byte(i.RowErrors), &rowErrors)
+	}
+
+	return &TeamMemberImportDto{
+		ID:           i.ID,
+		FileName:     i.FileName,
+		Format:       i.Format,
+		Mode:         i.Mode,
+		DryRun:       i.DryRun,
+		Status:       i.Status,
+		TotalRows:    i.TotalRows,
+		ValidRows:    i.ValidRows,
+		ImportedRows: i.ImportedRows,
+		RowErrors:    rowErrors,
+		Error:        i.Error,
+		CreatedAt:    i.CreatedAt,
+		StartedAt:    i.StartedAt,
+		FinishedAt:   i.FinishedAt,
+	}
+}
+
+// TeamMemberImportForm holds the options of an import, sent as query
+// parameters along with the file.
+type TeamMemberImportForm struct {
+	Mode   string `json:"mode" form:"oneof=all_or_nothing best_effort"`
+	DryRun bool   `json:"dryRun"`
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	"merchant/util/cryptoutil"
+)
+
+// TeamMemberVersion is the team member as it was at one of its versions.
+// The repository records one on every write of the member.
+type TeamMemberVersion struct {
+	ID           string `gorm:"primaryKey;size:36"`
+	TeamMemberID string `gorm:"size:36;index"`
+	MerchantID   string `gorm:"size:36;index"`
+	Version      int64
+	IsOwner      bool
+	GivenName    cryptoutil.EncryptedString
+	FamilyName   cryptoutil.EncryptedString
+	Email        cryptoutil.EncryptedString
+	Status       string
+	Metadata     Metadata `gorm:"type:json"`
+	// CreatedAt is when the member took on the version.
+	CreatedAt *time.Time `gorm:"index"`
+}
+
+type TeamMemberVersions []*TeamMemberVersion
+
+// ToTeamMember returns the team member at the version.
+func (v TeamMemberVersion) ToTeamMember() *TeamMember {
+	return &TeamMember{
+		Model: Model{
+			ID:        v.TeamMemberID,
+			UpdatedAt: v.CreatedAt,
+			Version:   v.Version,
+		},
+		IsOwner:    v.IsOwner,
+		GivenName:  v.GivenName,
+		FamilyName: v.FamilyName,
+		Email:      v.Email,
+		Status:     v.Status,
+		MerchantID: v.MerchantID,
+		Metadata:   v.Metadata,
+	}
+}
+
+type TeamMemberVersionDto struct {
+	Version    int64          `json:"version"`
+	CreatedAt  *time.Time     `json:"createdAt"`
+	TeamMember *TeamMemberDto `json:"teamMember"`
+}
+
+func (v TeamMemberVersion) ToDto() *TeamMemberVersionDto {
+	return &TeamMemberVersionDto{
+		Version:    v.Version,
+		CreatedAt:  v.CreatedAt,
+		TeamMember: v.ToTeamMember().ToDto(),
+	}
+}
+
+type TeamMemberVersionDtos []*TeamMemberVersionDto
+
+func (vs TeamMemberVersions) ToDto() TeamMemberVersionDtos {
+	result := make([]*TeamMemberVersionDto, len(vs))
+	for k, v := range vs {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
@@ -0,0 +1,149 @@
+package model
+
+import "time"
+
+const (
+	VerificationStatusSubmitted = "Submitted"
+	VerificationStatusInReview  = "InReview"
+	VerificationStatusApproved  = "Approved"
+	VerificationStatusRejected  = "Rejected"
+)
+
+// verificationTransitions lists the statuses a reviewer may move a
+// verification case to from its current status. Rejected cases go back to
+// Submitted only when the merchant resubmits them.
+var verificationTransitions = map[string][]string{
+	VerificationStatusSubmitted: {VerificationStatusInReview, VerificationStatusRejected},
+	VerificationStatusInReview:  {VerificationStatusApproved, VerificationStatusRejected},
+}
+
+// Verification is the business verification (KYC) case of a merchant.
+type Verification struct {
+	Model
+	MerchantID         string `gorm:"uniqueIndex;size:36"`
+	LegalName          string
+	RegistrationNumber string
+	AddressLine1       string
+	AddressLine2       string
+	City               string
+	Region             string
+	PostalCode         string
+	CountryCode        string
+	Status             string `gorm:"index"`
+	ReviewerNotes      string
+	ReviewedBy         string
+	SubmittedAt        *time.Time
+	ReviewedAt         *time.Time
+	Documents          []*VerificationDocument `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type Verifications []*Verification
+
+type VerificationDocument struct {
+	Model
+	VerificationID string `gorm:"index"`
+	Kind           string
+	FileName       string
+	ContentType    string
+	Size           int64
+	BlobKey        string
+}
+
+// IsApproved reports whether the merchant may use payment features.
+func (v Verification) IsApproved() bool {
+	return v.Status == VerificationStatusApproved
+}
+
+// CanBeResubmitted reports whether the merchant may still change the
+// details of the case, which is only the case before a review starts or
+// after it was rejected.
+func (v Verification) CanBeResubmitted() bool {
+	return v.Status == VerificationStatusSubmitted || v.Status == VerificationStatusRejected
+}
+
+// CanTransitionTo reports whether a reviewer may move the case to status.
+func (v Verification) CanTransitionTo(status string) bool {
+	for _, s := range verificationTransitions[v.Status] {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+type VerificationDto struct {
+	ID                 string                     `json:"id"`
+	MerchantID         string                     `json:"merchantID"`
+	LegalName          string                     `json:"legalName"`
+	RegistrationNumber string                     `json:"registrationNumber"`
+	AddressLine1       string                     `json:"addressLine1"`
+	AddressLine2       string                     `json:"addressLine2"`
+	City               string                     `json:"city"`
+	Region             string                     `json:"region"`
+	PostalCode         string                     `json:"postalCode"`
+	CountryCode        string                     `json:"countryCode"`
+	Status             string                     `json:"status"`
+	ReviewerNotes      string                     `json:"reviewerNotes"`
+	ReviewedBy         string                     `json:"reviewedBy"`
+	SubmittedAt        *time.Time                 `json:"submittedAt"`
+	ReviewedAt         *time.Time                 `json:"reviewedAt"`
+	Documents          []*VerificationDocumentDto `json:"documents"`
+}
+
+type VerificationDocumentDto struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	FileName    string     `json:"fileName"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`
+	UploadedAt  *time.Time `json:"uploadedAt"`
+}
+
+func (v Verification) ToDto() *VerificationDto {
+	documents := make([]*VerificationDocumentDto, len(v.Documents))
+	for k, d := range v.Documents {
+		documents[k] = d.ToDto()
+	}
+
+	return &VerificationDto{
+		ID:                 v.ID,
+		MerchantID:         v.MerchantID,
+		LegalName:          v.LegalName,
+		RegistrationNumber: v.RegistrationNumber,
+		AddressLine1:       v.AddressLine1,
+		AddressLine2:       v.AddressLine2,
+		City:               v.City,
+		Region:             v.Region,
+		PostalCode:         v.PostalCode,
+		CountryCode:        v.CountryCode,
+		Status:             v.Status,
+		ReviewerNotes:      v.ReviewerNotes,
+		ReviewedBy:         v.ReviewedBy,
+		SubmittedAt:        v.SubmittedAt,
+		ReviewedAt:         v.ReviewedAt,
+		Documents:          documents,
+	}
+}
+
+func (d VerificationDocument) ToDto() *VerificationDocumentDto {
+	return &VerificationDocumentDto{
+		ID:          d.ID,
+		Kind:        d.Kind,
+		FileName:    d.FileName,
+		ContentType: d.ContentType,
+		Size:        d.Size,
+		UploadedAt:  d.CreatedAt,
+	}
+}
+
+type VerificationDtos []*VerificationDto
+
+func (vs Verifications) ToDto() VerificationDtos {
+	result := make([]*VerificationDto, len(vs))
+	for k, v := range vs {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	VerificationDocumentBusinessRegistration = "BusinessRegistration"
+	VerificationDocumentProofOfAddress       = "ProofOfAddress"
+	VerificationDocumentIdentity             = "Identity"
+)
+
+type VerificationForm struct {
+	LegalName          string `json:"legalName" form:"required,max=255"`
+	RegistrationNumber string `json:"registrationNumber" form:"required,max=64"`
+	AddressLine1       string `json:"addressLine1" form:"required,max=255"`
+	AddressLine2       string `json:"addressLine2" form:"max=255"`
+	City               string `json:"city" form:"required,max=255"`
+	Region             string `json:"region" form:"max=255"`
+	PostalCode         string `json:"postalCode" form:"max=32"`
+	CountryCode        string `json:"countryCode" form:"required,iso3166_1_alpha2"`
+}
+
+type VerificationDocumentForm struct {
+	Kind string `json:"kind" form:"required,oneof=BusinessRegistration ProofOfAddress Identity"`
+}
+
+type VerificationReviewForm struct {
+	Status        string `json:"status" form:"required,oneof=InReview Approved Rejected"`
+	ReviewerNotes string `json:"reviewerNotes" form:"max=2000"`
+	ReviewedBy    string `json:"reviewedBy" form:"required,max=255"`
+}
+
+// ToModel returns a newly submitted verification case for the merchant.
+func (f *VerificationForm) ToModel(merchantId string) *Verification {
+	now := time.Now()
+	return &Verification{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		MerchantID:         merchantId,
+		LegalName:          f.LegalName,
+		RegistrationNumber: f.RegistrationNumber,
+		AddressLine1:       f.AddressLine1,
+		AddressLine2:       f.AddressLine2,
+		City:               f.City,
+		Region:             f.Region,
+		PostalCode:         f.PostalCode,
+		CountryCode:        f.CountryCode,
+		Status:             VerificationStatusSubmitted,
+		SubmittedAt:        &now,
+	}
+}
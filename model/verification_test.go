@@ -0,0 +1,39 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/model"
+)
+
+type verificationTransitionTestCase struct {
+	from     string
+	to       string
+	expected bool
+}
+
+var verificationTransitionTests = []*verificationTransitionTestCase{
+	{from: model.VerificationStatusSubmitted, to: model.VerificationStatusInReview, expected: true},
+	{from: model.VerificationStatusSubmitted, to: model.VerificationStatusRejected, expected: true},
+	{from: model.VerificationStatusSubmitted, to: model.VerificationStatusApproved, expected: false},
+	{from: model.VerificationStatusInReview, to: model.VerificationStatusApproved, expected: true},
+	{from: model.VerificationStatusInReview, to: model.VerificationStatusRejected, expected: true},
+	{from: model.VerificationStatusInReview, to: model.VerificationStatusSubmitted, expected: false},
+	{from: model.VerificationStatusApproved, to: model.VerificationStatusRejected, expected: false},
+	{from: model.VerificationStatusRejected, to: model.VerificationStatusApproved, expected: false},
+}
+
+func TestVerificationCanTransitionTo(t *testing.T) {
+	for _, tc := range verificationTransitionTests {
+		tc := tc
+		t.Run(tc.from+" to "+tc.to, func(t *testing.T) {
+			t.Parallel()
+
+			v := &model.Verification{Status: tc.from}
+			actual := v.CanTransitionTo(tc.to)
+			assert.Equalf(t, tc.expected, actual, "Expected: %v, Actual: %v", tc.expected, actual)
+		})
+	}
+}
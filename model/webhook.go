@@ -0,0 +1,227 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"merchant/util/cryptoutil"
+)
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	// WebhookDeliveryStatusDead marks a delivery which failed on every
+	// attempt. It is only retried when redelivered manually.
+	WebhookDeliveryStatusDead = "dead"
+)
+
+// WebhookMaxAttempts is the number of attempts after which a failing
+// delivery is dead-lettered. With the backoff of WebhookRetryBackoff the
+// last attempt is made a little over four hours after the first.
+const WebhookMaxAttempts = 10
+
+// WebhookSubscription asks for events of the given types to be posted to a
+// URL of the merchant, signed with the secret.
+type WebhookSubscription struct {
+	Model
+	MerchantID string `gorm:"index"`
+	URL        string
+	// EventTypes is a comma separated list of event types.
+	EventTypes string
+	Secret     cryptoutil.EncryptedString `gorm:"size:255"`
+	Active     bool
+}
+
+type WebhookSubscriptions []*WebhookSubscription
+
+// WebhookDelivery is an event queued for, or delivered to, a subscription.
+// The payload is fixed when the delivery is queued so every attempt sends
+// the same body.
+type WebhookDelivery struct {
+	Model
+	SubscriptionID string `gorm:"index"`
+	MerchantID     string `gorm:"index"`
+	EventID        string
+	EventType      string
+	// Payload is stored encrypted, like the payload of the outbox event it
+	// is made of.
+	Payload        cryptoutil.EncryptedString `gorm:"type:text"`
+	Status         string                     `gorm:"index:idx_webhook_deliveries_due"`
+	Attempts       int
+	NextAttemptAt  *time.Time `gorm:"index:idx_webhook_deliveries_due"`
+	LastAttemptAt  *time.Time
+	ResponseStatus int
+	LastError      string
+}
+
+type WebhookDeliveries []*WebhookDelivery
+
+// WebhookEvent is the JSON body posted to subscribers.
+type WebhookEvent struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	MerchantID string      `json:"merchantID"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	Data       interface{} `json:"data"`
+}
+
+// Events returns the event types of the subscription.
+func (s WebhookSubscription) Events() []string {
+	if s.EventTypes == "" {
+		return nil
+	}
+
+	return strings.Split(s.EventTypes, ",")
+}
+
+// Subscribes reports whether the subscription wants events of the type.
+func (s WebhookSubscription) Subscribes(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+
+	for _, t := range s.Events() {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewDelivery queues the event for the subscription.
+func (s WebhookSubscription) NewDelivery(event *WebhookEvent, payload string, at time.Time) *WebhookDelivery {
+	return &WebhookDelivery{
+		Model: Model{
+			ID: uuid.New().String(),
+		},
+		SubscriptionID: s.ID,
+		MerchantID:     s.MerchantID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        cryptoutil.EncryptedString(payload),
+		Status:         WebhookDeliveryStatusPending,
+		NextAttemptAt:  &at,
+	}
+}
+
+// WebhookRetryBackoff returns how long to wait before retrying a delivery
+// after the given number of failed attempts: 30 seconds, doubling with
+// every attempt up to 6 hours.
+func WebhookRetryBackoff(attempts int) time.Duration {
+	const max = 6 * time.Hour
+
+	backoff := 30 * time.Second
+	for k := 1; k < attempts; k++ {
+		backoff *= 2
+		if backoff >= max {
+			return max
+		}
+	}
+
+	return backoff
+}
+
+// RecordAttempt records the outcome of an attempt made at the given time: a
+// 2xx response status completes the delivery, anything else schedules a
+// retry until the attempts run out and the delivery is dead-lettered.
+func (d *WebhookDelivery) RecordAttempt(at time.Time, responseStatus int, errMessage string) {
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.ResponseStatus = responseStatus
+	d.LastError = errMessage
+
+	switch {
+	case responseStatus >= 200 && responseStatus < 300:
+		d.Status = WebhookDeliveryStatusSucceeded
+		d.NextAttemptAt = nil
+	case d.Attempts >= WebhookMaxAttempts:
+		d.Status = WebhookDeliveryStatusDead
+		d.NextAttemptAt = nil
+	default:
+		next := at.Add(WebhookRetryBackoff(d.Attempts))
+		d.Status = WebhookDeliveryStatusPending
+		d.NextAttemptAt = &next
+	}
+}
+
+type WebhookSubscriptionDto struct {
+	ID         string   `json:"id"`
+	MerchantID string   `json:"merchantID"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Active     bool     `json:"active"`
+	// Secret is only returned when the subscription is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+func (s WebhookSubscription) ToDto() *WebhookSubscriptionDto {
+	events := s.Events()
+	if events == nil {
+		events = []string{}
+	}
+
+	return &WebhookSubscriptionDto{
+		ID:         s.ID,
+		MerchantID: s.MerchantID,
+		URL:        s.URL,
+		EventTypes: events,
+		Active:     s.Active,
+	}
+}
+
+type WebhookSubscriptionDtos []*WebhookSubscriptionDto
+
+func (ss WebhookSubscriptions) ToDto() WebhookSubscriptionDtos {
+	result := make([]*WebhookSubscriptionDto, len(ss))
+	for k, v := range ss {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
+
+type WebhookDeliveryDto struct {
+	ID             string     `json:"id"`
+	SubscriptionID string     `json:"subscriptionID"`
+	EventID        string     `json:"eventID"`
+	EventType      string     `json:"eventType"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int        `json:"responseStatus,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	CreatedAt      *time.Time `json:"createdAt"`
+}
+
+func (d WebhookDelivery) ToDto() *WebhookDeliveryDto {
+	return &WebhookDeliveryDto{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload.String(),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastAttemptAt:  d.LastAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+type WebhookDeliveryDtos []*WebhookDeliveryDto
+
+func (ds WebhookDeliveries) ToDto() WebhookDeliveryDtos {
+	result := make([]*WebhookDeliveryDto, len(ds))
+	for k, v := range ds {
+		result[k] = v.ToDto()
+	}
+
+	return result
+}
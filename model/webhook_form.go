@@ -0,0 +1,63 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"merchant/util/cryptoutil"
+	"merchant/util/netutil"
+)
+
+type WebhookSubscriptionForm struct {
+	URL        string   `json:"url" form:"required,url,max=2048"`
+	EventTypes []string `json:"eventTypes" form:"required,min=1,dive,oneof=merchant.updated merchant.deleted team_member.created team_member.updated team_member.deleted inventory.low_stock"`
+	// Secret signs the deliveries; one is generated when it is left empty.
+	Secret string `json:"secret" form:"omitempty,min=16,max=255"`
+	// Active defaults to true.
+	Active *bool `json:"active"`
+}
+
+// HasPublicHttpsURL reports whether the URL is an https URL of a host on
+// the public internet, so deliveries can not be aimed at internal services.
+// The addresses a host name resolves to are checked when delivering.
+func (f *WebhookSubscriptionForm) HasPublicHttpsURL() bool {
+	u, err := url.Parse(f.URL)
+	return err == nil && u.Scheme == "https" && netutil.IsPublicHost(u.Hostname())
+}
+
+func (f *WebhookSubscriptionForm) ToModel(merchantId string) (*WebhookSubscription, error) {
+	secret := f.Secret
+	if secret == "" {
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		secret = "whsec_" + hex.EncodeToString(b)
+	}
+
+	return f.ToModelWithId(uuid.New().String(), merchantId, secret), nil
+}
+
+// ToModelWithId returns the subscription signing with the given secret
+// rather than the one in the form, which only applies on creation.
+func (f *WebhookSubscriptionForm) ToModelWithId(id, merchantId, secret string) *WebhookSubscription {
+	active := true
+	if f.Active != nil {
+		active = *f.Active
+	}
+
+	return &WebhookSubscription{
+		Model: Model{
+			ID: id,
+		},
+		MerchantID: merchantId,
+		URL:        f.URL,
+		EventTypes: strings.Join(f.EventTypes, ","),
+		Secret:     cryptoutil.EncryptedString(secret),
+		Active:     active,
+	}
+}
@@ -0,0 +1,105 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func TestWebhookRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attempts int
+		backoff  time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{9, 128 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{50, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.backoff, model.WebhookRetryBackoff(tt.attempts), "after %d attempts", tt.attempts)
+	}
+}
+
+func TestWebhookDeliveryRecordAttempt(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		attempts int
+		status   int
+		expected string
+		next     *time.Time
+	}{
+		{"accepted", 0, 204, model.WebhookDeliveryStatusSucceeded, nil},
+		{"server error", 0, 500, model.WebhookDeliveryStatusPending, timePtr(at.Add(30 * time.Second))},
+		{"unreachable", 2, 0, model.WebhookDeliveryStatusPending, timePtr(at.Add(2 * time.Minute))},
+		{"redirect", 3, 302, model.WebhookDeliveryStatusPending, timePtr(at.Add(4 * time.Minute))},
+		{"last attempt", model.WebhookMaxAttempts - 1, 500, model.WebhookDeliveryStatusDead, nil},
+		{"last attempt accepted", model.WebhookMaxAttempts - 1, 200, model.WebhookDeliveryStatusSucceeded, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := &model.WebhookDelivery{Status: model.WebhookDeliveryStatusPending, Attempts: tt.attempts}
+			d.RecordAttempt(at, tt.status, "")
+
+			assert.Equal(t, tt.expected, d.Status)
+			assert.Equal(t, tt.attempts+1, d.Attempts)
+			assert.Equal(t, tt.next, d.NextAttemptAt)
+			require.NotNil(t, d.LastAttemptAt)
+			assert.Equal(t, at, *d.LastAttemptAt)
+		})
+	}
+}
+
+func TestWebhookSubscriptionSubscribes(t *testing.T) {
+	t.Parallel()
+
+	s := &model.WebhookSubscription{EventTypes: "merchant.updated,team_member.created", Active: true}
+	assert.True(t, s.Subscribes(model.EventTeamMemberCreated))
+	assert.False(t, s.Subscribes(model.EventTeamMemberDeleted))
+
+	s.Active = false
+	assert.False(t, s.Subscribes(model.EventTeamMemberCreated))
+}
+
+func TestWebhookSubscriptionFormHasPublicHttpsURL(t *testing.T) {
+	t.Parallel()
+
+	for url, ok := range map[string]bool{
+		"https://example.com/hooks":                true,
+		"https://93.184.216.34/hooks":              true,
+		"http://example.com/hooks":                 false,
+		"https://127.0.0.1:8080/hook":              false,
+		"https://localhost/hooks":                  false,
+		"https://[::1]/hooks":                      false,
+		"https://10.0.0.5/hooks":                   false,
+		"https://169.254.169.254/latest/meta-data": false,
+		"ftp://example.com/hooks":                  false,
+		"javascript:alert(1)":                      false,
+		"https:///no-host":                         false,
+	} {
+		form := &model.WebhookSubscriptionForm{URL: url}
+		assert.Equal(t, ok, form.HasPublicHttpsURL(), url)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"merchant/model"
+	"merchant/repository"
+)
+
+// EventPublisher hands relayed events on. An event may be published more
+// than once, when a relay stops between publishing it and recording so, so
+// consumers must tolerate duplicates by event ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, e *model.OutboxEvent) error
+}
+
+// PublisherFunc adapts a function to the EventPublisher interface.
+type PublisherFunc func(ctx context.Context, e *model.OutboxEvent) error
+
+func (f PublisherFunc) Publish(ctx context.Context, e *model.OutboxEvent) error {
+	return f(ctx, e)
+}
+
+// MultiPublisher publishes every event to each of the publishers in turn.
+// An event failing on one publisher is retried on all of them, so they
+// receive it again; at-least-once delivery makes them tolerate that anyway.
+func MultiPublisher(publishers ...EventPublisher) EventPublisher {
+	return PublisherFunc(func(ctx context.Context, e *model.OutboxEvent) error {
+		for _, p := range publishers {
+			if err := p.Publish(ctx, e); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Relay publishes the events recorded in the outbox. Several relays may share
+// an outbox; every event is claimed before it is published, and an event is
+// only taken once every earlier event of its aggregate was published.
+type Relay struct {
+	db        repository.Repository
+	publisher EventPublisher
+	logger    *zap.Logger
+
+	// BatchSize is the number of events taken from the outbox at a time.
+	BatchSize int
+	// Lease is how long a claimed event is left alone by other relays. It
+	// must exceed the time publishing an event takes.
+	Lease time.Duration
+}
+
+func NewRelay(db repository.Repository, publisher EventPublisher, logger *zap.Logger) *Relay {
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		logger:    logger,
+		BatchSize: 100,
+		Lease:     time.Minute,
+	}
+}
+
+// Run relays pending events every interval until the context is done.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := r.RelayPending(ctx)
+			if err != nil {
+				r.logger.Warn(err.Error())
+			}
+			if err != nil || n == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes one batch of pending events and returns the number
+// of events published.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	events, err := r.db.ListPendingOutboxEvents(time.Now(), r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, e := range events {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+
+		ok, err := r.relay(ctx, e)
+		if err != nil && err != repository.ErrConflict {
+			r.logger.Warn(err.Error())
+		}
+		if ok {
+			published++
+		}
+	}
+
+	return published, nil
+}
+
+// relay publishes the event and reports whether it was published.
+func (r *Relay) relay(ctx context.Context, e *model.OutboxEvent) (bool, error) {
+	if err := r.db.ClaimOutboxEvent(e.Sequence, e.Attempts, time.Now().Add(r.Lease)); err != nil {
+		return false, err
+	}
+	e.Attempts++
+
+	if err := r.publisher.Publish(ctx, e); err != nil {
+		retryAt := time.Now().Add(model.OutboxRetryBackoff(e.Attempts))
+		if recordErr := r.db.RecordOutboxEventFailure(e.Sequence, retryAt, err.Error()); recordErr != nil {
+			return false, recordErr
+		}
+
+		return false, err
+	}
+
+	return true, r.db.MarkOutboxEventPublished(e.Sequence, time.Now())
+}
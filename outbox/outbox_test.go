@@ -0,0 +1,112 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"merchant/events"
+	"merchant/events/memevents"
+	"merchant/mock/mock_repository"
+	"merchant/model"
+	"merchant/outbox"
+	"merchant/repository"
+)
+
+func TestRelayPublishesPendingEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := model.OutboxEvents{
+		{Sequence: 1, ID: "e1", Type: model.EventMerchantRegistered, AggregateType: model.AggregateMerchant, AggregateID: "m1"},
+		{Sequence: 2, ID: "e2", Type: model.EventTeamMemberCreated, AggregateType: model.AggregateTeamMember, AggregateID: "t1", Attempts: 2},
+	}
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListPendingOutboxEvents(gomock.Any(), 100).Return(events, nil)
+	gomock.InOrder(
+		db.EXPECT().ClaimOutboxEvent(uint64(1), 0, gomock.Any()).Return(nil),
+		db.EXPECT().MarkOutboxEventPublished(uint64(1), gomock.Any()).Return(nil),
+		db.EXPECT().ClaimOutboxEvent(uint64(2), 2, gomock.Any()).Return(nil),
+		db.EXPECT().MarkOutboxEventPublished(uint64(2), gomock.Any()).Return(nil),
+	)
+
+	var published []string
+	publisher := outbox.PublisherFunc(func(_ context.Context, e *model.OutboxEvent) error {
+		published = append(published, e.ID)
+		return nil
+	})
+
+	n, err := outbox.NewRelay(db, publisher, zap.NewNop()).RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"e1", "e2"}, published)
+}
+
+func TestRelayKeepsFailedEventBack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	event := &model.OutboxEvent{Sequence: 4, ID: "e4", Attempts: 3}
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListPendingOutboxEvents(gomock.Any(), 100).Return(model.OutboxEvents{event}, nil)
+	db.EXPECT().ClaimOutboxEvent(uint64(4), 3, gomock.Any()).Return(nil)
+	db.EXPECT().RecordOutboxEventFailure(uint64(4), gomock.Any(), "broker unavailable").
+		DoAndReturn(func(_ uint64, retryAt time.Time, _ string) error {
+			assert.WithinDuration(t, time.Now().Add(model.OutboxRetryBackoff(4)), retryAt, time.Second)
+			return nil
+		})
+
+	publisher := outbox.PublisherFunc(func(context.Context, *model.OutboxEvent) error {
+		return errors.New("broker unavailable")
+	})
+
+	n, err := outbox.NewRelay(db, publisher, zap.NewNop()).RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestRelaySkipsClaimedEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListPendingOutboxEvents(gomock.Any(), 100).Return(model.OutboxEvents{{Sequence: 1, ID: "e1"}}, nil)
+	db.EXPECT().ClaimOutboxEvent(uint64(1), 0, gomock.Any()).Return(repository.ErrConflict)
+
+	publisher := outbox.PublisherFunc(func(context.Context, *model.OutboxEvent) error {
+		t.Fatal("claimed event published")
+		return nil
+	})
+
+	n, err := outbox.NewRelay(db, publisher, zap.NewNop()).RelayPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestMultiPublisherPublishesToEach(t *testing.T) {
+	bus := memevents.New()
+
+	var webhooks []string
+	publisher := outbox.MultiPublisher(
+		outbox.PublisherFunc(func(_ context.Context, e *model.OutboxEvent) error {
+			webhooks = append(webhooks, e.ID)
+			return nil
+		}),
+		events.NewOutboxPublisher(bus, "/merchant"),
+	)
+
+	err := publisher.Publish(context.Background(), &model.OutboxEvent{ID: "e1", Type: model.EventMerchantRegistered, Payload: `{}`})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"e1"}, webhooks)
+	require.Len(t, bus.Envelopes(), 1)
+	assert.Equal(t, "/merchant", bus.Envelopes()[0].Source)
+}
@@ -0,0 +1,214 @@
+package fakepay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"merchant/payment"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of a callback body.
+const SignatureHeader = "X-Fakepay-Signature"
+
+// Outcome is the scripted result of the next gateway operation.
+type Outcome struct {
+	decline string
+	err     error
+}
+
+// Approve lets the operation succeed.
+var Approve = Outcome{}
+
+// Decline makes the provider decline the operation with the reason given.
+func Decline(reason string) Outcome {
+	return Outcome{decline: reason}
+}
+
+// Fail makes the operation return the error given, as if the provider could
+// not be reached.
+func Fail(err error) Outcome {
+	return Outcome{err: err}
+}
+
+// Call records an operation the gateway was asked to perform.
+type Call struct {
+	Op        string
+	Reference string
+	Amount    int64
+}
+
+// Gateway is an in-process payment.Gateway for development and tests.
+// Operations succeed unless outcomes were scripted with Script, which are
+// used up in order by the following operations.
+type Gateway struct {
+	secret []byte
+
+	mu          sync.Mutex
+	outcomes    []Outcome
+	calls       []Call
+	authorized  map[string]*payment.Result
+	refunded    map[string]*payment.Result
+	nextPayment int
+	nextRefund  int
+}
+
+// New returns a fake gateway. Callbacks must be signed with the secret; all
+// of them are rejected when it is empty.
+func New(secret string) *Gateway {
+	return &Gateway{
+		secret:     []byte(secret),
+		authorized: make(map[string]*payment.Result),
+		refunded:   make(map[string]*payment.Result),
+	}
+}
+
+// Script queues outcomes for the next operations.
+func (g *Gateway) Script(outcomes ...Outcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.outcomes = append(g.outcomes, outcomes...)
+}
+
+// Calls returns the operations performed so far.
+func (g *Gateway) Calls() []Call {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]Call(nil), g.calls...)
+}
+
+func (g *Gateway) Name() string {
+	return "fake"
+}
+
+func (g *Gateway) Authorize(_ context.Context, req *payment.AuthorizeRequest) (*payment.Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.authorized[req.IdempotencyKey]; ok {
+		return res, nil
+	}
+
+	g.nextPayment++
+	reference := fmt.Sprintf("fake_pay_%d", g.nextPayment)
+
+	res, err := g.perform("authorize", reference, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if req.IdempotencyKey != "" {
+		g.authorized[req.IdempotencyKey] = res
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) Capture(_ context.Context, reference string, amount int64) (*payment.Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.perform("capture", reference, amount)
+}
+
+func (g *Gateway) Void(_ context.Context, reference string) (*payment.Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.perform("void", reference, 0)
+}
+
+func (g *Gateway) Refund(_ context.Context, req *payment.RefundRequest) (*payment.Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.refunded[req.IdempotencyKey]; ok {
+		return res, nil
+	}
+
+	g.nextRefund++
+	res, err := g.perform("refund", req.Reference, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	res.Reference = fmt.Sprintf("fake_refund_%d", g.nextRefund)
+	if req.IdempotencyKey != "" {
+		g.refunded[req.IdempotencyKey] = res
+	}
+
+	return res, nil
+}
+
+func (g *Gateway) ParseCallback(r *http.Request) (*payment.Callback, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(g.secret) == 0 {
+		return nil, payment.ErrInvalidCallback
+	}
+
+	signature, err := hex.DecodeString(r.Header.Get(SignatureHeader))
+	if err != nil || !hmac.Equal(signature, g.sign(body)) {
+		return nil, payment.ErrInvalidCallback
+	}
+
+	cb := &payment.Callback{}
+	if err := json.Unmarshal(body, cb); err != nil || cb.EventID == "" || cb.Reference == "" {
+		return nil, payment.ErrInvalidCallback
+	}
+
+	return cb, nil
+}
+
+// NewCallbackRequest returns a signed request delivering the callback, as
+// the provider would send it. It panics when target is not a valid URL.
+func (g *Gateway) NewCallbackRequest(target string, cb *payment.Callback) *http.Request {
+	body, _ := json.Marshal(cb)
+
+	r, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		panic("fakepay: invalid callback target " + target + ": " + err.Error())
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if len(g.secret) > 0 {
+		r.Header.Set(SignatureHeader, hex.EncodeToString(g.sign(body)))
+	}
+
+	return r
+}
+
+// perform records the call and applies the next scripted outcome. The
+// caller must hold the lock.
+func (g *Gateway) perform(op, reference string, amount int64) (*payment.Result, error) {
+	g.calls = append(g.calls, Call{Op: op, Reference: reference, Amount: amount})
+
+	outcome := Approve
+	if len(g.outcomes) > 0 {
+		outcome, g.outcomes = g.outcomes[0], g.outcomes[1:]
+	}
+	if outcome.err != nil {
+		return nil, outcome.err
+	}
+
+	return &payment.Result{
+		Reference:     reference,
+		Approved:      outcome.decline == "",
+		DeclineReason: outcome.decline,
+	}, nil
+}
+
+func (g *Gateway) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
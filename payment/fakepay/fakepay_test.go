@@ -0,0 +1,101 @@
+package fakepay_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/payment"
+	"merchant/payment/fakepay"
+)
+
+func TestGatewayAppliesScriptedOutcomesInOrder(t *testing.T) {
+	t.Parallel()
+
+	g := fakepay.New("")
+	unreachable := errors.New("provider unreachable")
+	g.Script(fakepay.Decline("insufficient_funds"), fakepay.Fail(unreachable))
+
+	ctx := context.Background()
+
+	res, err := g.Authorize(ctx, &payment.AuthorizeRequest{IdempotencyKey: "a", Amount: 1000})
+	require.NoError(t, err)
+	assert.False(t, res.Approved)
+	assert.Equal(t, "insufficient_funds", res.DeclineReason)
+
+	_, err = g.Authorize(ctx, &payment.AuthorizeRequest{IdempotencyKey: "b", Amount: 1000})
+	assert.Equal(t, unreachable, err)
+
+	res, err = g.Authorize(ctx, &payment.AuthorizeRequest{IdempotencyKey: "b", Amount: 1000})
+	require.NoError(t, err)
+	assert.True(t, res.Approved)
+
+	res, err = g.Capture(ctx, res.Reference, 1000)
+	require.NoError(t, err)
+	assert.True(t, res.Approved)
+
+	ops := make([]string, 0)
+	for _, c := range g.Calls() {
+		ops = append(ops, c.Op)
+	}
+	assert.Equal(t, []string{"authorize", "authorize", "authorize", "capture"}, ops)
+}
+
+func TestGatewayAuthorizeIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	g := fakepay.New("")
+	ctx := context.Background()
+
+	first, err := g.Authorize(ctx, &payment.AuthorizeRequest{IdempotencyKey: "a", Amount: 1000})
+	require.NoError(t, err)
+
+	g.Script(fakepay.Decline("do_not_honor"))
+	again, err := g.Authorize(ctx, &payment.AuthorizeRequest{IdempotencyKey: "a", Amount: 1000})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, again)
+	assert.Len(t, g.Calls(), 1)
+}
+
+func TestGatewayRefundIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	g := fakepay.New("")
+	ctx := context.Background()
+
+	g.Script(fakepay.Fail(errors.New("timeout")))
+	_, err := g.Refund(ctx, &payment.RefundRequest{IdempotencyKey: "r", Reference: "fake_pay_1", Amount: 500})
+	require.Error(t, err)
+
+	first, err := g.Refund(ctx, &payment.RefundRequest{IdempotencyKey: "r", Reference: "fake_pay_1", Amount: 500})
+	require.NoError(t, err)
+
+	again, err := g.Refund(ctx, &payment.RefundRequest{IdempotencyKey: "r", Reference: "fake_pay_1", Amount: 500})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, again)
+	assert.Len(t, g.Calls(), 2)
+}
+
+func TestGatewayParseCallbackVerifiesSignature(t *testing.T) {
+	t.Parallel()
+
+	g := fakepay.New("secret")
+	cb := &payment.Callback{EventID: "evt_1", Type: payment.CallbackCaptured, Reference: "fake_pay_1", Amount: 1000}
+
+	parsed, err := g.ParseCallback(g.NewCallbackRequest("/callbacks/payments", cb))
+	require.NoError(t, err)
+	assert.Equal(t, cb, parsed)
+
+	forged := fakepay.New("other").NewCallbackRequest("/callbacks/payments", cb)
+	_, err = g.ParseCallback(forged)
+	assert.Equal(t, payment.ErrInvalidCallback, err)
+
+	unsigned := fakepay.New("")
+	_, err = unsigned.ParseCallback(unsigned.NewCallbackRequest("/callbacks/payments", cb))
+	assert.Equal(t, payment.ErrInvalidCallback, err)
+}
@@ -0,0 +1,75 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const (
+	CallbackAuthorized = "payment.authorized"
+	CallbackCaptured   = "payment.captured"
+	CallbackFailed     = "payment.failed"
+	CallbackVoided     = "payment.voided"
+	CallbackRefunded   = "payment.refunded"
+)
+
+// ErrInvalidCallback is returned for callbacks that can not be parsed or
+// whose signature does not match.
+var ErrInvalidCallback = errors.New("invalid payment callback")
+
+// Gateway moves money through a payment provider. A declined operation is
+// reported in the Result; an error means the outcome is unknown, e.g.
+// because the provider could not be reached.
+type Gateway interface {
+	// Name identifies the provider payments are recorded against.
+	Name() string
+	// Authorize reserves the amount on the payment method of the customer.
+	// Repeating a request with the same idempotency key returns the result
+	// of the first one.
+	Authorize(ctx context.Context, req *AuthorizeRequest) (*Result, error)
+	// Capture collects up to the authorized amount of a payment.
+	Capture(ctx context.Context, reference string, amount int64) (*Result, error)
+	// Void releases the authorization of a payment that was not captured.
+	Void(ctx context.Context, reference string) (*Result, error)
+	// Refund returns up to the captured amount of a payment. Repeating a
+	// request with the same idempotency key returns the result of the first
+	// one, so refunds whose outcome is unknown can be retried safely.
+	Refund(ctx context.Context, req *RefundRequest) (*Result, error)
+	// ParseCallback verifies and decodes a notification the provider sent
+	// about a payment.
+	ParseCallback(r *http.Request) (*Callback, error)
+}
+
+type AuthorizeRequest struct {
+	IdempotencyKey string
+	Amount         int64
+	Currency       string
+	// PaymentMethod is the provider token of the card or account to charge.
+	PaymentMethod string
+}
+
+type RefundRequest struct {
+	IdempotencyKey string
+	// Reference identifies the refunded payment at the provider.
+	Reference string
+	Amount    int64
+}
+
+type Result struct {
+	// Reference identifies the payment, or for refunds the refund, at the
+	// provider.
+	Reference     string
+	Approved      bool
+	DeclineReason string
+}
+
+// Callback is an asynchronous notification from the provider. Providers may
+// deliver the same callback more than once; EventID identifies duplicates.
+type Callback struct {
+	EventID   string `json:"id"`
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason,omitempty"`
+}
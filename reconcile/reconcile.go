@@ -0,0 +1,105 @@
+package reconcile
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"merchant/payment"
+	"merchant/repository"
+)
+
+// Reconciler settles the refunds left pending because the outcome at the
+// provider was unknown, e.g. because it could not be reached. Each refund is
+// requested again with its id as the idempotency key, which makes the
+// provider either perform it or return the outcome of the first request.
+type Reconciler struct {
+	db       repository.Repository
+	payments payment.Gateway
+	logger   *zap.Logger
+
+	// After is how long a refund is left pending before it is requested
+	// again, so that requests still in flight are not reconciled.
+	After time.Duration
+	// BatchSize is the number of refunds reconciled at a time.
+	BatchSize int
+}
+
+func NewReconciler(db repository.Repository, payments payment.Gateway, logger *zap.Logger) *Reconciler {
+	return &Reconciler{
+		db:        db,
+		payments:  payments,
+		logger:    logger,
+		After:     5 * time.Minute,
+		BatchSize: 50,
+	}
+}
+
+// Run reconciles pending refunds every interval until the context is done.
+func (rc *Reconciler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := rc.ReconcileRefunds(ctx); err != nil {
+			rc.logger.Warn(err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileRefunds requests one batch of stale pending refunds again and
+// records their outcome. It returns the number of refunds settled; refunds
+// whose outcome is still unknown stay pending for the next run.
+func (rc *Reconciler) ReconcileRefunds(ctx context.Context) (int, error) {
+	refunds, err := rc.db.ListPendingRefunds(time.Now().Add(-rc.After), rc.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, rf := range refunds {
+		if ctx.Err() != nil {
+			return settled, ctx.Err()
+		}
+
+		intent, err := rc.db.ReadPaymentIntentById(rf.PaymentIntentID)
+		if err != nil {
+			rc.logger.Warn(err.Error())
+			continue
+		}
+
+		res, err := rc.payments.Refund(ctx, &payment.RefundRequest{
+			IdempotencyKey: rf.ID,
+			Reference:      intent.ProviderReference,
+			Amount:         rf.Amount,
+		})
+		if err != nil {
+			rc.logger.Warn(err.Error())
+			continue
+		}
+
+		if res.Approved {
+			err = rc.db.CompleteRefund(rf.ID, res.Reference)
+		} else {
+			err = rc.db.FailRefund(rf.ID, res.DeclineReason)
+		}
+		if err != nil {
+			// Settled meanwhile by another reconciler.
+			if err != repository.ErrConflict {
+				rc.logger.Warn(err.Error())
+			}
+			continue
+		}
+
+		settled++
+	}
+
+	return settled, nil
+}
@@ -0,0 +1,47 @@
+package reconcile_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"merchant/mock/mock_repository"
+	"merchant/model"
+	"merchant/payment/fakepay"
+	"merchant/reconcile"
+)
+
+func TestReconcileRefunds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	intent := &model.PaymentIntent{Model: model.Model{ID: "p1"}, ProviderReference: "fake_pay_1"}
+	approved := &model.Refund{Model: model.Model{ID: "r1"}, PaymentIntentID: "p1", Amount: 500}
+	declined := &model.Refund{Model: model.Model{ID: "r2"}, PaymentIntentID: "p1", Amount: 300}
+	unknown := &model.Refund{Model: model.Model{ID: "r3"}, PaymentIntentID: "p1", Amount: 200}
+
+	gateway := fakepay.New("secret")
+	gateway.Script(fakepay.Approve, fakepay.Decline("refund_window_closed"), fakepay.Fail(errors.New("timeout")))
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListPendingRefunds(gomock.Any(), 50).Return(model.Refunds{approved, declined, unknown}, nil)
+	db.EXPECT().ReadPaymentIntentById("p1").Return(intent, nil).Times(3)
+	db.EXPECT().CompleteRefund("r1", "fake_refund_1").Return(nil)
+	db.EXPECT().FailRefund("r2", "refund_window_closed").Return(nil)
+
+	rc := reconcile.NewReconciler(db, gateway, zap.NewNop())
+	settled, err := rc.ReconcileRefunds(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, settled)
+
+	calls := gateway.Calls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, "fake_pay_1", calls[2].Reference)
+	assert.Equal(t, int64(200), calls[2].Amount)
+}
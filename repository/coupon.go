@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"merchant/model"
+)
+
+func (r *repo) ListCouponsByMerchantId(merchantId string) (model.Coupons, error) {
+	cs := make([]*model.Coupon, 0)
+	err := r.DB.Where(`merchant_id = ?`, merchantId).Order(`created_at DESC`).Find(&cs).Error
+	return cs, err
+}
+
+// CreateCoupon returns ErrDuplicate when the merchant has a coupon with the
+// code already.
+func (r *repo) CreateCoupon(c *model.Coupon) error {
+	return translateError(r.DB.Create(c).Error)
+}
+
+func (r *repo) ReadCouponById(id string) (*model.Coupon, error) {
+	c := &model.Coupon{}
+	if err := r.DB.Where(`id = ?`, id).First(c).Error; err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *repo) ReadCouponByCode(merchantId, code string) (*model.Coupon, error) {
+	c := &model.Coupon{}
+	if err := r.DB.Where(`merchant_id = ? AND code = ?`, merchantId, code).First(c).Error; err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// UpdateCouponById changes when and how often the coupon may be redeemed.
+func (r *repo) UpdateCouponById(id string, c *model.Coupon) error {
+	return r.DB.Model(&model.Coupon{}).Where(`id = ?`, id).Updates(map[string]interface{}{
+		"starts_at":                    c.StartsAt,
+		"ends_at":                      c.EndsAt,
+		"max_redemptions":              c.MaxRedemptions,
+		"max_redemptions_per_customer": c.MaxRedemptionsPerCustomer,
+		"active":                       c.Active,
+	}).Error
+}
+
+// RedeemCoupon records the redemption and stores the draft order priced with
+// the coupon in one transaction. The coupon is locked while its limits are
+// checked and its redemptions counted, so parallel checkouts redeeming the
+// same coupon take turns and can never exceed the limits. It returns
+// model.ErrCouponExhausted or model.ErrCouponCustomerLimit when a limit is
+// reached, model.ErrCouponCustomerRequired when the coupon is limited per
+// customer and the order has no customer email, ErrDuplicate when the order
+// redeemed a coupon already and ErrConflict when the order is no longer a
+// draft.
+func (r *repo) RedeemCoupon(rd *model.CouponRedemption, o *model.Order) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		c := &model.Coupon{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(`id = ?`, rd.CouponID).First(c).Error
+		if err != nil {
+			return err
+		}
+
+		if err := c.CheckCustomer(rd.CustomerEmail); err != nil {
+			return err
+		}
+
+		var customerRedemptions int64
+		err = tx.Model(&model.CouponRedemption{}).
+			Where(`coupon_id = ? AND customer_email = ?`, rd.CouponID, rd.CustomerEmail).
+			Count(&customerRedemptions).Error
+		if err != nil {
+			return err
+		}
+
+		if err := c.CheckLimits(customerRedemptions); err != nil {
+			return err
+		}
+
+		if err := tx.Create(rd).Error; err != nil {
+			return translateError(err)
+		}
+
+		err = tx.Model(&model.Coupon{}).Where(`id = ?`, c.ID).
+			Update("redemptions", gorm.Expr(`redemptions + 1`)).Error
+		if err != nil {
+			return err
+		}
+
+		return updateDraftOrder(tx, o.ID, o)
+	})
+}
+
+// UpdateRedeemedDraftOrder stores the draft order which redeemed the coupon
+// of rd, along with the redemption re-priced for the order and moved to its
+// customer. The coupon is locked like RedeemCoupon locks it; when the
+// customer changed, the per customer limit is checked again for the new
+// customer, returning model.ErrCouponCustomerLimit or
+// model.ErrCouponCustomerRequired when it is not met. It returns
+// ErrConflict when the order is no longer a draft.
+func (r *repo) UpdateRedeemedDraftOrder(rd *model.CouponRedemption, o *model.Order) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		c := &model.Coupon{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(`id = ?`, rd.CouponID).First(c).Error
+		if err != nil {
+			return err
+		}
+
+		existing := &model.CouponRedemption{}
+		if err := tx.Where(`coupon_id = ? AND order_id = ?`, rd.CouponID, rd.OrderID).First(existing).Error; err != nil {
+			return err
+		}
+
+		if existing.CustomerEmail != rd.CustomerEmail {
+			if err := c.CheckCustomer(rd.CustomerEmail); err != nil {
+				return err
+			}
+
+			var customerRedemptions int64
+			err = tx.Model(&model.CouponRedemption{}).
+				Where(`coupon_id = ? AND customer_email = ?`, rd.CouponID, rd.CustomerEmail).
+				Count(&customerRedemptions).Error
+			if err != nil {
+				return err
+			}
+
+			if err := c.CheckCustomerLimit(customerRedemptions); err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&model.CouponRedemption{}).Where(`id = ?`, existing.ID).Updates(map[string]interface{}{
+			"customer_email": rd.CustomerEmail,
+			"amount":         rd.Amount,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return updateDraftOrder(tx, o.ID, o)
+	})
+}
+
+// ReleaseCoupon removes the redemption of the coupon on the draft order and
+// stores the order priced without it, giving the redemption back to the
+// coupon. It returns ErrConflict when the order is no longer a draft.
+func (r *repo) ReleaseCoupon(couponId string, o *model.Order) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := releaseRedemption(tx, couponId, o.ID); err != nil {
+			return err
+		}
+
+		return updateDraftOrder(tx, o.ID, o)
+	})
+}
+
+// releaseRedemption removes the redemption of the coupon on the order and
+// gives it back to the coupon, which is locked like RedeemCoupon locks it.
+func releaseRedemption(tx *gorm.DB, couponId, orderId string) error {
+	c := &model.Coupon{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(`id = ?`, couponId).First(c).Error
+	if err != nil {
+		return err
+	}
+
+	res := tx.Where(`coupon_id = ? AND order_id = ?`, couponId, orderId).Delete(&model.CouponRedemption{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+
+	return tx.Model(&model.Coupon{}).Where(`id = ?`, c.ID).
+		Update("redemptions", gorm.Expr(`redemptions - ?`, res.RowsAffected)).Error
+}
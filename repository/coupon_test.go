@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Redeem_Coupon_Exhausted() {
+	rd := &model.CouponRedemption{
+		Model:         model.Model{ID: "b2c4d6e8-0a1c-4e3f-8a5b-7c9d1e3f5a7b"},
+		CouponID:      "7f1e3d5c-9b2a-4c6e-8d0f-1a3b5c7d9e2f",
+		OrderID:       "5a7c9e1b-3d5f-4a8c-9e2b-4d6f8a0c2e4b",
+		CustomerEmail: "ada@example.com",
+	}
+
+	lock := "SELECT * FROM `coupons` WHERE id = ? ORDER BY `coupons`.`id` LIMIT 1 FOR UPDATE"
+	count := "SELECT count(1) FROM `coupon_redemptions` WHERE coupon_id = ? AND customer_email = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(lock).
+		WithArgs(rd.CouponID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "max_redemptions", "redemptions"}).
+			AddRow(rd.CouponID, 100, 100))
+	s.mock.ExpectQuery(count).
+		WithArgs(rd.CouponID, rd.CustomerEmail).
+		WillReturnRows(sqlmock.NewRows([]string{"count(1)"}).AddRow(0))
+	s.mock.ExpectRollback()
+
+	err := s.repository.RedeemCoupon(rd, &model.Order{Model: model.Model{ID: rd.OrderID}})
+
+	require.Equal(s.T(), model.ErrCouponExhausted, err)
+}
+
+func (s *Suite) Test_repository_Redeem_Coupon() {
+	rd := &model.CouponRedemption{
+		Model:         model.Model{ID: "b2c4d6e8-0a1c-4e3f-8a5b-7c9d1e3f5a7b"},
+		CouponID:      "7f1e3d5c-9b2a-4c6e-8d0f-1a3b5c7d9e2f",
+		MerchantID:    "1d3f5b7a-9c2e-4b6d-8f0a-2c4e6a8b0d1f",
+		OrderID:       "5a7c9e1b-3d5f-4a8c-9e2b-4d6f8a0c2e4b",
+		CustomerEmail: "ada@example.com",
+		Amount:        250,
+	}
+	o := &model.Order{
+		Model:         model.Model{ID: rd.OrderID},
+		CustomerEmail: rd.CustomerEmail,
+		Currency:      "EUR",
+		CouponID:      rd.CouponID,
+		CouponCode:    "SUMMER",
+		Subtotal:      1000,
+		DiscountTotal: 250,
+		TaxTotal:      150,
+		Total:         900,
+		Items: []*model.OrderItem{
+			{OrderID: rd.OrderID, VariantID: "v1", UnitPrice: 1000, Quantity: 1, Subtotal: 1000, Discount: 250, Tax: 150, Total: 900},
+		},
+	}
+
+	lock := "SELECT * FROM `coupons` WHERE id = ? ORDER BY `coupons`.`id` LIMIT 1 FOR UPDATE"
+	count := "SELECT count(1) FROM `coupon_redemptions` WHERE coupon_id = ? AND customer_email = ?"
+	insert := "INSERT INTO `coupon_redemptions` (`id`,`created_at`,`updated_at`,`version`,`coupon_id`,`merchant_id`,`order_id`,`customer_email`,`amount`) VALUES (?,?,?,?,?,?,?,?,?)"
+	increment := "UPDATE `coupons` SET `redemptions`=redemptions + 1,`version`=version + 1,`updated_at`=? WHERE id = ?"
+	update := "UPDATE `orders` SET `coupon_code`=?,`coupon_id`=?,`currency`=?,`customer_email`=?,`customer_name`=?,`discount_total`=?,`note`=?,`subtotal`=?,`tax_total`=?,`total`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND state = ?"
+	deleteItems := "DELETE FROM `order_items` WHERE order_id = ?"
+	insertItems := "INSERT INTO `order_items` (`order_id`,`product_id`,`variant_id`,`sku`,`name`,`tax_category`,`unit_price`,`quantity`,`subtotal`,`discount`,`tax`,`total`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(lock).
+		WithArgs(rd.CouponID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "max_redemptions", "max_redemptions_per_customer", "redemptions"}).
+			AddRow(rd.CouponID, 100, 1, 99))
+	s.mock.ExpectQuery(count).
+		WithArgs(rd.CouponID, rd.CustomerEmail).
+		WillReturnRows(sqlmock.NewRows([]string{"count(1)"}).AddRow(0))
+	s.mock.ExpectExec(insert).
+		WithArgs(rd.ID, s.Time, s.Time, int64(0), rd.CouponID, rd.MerchantID, rd.OrderID, rd.CustomerEmail, rd.Amount).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(increment).
+		WithArgs(s.Time, rd.CouponID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(update).
+		WithArgs("SUMMER", rd.CouponID, "EUR", rd.CustomerEmail, "", int64(250), "", int64(1000), int64(150), int64(900), s.Time, o.ID, model.OrderStateDraft).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(deleteItems).
+		WithArgs(o.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(insertItems).
+		WithArgs(rd.OrderID, "", "v1", "", "", "", int64(1000), int64(1), int64(1000), int64(250), int64(150), int64(900)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.RedeemCoupon(rd, o)
+
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) Test_repository_Cancel_Order_Releases_Coupon() {
+	orderId := "5a7c9e1b-3d5f-4a8c-9e2b-4d6f8a0c2e4b"
+	couponId := "7f1e3d5c-9b2a-4c6e-8d0f-1a3b5c7d9e2f"
+
+	update := "UPDATE `orders` SET `cancelled_at`=?,`state`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND state = ?"
+	redemption := "SELECT * FROM `coupon_redemptions` WHERE order_id = ? ORDER BY `coupon_redemptions`.`id` LIMIT 1"
+	lock := "SELECT * FROM `coupons` WHERE id = ? ORDER BY `coupons`.`id` LIMIT 1 FOR UPDATE"
+	release := "DELETE FROM `coupon_redemptions` WHERE coupon_id = ? AND order_id = ?"
+	decrement := "UPDATE `coupons` SET `redemptions`=redemptions - ?,`version`=version + 1,`updated_at`=? WHERE id = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(update).
+		WithArgs(s.Time, model.OrderStateCancelled, s.Time, orderId, model.OrderStatePlaced).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(redemption).
+		WithArgs(orderId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_id", "order_id"}).
+			AddRow("b2c4d6e8-0a1c-4e3f-8a5b-7c9d1e3f5a7b", couponId, orderId))
+	s.mock.ExpectQuery(lock).
+		WithArgs(couponId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "redemptions"}).AddRow(couponId, 10))
+	s.mock.ExpectExec(release).
+		WithArgs(couponId, orderId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(decrement).
+		WithArgs(int64(1), s.Time, couponId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.UpdateOrderStateById(orderId, model.OrderStatePlaced, model.OrderStateCancelled, time.Now())
+
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) Test_repository_Cancel_Order_Without_Coupon() {
+	orderId := "5a7c9e1b-3d5f-4a8c-9e2b-4d6f8a0c2e4b"
+
+	update := "UPDATE `orders` SET `cancelled_at`=?,`state`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND state = ?"
+	redemption := "SELECT * FROM `coupon_redemptions` WHERE order_id = ? ORDER BY `coupon_redemptions`.`id` LIMIT 1"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(update).
+		WithArgs(s.Time, model.OrderStateCancelled, s.Time, orderId, model.OrderStatePlaced).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(redemption).
+		WithArgs(orderId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectCommit()
+
+	err := s.repository.UpdateOrderStateById(orderId, model.OrderStatePlaced, model.OrderStateCancelled, time.Now())
+
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) Test_repository_Update_Redeemed_Draft_Order_New_Customer_Over_Limit() {
+	rd := &model.CouponRedemption{
+		CouponID:      "7f1e3d5c-9b2a-4c6e-8d0f-1a3b5c7d9e2f",
+		OrderID:       "5a7c9e1b-3d5f-4a8c-9e2b-4d6f8a0c2e4b",
+		CustomerEmail: "grace@example.com",
+		Amount:        250,
+	}
+
+	lock := "SELECT * FROM `coupons` WHERE id = ? ORDER BY `coupons`.`id` LIMIT 1 FOR UPDATE"
+	read := "SELECT * FROM `coupon_redemptions` WHERE coupon_id = ? AND order_id = ? ORDER BY `coupon_redemptions`.`id` LIMIT 1"
+	count := "SELECT count(1) FROM `coupon_redemptions` WHERE coupon_id = ? AND customer_email = ?"
+
+	// The order redeemed the coupon for Ada, then moved to Grace, who has
+	// redeemed it once already.
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(lock).
+		WithArgs(rd.CouponID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "max_redemptions_per_customer", "redemptions"}).
+			AddRow(rd.CouponID, 1, 2))
+	s.mock.ExpectQuery(read).
+		WithArgs(rd.CouponID, rd.OrderID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_id", "order_id", "customer_email"}).
+			AddRow("b2c4d6e8-0a1c-4e3f-8a5b-7c9d1e3f5a7b", rd.CouponID, rd.OrderID, "ada@example.com"))
+	s.mock.ExpectQuery(count).
+		WithArgs(rd.CouponID, rd.CustomerEmail).
+		WillReturnRows(sqlmock.NewRows([]string{"count(1)"}).AddRow(1))
+	s.mock.ExpectRollback()
+
+	err := s.repository.UpdateRedeemedDraftOrder(rd, &model.Order{Model: model.Model{ID: rd.OrderID}})
+
+	require.Equal(s.T(), model.ErrCouponCustomerLimit, err)
+}
+
+func (s *Suite) Test_repository_Redeem_Coupon_Without_Customer() {
+	rd := &model.CouponRedemption{
+		CouponID: "7f1e3d5c-9b2a-4c6e-8d0f-1a3b5c7d9e2f",
+		OrderID:  "5a7c9e1b-3d5f-4a8c-9e2b-4d6f8a0c2e4b",
+	}
+
+	lock := "SELECT * FROM `coupons` WHERE id = ? ORDER BY `coupons`.`id` LIMIT 1 FOR UPDATE"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(lock).
+		WithArgs(rd.CouponID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "max_redemptions_per_customer"}).AddRow(rd.CouponID, 1))
+	s.mock.ExpectRollback()
+
+	err := s.repository.RedeemCoupon(rd, &model.Order{Model: model.Model{ID: rd.OrderID}})
+
+	require.Equal(s.T(), model.ErrCouponCustomerRequired, err)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"merchant/model"
+)
+
+func (r *repo) CreateDataExport(e *model.DataExport) error {
+	return r.DB.Create(e).Error
+}
+
+func (r *repo) ReadDataExportById(id string) (*model.DataExport, error) {
+	e := &model.DataExport{}
+	if err := r.DB.Where(`id = ?`, id).First(e).Error; err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// ListPendingDataExports returns the oldest exports waiting to run, along
+// with those which started running before staleBefore and were presumably
+// cut off.
+func (r *repo) ListPendingDataExports(staleBefore time.Time, limit int) (model.DataExports, error) {
+	es := make([]*model.DataExport, 0)
+	err := r.DB.
+		Where(`status = ? OR (status = ? AND started_at < ?)`,
+			model.DataExportStatusPending, model.DataExportStatusRunning, staleBefore).
+		Order(`created_at`).Limit(limit).Find(&es).Error
+	return es, err
+}
+
+// ClaimDataExport marks the export as running from the given time. It
+// returns ErrConflict when the export was claimed by another exporter.
+func (r *repo) ClaimDataExport(id string, at, staleBefore time.Time) error {
+	res := r.DB.Model(&model.DataExport{}).
+		Where(`id = ? AND (status = ? OR (status = ? AND started_at < ?))`,
+			id, model.DataExportStatusPending, model.DataExportStatusRunning, staleBefore).
+		Updates(map[string]interface{}{
+			"status":     model.DataExportStatusRunning,
+			"started_at": at,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// FinishDataExport stores the outcome of the export.
+func (r *repo) FinishDataExport(e *model.DataExport) error {
+	return r.DB.Model(&model.DataExport{}).Where(`id = ?`, e.ID).Updates(map[string]interface{}{
+		"status":      e.Status,
+		"blob_key":    e.BlobKey,
+		"size":        e.Size,
+		"error":       e.Error,
+		"finished_at": e.FinishedAt,
+		"expires_at":  e.ExpiresAt,
+	}).Error
+}
+
+// ListExpiredDataExports returns the exports whose retention period ended
+// before now.
+func (r *repo) ListExpiredDataExports(now time.Time, limit int) (model.DataExports, error) {
+	es := make([]*model.DataExport, 0)
+	err := r.DB.Where(`expires_at < ?`, now).Order(`expires_at`).Limit(limit).Find(&es).Error
+	return es, err
+}
+
+func (r *repo) DeleteDataExport(id string) error {
+	return r.DB.Where(`id = ?`, id).Delete(&model.DataExport{}).Error
+}
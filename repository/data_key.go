@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"merchant/model"
+	"merchant/util/cryptoutil"
+)
+
+// CreateDataKey returns ErrDuplicate when a key of the same id exists.
+func (r *repo) CreateDataKey(k *model.DataKey) error {
+	return translateError(r.DB.Create(k).Error)
+}
+
+func (r *repo) ReadDataKeyById(id string) (*model.DataKey, error) {
+	k := &model.DataKey{}
+	if err := r.DB.Where(`id = ?`, id).First(k).Error; err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// ListDataKeys returns every data key, oldest first.
+func (r *repo) ListDataKeys() (model.DataKeys, error) {
+	ks := make([]*model.DataKey, 0)
+	err := r.DB.Order(`created_at, id`).Find(&ks).Error
+	return ks, err
+}
+
+func (r *repo) UpdateDataKeyWrappedKey(id, wrappedKey string) error {
+	return r.DB.Model(&model.DataKey{}).Where(`id = ?`, id).Update("wrapped_key", wrappedKey).Error
+}
+
+// EncryptedTables lists the tables with encrypted columns, which
+// ReencryptRows goes through.
+var EncryptedTables = []string{
+	"merchants", "merchant_versions", "team_members", "team_member_versions", "payout_accounts", "webhook_subscriptions",
+	"webhook_deliveries", "outbox_events", "idempotency_keys",
+}
+
+// encryptedColumns lists the encrypted columns of each table, along with
+// the column holding the blind index of the email column, if any.
+var encryptedColumns = map[string]struct {
+	columns    []string
+	emailIndex string
+}{
+	"merchants":             {[]string{"email", "business_name"}, "email_index"},
+	"merchant_versions":     {[]string{"email", "business_name"}, ""},
+	"team_members":          {[]string{"given_name", "family_name", "email"}, "email_index"},
+	"team_member_versions":  {[]string{"given_name", "family_name", "email"}, ""},
+	"payout_accounts":       {[]string{"iban", "account_number", "sort_code"}, ""},
+	"webhook_subscriptions": {[]string{"secret"}, ""},
+	"webhook_deliveries":    {[]string{"payload"}, ""},
+	"outbox_events":         {[]string{"payload"}, ""},
+	"idempotency_keys":      {[]string{"response_body"}, ""},
+}
+
+// ReencryptRows encrypts again with the active data key the stale values of
+// up to limit rows of the table whose id follows afterId, that is values in
+// plaintext or encrypted with another key, and sets the blind indexes which
+// are missing or out of date. Rows changed since they were read are left for
+// the next pass. It returns the id of the last row read, empty when there
+// were none, and the number of rows written.
+func (r *repo) ReencryptRows(table, afterId string, limit int) (string, int, error) {
+	t, ok := encryptedColumns[table]
+	if !ok {
+		return "", 0, fmt.Errorf("table %q has no encrypted columns", table)
+	}
+
+	columns := append([]string{"id"}, t.columns...)
+	if t.emailIndex != "" {
+		columns = append(columns, t.emailIndex)
+	}
+
+	rows := make([]map[string]interface{}, 0)
+	err := r.DB.Table(table).Select(columns).Where(`id > ?`, afterId).Order(`id`).Limit(limit).Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return "", 0, err
+	}
+
+	written := 0
+	for _, row := range rows {
+		values := make(map[string]interface{})
+		plaintexts := make(map[string]string)
+		conditions := []string{`id = ?`}
+		args := []interface{}{row["id"]}
+
+		for _, c := range t.columns {
+			stored := columnString(row[c])
+			var s cryptoutil.EncryptedString
+			if err := s.Scan(stored); err != nil {
+				return "", 0, err
+			}
+			plaintexts[c] = s.String()
+
+			if cryptoutil.Stale(stored) {
+				values[c] = s
+				conditions = append(conditions, c+` = ?`)
+				args = append(args, stored)
+			}
+		}
+
+		if t.emailIndex != "" {
+			index, err := model.EmailIndex(plaintexts["email"])
+			if err != nil {
+				return "", 0, err
+			}
+			if index != columnString(row[t.emailIndex]) {
+				values[t.emailIndex] = index
+				if _, ok := values["email"]; !ok {
+					conditions = append(conditions, `email = ?`)
+					args = append(args, columnString(row["email"]))
+				}
+			}
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+		res := r.DB.Table(table).Where(strings.Join(conditions, " AND "), args...).Updates(values)
+		if res.Error != nil {
+			return "", 0, res.Error
+		}
+		written += int(res.RowsAffected)
+	}
+
+	return columnString(rows[len(rows)-1]["id"]), written, nil
+}
+
+// columnString returns the value of a text column read into a map.
+func columnString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+
+	return ""
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/util/cryptoutil"
+)
+
+// activeCipherText matches values encrypted with the active data key.
+type activeCipherText struct{}
+
+func (activeCipherText) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, "enc:v2:active:")
+}
+
+func (s *Suite) Test_repository_List_Data_Keys() {
+	query := "SELECT * FROM `data_keys` ORDER BY created_at, id"
+	rows := sqlmock.NewRows([]string{"id", "purpose", "wrapped_key", "created_at"}).
+		AddRow("index", model.DataKeyPurposeIndex, "d3JhcHBlZA==", now)
+
+	s.mock.ExpectQuery(query).WillReturnRows(rows)
+
+	res, err := s.repository.ListDataKeys()
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, len(res))
+	require.Equal(s.T(), "d3JhcHBlZA==", res[0].WrappedKey)
+}
+
+func (s *Suite) Test_repository_Reencrypt_Rows() {
+	current, err := cryptoutil.EncryptedString("Current Ltd").Value()
+	require.NoError(s.T(), err)
+	index, err := model.EmailIndex("jane@example.com")
+	require.NoError(s.T(), err)
+
+	query := "SELECT id,email,business_name,email_index FROM `merchants` WHERE id > ? ORDER BY id LIMIT 10"
+	rows := sqlmock.NewRows([]string{"id", "email", "business_name", "email_index"}).
+		AddRow("a", "jane@example.com", "Legacy Ltd", "").
+		AddRow("b", "", current, "")
+
+	s.mock.ExpectQuery(query).WithArgs("").WillReturnRows(rows)
+	s.mock.ExpectExec("UPDATE `merchants` SET `business_name`=?,`email`=?,`email_index`=? WHERE id = ? AND email = ? AND business_name = ?").
+		WithArgs(activeCipherText{}, activeCipherText{}, index, "a", "jane@example.com", "Legacy Ltd").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	last, written, err := s.repository.ReencryptRows("merchants", "", 10)
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "b", last)
+	require.Equal(s.T(), 1, written)
+}
+
+func (s *Suite) Test_repository_Reencrypt_Rows_Unknown_Table() {
+	_, _, err := s.repository.ReencryptRows("orders", "", 10)
+
+	require.Error(s.T(), err)
+}
@@ -1,33 +1,216 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 
 	"merchant/model"
 )
 
+// ErrConflict is returned by conditional writes whose condition no longer
+// holds because the record was changed concurrently.
+var ErrConflict = errors.New("record was changed concurrently")
+
+// ErrDuplicate is returned by writes that would violate a unique index.
+var ErrDuplicate = errors.New("record already exists")
+
+// mysqlErrDuplicateEntry is the MySQL error number of a unique index violation.
+const mysqlErrDuplicateEntry = 1062
+
+// mysqlErrLockDeadlock is the MySQL error number of a transaction rolled back
+// to break a deadlock.
+const mysqlErrLockDeadlock = 1213
+
+// translateError maps driver errors callers need to tell apart onto the
+// errors of this package and returns any other error unchanged.
+func translateError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+		return ErrDuplicate
+	}
+
+	return err
+}
+
 type repo struct {
 	DB *gorm.DB
 }
 
 func New(db *gorm.DB) Repository {
+	if db.Callback().Update().Get(versionCallback) == nil {
+		db.Callback().Update().Before("gorm:update").Register(versionCallback, bumpVersion)
+	}
+
 	return &repo{
 		DB: db,
 	}
 }
 
+func (r *repo) Transaction(fn func(tx Repository) error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(&repo{DB: tx})
+	})
+}
+
 type Repository interface {
-	ListMerchants() (model.Merchants, error)
+	// Transaction runs fn with a repository whose writes are committed
+	// together when fn returns nil and rolled back otherwise.
+	Transaction(fn func(tx Repository) error) error
+
+	ListMerchants(metadata model.Metadata) (model.Merchants, error)
+	ListMerchantsByIdsAfter(ids []string, afterId string, limit int) (model.Merchants, error)
 	CreateMerchant(u *model.Merchant) error
 	ReadMerchantById(id string) (*model.Merchant, error)
 	ReadMerchantByEmail(email string) (*model.Merchant, error)
-	UpdateMerchantDescriptionById(id string, d string) error
-	DeleteMerchant(id string) error
+	UpdateMerchantById(id string, version int64, m *model.Merchant) error
+	UpdateMerchantLogoById(id string, version int64, key, thumbnailKey string) error
+	UpdateMerchantBannerById(id string, version int64, key, thumbnailKey string) error
+	ListMerchantDescendantIds(id string) ([]string, error)
+	ListMerchantsByIds(ids []string, metadata model.Metadata) (model.Merchants, error)
+	UpdateMerchantParentById(id string, version int64, parentId *string) error
+	RequestMerchantClosure(id string, version int64, requestedAt, closesAt time.Time) error
+	CancelMerchantClosure(id string, version int64, now time.Time) error
+	ListMerchantsDueForClosure(now time.Time, limit int) (model.Merchants, error)
+	EraseMerchant(id string, now time.Time) (map[string]int64, []string, error)
+	CreateMerchantTombstone(t *model.MerchantTombstone) error
+	ListMerchantVersions(merchantId string, at *time.Time, limit, offset int) (model.MerchantVersions, int64, error)
+	ReadMerchantVersion(merchantId string, version int64) (*model.MerchantVersion, error)
+	RestoreMerchantById(id string, version int64, v *model.MerchantVersion) error
 
-	ListTeamMembersByMerchantId(merchantId string) (model.TeamMembers, error)
+	ListTeamMembersByMerchantId(merchantId string, metadata model.Metadata) (model.TeamMembers, error)
+	ListTeamMembersByMerchantIdAfter(merchantId, afterId string, limit int) (model.TeamMembers, error)
+	ListTeamMembersByMerchantIds(merchantIds []string, metadata model.Metadata) (model.TeamMembers, error)
 	CreateTeamMember(t *model.TeamMember) error
 	ReadTeamMemberById(id string) (*model.TeamMember, error)
 	ReadTeamMemberByEmail(email string) (*model.TeamMember, error)
-	UpdateTeamMemberById(id string, t *model.TeamMember) error
-	DeleteTeamMember(id string) error
+	UpdateTeamMemberById(id string, version int64, t *model.TeamMember) error
+	DeleteTeamMember(id string, version int64) error
+	ListTeamMemberVersions(teamMemberId string, at *time.Time, limit, offset int) (model.TeamMemberVersions, int64, error)
+	ListTeamMemberVersionsByMerchantId(merchantId string, limit, offset int) (model.TeamMemberVersions, error)
+	ReadTeamMemberVersion(teamMemberId string, version int64) (*model.TeamMemberVersion, error)
+
+	CreateTeamMemberImport(i *model.TeamMemberImport) error
+	ReadTeamMemberImportById(id string) (*model.TeamMemberImport, error)
+	ListPendingTeamMemberImports(staleBefore time.Time, limit int) (model.TeamMemberImports, error)
+	ClaimTeamMemberImport(id string, at, staleBefore time.Time) error
+	FinishTeamMemberImport(i *model.TeamMemberImport) error
+
+	ListLocationsByMerchantId(merchantId string) (model.Locations, error)
+	CreateLocation(l *model.Location) error
+	ReadLocationById(id string) (*model.Location, error)
+	UpdateLocationById(id string, l *model.Location) error
+	DeleteLocation(id string) error
+
+	ListVerificationsByStatus(status string) (model.Verifications, error)
+	CreateVerification(v *model.Verification) error
+	ReadVerificationById(id string) (*model.Verification, error)
+	ReadVerificationByMerchantId(merchantId string) (*model.Verification, error)
+	ResubmitVerificationById(id string, v *model.Verification) error
+	UpdateVerificationStatusById(id, from, to, notes, reviewedBy string) error
+	CreateVerificationDocument(d *model.VerificationDocument) error
+	ReadVerificationDocumentById(id string) (*model.VerificationDocument, error)
+
+	ReadMerchantSettingsByMerchantId(merchantId string) (*model.MerchantSettings, error)
+	SaveMerchantSettings(s *model.MerchantSettings) error
+
+	ListPayoutAccountsByMerchantId(merchantId string) (model.PayoutAccounts, error)
+	ListPayoutAccountsByStatus(status string) (model.PayoutAccounts, error)
+	CreatePayoutAccount(p *model.PayoutAccount) error
+	ReadPayoutAccountById(id string) (*model.PayoutAccount, error)
+	UpdatePayoutAccountStatusById(id, status string) error
+	DeletePayoutAccount(id string) error
+
+	SearchProducts(merchantId string, search *model.ProductSearch) (model.Products, int64, error)
+	ListProductsByVariantIds(variantIds []string) (model.Products, error)
+	CreateProduct(p *model.Product) error
+	ReadProductById(id string) (*model.Product, error)
+	UpdateProductById(id string, p *model.Product) error
+	DeleteProduct(id string) error
+	CreateProductVariant(v *model.ProductVariant) error
+	ReadProductVariantById(id string) (*model.ProductVariant, error)
+	UpdateProductVariantById(id string, v *model.ProductVariant) error
+	DeleteProductVariant(id string) error
+
+	ListInventoryLevels(merchantId, variantId, locationId string) (model.InventoryLevels, error)
+	ApplyStockMovement(m *model.StockMovement) (*model.InventoryLevel, *model.StockAlert, error)
+	UpdateInventoryThreshold(merchantId, variantId, locationId string, threshold int64) error
+	ListStockMovements(merchantId, variantId, locationId string, limit, offset int) (model.StockMovements, error)
+	ListStockAlertsByMerchantId(merchantId string) (model.StockAlerts, error)
+
+	SearchOrders(merchantId string, search *model.OrderSearch) (model.Orders, int64, error)
+	CreateOrder(o *model.Order) error
+	ReadOrderById(id string) (*model.Order, error)
+	UpdateDraftOrderById(id string, o *model.Order) error
+	UpdateOrderStateById(id, from, to string, at time.Time) error
+
+	ListPaymentIntents(merchantId, orderId string) (model.PaymentIntents, error)
+	CreatePaymentIntent(p *model.PaymentIntent) error
+	ReadPaymentIntentById(id string) (*model.PaymentIntent, error)
+	ReadPaymentIntentByProviderReference(provider, reference string) (*model.PaymentIntent, error)
+	UpdatePaymentIntentById(id, from string, p *model.PaymentIntent) error
+	ProcessPaymentCallback(cb *model.ProcessedCallback, id, from string, p *model.PaymentIntent) error
+
+	ListRefundsByPaymentIntentId(paymentIntentId string) (model.Refunds, error)
+	ReadRefundById(id string) (*model.Refund, error)
+	ReserveRefund(rf *model.Refund) error
+	CompleteRefund(id, providerReference string) error
+	FailRefund(id, reason string) error
+	ListPendingRefunds(before time.Time, limit int) (model.Refunds, error)
+
+	ListCouponsByMerchantId(merchantId string) (model.Coupons, error)
+	CreateCoupon(c *model.Coupon) error
+	ReadCouponById(id string) (*model.Coupon, error)
+	ReadCouponByCode(merchantId, code string) (*model.Coupon, error)
+	UpdateCouponById(id string, c *model.Coupon) error
+	RedeemCoupon(rd *model.CouponRedemption, o *model.Order) error
+	UpdateRedeemedDraftOrder(rd *model.CouponRedemption, o *model.Order) error
+	ReleaseCoupon(couponId string, o *model.Order) error
+
+	ListInvoicesByMerchantId(merchantId string, limit, offset int) (model.Invoices, int64, error)
+	CreateInvoice(i *model.Invoice) error
+	ReadInvoiceById(id string) (*model.Invoice, error)
+	UpdateInvoiceDocumentsById(id, pdfKey, htmlKey string) error
+
+	ListWebhookSubscriptionsByMerchantId(merchantId string) (model.WebhookSubscriptions, error)
+	CreateWebhookSubscription(s *model.WebhookSubscription) error
+	ReadWebhookSubscriptionById(id string) (*model.WebhookSubscription, error)
+	UpdateWebhookSubscriptionById(id string, s *model.WebhookSubscription) error
+	DeleteWebhookSubscription(id string) error
+	CreateWebhookDeliveries(ds model.WebhookDeliveries) error
+	ListWebhookDeliveries(subscriptionId, status string, limit, offset int) (model.WebhookDeliveries, int64, error)
+	ReadWebhookDeliveryById(id string) (*model.WebhookDelivery, error)
+	ListDueWebhookDeliveries(now time.Time, limit int) (model.WebhookDeliveries, error)
+	ClaimWebhookDelivery(id string, attempts int, until time.Time) error
+	UpdateWebhookDeliveryById(id string, attempts int, d *model.WebhookDelivery) error
+	RedeliverWebhookDelivery(id string, at time.Time) error
+
+	CreateOutboxEvent(e *model.OutboxEvent) error
+	ListOutboxEventsByMerchantId(merchantId string, limit, offset int) (model.OutboxEvents, error)
+	ListPendingOutboxEvents(now time.Time, limit int) (model.OutboxEvents, error)
+	ClaimOutboxEvent(sequence uint64, attempts int, until time.Time) error
+	MarkOutboxEventPublished(sequence uint64, at time.Time) error
+	RecordOutboxEventFailure(sequence uint64, retryAt time.Time, errMessage string) error
+
+	CreateIdempotencyKey(k *model.IdempotencyKey) error
+	ReadIdempotencyKey(scope, key string) (*model.IdempotencyKey, error)
+	CompleteIdempotencyKey(id string, statusCode int, headers, body string) error
+	DeleteIdempotencyKey(id string) error
+	DeleteExpiredIdempotencyKeys(now time.Time) (int64, error)
+
+	CreateDataExport(e *model.DataExport) error
+	ReadDataExportById(id string) (*model.DataExport, error)
+	ListPendingDataExports(staleBefore time.Time, limit int) (model.DataExports, error)
+	ClaimDataExport(id string, at, staleBefore time.Time) error
+	FinishDataExport(e *model.DataExport) error
+	ListExpiredDataExports(now time.Time, limit int) (model.DataExports, error)
+	DeleteDataExport(id string) error
+
+	CreateDataKey(k *model.DataKey) error
+	ReadDataKeyById(id string) (*model.DataKey, error)
+	ListDataKeys() (model.DataKeys, error)
+	UpdateDataKeyWrappedKey(id, wrappedKey string) error
+	ReencryptRows(table, afterId string, limit int) (string, int, error)
 }
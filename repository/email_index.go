@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"merchant/model"
+	"merchant/util/cryptoutil"
+)
+
+// emailIndexScanBatchSize is the number of rows without an email index read
+// at a time while looking for an email among them.
+const emailIndexScanBatchSize = 500
+
+// indexUnindexedEmail finds the row of the table, among the rows written
+// before the blind index of their email existed, whose email has the index,
+// and sets the index of the row. Those rows have none until the keyring pass
+// sets it, so lookups by index fall back on this to find them meanwhile. It
+// returns gorm.ErrRecordNotFound when no such row holds the email.
+func (r *repo) indexUnindexedEmail(table, index string) (string, error) {
+	afterId := ""
+	for {
+		rows := make([]map[string]interface{}, 0)
+		err := r.DB.Table(table).Select("id", "email").
+			Where(`(email_index IS NULL OR email_index = '') AND id > ?`, afterId).
+			Order(`id`).Limit(emailIndexScanBatchSize).Find(&rows).Error
+		if err != nil {
+			return "", err
+		}
+		if len(rows) == 0 {
+			return "", gorm.ErrRecordNotFound
+		}
+
+		for _, row := range rows {
+			stored := columnString(row["email"])
+			var email cryptoutil.EncryptedString
+			if err := email.Scan(stored); err != nil {
+				return "", err
+			}
+
+			rowIndex, err := model.EmailIndex(email.String())
+			if err != nil {
+				return "", err
+			}
+			if rowIndex != index {
+				continue
+			}
+
+			id := columnString(row["id"])
+			err = r.DB.Table(table).Where(`id = ? AND email = ?`, id, stored).Update("email_index", index).Error
+			return id, err
+		}
+
+		afterId = columnString(rows[len(rows)-1]["id"])
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"time"
+
+	"merchant/model"
+	"merchant/util/cryptoutil"
+)
+
+// CreateIdempotencyKey returns ErrDuplicate when the key is taken already.
+func (r *repo) CreateIdempotencyKey(k *model.IdempotencyKey) error {
+	return translateError(r.DB.Create(k).Error)
+}
+
+func (r *repo) ReadIdempotencyKey(scope, key string) (*model.IdempotencyKey, error) {
+	k := &model.IdempotencyKey{}
+	if err := r.DB.Where("scope = ? AND `key` = ?", scope, key).First(k).Error; err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// CompleteIdempotencyKey stores the response to the request which took the
+// key, to be replayed to its retries.
+func (r *repo) CompleteIdempotencyKey(id string, statusCode int, headers, body string) error {
+	return r.DB.Model(&model.IdempotencyKey{}).Where(`id = ?`, id).Updates(map[string]interface{}{
+		"status_code":      statusCode,
+		"response_headers": headers,
+		"response_body":    cryptoutil.EncryptedString(body),
+	}).Error
+}
+
+func (r *repo) DeleteIdempotencyKey(id string) error {
+	return r.DB.Where(`id = ?`, id).Delete(&model.IdempotencyKey{}).Error
+}
+
+func (r *repo) DeleteExpiredIdempotencyKeys(now time.Time) (int64, error) {
+	res := r.DB.Where(`expires_at <= ?`, now).Delete(&model.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
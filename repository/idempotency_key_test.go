@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) Test_repository_Complete_Idempotency_Key_Encrypts_Body() {
+	query := "UPDATE `idempotency_keys` SET `response_body`=?,`response_headers`=?,`status_code`=?,`version`=version + 1,`updated_at`=? WHERE id = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs(activeCipherText{}, `{"Content-Type":"application/json"}`, 201, s.Time, "k1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.CompleteIdempotencyKey("k1", 201, `{"Content-Type":"application/json"}`, `{"email": "shop@example.com"}`)
+
+	require.NoError(s.T(), err)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"merchant/model"
+)
+
+// ListInventoryLevels returns the inventory levels of the merchant, optionally
+// only those of a variant or at a location.
+func (r *repo) ListInventoryLevels(merchantId, variantId, locationId string) (model.InventoryLevels, error) {
+	q := r.DB.Where(`merchant_id = ?`, merchantId)
+	if variantId != "" {
+		q = q.Where(`variant_id = ?`, variantId)
+	}
+	if locationId != "" {
+		q = q.Where(`location_id = ?`, locationId)
+	}
+
+	ls := make([]*model.InventoryLevel, 0)
+	err := q.Find(&ls).Error
+	return ls, err
+}
+
+// ApplyStockMovement applies the movement to the inventory level of its
+// variant and location, creating the level when there is none yet, and
+// records it in the ledger. The level is locked for the duration of the
+// transaction so concurrent movements are applied one after another. When
+// the movement takes the available stock to or below the low stock
+// threshold, an alert is recorded and returned as well, and an
+// inventory.low_stock event is written to the outbox.
+func (r *repo) ApplyStockMovement(m *model.StockMovement) (*model.InventoryLevel, *model.StockAlert, error) {
+	var (
+		level *model.InventoryLevel
+		alert *model.StockAlert
+	)
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		level, err = lockInventoryLevel(tx, m.MerchantID, m.VariantID, m.LocationID)
+		if err != nil {
+			return err
+		}
+
+		wasLow := level.IsLowStock()
+		if err := level.Apply(m); err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.InventoryLevel{}).Where(`id = ?`, level.ID).Updates(map[string]interface{}{
+			"on_hand":  level.OnHand,
+			"reserved": level.Reserved,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+
+		if !wasLow && level.IsLowStock() {
+			alert = level.NewStockAlert()
+			if err := tx.Create(alert).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(model.NewLowStockEvent(level, alert)).Error
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return level, alert, nil
+}
+
+func (r *repo) UpdateInventoryThreshold(merchantId, variantId, locationId string, threshold int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		level, err := lockInventoryLevel(tx, merchantId, variantId, locationId)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.InventoryLevel{}).Where(`id = ?`, level.ID).
+			Update("low_stock_threshold", threshold).Error
+	})
+}
+
+// ListStockMovements returns a page of the stock ledger of the merchant,
+// newest first, optionally only the movements of a variant or at a location.
+func (r *repo) ListStockMovements(merchantId, variantId, locationId string, limit, offset int) (model.StockMovements, error) {
+	q := r.DB.Where(`merchant_id = ?`, merchantId)
+	if variantId != "" {
+		q = q.Where(`variant_id = ?`, variantId)
+	}
+	if locationId != "" {
+		q = q.Where(`location_id = ?`, locationId)
+	}
+
+	ms := make([]*model.StockMovement, 0)
+	err := q.Order(`created_at DESC`).Limit(limit).Offset(offset).Find(&ms).Error
+	return ms, err
+}
+
+func (r *repo) ListStockAlertsByMerchantId(merchantId string) (model.StockAlerts, error) {
+	as := make([]*model.StockAlert, 0)
+	err := r.DB.Where(`merchant_id = ?`, merchantId).Order(`created_at DESC`).Find(&as).Error
+	return as, err
+}
+
+// lockInventoryLevel reads the inventory level of the variant at the location
+// for update, creating an empty one first when there is none.
+func lockInventoryLevel(tx *gorm.DB, merchantId, variantId, locationId string) (*model.InventoryLevel, error) {
+	empty := model.NewInventoryLevel(merchantId, variantId, locationId)
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(empty).Error; err != nil {
+		return nil, err
+	}
+
+	level := &model.InventoryLevel{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(`variant_id = ? AND location_id = ?`, variantId, locationId).First(level).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return level, nil
+}
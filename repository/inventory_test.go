@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Apply_Stock_Movement_Insufficient_Stock() {
+	movement := &model.StockMovement{
+		Model:      model.Model{ID: "5b0e1d0c-3a6f-4c55-9d3e-0a5f1e6c7b21"},
+		MerchantID: "8336fc00-43b5-40f7-83e3-27c018058054",
+		VariantID:  "6b1f2a8e-51e9-4f83-a4d6-2c1b7f0f4f10",
+		LocationID: "a3d5c1e2-7b9f-4d8a-9e6c-5f4b3a2d1c0e",
+		Kind:       model.StockMovementReservation,
+		Quantity:   8,
+	}
+
+	insert := "INSERT INTO `inventory_levels` (`id`,`created_at`,`updated_at`,`version`,`merchant_id`,`variant_id`,`location_id`,`on_hand`,`reserved`,`low_stock_threshold`) VALUES (?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`"
+	lock := "SELECT * FROM `inventory_levels` WHERE variant_id = ? AND location_id = ? ORDER BY `inventory_levels`.`id` LIMIT 1 FOR UPDATE"
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "variant_id", "location_id", "on_hand", "reserved", "low_stock_threshold"}).
+		AddRow("c0e1f2a3-b4c5-4d6e-8f70-8192a3b4c5d6", movement.MerchantID, movement.VariantID, movement.LocationID, 10, 3, 0)
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(insert).
+		WithArgs(sqlmock.AnyArg(), s.Time, s.Time, int64(0), movement.MerchantID, movement.VariantID, movement.LocationID, 0, 0, 0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery(lock).WithArgs(movement.VariantID, movement.LocationID).WillReturnRows(rows)
+	s.mock.ExpectRollback()
+
+	level, alert, err := s.repository.ApplyStockMovement(movement)
+
+	require.Equal(s.T(), model.ErrInsufficientStock, err)
+	require.Nil(s.T(), level)
+	require.Nil(s.T(), alert)
+}
+
+func (s *Suite) Test_repository_Apply_Stock_Movement_Low_Stock() {
+	movement := &model.StockMovement{
+		Model:      model.Model{ID: "5b0e1d0c-3a6f-4c55-9d3e-0a5f1e6c7b21"},
+		MerchantID: "8336fc00-43b5-40f7-83e3-27c018058054",
+		VariantID:  "6b1f2a8e-51e9-4f83-a4d6-2c1b7f0f4f10",
+		LocationID: "a3d5c1e2-7b9f-4d8a-9e6c-5f4b3a2d1c0e",
+		Kind:       model.StockMovementReservation,
+		Quantity:   2,
+	}
+	levelId := "c0e1f2a3-b4c5-4d6e-8f70-8192a3b4c5d6"
+
+	insert := "INSERT INTO `inventory_levels` (`id`,`created_at`,`updated_at`,`version`,`merchant_id`,`variant_id`,`location_id`,`on_hand`,`reserved`,`low_stock_threshold`) VALUES (?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `id`=`id`"
+	lock := "SELECT * FROM `inventory_levels` WHERE variant_id = ? AND location_id = ? ORDER BY `inventory_levels`.`id` LIMIT 1 FOR UPDATE"
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "variant_id", "location_id", "on_hand", "reserved", "low_stock_threshold"}).
+		AddRow(levelId, movement.MerchantID, movement.VariantID, movement.LocationID, 10, 3, 5)
+	update := "UPDATE `inventory_levels` SET `on_hand`=?,`reserved`=?,`version`=version + 1,`updated_at`=? WHERE id = ?"
+	insertMovement := "INSERT INTO `stock_movements` (`id`,`created_at`,`updated_at`,`version`,`merchant_id`,`variant_id`,`location_id`,`kind`,`quantity`,`on_hand`,`reserved`,`reference`,`reason`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)"
+	insertAlert := "INSERT INTO `stock_alerts` (`id`,`created_at`,`updated_at`,`version`,`merchant_id`,`variant_id`,`location_id`,`available`,`threshold`) VALUES (?,?,?,?,?,?,?,?,?)"
+	insertEvent := "INSERT INTO `outbox_events` (`id`,`type`,`aggregate_type`,`aggregate_id`,`merchant_id`,`payload`,`occurred_at`,`attempts`,`available_at`,`published_at`,`last_error`) VALUES (?,?,?,?,?,?,?,?,?,?,?)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(insert).
+		WithArgs(sqlmock.AnyArg(), s.Time, s.Time, int64(0), movement.MerchantID, movement.VariantID, movement.LocationID, 0, 0, 0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery(lock).WithArgs(movement.VariantID, movement.LocationID).WillReturnRows(rows)
+	s.mock.ExpectExec(update).
+		WithArgs(int64(10), int64(5), s.Time, levelId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(insertMovement).
+		WithArgs(movement.ID, s.Time, s.Time, int64(0), movement.MerchantID, movement.VariantID, movement.LocationID, movement.Kind, int64(2), int64(10), int64(5), "", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(insertAlert).
+		WithArgs(sqlmock.AnyArg(), s.Time, s.Time, int64(0), movement.MerchantID, movement.VariantID, movement.LocationID, int64(5), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(insertEvent).
+		WithArgs(sqlmock.AnyArg(), model.EventInventoryLowStock, model.AggregateInventoryLevel, levelId, movement.MerchantID, activeCipherText{}, s.Time, 0, s.Time, nil, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	level, alert, err := s.repository.ApplyStockMovement(movement)
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(5), level.Available())
+	require.NotNil(s.T(), alert)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"merchant/model"
+)
+
+func (r *repo) ListInvoicesByMerchantId(merchantId string, limit, offset int) (model.Invoices, int64, error) {
+	var total int64
+	if err := r.DB.Model(&model.Invoice{}).Where(`merchant_id = ?`, merchantId).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	is := make([]*model.Invoice, 0)
+	err := r.DB.Where(`merchant_id = ?`, merchantId).Order(`number DESC`).
+		Limit(limit).Offset(offset).Find(&is).Error
+	return is, total, err
+}
+
+// CreateInvoice numbers the invoice with the next number of its merchant and
+// stores it. The merchant's sequence is locked until the invoice is stored,
+// and rolled back with it on failure, so numbers are issued without gaps. It
+// returns ErrDuplicate when the order already has an invoice.
+func (r *repo) CreateInvoice(i *model.Invoice) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		seq := &model.InvoiceSequence{MerchantID: i.MerchantID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(seq).Error; err != nil {
+			return err
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(`merchant_id = ?`, i.MerchantID).First(seq).Error
+		if err != nil {
+			return err
+		}
+
+		seq.LastNumber++
+		err = tx.Model(&model.InvoiceSequence{}).Where(`merchant_id = ?`, i.MerchantID).
+			Update("last_number", seq.LastNumber).Error
+		if err != nil {
+			return err
+		}
+
+		i.Number = seq.LastNumber
+		return translateError(tx.Create(i).Error)
+	})
+}
+
+func (r *repo) ReadInvoiceById(id string) (*model.Invoice, error) {
+	i := &model.Invoice{}
+	if err := r.DB.Where(`id = ?`, id).First(i).Error; err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func (r *repo) UpdateInvoiceDocumentsById(id, pdfKey, htmlKey string) error {
+	return r.DB.Model(&model.Invoice{}).Where(`id = ?`, id).Updates(map[string]interface{}{
+		"pdf_key":  pdfKey,
+		"html_key": htmlKey,
+	}).Error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Create_Invoice_Takes_Next_Number() {
+	invoice := &model.Invoice{
+		Model:      model.Model{ID: "2d4f6a8c-0e1b-4c3d-8e5f-7a9b1c3d5e7f"},
+		MerchantID: "8336fc00-43b5-40f7-83e3-27c018058054",
+		OrderID:    "9a7b5c3d-1e2f-4a6b-8c0d-2e4f6a8b0c1d",
+		Currency:   "EUR",
+		Total:      2500,
+		IssuedAt:   time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC),
+		Snapshot:   &model.InvoiceSnapshot{BusinessName: "Corner Shop", Lines: []*model.InvoiceLine{}},
+	}
+
+	sequence := "INSERT INTO `invoice_sequences` (`merchant_id`,`last_number`) VALUES (?,?) ON DUPLICATE KEY UPDATE `merchant_id`=`merchant_id`"
+	lock := "SELECT * FROM `invoice_sequences` WHERE merchant_id = ? AND `invoice_sequences`.`merchant_id` = ? ORDER BY `invoice_sequences`.`merchant_id` LIMIT 1 FOR UPDATE"
+	increment := "UPDATE `invoice_sequences` SET `last_number`=? WHERE merchant_id = ?"
+	insert := "INSERT INTO `invoices` (`id`,`created_at`,`updated_at`,`version`,`merchant_id`,`number`,`order_id`,`receipt`,`customer_name`,`customer_email`,`currency`,`subtotal`,`discount_total`,`tax_total`,`total`,`issued_at`,`snapshot`,`pdf_key`,`html_key`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(sequence).WithArgs(invoice.MerchantID, 0).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery(lock).WithArgs(invoice.MerchantID, invoice.MerchantID).
+		WillReturnRows(sqlmock.NewRows([]string{"merchant_id", "last_number"}).AddRow(invoice.MerchantID, 41))
+	s.mock.ExpectExec(increment).WithArgs(int64(42), invoice.MerchantID).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(insert).
+		WithArgs(invoice.ID, s.Time, s.Time, int64(0), invoice.MerchantID, int64(42), invoice.OrderID, false, "", "", "EUR", int64(0), int64(0), int64(0), int64(2500), invoice.IssuedAt, `{"businessName":"Corner Shop","lines":[]}`, "", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.CreateInvoice(invoice)
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(42), invoice.Number)
+}
+
+func (s *Suite) Test_repository_Create_Invoice_Duplicate_Rolls_Back_Number() {
+	invoice := &model.Invoice{
+		Model:      model.Model{ID: "2d4f6a8c-0e1b-4c3d-8e5f-7a9b1c3d5e7f"},
+		MerchantID: "8336fc00-43b5-40f7-83e3-27c018058054",
+		OrderID:    "9a7b5c3d-1e2f-4a6b-8c0d-2e4f6a8b0c1d",
+	}
+
+	sequence := "INSERT INTO `invoice_sequences` (`merchant_id`,`last_number`) VALUES (?,?) ON DUPLICATE KEY UPDATE `merchant_id`=`merchant_id`"
+	lock := "SELECT * FROM `invoice_sequences` WHERE merchant_id = ? AND `invoice_sequences`.`merchant_id` = ? ORDER BY `invoice_sequences`.`merchant_id` LIMIT 1 FOR UPDATE"
+	increment := "UPDATE `invoice_sequences` SET `last_number`=? WHERE merchant_id = ?"
+	insert := "INSERT INTO `invoices` (`id`,`created_at`,`updated_at`,`version`,`merchant_id`,`number`,`order_id`,`receipt`,`customer_name`,`customer_email`,`currency`,`subtotal`,`discount_total`,`tax_total`,`total`,`issued_at`,`snapshot`,`pdf_key`,`html_key`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(sequence).WithArgs(invoice.MerchantID, 0).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery(lock).WithArgs(invoice.MerchantID, invoice.MerchantID).
+		WillReturnRows(sqlmock.NewRows([]string{"merchant_id", "last_number"}).AddRow(invoice.MerchantID, 7))
+	s.mock.ExpectExec(increment).WithArgs(int64(8), invoice.MerchantID).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(insert).WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	s.mock.ExpectRollback()
+
+	err := s.repository.CreateInvoice(invoice)
+
+	require.Equal(s.T(), ErrDuplicate, err)
+}
+
+func (s *Suite) Test_repository_Read_Invoice_Snapshot() {
+	id := "2d4f6a8c-0e1b-4c3d-8e5f-7a9b1c3d5e7f"
+	rows := sqlmock.NewRows([]string{"id", "number", "snapshot"}).
+		AddRow(id, 7, `{"businessName":"Corner Shop","lines":[{"description":"T-shirt","quantity":1,"unitPrice":2500,"discount":0,"tax":0,"total":2500}]}`)
+
+	query := "SELECT * FROM `invoices` WHERE id = ? ORDER BY `invoices`.`id` LIMIT 1"
+	s.mock.ExpectQuery(query).WithArgs(id).WillReturnRows(rows)
+
+	invoice, err := s.repository.ReadInvoiceById(id)
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "Corner Shop", invoice.Snapshot.BusinessName)
+	require.Equal(s.T(), "T-shirt", invoice.Snapshot.Lines[0].Description)
+}
+
+func (s *Suite) Test_repository_Read_Invoice_Without_Snapshot() {
+	id := "2d4f6a8c-0e1b-4c3d-8e5f-7a9b1c3d5e7f"
+	rows := sqlmock.NewRows([]string{"id", "number", "snapshot"}).AddRow(id, 7, nil)
+
+	query := "SELECT * FROM `invoices` WHERE id = ? ORDER BY `invoices`.`id` LIMIT 1"
+	s.mock.ExpectQuery(query).WithArgs(id).WillReturnRows(rows)
+
+	invoice, err := s.repository.ReadInvoiceById(id)
+
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), invoice.Snapshot)
+}
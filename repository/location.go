@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+func (r *repo) ListLocationsByMerchantId(merchantId string) (model.Locations, error) {
+	ls := make([]*model.Location, 0)
+	err := r.DB.Preload("OpeningHours").Preload("HolidayExceptions").
+		Where(`merchant_id = ?`, merchantId).Find(&ls).Error
+	return ls, err
+}
+
+func (r *repo) CreateLocation(l *model.Location) error {
+	return r.DB.Create(&l).Error
+}
+
+func (r *repo) ReadLocationById(id string) (*model.Location, error) {
+	l := &model.Location{}
+	if err := r.DB.Preload("OpeningHours").Preload("HolidayExceptions").
+		Where(`id = ?`, id).First(l).Error; err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// UpdateLocationById overwrites the location and replaces its opening hours
+// and holiday exceptions with the ones given.
+func (r *repo) UpdateLocationById(id string, l *model.Location) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Location{}).Where(`id = ?`, id).Updates(map[string]interface{}{
+			"name":          l.Name,
+			"address_line1": l.AddressLine1,
+			"address_line2": l.AddressLine2,
+			"city":          l.City,
+			"region":        l.Region,
+			"postal_code":   l.PostalCode,
+			"country_code":  l.CountryCode,
+			"latitude":      l.Latitude,
+			"longitude":     l.Longitude,
+			"phone":         l.Phone,
+			"timezone":      l.Timezone,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where(`location_id = ?`, id).Delete(&model.OpeningHours{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(`location_id = ?`, id).Delete(&model.HolidayException{}).Error; err != nil {
+			return err
+		}
+
+		if len(l.OpeningHours) > 0 {
+			if err := tx.Create(&l.OpeningHours).Error; err != nil {
+				return err
+			}
+		}
+		if len(l.HolidayExceptions) > 0 {
+			if err := tx.Create(&l.HolidayExceptions).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *repo) DeleteLocation(id string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(`location_id = ?`, id).Delete(&model.OpeningHours{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(`location_id = ?`, id).Delete(&model.HolidayException{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where(`id = ?`, id).Delete(&model.Location{}).Error
+	})
+}
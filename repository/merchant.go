@@ -1,15 +1,35 @@
 package repository
 
-import "merchant/model"
+import (
+	"time"
 
-func (r *repo) ListMerchants() (model.Merchants, error) {
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// ListMerchants returns the merchants whose metadata holds every pair of the
+// given metadata.
+func (r *repo) ListMerchants(metadata model.Metadata) (model.Merchants, error) {
+	ms := make([]*model.Merchant, 0)
+	err := r.DB.Scopes(metadataFilter(metadata)).Find(&ms).Error
+	return ms, err
+}
+
+// ListMerchantsByIdsAfter returns up to limit merchants of the ids whose id
+// follows afterId, in id order, so that they may be read a batch at a time.
+func (r *repo) ListMerchantsByIdsAfter(ids []string, afterId string, limit int) (model.Merchants, error) {
 	ms := make([]*model.Merchant, 0)
-	err := r.DB.Find(&ms).Error
+	err := r.DB.Where(`id IN ? AND id > ?`, ids, afterId).Order(`id`).Limit(limit).Find(&ms).Error
 	return ms, err
 }
 
 func (r *repo) CreateMerchant(u *model.Merchant) error {
-	return r.DB.Create(&u).Error
+	if err := r.DB.Create(&u).Error; err != nil {
+		return err
+	}
+
+	return r.createMerchantVersions(`id = ?`, u.ID)
 }
 
 func (r *repo) ReadMerchantById(id string) (*model.Merchant, error) {
@@ -21,19 +41,136 @@ func (r *repo) ReadMerchantById(id string) (*model.Merchant, error) {
 	return m, nil
 }
 
+// ReadMerchantByEmail looks the email up by its blind index, then among the
+// merchants written before the index existed which the keyring pass has yet to
+// index.
 func (r *repo) ReadMerchantByEmail(email string) (*model.Merchant, error) {
+	index, err := model.EmailIndex(email)
+	if err != nil {
+		return nil, err
+	}
+
 	m := &model.Merchant{}
-	if err := r.DB.Where(`email = ?`, email).First(m).Error; err != nil {
+	err = r.DB.Where(`email_index = ?`, index).First(m).Error
+	if err == gorm.ErrRecordNotFound {
+		var id string
+		if id, err = r.indexUnindexedEmail(`merchants`, index); err == nil {
+			err = r.DB.Where(`id = ?`, id).First(m).Error
+		}
+	}
+	if err != nil {
 		return nil, err
 	}
 
 	return m, nil
 }
 
-func (r *repo) UpdateMerchantDescriptionById(id, des string) error {
-	return r.DB.Model(&model.Merchant{}).Where(`id = ?`, id).Update("description", des).Error
+// UpdateMerchantById updates the description of the merchant, and its
+// metadata unless nil. It returns ErrConflict when the merchant is no longer
+// at the given version.
+func (r *repo) UpdateMerchantById(id string, version int64, m *model.Merchant) error {
+	fields := map[string]interface{}{
+		"description": m.Description,
+	}
+	if m.Metadata != nil {
+		fields["metadata"] = m.Metadata
+	}
+
+	res := r.DB.Model(&model.Merchant{}).Where(`id = ? AND version = ?`, id, version).Updates(fields)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return r.createMerchantVersions(`id = ?`, id)
 }
 
-func (r *repo) DeleteMerchant(id string) error {
-	return r.DB.Where(`id = ?`, id).Delete(&model.Merchant{}).Error
+// UpdateMerchantLogoById replaces the logo of the merchant. It returns
+// ErrConflict when the merchant is no longer at the given version.
+func (r *repo) UpdateMerchantLogoById(id string, version int64, key, thumbnailKey string) error {
+	res := r.DB.Model(&model.Merchant{}).Where(`id = ? AND version = ?`, id, version).Updates(map[string]interface{}{
+		"logo_key":           key,
+		"logo_thumbnail_key": thumbnailKey,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return r.createMerchantVersions(`id = ?`, id)
+}
+
+// UpdateMerchantBannerById replaces the banner of the merchant. It returns
+// ErrConflict when the merchant is no longer at the given version.
+func (r *repo) UpdateMerchantBannerById(id string, version int64, key, thumbnailKey string) error {
+	res := r.DB.Model(&model.Merchant{}).Where(`id = ? AND version = ?`, id, version).Updates(map[string]interface{}{
+		"banner_key":           key,
+		"banner_thumbnail_key": thumbnailKey,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return r.createMerchantVersions(`id = ?`, id)
+}
+
+// RequestMerchantClosure schedules the erasure of the merchant at closesAt.
+// It returns ErrConflict when the merchant is no longer at the given
+// version.
+func (r *repo) RequestMerchantClosure(id string, version int64, requestedAt, closesAt time.Time) error {
+	res := r.DB.Model(&model.Merchant{}).Where(`id = ? AND version = ?`, id, version).Updates(map[string]interface{}{
+		"closure_requested_at": requestedAt,
+		"closes_at":            closesAt,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return r.createMerchantVersions(`id = ?`, id)
+}
+
+// CancelMerchantClosure keeps the merchant open, provided the grace period
+// of its closure has not ended by now. It returns
+// model.ErrClosureGracePeriodOver when it has and ErrConflict when the
+// merchant is no longer at the given version.
+func (r *repo) CancelMerchantClosure(id string, version int64, now time.Time) error {
+	res := r.DB.Model(&model.Merchant{}).Where(`id = ? AND version = ? AND closes_at > ?`, id, version, now).Updates(map[string]interface{}{
+		"closure_requested_at": nil,
+		"closes_at":            nil,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		var due int64
+		err := r.DB.Model(&model.Merchant{}).Where(`id = ? AND closes_at <= ?`, id, now).Count(&due).Error
+		if err != nil {
+			return err
+		}
+		if due > 0 {
+			return model.ErrClosureGracePeriodOver
+		}
+
+		return ErrConflict
+	}
+
+	return r.createMerchantVersions(`id = ?`, id)
+}
+
+// ListMerchantsDueForClosure returns the merchants whose grace period ended
+// before now, those due first.
+func (r *repo) ListMerchantsDueForClosure(now time.Time, limit int) (model.Merchants, error) {
+	ms := make([]*model.Merchant, 0)
+	err := r.DB.Where(`closes_at <= ?`, now).Order(`closes_at`).Limit(limit).Find(&ms).Error
+	return ms, err
 }
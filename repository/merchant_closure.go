@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"merchant/model"
+)
+
+// merchantErasures lists the records deleted along with a merchant, each
+// selected by a condition on the merchant id, children ahead of their
+// parents.
+var merchantErasures = []struct {
+	value interface{}
+	where string
+}{
+	{&model.TeamMemberVersion{}, `merchant_id = ?`},
+	{&model.TeamMember{}, `merchant_id = ?`},
+	{&model.TeamMemberImport{}, `merchant_id = ?`},
+	{&model.OpeningHours{}, `location_id IN (SELECT id FROM locations WHERE merchant_id = ?)`},
+	{&model.HolidayException{}, `location_id IN (SELECT id FROM locations WHERE merchant_id = ?)`},
+	{&model.Location{}, `merchant_id = ?`},
+	{&model.VerificationDocument{}, `verification_id IN (SELECT id FROM verifications WHERE merchant_id = ?)`},
+	{&model.Verification{}, `merchant_id = ?`},
+	{&model.MerchantSettings{}, `merchant_id = ?`},
+	{&model.PayoutAccount{}, `merchant_id = ?`},
+	{&model.InventoryLevel{}, `merchant_id = ?`},
+	{&model.StockMovement{}, `merchant_id = ?`},
+	{&model.StockAlert{}, `merchant_id = ?`},
+	{&model.ProductVariant{}, `merchant_id = ?`},
+	{&model.Product{}, `merchant_id = ?`},
+	{&model.CouponRedemption{}, `merchant_id = ?`},
+	{&model.Coupon{}, `merchant_id = ?`},
+	{&model.WebhookDelivery{}, `merchant_id = ?`},
+	{&model.WebhookSubscription{}, `merchant_id = ?`},
+	{&model.DataExport{}, `merchant_id = ?`},
+	{&model.IdempotencyKey{}, `scope = ?`},
+	{&model.OutboxEvent{}, `merchant_id = ?`},
+	{&model.MerchantVersion{}, `merchant_id = ?`},
+}
+
+// EraseMerchant deletes the merchant along with its team, catalog, stock,
+// settings and every other record only kept for it to trade. Orders,
+// payments, refunds and invoices are kept for bookkeeping, stripped of the
+// contact details of customers and of free text notes; invoices are kept as
+// issued. The versions of the merchant and of its team go too. The children
+// of the merchant are detached from it. Call it on the repository of a
+// transaction, so that the merchant is erased entirely or not at all.
+//
+// It returns ErrConflict when the merchant is not due for closure at now.
+// Otherwise it returns the number of records erased or anonymised, by table,
+// and the keys of the blobs of the erased records, which are left for the
+// caller to delete once the transaction commits.
+func (r *repo) EraseMerchant(id string, now time.Time) (map[string]int64, []string, error) {
+	m := &model.Merchant{}
+	err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(`id = ? AND closes_at <= ?`, id, now).First(m).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, ErrConflict
+		}
+		return nil, nil, err
+	}
+
+	var keys []string
+	for _, k := range []string{m.LogoKey, m.LogoThumbnailKey, m.BannerKey, m.BannerThumbnailKey} {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	for _, q := range []*gorm.DB{
+		r.DB.Model(&model.VerificationDocument{}).
+			Where(`verification_id IN (SELECT id FROM verifications WHERE merchant_id = ?)`, id),
+		r.DB.Model(&model.TeamMemberImport{}).Where(`merchant_id = ?`, id),
+		r.DB.Model(&model.DataExport{}).Where(`merchant_id = ?`, id),
+	} {
+		var ks []string
+		if err := q.Where(`blob_key <> ''`).Pluck("blob_key", &ks).Error; err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, ks...)
+	}
+
+	erased := make(map[string]int64)
+	for _, e := range merchantErasures {
+		res := r.DB.Where(e.where, id).Delete(e.value)
+		if res.Error != nil {
+			return nil, nil, res.Error
+		}
+		erased[res.Statement.Table] = res.RowsAffected
+	}
+
+	res := r.DB.Model(&model.Order{}).Where(`merchant_id = ?`, id).Updates(map[string]interface{}{
+		"customer_name":  "",
+		"customer_email": "",
+		"note":           "",
+	})
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	erased["orders"] = res.RowsAffected
+
+	res = r.DB.Model(&model.Refund{}).Where(`merchant_id = ?`, id).Update("note", "")
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	erased["refunds"] = res.RowsAffected
+
+	// Children of the merchant carry on at the top of the hierarchy.
+	var children []string
+	if err := r.DB.Model(&model.Merchant{}).Where(`parent_id = ?`, id).Pluck("id", &children).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(children) > 0 {
+		res = r.DB.Model(&model.Merchant{}).Where(`id IN ?`, children).Update("parent_id", nil)
+		if res.Error != nil {
+			return nil, nil, res.Error
+		}
+		if err := r.createMerchantVersions(`id IN ?`, children); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	res = r.DB.Where(`id = ?`, id).Delete(&model.Merchant{})
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	erased["merchants"] = res.RowsAffected
+
+	return erased, keys, nil
+}
+
+func (r *repo) CreateMerchantTombstone(t *model.MerchantTombstone) error {
+	return r.DB.Create(t).Error
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Erase_Merchant() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+	byLocation := "location_id IN (SELECT id FROM locations WHERE merchant_id = ?)"
+	byVerification := "verification_id IN (SELECT id FROM verifications WHERE merchant_id = ?)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("SELECT * FROM `merchants` WHERE id = ? AND closes_at <= ? ORDER BY `merchants`.`id` LIMIT 1 FOR UPDATE").
+		WithArgs(id, s.Time).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "logo_key", "logo_thumbnail_key"}).
+			AddRow(id, "merchants/logo.png", "merchants/logo-thumb.png"))
+	s.mock.ExpectQuery("SELECT `blob_key` FROM `verification_documents` WHERE " + byVerification + " AND blob_key <> ''").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"blob_key"}).AddRow("verifications/passport.pdf"))
+	s.mock.ExpectQuery("SELECT `blob_key` FROM `team_member_imports` WHERE merchant_id = ? AND blob_key <> ''").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"blob_key"}))
+	s.mock.ExpectQuery("SELECT `blob_key` FROM `data_exports` WHERE merchant_id = ? AND blob_key <> ''").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"blob_key"}).AddRow("exports/archive.zip"))
+
+	for _, d := range []struct {
+		table string
+		where string
+		rows  int64
+	}{
+		{"team_member_versions", "merchant_id = ?", 6},
+		{"team_members", "merchant_id = ?", 3},
+		{"team_member_imports", "merchant_id = ?", 0},
+		{"opening_hours", byLocation, 7},
+		{"holiday_exceptions", byLocation, 0},
+		{"locations", "merchant_id = ?", 1},
+		{"verification_documents", byVerification, 1},
+		{"verifications", "merchant_id = ?", 1},
+		{"merchant_settings", "merchant_id = ?", 1},
+		{"payout_accounts", "merchant_id = ?", 1},
+		{"inventory_levels", "merchant_id = ?", 0},
+		{"stock_movements", "merchant_id = ?", 0},
+		{"stock_alerts", "merchant_id = ?", 0},
+		{"product_variants", "merchant_id = ?", 0},
+		{"products", "merchant_id = ?", 0},
+		{"coupon_redemptions", "merchant_id = ?", 0},
+		{"coupons", "merchant_id = ?", 0},
+		{"webhook_deliveries", "merchant_id = ?", 0},
+		{"webhook_subscriptions", "merchant_id = ?", 0},
+		{"data_exports", "merchant_id = ?", 1},
+		{"idempotency_keys", "scope = ?", 2},
+		{"outbox_events", "merchant_id = ?", 5},
+		{"merchant_versions", "merchant_id = ?", 4},
+	} {
+		s.mock.ExpectExec("DELETE FROM `" + d.table + "` WHERE " + d.where).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, d.rows))
+	}
+
+	s.mock.ExpectExec("UPDATE `orders` SET `customer_email`=?,`customer_name`=?,`note`=?,`version`=version + 1,`updated_at`=? WHERE merchant_id = ?").
+		WithArgs("", "", "", s.Time, id).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	s.mock.ExpectExec("UPDATE `refunds` SET `note`=?,`version`=version + 1,`updated_at`=? WHERE merchant_id = ?").
+		WithArgs("", s.Time, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery("SELECT `id` FROM `merchants` WHERE parent_id = ?").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("brand").AddRow("store"))
+	s.mock.ExpectExec("UPDATE `merchants` SET `parent_id`=?,`version`=version + 1,`updated_at`=? WHERE id IN (?,?)").
+		WithArgs(nil, s.Time, "brand", "store").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectExec("INSERT INTO merchant_versions (id, merchant_id, version, created_at, "+merchantVersionColumns+") "+
+		"SELECT UUID(), id, version, updated_at, "+merchantVersionColumns+" FROM merchants WHERE id IN (?,?)").
+		WithArgs("brand", "store").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	s.mock.ExpectExec("DELETE FROM `merchants` WHERE id = ?").
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	var (
+		erased map[string]int64
+		keys   []string
+	)
+	err := s.repository.Transaction(func(tx Repository) error {
+		var err error
+		erased, keys, err = tx.EraseMerchant(id, time.Now())
+		return err
+	})
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"merchants/logo.png", "merchants/logo-thumb.png", "verifications/passport.pdf", "exports/archive.zip"}, keys)
+	require.Equal(s.T(), int64(3), erased["team_members"])
+	require.Equal(s.T(), int64(4), erased["merchant_versions"])
+	require.Equal(s.T(), int64(7), erased["opening_hours"])
+	require.Equal(s.T(), int64(4), erased["orders"])
+	require.Equal(s.T(), int64(1), erased["merchants"])
+}
+
+func (s *Suite) Test_repository_Erase_Merchant_Not_Due() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery("SELECT * FROM `merchants` WHERE id = ? AND closes_at <= ? ORDER BY `merchants`.`id` LIMIT 1 FOR UPDATE").
+		WithArgs(id, s.Time).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectRollback()
+
+	err := s.repository.Transaction(func(tx Repository) error {
+		_, _, err := tx.EraseMerchant(id, time.Now())
+		return err
+	})
+
+	require.Equal(s.T(), ErrConflict, err)
+}
+
+func (s *Suite) Test_repository_Cancel_Merchant_Closure_Grace_Period_Over() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE `merchants` SET `closes_at`=?,`closure_requested_at`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ? AND closes_at > ?").
+		WithArgs(nil, nil, s.Time, id, int64(3), s.Time).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectQuery("SELECT count(1) FROM `merchants` WHERE id = ? AND closes_at <= ?").
+		WithArgs(id, s.Time).
+		WillReturnRows(sqlmock.NewRows([]string{"count(1)"}).AddRow(1))
+	s.mock.ExpectRollback()
+
+	err := s.repository.Transaction(func(tx Repository) error {
+		return tx.CancelMerchantClosure(id, 3, time.Now())
+	})
+
+	require.Equal(s.T(), model.ErrClosureGracePeriodOver, err)
+}
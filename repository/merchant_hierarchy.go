@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm/clause"
+
+	"merchant/model"
+)
+
+// maxHierarchyDepth bounds how many levels ListMerchantDescendantIds walks
+// down, so a hierarchy corrupted into a cycle can not keep it going.
+const maxHierarchyDepth = 32
+
+// ListMerchantDescendantIds returns the ids of the children of the merchant,
+// of their children, and so on down the hierarchy. The hierarchy is walked
+// one level per query rather than with a recursive query, which MySQL 5.7
+// does not support.
+func (r *repo) ListMerchantDescendantIds(id string) ([]string, error) {
+	ids := make([]string, 0)
+	seen := map[string]bool{id: true}
+
+	level := []string{id}
+	for depth := 0; len(level) > 0; depth++ {
+		if depth == maxHierarchyDepth {
+			return nil, fmt.Errorf("merchant %s has descendants more than %d levels down", id, maxHierarchyDepth)
+		}
+
+		children := make([]string, 0)
+		if err := r.DB.Model(&model.Merchant{}).Where(`parent_id IN ?`, level).Order(`id`).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+
+		level = level[:0]
+		for _, child := range children {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			ids = append(ids, child)
+			level = append(level, child)
+		}
+	}
+
+	return ids, nil
+}
+
+// ListMerchantsByIds returns the merchants of the ids whose metadata holds
+// every pair of the given metadata.
+func (r *repo) ListMerchantsByIds(ids []string, metadata model.Metadata) (model.Merchants, error) {
+	ms := make([]*model.Merchant, 0)
+	err := r.DB.Scopes(metadataFilter(metadata)).Where(`id IN ?`, ids).Order(`id`).Find(&ms).Error
+	return ms, err
+}
+
+// UpdateMerchantParentById moves the merchant under another parent, or to
+// the top of the hierarchy when parentId is nil. It returns ErrConflict when
+// the merchant is no longer at the given version, and
+// model.ErrMerchantParentCycle when the parent is the merchant itself or one
+// of its descendants. It is meant to run in a transaction: the ancestors of
+// the parent are checked under lock once the merchant is moved, so moves
+// racing to close a cycle wait on each other, and the later one either finds
+// the cycle or is rolled back with ErrConflict.
+func (r *repo) UpdateMerchantParentById(id string, version int64, parentId *string) error {
+	res := r.DB.Model(&model.Merchant{}).Where(`id = ? AND version = ?`, id, version).Update("parent_id", parentId)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	if parentId != nil {
+		if err := r.checkMerchantAncestors(id, *parentId); err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrLockDeadlock {
+				return ErrConflict
+			}
+			return err
+		}
+	}
+
+	return r.createMerchantVersions(`id = ?`, id)
+}
+
+// checkMerchantAncestors walks up the hierarchy from parentId, locking each
+// merchant on the way, and returns model.ErrMerchantParentCycle when it
+// reaches the merchant id.
+func (r *repo) checkMerchantAncestors(id, parentId string) error {
+	next := &parentId
+	for depth := 0; next != nil; depth++ {
+		if *next == id {
+			return model.ErrMerchantParentCycle
+		}
+		if depth == maxHierarchyDepth {
+			return fmt.Errorf("merchant %s has ancestors more than %d levels up", parentId, maxHierarchyDepth)
+		}
+
+		ancestor := &model.Merchant{}
+		err := r.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "parent_id").
+			Where(`id = ?`, *next).First(ancestor).Error
+		if err != nil {
+			return err
+		}
+		next = ancestor.ParentID
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_List_Merchant_Descendant_Ids() {
+	query := "SELECT `id` FROM `merchants` WHERE parent_id IN (?) ORDER BY id"
+
+	s.mock.ExpectQuery(query).WithArgs("organisation").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("brand"))
+	s.mock.ExpectQuery(query).WithArgs("brand").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("store"))
+	s.mock.ExpectQuery(query).WithArgs("store").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	res, err := s.repository.ListMerchantDescendantIds("organisation")
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"brand", "store"}, res)
+}
+
+func (s *Suite) Test_repository_List_Merchant_Descendant_Ids_Stops_At_Cycle() {
+	query := "SELECT `id` FROM `merchants` WHERE parent_id IN (?) ORDER BY id"
+
+	// The brand was made the parent of its own organisation.
+	s.mock.ExpectQuery(query).WithArgs("organisation").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("brand"))
+	s.mock.ExpectQuery(query).WithArgs("brand").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("organisation"))
+
+	res, err := s.repository.ListMerchantDescendantIds("organisation")
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"brand"}, res)
+}
+
+func (s *Suite) Test_repository_List_Team_Members_By_Merchant_Ids() {
+	query := "SELECT * FROM `team_members` WHERE merchant_id IN (?,?) AND JSON_CONTAINS(metadata, ?) ORDER BY merchant_id, id"
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "email"}).
+		AddRow("a", "brand", "jane@example.com").
+		AddRow("b", "store", "john@example.com")
+
+	s.mock.ExpectQuery(query).WithArgs("brand", "store", `{"region":"north"}`).WillReturnRows(rows)
+
+	res, err := s.repository.ListTeamMembersByMerchantIds([]string{"brand", "store"}, model.Metadata{"region": "north"})
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), res, 2)
+	require.Equal(s.T(), "store", res[1].MerchantID)
+}
+
+func (s *Suite) Test_repository_Update_Merchant_Parent_Version_Moved_On() {
+	parentId := "organisation"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE `merchants` SET `parent_id`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?").
+		WithArgs(parentId, s.Time, "brand", 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repository.UpdateMerchantParentById("brand", 2, &parentId)
+
+	require.Equal(s.T(), ErrConflict, err)
+}
+
+func (s *Suite) Test_repository_Update_Merchant_Parent() {
+	parentId := "brand"
+	lock := "SELECT `id`,`parent_id` FROM `merchants` WHERE id = ? ORDER BY `merchants`.`id` LIMIT 1 FOR UPDATE"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE `merchants` SET `parent_id`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?").
+		WithArgs(parentId, s.Time, "store", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.mock.ExpectQuery(lock).WithArgs("brand").WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow("brand", "organisation"))
+	s.mock.ExpectQuery(lock).WithArgs("organisation").WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow("organisation", nil))
+	s.mock.ExpectExec("INSERT INTO merchant_versions (id, merchant_id, version, created_at, " + merchantVersionColumns + ") " +
+		"SELECT UUID(), id, version, updated_at, " + merchantVersionColumns + " FROM merchants WHERE id = ?").
+		WithArgs("store").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.repository.UpdateMerchantParentById("store", 2, &parentId)
+
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) Test_repository_Update_Merchant_Parent_Cycle() {
+	// The organisation is moved under the store, which trades under a brand
+	// of the organisation.
+	parentId := "store"
+	lock := "SELECT `id`,`parent_id` FROM `merchants` WHERE id = ? ORDER BY `merchants`.`id` LIMIT 1 FOR UPDATE"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE `merchants` SET `parent_id`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?").
+		WithArgs(parentId, s.Time, "organisation", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.mock.ExpectQuery(lock).WithArgs("store").WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow("store", "brand"))
+	s.mock.ExpectQuery(lock).WithArgs("brand").WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow("brand", "organisation"))
+
+	err := s.repository.UpdateMerchantParentById("organisation", 4, &parentId)
+
+	require.Equal(s.T(), model.ErrMerchantParentCycle, err)
+}
+
+func (s *Suite) Test_repository_Update_Merchant_Parent_Deadlock() {
+	parentId := "brand"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE `merchants` SET `parent_id`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?").
+		WithArgs(parentId, s.Time, "store", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.mock.ExpectQuery("SELECT `id`,`parent_id` FROM `merchants` WHERE id = ? ORDER BY `merchants`.`id` LIMIT 1 FOR UPDATE").
+		WithArgs("brand").
+		WillReturnError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+
+	err := s.repository.UpdateMerchantParentById("store", 2, &parentId)
+
+	require.Equal(s.T(), ErrConflict, err)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-test/deep"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 
 	"merchant/model"
 )
@@ -26,21 +27,139 @@ func (s *Suite) Test_repository_List_Merchant() {
 
 	s.mock.ExpectQuery(query).WillReturnRows(rows)
 
-	res, err := s.repository.ListMerchants()
+	res, err := s.repository.ListMerchants(nil)
+
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), deep.Equal(merchants, res))
+}
+
+func (s *Suite) Test_repository_List_Merchant_By_Metadata() {
+	query := "SELECT * FROM `merchants` WHERE JSON_CONTAINS(metadata, ?)"
+	rows := sqlmock.NewRows([]string{"id", "business_name", "status", "metadata", "created_at", "updated_at"}).
+		AddRow(merchant.ID, merchant.BusinessName, merchant.Status, `{"plan": "pro", "region": "eu"}`, now, now)
+
+	s.mock.ExpectQuery(query).WithArgs(`{"plan":"pro"}`).WillReturnRows(rows)
+
+	res, err := s.repository.ListMerchants(model.Metadata{"plan": "pro"})
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), res, 1)
+	require.Equal(s.T(), model.Metadata{"plan": "pro", "region": "eu"}, res[0].Metadata)
+}
+
+func (s *Suite) Test_repository_List_Merchants_By_Ids_After() {
+	query := "SELECT * FROM `merchants` WHERE id IN (?,?) AND id > ? ORDER BY id LIMIT 2"
+	rows := sqlmock.NewRows([]string{"id", "business_name", "status", "created_at", "updated_at"}).
+		AddRow(merchant.ID, merchant.BusinessName, merchant.Status, now, now)
+
+	s.mock.ExpectQuery(query).WithArgs("8336fc00-43b5-40f7-83e3-27c018058054", merchant.ID, "8336fc00-43b5-40f7-83e3-27c018058054").WillReturnRows(rows)
+
+	res, err := s.repository.ListMerchantsByIdsAfter([]string{"8336fc00-43b5-40f7-83e3-27c018058054", merchant.ID}, "8336fc00-43b5-40f7-83e3-27c018058054", 2)
 
 	require.NoError(s.T(), err)
 	require.Nil(s.T(), deep.Equal(merchants, res))
 }
 
 func (s *Suite) Test_repository_Read_Merchant() {
-	query := "SELECT * FROM `merchants` WHERE email = ? ORDER BY `merchants`.`id` LIMIT 1"
+	index, err := model.EmailIndex("admin@pacenow.com")
+	require.NoError(s.T(), err)
+
+	query := "SELECT * FROM `merchants` WHERE email_index = ? ORDER BY `merchants`.`id` LIMIT 1"
 	rows := sqlmock.NewRows([]string{"id", "business_name", "status", "created_at", "updated_at"}).
 		AddRow(merchant.ID, merchant.BusinessName, merchant.Status, now, now)
 
-	s.mock.ExpectQuery(query).WithArgs(merchant.Email).WillReturnRows(rows)
+	s.mock.ExpectQuery(query).WithArgs(index).WillReturnRows(rows)
 
-	res, err := s.repository.ReadMerchantByEmail(merchant.Email)
+	res, err := s.repository.ReadMerchantByEmail(" Admin@PaceNow.com")
 
 	require.NoError(s.T(), err)
 	require.Nil(s.T(), deep.Equal(merchant, res))
 }
+
+func (s *Suite) Test_repository_Read_Merchant_Unindexed() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+	index, err := model.EmailIndex("admin@pacenow.com")
+	require.NoError(s.T(), err)
+
+	s.mock.ExpectQuery("SELECT * FROM `merchants` WHERE email_index = ? ORDER BY `merchants`.`id` LIMIT 1").
+		WithArgs(index).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectQuery("SELECT id,email FROM `merchants` WHERE (email_index IS NULL OR email_index = '') AND id > ? ORDER BY id LIMIT 500").
+		WithArgs("").WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).
+		AddRow("18a3b9a4-0a0e-4ab5-b3e4-6a47c5c1d0f1", "owner@pacenow.com").
+		AddRow(id, "Admin@PaceNow.com"))
+	s.mock.ExpectExec("UPDATE `merchants` SET `email_index`=? WHERE id = ? AND email = ?").
+		WithArgs(index, id, "Admin@PaceNow.com").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery("SELECT * FROM `merchants` WHERE id = ? ORDER BY `merchants`.`id` LIMIT 1").
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(id, "Admin@PaceNow.com"))
+
+	res, err := s.repository.ReadMerchantByEmail("admin@pacenow.com")
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), id, res.ID)
+}
+
+func (s *Suite) Test_repository_Read_Merchant_Unindexed_Not_Found() {
+	index, err := model.EmailIndex("admin@pacenow.com")
+	require.NoError(s.T(), err)
+
+	s.mock.ExpectQuery("SELECT * FROM `merchants` WHERE email_index = ? ORDER BY `merchants`.`id` LIMIT 1").
+		WithArgs(index).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.mock.ExpectQuery("SELECT id,email FROM `merchants` WHERE (email_index IS NULL OR email_index = '') AND id > ? ORDER BY id LIMIT 500").
+		WithArgs("").WillReturnRows(sqlmock.NewRows([]string{"id", "email"}))
+
+	_, err = s.repository.ReadMerchantByEmail("admin@pacenow.com")
+
+	require.Equal(s.T(), gorm.ErrRecordNotFound, err)
+}
+
+func (s *Suite) Test_repository_Update_Merchant_Version_Moved_On() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+	query := "UPDATE `merchants` SET `description`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs("Handmade ceramics", s.Time, id, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repository.UpdateMerchantById(id, 3, &model.Merchant{Description: "Handmade ceramics"})
+
+	require.Equal(s.T(), ErrConflict, err)
+}
+
+func (s *Suite) Test_repository_Update_Merchant_Logo_Version_Moved_On() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+	query := "UPDATE `merchants` SET `logo_key`=?,`logo_thumbnail_key`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs("merchants/"+id+"/logo-a.png", "merchants/"+id+"/logo-a-thumb.png", s.Time, id, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repository.UpdateMerchantLogoById(id, 3, "merchants/"+id+"/logo-a.png", "merchants/"+id+"/logo-a-thumb.png")
+
+	require.Equal(s.T(), ErrConflict, err)
+}
+
+func (s *Suite) Test_repository_Update_Merchant_Metadata() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+	query := "UPDATE `merchants` SET `description`=?,`metadata`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs("Handmade ceramics", `{"plan":"pro"}`, s.Time, id, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.mock.ExpectExec("INSERT INTO merchant_versions (id, merchant_id, version, created_at, " + merchantVersionColumns + ") " +
+		"SELECT UUID(), id, version, updated_at, " + merchantVersionColumns + " FROM merchants WHERE id = ?").
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.repository.UpdateMerchantById(id, 3, &model.Merchant{
+		Description: "Handmade ceramics",
+		Metadata:    model.Metadata{"plan": "pro"},
+	})
+
+	require.NoError(s.T(), err)
+}
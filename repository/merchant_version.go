@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// merchantVersionColumns lists the columns of merchants which versions
+// record.
+const merchantVersionColumns = `email, business_name, description, status, logo_key, logo_thumbnail_key, ` +
+	`banner_key, banner_thumbnail_key, parent_id, metadata`
+
+// createMerchantVersions records the merchants matching the condition as
+// they are, at their current version. The values are copied as stored, so
+// encrypted columns stay encrypted.
+func (r *repo) createMerchantVersions(where string, args ...interface{}) error {
+	return r.DB.Exec(`INSERT INTO merchant_versions (id, merchant_id, version, created_at, `+merchantVersionColumns+`) `+
+		`SELECT UUID(), id, version, updated_at, `+merchantVersionColumns+` FROM merchants WHERE `+where, args...).Error
+}
+
+// ListMerchantVersions returns a page of the versions of the merchant,
+// newest first, along with their number in total. When at is set, only the
+// versions taken on before then are listed, so that the first is the
+// merchant as it was at that time.
+func (r *repo) ListMerchantVersions(merchantId string, at *time.Time, limit, offset int) (model.MerchantVersions, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where(`merchant_id = ?`, merchantId)
+		if at != nil {
+			db = db.Where(`created_at < ?`, at)
+		}
+
+		return db
+	}
+
+	var total int64
+	if err := r.DB.Model(&model.MerchantVersion{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	vs := make([]*model.MerchantVersion, 0)
+	err := r.DB.Scopes(filter).Order(`version DESC`).Limit(limit).Offset(offset).Find(&vs).Error
+	return vs, total, err
+}
+
+func (r *repo) ReadMerchantVersion(merchantId string, version int64) (*model.MerchantVersion, error) {
+	v := &model.MerchantVersion{}
+	if err := r.DB.Where(`merchant_id = ? AND version = ?`, merchantId, version).First(v).Error; err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// RestoreMerchantById sets the business name, description and metadata of
+// the merchant back to those of the version, which records another version.
+// Images are not restored, as the images replaced since are deleted. It
+// returns ErrConflict when the merchant is no longer at the given version.
+func (r *repo) RestoreMerchantById(id string, version int64, v *model.MerchantVersion) error {
+	res := r.DB.Model(&model.Merchant{}).Where(`id = ? AND version = ?`, id, version).Updates(map[string]interface{}{
+		"business_name": v.BusinessName,
+		"description":   v.Description,
+		"metadata":      v.Metadata.ToDto(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return r.createMerchantVersions(`id = ?`, id)
+}
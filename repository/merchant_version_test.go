@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/util/cryptoutil"
+)
+
+func (s *Suite) Test_repository_List_Merchant_Versions_At() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+	at := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	s.mock.ExpectQuery("SELECT count(1) FROM `merchant_versions` WHERE merchant_id = ? AND created_at < ?").
+		WithArgs(id, at).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	s.mock.ExpectQuery("SELECT * FROM `merchant_versions` WHERE merchant_id = ? AND created_at < ? ORDER BY version DESC LIMIT 1").
+		WithArgs(id, at).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "merchant_id", "version", "description", "metadata"}).
+			AddRow("v3", id, 2, "Handmade ceramics", `{"plan": "pro"}`))
+
+	res, total, err := s.repository.ListMerchantVersions(id, &at, 1, 0)
+
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(3), total)
+	require.Len(s.T(), res, 1)
+	require.Equal(s.T(), int64(2), res[0].Version)
+	require.Equal(s.T(), model.Metadata{"plan": "pro"}, res[0].Metadata)
+}
+
+func (s *Suite) Test_repository_Restore_Merchant() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+	version := &model.MerchantVersion{
+		MerchantID:   id,
+		Version:      2,
+		BusinessName: cryptoutil.EncryptedString("Pottery Co"),
+		Description:  "Handmade ceramics",
+	}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE `merchants` SET `business_name`=?,`description`=?,`metadata`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?").
+		WithArgs(sqlmock.AnyArg(), "Handmade ceramics", "{}", s.Time, id, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+	s.mock.ExpectExec("INSERT INTO merchant_versions (id, merchant_id, version, created_at, " + merchantVersionColumns + ") " +
+		"SELECT UUID(), id, version, updated_at, " + merchantVersionColumns + " FROM merchants WHERE id = ?").
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.repository.RestoreMerchantById(id, 5, version)
+
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) Test_repository_Restore_Merchant_Version_Moved_On() {
+	id := "8336fc00-43b5-40f7-83e3-27c018058054"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("UPDATE `merchants` SET `business_name`=?,`description`=?,`metadata`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND version = ?").
+		WithArgs(sqlmock.AnyArg(), "", "{}", s.Time, id, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repository.RestoreMerchantById(id, 5, &model.MerchantVersion{MerchantID: id, Version: 2})
+
+	require.Equal(s.T(), ErrConflict, err)
+}
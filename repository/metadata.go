@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// metadataFilter returns a scope keeping the records whose metadata holds
+// every pair of the filter. An empty filter keeps every record.
+func metadataFilter(metadata model.Metadata) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(metadata) == 0 {
+			return db
+		}
+
+		b, err := json.Marshal(metadata)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+
+		return db.Where(`JSON_CONTAINS(metadata, ?)`, string(b))
+	}
+}
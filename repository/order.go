@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// SearchOrders returns a page of the merchant's orders, newest first, along
+// with the number of orders matching the search in total.
+func (r *repo) SearchOrders(merchantId string, search *model.OrderSearch) (model.Orders, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where(`merchant_id = ?`, merchantId)
+		if search.State != "" {
+			db = db.Where(`state = ?`, search.State)
+		}
+		if search.From != nil {
+			db = db.Where(`created_at >= ?`, *search.From)
+		}
+		if search.To != nil {
+			db = db.Where(`created_at < ?`, *search.To)
+		}
+
+		return db
+	}
+
+	var total int64
+	if err := r.DB.Model(&model.Order{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	os := make([]*model.Order, 0)
+	err := r.DB.Scopes(filter).Preload("Items").Order(`created_at DESC`).
+		Limit(search.Limit).Offset(search.Offset).Find(&os).Error
+	return os, total, err
+}
+
+func (r *repo) CreateOrder(o *model.Order) error {
+	return r.DB.Create(&o).Error
+}
+
+func (r *repo) ReadOrderById(id string) (*model.Order, error) {
+	o := &model.Order{}
+	if err := r.DB.Preload("Items").Where(`id = ?`, id).First(o).Error; err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// UpdateDraftOrderById overwrites the order and replaces its lines, provided
+// it is still a draft.
+func (r *repo) UpdateDraftOrderById(id string, o *model.Order) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return updateDraftOrder(tx, id, o)
+	})
+}
+
+func updateDraftOrder(tx *gorm.DB, id string, o *model.Order) error {
+	res := tx.Model(&model.Order{}).Where(`id = ? AND state = ?`, id, model.OrderStateDraft).Updates(map[string]interface{}{
+		"customer_name":  o.CustomerName,
+		"customer_email": o.CustomerEmail,
+		"currency":       o.Currency,
+		"note":           o.Note,
+		"coupon_id":      o.CouponID,
+		"coupon_code":    o.CouponCode,
+		"subtotal":       o.Subtotal,
+		"discount_total": o.DiscountTotal,
+		"tax_total":      o.TaxTotal,
+		"total":          o.Total,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	if err := tx.Where(`order_id = ?`, id).Delete(&model.OrderItem{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&o.Items).Error
+}
+
+// UpdateOrderStateById moves the order from one state to another and stamps
+// the time it entered the new state. Cancelling an order releases the coupon
+// it redeemed in the same transaction, so cancelled orders do not count
+// towards the limits of the coupon. It returns ErrConflict when the order is
+// no longer in the from state.
+func (r *repo) UpdateOrderStateById(id, from, to string, at time.Time) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Order{}).Where(`id = ? AND state = ?`, id, from).Updates(map[string]interface{}{
+			"state":    to,
+			to + "_at": at,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrConflict
+		}
+
+		if to != model.OrderStateCancelled {
+			return nil
+		}
+
+		rd := &model.CouponRedemption{}
+		if err := tx.Where(`order_id = ?`, id).First(rd).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		return releaseRedemption(tx, rd.CouponID, id)
+	})
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"time"
+
+	"merchant/model"
+)
+
+// CreateOutboxEvent records an event. Call it on the repository of the
+// transaction making the change the event reports.
+func (r *repo) CreateOutboxEvent(e *model.OutboxEvent) error {
+	return r.DB.Create(e).Error
+}
+
+// ListOutboxEventsByMerchantId returns a page of the events of the merchant,
+// published or not, in the order they were recorded.
+func (r *repo) ListOutboxEventsByMerchantId(merchantId string, limit, offset int) (model.OutboxEvents, error) {
+	es := make([]*model.OutboxEvent, 0)
+	err := r.DB.Where(`merchant_id = ?`, merchantId).
+		Order(`sequence`).Limit(limit).Offset(offset).Find(&es).Error
+	return es, err
+}
+
+// ListPendingOutboxEvents returns the oldest unpublished event of every
+// aggregate, if it is available for relaying, in sequence order. Later events
+// of an aggregate are only listed once the earlier ones are published, which
+// keeps the events of an aggregate in order however many relays run.
+func (r *repo) ListPendingOutboxEvents(now time.Time, limit int) (model.OutboxEvents, error) {
+	heads := r.DB.Model(&model.OutboxEvent{}).Select(`MIN(sequence)`).
+		Where(`published_at IS NULL`).Group(`aggregate_type, aggregate_id`)
+
+	es := make([]*model.OutboxEvent, 0)
+	err := r.DB.Where(`sequence IN (?) AND available_at <= ?`, heads, now).
+		Order(`sequence`).Limit(limit).Find(&es).Error
+	return es, err
+}
+
+// ClaimOutboxEvent takes an event for relaying by counting the attempt and
+// moving its availability to until, so other relays leave it alone meanwhile
+// and pick it up again should this one never record the outcome. It returns
+// ErrConflict when another relay claimed the event first.
+func (r *repo) ClaimOutboxEvent(sequence uint64, attempts int, until time.Time) error {
+	res := r.DB.Model(&model.OutboxEvent{}).
+		Where(`sequence = ? AND published_at IS NULL AND attempts = ?`, sequence, attempts).
+		Updates(map[string]interface{}{
+			"attempts":     attempts + 1,
+			"available_at": until,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+func (r *repo) MarkOutboxEventPublished(sequence uint64, at time.Time) error {
+	return r.DB.Model(&model.OutboxEvent{}).Where(`sequence = ?`, sequence).Updates(map[string]interface{}{
+		"published_at": at,
+		"last_error":   "",
+	}).Error
+}
+
+// RecordOutboxEventFailure keeps a failed event back until retryAt.
+func (r *repo) RecordOutboxEventFailure(sequence uint64, retryAt time.Time, errMessage string) error {
+	return r.DB.Model(&model.OutboxEvent{}).Where(`sequence = ? AND published_at IS NULL`, sequence).Updates(map[string]interface{}{
+		"available_at": retryAt,
+		"last_error":   errMessage,
+	}).Error
+}
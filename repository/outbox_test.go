@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"merchant/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) Test_repository_List_Pending_Outbox_Events() {
+	query := "SELECT * FROM `outbox_events` WHERE sequence IN (SELECT MIN(sequence) FROM `outbox_events` WHERE published_at IS NULL GROUP BY aggregate_type, aggregate_id) AND available_at <= ? ORDER BY sequence LIMIT 10"
+
+	s.mock.ExpectQuery(query).
+		WithArgs(s.Time).
+		WillReturnRows(sqlmock.NewRows([]string{"sequence", "id", "aggregate_type", "aggregate_id"}).
+			AddRow(3, "e3", "team_member", "t1").
+			AddRow(5, "e5", "merchant", "m1"))
+
+	es, err := s.repository.ListPendingOutboxEvents(time.Now(), 10)
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), es, 2)
+	require.Equal(s.T(), uint64(3), es[0].Sequence)
+}
+
+func (s *Suite) Test_repository_Claim_Outbox_Event_Taken() {
+	until := time.Date(2021, 3, 4, 10, 5, 0, 0, time.UTC)
+
+	query := "UPDATE `outbox_events` SET `attempts`=?,`available_at`=? WHERE sequence = ? AND published_at IS NULL AND attempts = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs(2, until, 7, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repository.ClaimOutboxEvent(7, 1, until)
+
+	require.Equal(s.T(), ErrConflict, err)
+}
+
+func (s *Suite) Test_repository_List_Outbox_Events_By_Merchant() {
+	query := "SELECT * FROM `outbox_events` WHERE merchant_id = ? ORDER BY sequence LIMIT 500 OFFSET 500"
+
+	s.mock.ExpectQuery(query).
+		WithArgs("m1").
+		WillReturnRows(sqlmock.NewRows([]string{"sequence", "id", "merchant_id"}).
+			AddRow(501, "e501", "m1"))
+
+	es, err := s.repository.ListOutboxEventsByMerchantId("m1", 500, 500)
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), es, 1)
+	require.Equal(s.T(), uint64(501), es[0].Sequence)
+}
+
+func (s *Suite) Test_repository_Create_Outbox_Event_Encrypts_Payload() {
+	e := model.NewMerchantEvent(model.EventMerchantRegistered, &model.Merchant{
+		Model: model.Model{ID: "m1"},
+		Email: "shop@example.com",
+	})
+
+	query := "INSERT INTO `outbox_events` (`id`,`type`,`aggregate_type`,`aggregate_id`,`merchant_id`,`payload`,`occurred_at`,`attempts`,`available_at`,`published_at`,`last_error`) VALUES (?,?,?,?,?,?,?,?,?,?,?)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs(e.ID, model.EventMerchantRegistered, model.AggregateMerchant, "m1", "m1", activeCipherText{}, e.OccurredAt, 0, e.AvailableAt, nil, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.CreateOutboxEvent(e)
+
+	require.NoError(s.T(), err)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"merchant/model"
+)
+
+// ListPaymentIntents returns the payments of the merchant, newest first,
+// optionally only those of an order.
+func (r *repo) ListPaymentIntents(merchantId, orderId string) (model.PaymentIntents, error) {
+	q := r.DB.Where(`merchant_id = ?`, merchantId)
+	if orderId != "" {
+		q = q.Where(`order_id = ?`, orderId)
+	}
+
+	ps := make([]*model.PaymentIntent, 0)
+	err := q.Order(`created_at DESC`).Find(&ps).Error
+	return ps, err
+}
+
+// CreatePaymentIntent records a payment for a placed order. The order is
+// locked while its payments are checked, so concurrent requests can never
+// both charge it; model.ErrPaymentInProgress is returned when the order
+// already has a live payment, and ErrConflict when it is no longer placed.
+func (r *repo) CreatePaymentIntent(p *model.PaymentIntent) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		o := &model.Order{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(`id = ? AND state = ?`, p.OrderID, model.OrderStatePlaced).First(o).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrConflict
+			}
+			return err
+		}
+
+		var live int64
+		err = tx.Model(&model.PaymentIntent{}).
+			Where(`order_id = ? AND status IN ?`, p.OrderID, model.PaymentIntentLiveStatuses).Count(&live).Error
+		if err != nil {
+			return err
+		}
+		if live > 0 {
+			return model.ErrPaymentInProgress
+		}
+
+		return tx.Create(p).Error
+	})
+}
+
+func (r *repo) ReadPaymentIntentById(id string) (*model.PaymentIntent, error) {
+	p := &model.PaymentIntent{}
+	if err := r.DB.Where(`id = ?`, id).First(p).Error; err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (r *repo) ReadPaymentIntentByProviderReference(provider, reference string) (*model.PaymentIntent, error) {
+	p := &model.PaymentIntent{}
+	if err := r.DB.Where(`provider = ? AND provider_reference = ?`, provider, reference).First(p).Error; err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// UpdatePaymentIntentById writes the outcome of a gateway operation, provided
+// the payment is still in the from status; it returns ErrConflict otherwise.
+// Capturing a payment marks its order paid.
+func (r *repo) UpdatePaymentIntentById(id, from string, p *model.PaymentIntent) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return updatePaymentIntent(tx, id, from, p)
+	})
+}
+
+// ProcessPaymentCallback records the callback and, unless p is nil, applies
+// it to the payment like UpdatePaymentIntentById does. It returns
+// ErrDuplicate when the callback was processed before.
+func (r *repo) ProcessPaymentCallback(cb *model.ProcessedCallback, id, from string, p *model.PaymentIntent) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(cb).Error; err != nil {
+			return translateError(err)
+		}
+
+		if p == nil {
+			return nil
+		}
+
+		return updatePaymentIntent(tx, id, from, p)
+	})
+}
+
+func updatePaymentIntent(tx *gorm.DB, id, from string, p *model.PaymentIntent) error {
+	res := tx.Model(&model.PaymentIntent{}).Where(`id = ? AND status = ?`, id, from).Updates(map[string]interface{}{
+		"status":             p.Status,
+		"provider_reference": p.ProviderReference,
+		"captured_amount":    p.CapturedAmount,
+		"decline_reason":     p.DeclineReason,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	if p.Status != model.PaymentIntentStatusCaptured {
+		return nil
+	}
+
+	// An order which is no longer placed can not be marked paid; the capture
+	// is rolled back rather than leaving a charge the order does not show.
+	res = tx.Model(&model.Order{}).Where(`id = ? AND state = ?`, p.OrderID, model.OrderStatePlaced).Updates(map[string]interface{}{
+		"state":   model.OrderStatePaid,
+		"paid_at": time.Now(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Create_Payment_Intent_In_Progress() {
+	intent := &model.PaymentIntent{
+		Model:   model.Model{ID: "0f4c2b8a-9d1e-4a6b-8c3d-7e5f6a1b2c3d"},
+		OrderID: "4e8a1c2b-7d3f-4b5a-9c6e-1d2f3a4b5c6d",
+		Amount:  2500,
+		Status:  model.PaymentIntentStatusPending,
+	}
+
+	lock := "SELECT * FROM `orders` WHERE id = ? AND state = ? ORDER BY `orders`.`id` LIMIT 1 FOR UPDATE"
+	count := "SELECT count(1) FROM `payment_intents` WHERE order_id = ? AND status IN (?,?,?,?,?)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(lock).WithArgs(intent.OrderID, model.OrderStatePlaced).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow(intent.OrderID, model.OrderStatePlaced))
+	s.mock.ExpectQuery(count).
+		WithArgs(intent.OrderID, model.PaymentIntentStatusPending, model.PaymentIntentStatusAuthorized, model.PaymentIntentStatusCaptured,
+			model.PaymentIntentStatusPartiallyRefunded, model.PaymentIntentStatusRefunded).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	s.mock.ExpectRollback()
+
+	err := s.repository.CreatePaymentIntent(intent)
+
+	require.Equal(s.T(), model.ErrPaymentInProgress, err)
+}
+
+func (s *Suite) Test_repository_Capture_Payment_Intent_Order_No_Longer_Placed() {
+	intent := &model.PaymentIntent{
+		Model:          model.Model{ID: "0f4c2b8a-9d1e-4a6b-8c3d-7e5f6a1b2c3d"},
+		OrderID:        "4e8a1c2b-7d3f-4b5a-9c6e-1d2f3a4b5c6d",
+		CapturedAmount: 2500,
+		Status:         model.PaymentIntentStatusCaptured,
+	}
+
+	update := "UPDATE `payment_intents` SET `captured_amount`=?,`decline_reason`=?,`provider_reference`=?,`status`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND status = ?"
+	paid := "UPDATE `orders` SET `paid_at`=?,`state`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND state = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(update).
+		WithArgs(int64(2500), "", "", model.PaymentIntentStatusCaptured, s.Time, intent.ID, model.PaymentIntentStatusAuthorized).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(paid).
+		WithArgs(s.Time, model.OrderStatePaid, s.Time, intent.OrderID, model.OrderStatePlaced).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectRollback()
+
+	err := s.repository.UpdatePaymentIntentById(intent.ID, model.PaymentIntentStatusAuthorized, intent)
+
+	require.Equal(s.T(), ErrConflict, err)
+}
@@ -0,0 +1,40 @@
+package repository
+
+import "merchant/model"
+
+func (r *repo) ListPayoutAccountsByMerchantId(merchantId string) (model.PayoutAccounts, error) {
+	ps := make([]*model.PayoutAccount, 0)
+	err := r.DB.Where(`merchant_id = ?`, merchantId).Find(&ps).Error
+	return ps, err
+}
+
+func (r *repo) ListPayoutAccountsByStatus(status string) (model.PayoutAccounts, error) {
+	ps := make([]*model.PayoutAccount, 0)
+	tx := r.DB.Order(`created_at`)
+	if status != "" {
+		tx = tx.Where(`status = ?`, status)
+	}
+	err := tx.Find(&ps).Error
+	return ps, err
+}
+
+func (r *repo) CreatePayoutAccount(p *model.PayoutAccount) error {
+	return r.DB.Create(&p).Error
+}
+
+func (r *repo) ReadPayoutAccountById(id string) (*model.PayoutAccount, error) {
+	p := &model.PayoutAccount{}
+	if err := r.DB.Where(`id = ?`, id).First(p).Error; err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (r *repo) UpdatePayoutAccountStatusById(id, status string) error {
+	return r.DB.Model(&model.PayoutAccount{}).Where(`id = ?`, id).Update("status", status).Error
+}
+
+func (r *repo) DeletePayoutAccount(id string) error {
+	return r.DB.Where(`id = ?`, id).Delete(&model.PayoutAccount{}).Error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// SearchProducts returns a page of the merchant's products, newest first,
+// along with the number of products matching the search in total.
+func (r *repo) SearchProducts(merchantId string, search *model.ProductSearch) (model.Products, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where(`merchant_id = ?`, merchantId)
+		if search.Query != "" {
+			db = db.Where(
+				`(name LIKE ? OR id IN (SELECT product_id FROM product_variants WHERE merchant_id = ? AND sku = ?))`,
+				"%"+escapeLike(search.Query)+"%", merchantId, search.Query,
+			)
+		}
+		if search.Active != nil {
+			db = db.Where(`active = ?`, *search.Active)
+		}
+
+		return db
+	}
+
+	var total int64
+	if err := r.DB.Model(&model.Product{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	ps := make([]*model.Product, 0)
+	err := r.DB.Scopes(filter).Preload("Variants").Order(`created_at DESC`).
+		Limit(search.Limit).Offset(search.Offset).Find(&ps).Error
+	return ps, total, err
+}
+
+// ListProductsByVariantIds returns the products the variants belong to, with
+// all of their variants.
+func (r *repo) ListProductsByVariantIds(variantIds []string) (model.Products, error) {
+	ps := make([]*model.Product, 0)
+	err := r.DB.Preload("Variants").
+		Where(`id IN (SELECT product_id FROM product_variants WHERE id IN ?)`, variantIds).Find(&ps).Error
+	return ps, err
+}
+
+func (r *repo) CreateProduct(p *model.Product) error {
+	return translateError(r.DB.Create(&p).Error)
+}
+
+func (r *repo) ReadProductById(id string) (*model.Product, error) {
+	p := &model.Product{}
+	if err := r.DB.Preload("Variants").Where(`id = ?`, id).First(p).Error; err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (r *repo) UpdateProductById(id string, p *model.Product) error {
+	return r.DB.Model(&model.Product{}).Where(`id = ?`, id).Updates(map[string]interface{}{
+		"name":         p.Name,
+		"description":  p.Description,
+		"tax_category": p.TaxCategory,
+		"active":       p.Active,
+	}).Error
+}
+
+func (r *repo) DeleteProduct(id string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(`product_id = ?`, id).Delete(&model.ProductVariant{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where(`id = ?`, id).Delete(&model.Product{}).Error
+	})
+}
+
+func (r *repo) CreateProductVariant(v *model.ProductVariant) error {
+	return translateError(r.DB.Create(&v).Error)
+}
+
+func (r *repo) ReadProductVariantById(id string) (*model.ProductVariant, error) {
+	v := &model.ProductVariant{}
+	if err := r.DB.Where(`id = ?`, id).First(v).Error; err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r *repo) UpdateProductVariantById(id string, v *model.ProductVariant) error {
+	return translateError(r.DB.Model(&model.ProductVariant{}).Where(`id = ?`, id).Updates(map[string]interface{}{
+		"sku":      v.SKU,
+		"name":     v.Name,
+		"price":    v.Price,
+		"currency": v.Currency,
+		"active":   v.Active,
+	}).Error)
+}
+
+func (r *repo) DeleteProductVariant(id string) error {
+	return r.DB.Where(`id = ?`, id).Delete(&model.ProductVariant{}).Error
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern so s matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Create_Product_Variant_Duplicate_Sku() {
+	variant := &model.ProductVariant{
+		Model:      model.Model{ID: "6b1f2a8e-51e9-4f83-a4d6-2c1b7f0f4f10"},
+		ProductID:  "0d9c3c55-0d7f-4e0c-9d3b-9f1c5f6a7e21",
+		MerchantID: "8336fc00-43b5-40f7-83e3-27c018058054",
+		SKU:        "TS-S",
+		Price:      1999,
+		Currency:   "EUR",
+	}
+
+	query := "INSERT INTO `product_variants` (`id`,`created_at`,`updated_at`,`version`,`product_id`,`merchant_id`,`sku`,`name`,`price`,`currency`,`active`) VALUES (?,?,?,?,?,?,?,?,?,?,?)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs(variant.ID, s.Time, s.Time, int64(0), variant.ProductID, variant.MerchantID, "TS-S", "", int64(1999), "EUR", false).
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	s.mock.ExpectRollback()
+
+	err := s.repository.CreateProductVariant(variant)
+
+	require.Equal(s.T(), ErrDuplicate, err)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"merchant/model"
+)
+
+func (r *repo) ListRefundsByPaymentIntentId(paymentIntentId string) (model.Refunds, error) {
+	rs := make([]*model.Refund, 0)
+	err := r.DB.Where(`payment_intent_id = ?`, paymentIntentId).Order(`created_at DESC`).Find(&rs).Error
+	return rs, err
+}
+
+func (r *repo) ReadRefundById(id string) (*model.Refund, error) {
+	rf := &model.Refund{}
+	if err := r.DB.Where(`id = ?`, id).First(rf).Error; err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+// ReserveRefund records a pending refund and sets its amount aside on the
+// payment in one conditional update, so concurrent refunds can never add up
+// to more than was captured. It returns model.ErrRefundExceedsBalance when
+// the payment can not cover the refund.
+func (r *repo) ReserveRefund(rf *model.Refund) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.PaymentIntent{}).
+			Where(`id = ? AND status IN ? AND refunded_amount + ? <= captured_amount`, rf.PaymentIntentID,
+				[]string{model.PaymentIntentStatusCaptured, model.PaymentIntentStatusPartiallyRefunded}, rf.Amount).
+			Update("refunded_amount", gorm.Expr(`refunded_amount + ?`, rf.Amount))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return model.ErrRefundExceedsBalance
+		}
+
+		return tx.Create(rf).Error
+	})
+}
+
+// ListPendingRefunds returns up to limit refunds requested before the given
+// time which are still pending, oldest first.
+func (r *repo) ListPendingRefunds(before time.Time, limit int) (model.Refunds, error) {
+	rs := make([]*model.Refund, 0)
+	err := r.DB.Where(`status = ? AND created_at < ?`, model.RefundStatusPending, before).
+		Order(`created_at`).Limit(limit).Find(&rs).Error
+	return rs, err
+}
+
+// CompleteRefund marks a pending refund succeeded and updates the status of
+// its payment. Once a payment is refunded in full, its order is marked
+// refunded as well.
+func (r *repo) CompleteRefund(id, providerReference string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		rf, err := settleRefund(tx, id, map[string]interface{}{
+			"status":             model.RefundStatusSucceeded,
+			"provider_reference": providerReference,
+		})
+		if err != nil {
+			return err
+		}
+
+		p, err := updateRefundStatus(tx, rf.PaymentIntentID)
+		if err != nil {
+			return err
+		}
+
+		if p.Status != model.PaymentIntentStatusRefunded {
+			return nil
+		}
+
+		return tx.Model(&model.Order{}).
+			Where(`id = ? AND state IN ?`, p.OrderID, []string{model.OrderStatePaid, model.OrderStateFulfilled}).
+			Updates(map[string]interface{}{
+				"state":       model.OrderStateRefunded,
+				"refunded_at": time.Now(),
+			}).Error
+	})
+}
+
+// FailRefund marks a pending refund failed, returns its amount to the
+// refundable balance of the payment and updates the status of the payment.
+func (r *repo) FailRefund(id, reason string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		rf, err := settleRefund(tx, id, map[string]interface{}{
+			"status":         model.RefundStatusFailed,
+			"failure_reason": reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.PaymentIntent{}).Where(`id = ?`, rf.PaymentIntentID).
+			Update("refunded_amount", gorm.Expr(`refunded_amount - ?`, rf.Amount)).Error
+		if err != nil {
+			return err
+		}
+
+		_, err = updateRefundStatus(tx, rf.PaymentIntentID)
+		return err
+	})
+}
+
+// updateRefundStatus locks the payment and sets its status from the amount
+// of its refunds which succeeded, returning the updated payment.
+func updateRefundStatus(tx *gorm.DB, paymentIntentId string) (*model.PaymentIntent, error) {
+	p := &model.PaymentIntent{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(`id = ?`, paymentIntentId).First(p).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var refunded int64
+	err = tx.Model(&model.Refund{}).Select(`COALESCE(SUM(amount), 0)`).
+		Where(`payment_intent_id = ? AND status = ?`, p.ID, model.RefundStatusSucceeded).Scan(&refunded).Error
+	if err != nil {
+		return nil, err
+	}
+
+	p.Status = p.RefundStatus(refunded)
+	if err := tx.Model(&model.PaymentIntent{}).Where(`id = ?`, p.ID).Update("status", p.Status).Error; err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// settleRefund moves a pending refund to its final status, returning
+// ErrConflict when it was settled already.
+func settleRefund(tx *gorm.DB, id string, updates map[string]interface{}) (*model.Refund, error) {
+	res := tx.Model(&model.Refund{}).Where(`id = ? AND status = ?`, id, model.RefundStatusPending).Updates(updates)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrConflict
+	}
+
+	rf := &model.Refund{}
+	if err := tx.Where(`id = ?`, id).First(rf).Error; err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Reserve_Refund_Exceeding_Balance() {
+	refund := &model.Refund{
+		Model:           model.Model{ID: "0f4c2b8a-9d1e-4a6b-8c3d-7e5f6a1b2c3d"},
+		PaymentIntentID: "4e8a1c2b-7d3f-4b5a-9c6e-1d2f3a4b5c6d",
+		Amount:          1500,
+		Status:          model.RefundStatusPending,
+	}
+
+	query := "UPDATE `payment_intents` SET `refunded_amount`=refunded_amount + ?,`version`=version + 1,`updated_at`=? WHERE id = ? AND status IN (?,?) AND refunded_amount + ? <= captured_amount"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs(int64(1500), s.Time, refund.PaymentIntentID, model.PaymentIntentStatusCaptured, model.PaymentIntentStatusPartiallyRefunded, int64(1500)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectRollback()
+
+	err := s.repository.ReserveRefund(refund)
+
+	require.Equal(s.T(), model.ErrRefundExceedsBalance, err)
+}
+
+func (s *Suite) Test_repository_Fail_Refund_Recomputes_Payment_Status() {
+	refundId := "0f4c2b8a-9d1e-4a6b-8c3d-7e5f6a1b2c3d"
+	paymentId := "4e8a1c2b-7d3f-4b5a-9c6e-1d2f3a4b5c6d"
+
+	settle := "UPDATE `refunds` SET `failure_reason`=?,`status`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND status = ?"
+	read := "SELECT * FROM `refunds` WHERE id = ? ORDER BY `refunds`.`id` LIMIT 1"
+	release := "UPDATE `payment_intents` SET `refunded_amount`=refunded_amount - ?,`version`=version + 1,`updated_at`=? WHERE id = ?"
+	lock := "SELECT * FROM `payment_intents` WHERE id = ? ORDER BY `payment_intents`.`id` LIMIT 1 FOR UPDATE"
+	sum := "SELECT COALESCE(SUM(amount), 0) FROM `refunds` WHERE payment_intent_id = ? AND status = ?"
+	status := "UPDATE `payment_intents` SET `status`=?,`version`=version + 1,`updated_at`=? WHERE id = ?"
+
+	// A concurrent refund of the other half succeeded meanwhile; this one
+	// failing leaves the payment partially refunded, not refunded.
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(settle).
+		WithArgs("refund_window_closed", model.RefundStatusFailed, s.Time, refundId, model.RefundStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(read).WithArgs(refundId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payment_intent_id", "amount"}).AddRow(refundId, paymentId, 5000))
+	s.mock.ExpectExec(release).WithArgs(int64(5000), s.Time, paymentId).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(lock).WithArgs(paymentId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "captured_amount", "refunded_amount", "status"}).
+			AddRow(paymentId, 10000, 5000, model.PaymentIntentStatusPartiallyRefunded))
+	s.mock.ExpectQuery(sum).WithArgs(paymentId, model.RefundStatusSucceeded).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(5000))
+	s.mock.ExpectExec(status).WithArgs(model.PaymentIntentStatusPartiallyRefunded, s.Time, paymentId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.FailRefund(refundId, "refund_window_closed")
+
+	require.NoError(s.T(), err)
+}
+
+func (s *Suite) Test_repository_Complete_Refund_Ignores_Pending_Refunds() {
+	refundId := "0f4c2b8a-9d1e-4a6b-8c3d-7e5f6a1b2c3d"
+	paymentId := "4e8a1c2b-7d3f-4b5a-9c6e-1d2f3a4b5c6d"
+
+	settle := "UPDATE `refunds` SET `provider_reference`=?,`status`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND status = ?"
+	read := "SELECT * FROM `refunds` WHERE id = ? ORDER BY `refunds`.`id` LIMIT 1"
+	lock := "SELECT * FROM `payment_intents` WHERE id = ? ORDER BY `payment_intents`.`id` LIMIT 1 FOR UPDATE"
+	sum := "SELECT COALESCE(SUM(amount), 0) FROM `refunds` WHERE payment_intent_id = ? AND status = ?"
+	status := "UPDATE `payment_intents` SET `status`=?,`version`=version + 1,`updated_at`=? WHERE id = ?"
+
+	// The whole capture is set aside, but the other half is still pending.
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(settle).
+		WithArgs("fake_refund_1", model.RefundStatusSucceeded, s.Time, refundId, model.RefundStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(read).WithArgs(refundId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payment_intent_id", "amount"}).AddRow(refundId, paymentId, 5000))
+	s.mock.ExpectQuery(lock).WithArgs(paymentId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "captured_amount", "refunded_amount", "status"}).
+			AddRow(paymentId, 10000, 10000, model.PaymentIntentStatusCaptured))
+	s.mock.ExpectQuery(sum).WithArgs(paymentId, model.RefundStatusSucceeded).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(5000))
+	s.mock.ExpectExec(status).WithArgs(model.PaymentIntentStatusPartiallyRefunded, s.Time, paymentId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.CompleteRefund(refundId, "fake_refund_1")
+
+	require.NoError(s.T(), err)
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"gorm.io/gorm/clause"
+
+	"merchant/model"
+)
+
+func (r *repo) ReadMerchantSettingsByMerchantId(merchantId string) (*model.MerchantSettings, error) {
+	s := &model.MerchantSettings{}
+	if err := r.DB.Where(`merchant_id = ?`, merchantId).First(s).Error; err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SaveMerchantSettings creates the settings of the merchant or replaces
+// the existing ones.
+func (r *repo) SaveMerchantSettings(s *model.MerchantSettings) error {
+	return r.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{
+			"currency",
+			"locale",
+			"timezone",
+			"notify_order_placed",
+			"notify_payment_received",
+			"notify_payout_sent",
+			"notify_weekly_summary",
+			"updated_at",
+		}),
+	}).Create(&s).Error
+}
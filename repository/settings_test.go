@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Save_Merchant_Settings() {
+	settings := model.DefaultMerchantSettings("8336fc00-43b5-40f7-83e3-27c018058054")
+
+	query := "INSERT INTO `merchant_settings` (`merchant_id`,`currency`,`locale`,`timezone`,`notify_order_placed`,`notify_payment_received`,`notify_payout_sent`,`notify_weekly_summary`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?) " +
+		"ON DUPLICATE KEY UPDATE `currency`=VALUES(`currency`),`locale`=VALUES(`locale`),`timezone`=VALUES(`timezone`),`notify_order_placed`=VALUES(`notify_order_placed`),`notify_payment_received`=VALUES(`notify_payment_received`),`notify_payout_sent`=VALUES(`notify_payout_sent`),`notify_weekly_summary`=VALUES(`notify_weekly_summary`),`updated_at`=VALUES(`updated_at`)"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs(settings.MerchantID, "USD", "en-US", "UTC", true, true, true, false, s.Time, s.Time).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repository.SaveMerchantSettings(settings)
+
+	require.NoError(s.T(), err)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"bytes"
 	"database/sql"
 	"database/sql/driver"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	"merchant/util/cryptoutil"
 )
 
 type AnyTime struct{}
@@ -51,6 +54,14 @@ func (s *Suite) SetupSuite() {
 	s.DB.Logger.LogMode(logger.Info)
 
 	s.repository = New(s.DB)
+
+	master, err := cryptoutil.NewAESGCM(bytes.Repeat([]byte{7}, 32))
+	require.NoError(s.T(), err)
+	keyring := cryptoutil.NewKeyring(master)
+	require.NoError(s.T(), keyring.AddKey("active", bytes.Repeat([]byte{1}, 32)))
+	require.NoError(s.T(), keyring.Activate("active"))
+	keyring.SetIndexKey(bytes.Repeat([]byte{2}, 32))
+	cryptoutil.SetKeyring(keyring)
 }
 
 func (s *Suite) AfterTest(_, _ string) {
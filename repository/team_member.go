@@ -1,15 +1,42 @@
 package repository
 
-import "merchant/model"
+import (
+	"gorm.io/gorm"
 
-func (r *repo) ListTeamMembersByMerchantId(merchantId string) (model.TeamMembers, error) {
+	"merchant/model"
+)
+
+// ListTeamMembersByMerchantId returns the team members of the merchant whose
+// metadata holds every pair of the given metadata.
+func (r *repo) ListTeamMembersByMerchantId(merchantId string, metadata model.Metadata) (model.TeamMembers, error) {
+	ts := make([]*model.TeamMember, 0)
+	err := r.DB.Scopes(metadataFilter(metadata)).Where("merchant_id", merchantId).Find(&ts).Error
+	return ts, err
+}
+
+// ListTeamMembersByMerchantIdAfter returns up to limit team members of the
+// merchant whose id follows afterId, in id order.
+func (r *repo) ListTeamMembersByMerchantIdAfter(merchantId, afterId string, limit int) (model.TeamMembers, error) {
+	ts := make([]*model.TeamMember, 0)
+	err := r.DB.Where(`merchant_id = ? AND id > ?`, merchantId, afterId).Order(`id`).Limit(limit).Find(&ts).Error
+	return ts, err
+}
+
+// ListTeamMembersByMerchantIds returns the team members of all the
+// merchants whose metadata holds every pair of the given metadata, grouped
+// by merchant.
+func (r *repo) ListTeamMembersByMerchantIds(merchantIds []string, metadata model.Metadata) (model.TeamMembers, error) {
 	ts := make([]*model.TeamMember, 0)
-	err := r.DB.Where("merchant_id", merchantId).Find(&ts).Error
+	err := r.DB.Scopes(metadataFilter(metadata)).Where(`merchant_id IN ?`, merchantIds).Order(`merchant_id, id`).Find(&ts).Error
 	return ts, err
 }
 
 func (r *repo) CreateTeamMember(t *model.TeamMember) error {
-	return r.DB.Create(&t).Error
+	if err := r.DB.Create(&t).Error; err != nil {
+		return err
+	}
+
+	return r.createTeamMemberVersions(`id = ?`, t.ID)
 }
 
 func (r *repo) ReadTeamMemberById(id string) (*model.TeamMember, error) {
@@ -21,19 +48,63 @@ func (r *repo) ReadTeamMemberById(id string) (*model.TeamMember, error) {
 	return t, nil
 }
 
+// ReadTeamMemberByEmail looks the email up by its blind index, then among the
+// members written before the index existed which the keyring pass has yet to
+// index.
 func (r *repo) ReadTeamMemberByEmail(email string) (*model.TeamMember, error) {
+	index, err := model.EmailIndex(email)
+	if err != nil {
+		return nil, err
+	}
+
 	m := &model.TeamMember{}
-	if err := r.DB.Where(`email = ?`, email).First(m).Error; err != nil {
+	err = r.DB.Where(`email_index = ?`, index).First(m).Error
+	if err == gorm.ErrRecordNotFound {
+		var id string
+		if id, err = r.indexUnindexedEmail(`team_members`, index); err == nil {
+			err = r.DB.Where(`id = ?`, id).First(m).Error
+		}
+	}
+	if err != nil {
 		return nil, err
 	}
 
 	return m, nil
 }
 
-func (r *repo) UpdateTeamMemberById(id string, t *model.TeamMember) error {
-	return r.DB.Model(&model.TeamMember{}).Where(`id = ?`, id).Updates(t).Error
+// UpdateTeamMemberById updates the metadata of the member unless nil. It
+// returns ErrConflict when the member is no longer at the given version.
+func (r *repo) UpdateTeamMemberById(id string, version int64, t *model.TeamMember) error {
+	fields := map[string]interface{}{
+		"is_owner":    t.IsOwner,
+		"given_name":  t.GivenName,
+		"family_name": t.FamilyName,
+	}
+	if t.Metadata != nil {
+		fields["metadata"] = t.Metadata
+	}
+
+	res := r.DB.Model(&model.TeamMember{}).Where(`id = ? AND version = ?`, id, version).Updates(fields)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return r.createTeamMemberVersions(`id = ?`, id)
 }
 
-func (r *repo) DeleteTeamMember(id string) error {
-	return r.DB.Where(`id = ?`, id).Delete(&model.TeamMember{}).Error
+// DeleteTeamMember deletes the member along with its versions. It returns
+// ErrConflict when the member is no longer at the given version.
+func (r *repo) DeleteTeamMember(id string, version int64) error {
+	res := r.DB.Where(`id = ? AND version = ?`, id, version).Delete(&model.TeamMember{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return r.DB.Where(`team_member_id = ?`, id).Delete(&model.TeamMemberVersion{}).Error
 }
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"merchant/model"
+)
+
+func (r *repo) CreateTeamMemberImport(i *model.TeamMemberImport) error {
+	return r.DB.Create(i).Error
+}
+
+func (r *repo) ReadTeamMemberImportById(id string) (*model.TeamMemberImport, error) {
+	i := &model.TeamMemberImport{}
+	if err := r.DB.Where(`id = ?`, id).First(i).Error; err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// ListPendingTeamMemberImports returns the oldest imports waiting to run,
+// along with those which started running before staleBefore and were
+// presumably cut off.
+func (r *repo) ListPendingTeamMemberImports(staleBefore time.Time, limit int) (model.TeamMemberImports, error) {
+	is := make([]*model.TeamMemberImport, 0)
+	err := r.DB.
+		Where(`status = ? OR (status = ? AND started_at < ?)`,
+			model.TeamMemberImportStatusPending, model.TeamMemberImportStatusRunning, staleBefore).
+		Order(`created_at`).Limit(limit).Find(&is).Error
+	return is, err
+}
+
+// ClaimTeamMemberImport marks the import as running from the given time. It
+// returns ErrConflict when the import was claimed by another importer.
+func (r *repo) ClaimTeamMemberImport(id string, at, staleBefore time.Time) error {
+	res := r.DB.Model(&model.TeamMemberImport{}).
+		Where(`id = ? AND (status = ? OR (status = ? AND started_at < ?))`,
+			id, model.TeamMemberImportStatusPending, model.TeamMemberImportStatusRunning, staleBefore).
+		Updates(map[string]interface{}{
+			"status":     model.TeamMemberImportStatusRunning,
+			"started_at": at,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// FinishTeamMemberImport stores the outcome of the import.
+func (r *repo) FinishTeamMemberImport(i *model.TeamMemberImport) error {
+	return r.DB.Model(&model.TeamMemberImport{}).Where(`id = ?`, i.ID).Updates(map[string]interface{}{
+		"status":        i.Status,
+		"total_rows":    i.TotalRows,
+		"valid_rows":    i.ValidRows,
+		"imported_rows": i.ImportedRows,
+		"row_errors":    i.RowErrors,
+		"error":         i.Error,
+		"finished_at":   i.FinishedAt,
+	}).Error
+}
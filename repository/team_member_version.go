@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+// teamMemberVersionColumns lists the columns of team members which versions
+// record.
+const teamMemberVersionColumns = `merchant_id, is_owner, given_name, family_name, email, status, metadata`
+
+// createTeamMemberVersions records the team members matching the condition
+// as they are, at their current version. The values are copied as stored,
+// so encrypted columns stay encrypted.
+func (r *repo) createTeamMemberVersions(where string, args ...interface{}) error {
+	return r.DB.Exec(`INSERT INTO team_member_versions (id, team_member_id, version, created_at, `+teamMemberVersionColumns+`) `+
+		`SELECT UUID(), id, version, updated_at, `+teamMemberVersionColumns+` FROM team_members WHERE `+where, args...).Error
+}
+
+// ListTeamMemberVersions returns a page of the versions of the member,
+// newest first, along with their number in total. When at is set, only the
+// versions taken on before then are listed.
+func (r *repo) ListTeamMemberVersions(teamMemberId string, at *time.Time, limit, offset int) (model.TeamMemberVersions, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where(`team_member_id = ?`, teamMemberId)
+		if at != nil {
+			db = db.Where(`created_at < ?`, at)
+		}
+
+		return db
+	}
+
+	var total int64
+	if err := r.DB.Model(&model.TeamMemberVersion{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	vs := make([]*model.TeamMemberVersion, 0)
+	err := r.DB.Scopes(filter).Order(`version DESC`).Limit(limit).Offset(offset).Find(&vs).Error
+	return vs, total, err
+}
+
+// ListTeamMemberVersionsByMerchantId returns a page of the versions of all
+// the team members of the merchant, including members since deleted,
+// grouped by member and oldest first.
+func (r *repo) ListTeamMemberVersionsByMerchantId(merchantId string, limit, offset int) (model.TeamMemberVersions, error) {
+	vs := make([]*model.TeamMemberVersion, 0)
+	err := r.DB.Where(`merchant_id = ?`, merchantId).Order(`team_member_id, version`).Limit(limit).Offset(offset).Find(&vs).Error
+	return vs, err
+}
+
+func (r *repo) ReadTeamMemberVersion(teamMemberId string, version int64) (*model.TeamMemberVersion, error) {
+	v := &model.TeamMemberVersion{}
+	if err := r.DB.Where(`team_member_id = ? AND version = ?`, teamMemberId, version).First(v).Error; err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) Test_repository_List_Team_Member_Versions_By_Merchant_Id() {
+	merchantId := "8336fc00-43b5-40f7-83e3-27c018058054"
+
+	s.mock.ExpectQuery("SELECT * FROM `team_member_versions` WHERE merchant_id = ? ORDER BY team_member_id, version LIMIT 500 OFFSET 500").
+		WithArgs(merchantId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "team_member_id", "merchant_id", "version", "status"}).
+			AddRow("v1", "t1", merchantId, 1, "active").
+			AddRow("v2", "t1", merchantId, 2, "inactive"))
+
+	res, err := s.repository.ListTeamMemberVersionsByMerchantId(merchantId, 500, 500)
+
+	require.NoError(s.T(), err)
+	require.Len(s.T(), res, 2)
+	require.Equal(s.T(), int64(2), res[1].Version)
+}
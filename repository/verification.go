@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"merchant/model"
+)
+
+func (r *repo) ListVerificationsByStatus(status string) (model.Verifications, error) {
+	vs := make([]*model.Verification, 0)
+	tx := r.DB.Preload("Documents").Order(`submitted_at`)
+	if status != "" {
+		tx = tx.Where(`status = ?`, status)
+	}
+	err := tx.Find(&vs).Error
+	return vs, err
+}
+
+func (r *repo) CreateVerification(v *model.Verification) error {
+	return r.DB.Create(&v).Error
+}
+
+func (r *repo) ReadVerificationById(id string) (*model.Verification, error) {
+	v := &model.Verification{}
+	if err := r.DB.Preload("Documents").Where(`id = ?`, id).First(v).Error; err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r *repo) ReadVerificationByMerchantId(merchantId string) (*model.Verification, error) {
+	v := &model.Verification{}
+	if err := r.DB.Preload("Documents").Where(`merchant_id = ?`, merchantId).First(v).Error; err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ResubmitVerificationById replaces the business details of a case and puts
+// it back in the review queue, clearing the outcome of any earlier review.
+func (r *repo) ResubmitVerificationById(id string, v *model.Verification) error {
+	res := r.DB.Model(&model.Verification{}).
+		Where(`id = ? AND status IN ?`, id, []string{model.VerificationStatusSubmitted, model.VerificationStatusRejected}).
+		Updates(map[string]interface{}{
+			"legal_name":          v.LegalName,
+			"registration_number": v.RegistrationNumber,
+			"address_line1":       v.AddressLine1,
+			"address_line2":       v.AddressLine2,
+			"city":                v.City,
+			"region":              v.Region,
+			"postal_code":         v.PostalCode,
+			"country_code":        v.CountryCode,
+			"status":              model.VerificationStatusSubmitted,
+			"reviewer_notes":      "",
+			"reviewed_by":         "",
+			"submitted_at":        v.SubmittedAt,
+			"reviewed_at":         nil,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// UpdateVerificationStatusById moves a case from one status to another,
+// failing with ErrConflict when another review changed it in the meantime.
+func (r *repo) UpdateVerificationStatusById(id, from, to, notes, reviewedBy string) error {
+	res := r.DB.Model(&model.Verification{}).Where(`id = ? AND status = ?`, id, from).
+		Updates(map[string]interface{}{
+			"status":         to,
+			"reviewer_notes": notes,
+			"reviewed_by":    reviewedBy,
+			"reviewed_at":    time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+func (r *repo) CreateVerificationDocument(d *model.VerificationDocument) error {
+	return r.DB.Create(&d).Error
+}
+
+func (r *repo) ReadVerificationDocumentById(id string) (*model.VerificationDocument, error) {
+	d := &model.VerificationDocument{}
+	if err := r.DB.Where(`id = ?`, id).First(d).Error; err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import "gorm.io/gorm"
+
+const versionCallback = "merchant:bump_version"
+
+// bumpVersion increments the version of the records an update changes. The
+// repository updates with maps, which the increment is added to unless the
+// update sets the version itself.
+func bumpVersion(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	if _, ok := db.Statement.Schema.FieldsByDBName["version"]; !ok {
+		return
+	}
+
+	if values, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		if _, ok := values["version"]; !ok {
+			values["version"] = gorm.Expr("version + 1")
+		}
+	}
+}
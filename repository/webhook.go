@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"merchant/model"
+)
+
+func (r *repo) ListWebhookSubscriptionsByMerchantId(merchantId string) (model.WebhookSubscriptions, error) {
+	ss := make([]*model.WebhookSubscription, 0)
+	err := r.DB.Where(`merchant_id = ?`, merchantId).Order(`created_at`).Find(&ss).Error
+	return ss, err
+}
+
+func (r *repo) CreateWebhookSubscription(s *model.WebhookSubscription) error {
+	return r.DB.Create(s).Error
+}
+
+func (r *repo) ReadWebhookSubscriptionById(id string) (*model.WebhookSubscription, error) {
+	s := &model.WebhookSubscription{}
+	if err := r.DB.Where(`id = ?`, id).First(s).Error; err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// UpdateWebhookSubscriptionById changes where and which events are sent;
+// the secret is kept.
+func (r *repo) UpdateWebhookSubscriptionById(id string, s *model.WebhookSubscription) error {
+	return r.DB.Model(&model.WebhookSubscription{}).Where(`id = ?`, id).Updates(map[string]interface{}{
+		"url":         s.URL,
+		"event_types": s.EventTypes,
+		"active":      s.Active,
+	}).Error
+}
+
+// DeleteWebhookSubscription removes the subscription along with its
+// deliveries, so nothing more is sent to it.
+func (r *repo) DeleteWebhookSubscription(id string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(`subscription_id = ?`, id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where(`id = ?`, id).Delete(&model.WebhookSubscription{}).Error
+	})
+}
+
+func (r *repo) CreateWebhookDeliveries(ds model.WebhookDeliveries) error {
+	if len(ds) == 0 {
+		return nil
+	}
+
+	return r.DB.Create(&ds).Error
+}
+
+// ListWebhookDeliveries returns a page of the delivery log of the
+// subscription, newest first, optionally only the deliveries with a status,
+// along with the number of matching deliveries in total.
+func (r *repo) ListWebhookDeliveries(subscriptionId, status string, limit, offset int) (model.WebhookDeliveries, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where(`subscription_id = ?`, subscriptionId)
+		if status != "" {
+			db = db.Where(`status = ?`, status)
+		}
+
+		return db
+	}
+
+	var total int64
+	if err := r.DB.Model(&model.WebhookDelivery{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	ds := make([]*model.WebhookDelivery, 0)
+	err := r.DB.Scopes(filter).Order(`created_at DESC`).Limit(limit).Offset(offset).Find(&ds).Error
+	return ds, total, err
+}
+
+func (r *repo) ReadWebhookDeliveryById(id string) (*model.WebhookDelivery, error) {
+	d := &model.WebhookDelivery{}
+	if err := r.DB.Where(`id = ?`, id).First(d).Error; err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ListDueWebhookDeliveries returns pending deliveries whose next attempt is
+// due, oldest first.
+func (r *repo) ListDueWebhookDeliveries(now time.Time, limit int) (model.WebhookDeliveries, error) {
+	ds := make([]*model.WebhookDelivery, 0)
+	err := r.DB.Where(`status = ? AND next_attempt_at <= ?`, model.WebhookDeliveryStatusPending, now).
+		Order(`next_attempt_at`).Limit(limit).Find(&ds).Error
+	return ds, err
+}
+
+// ClaimWebhookDelivery takes a due delivery for an attempt by moving its
+// next attempt to until, so other workers leave it alone meanwhile and pick
+// it up again should this one never record the outcome. It returns
+// ErrConflict when another worker claimed the delivery first.
+func (r *repo) ClaimWebhookDelivery(id string, attempts int, until time.Time) error {
+	res := r.DB.Model(&model.WebhookDelivery{}).
+		Where(`id = ? AND status = ? AND attempts = ?`, id, model.WebhookDeliveryStatusPending, attempts).
+		Update("next_attempt_at", until)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// UpdateWebhookDeliveryById records the outcome of an attempt on a delivery
+// claimed with the given number of attempts. It returns ErrConflict when the
+// delivery was redelivered in the meantime.
+func (r *repo) UpdateWebhookDeliveryById(id string, attempts int, d *model.WebhookDelivery) error {
+	res := r.DB.Model(&model.WebhookDelivery{}).Where(`id = ? AND attempts = ?`, id, attempts).Updates(map[string]interface{}{
+		"status":          d.Status,
+		"attempts":        d.Attempts,
+		"next_attempt_at": d.NextAttemptAt,
+		"last_attempt_at": d.LastAttemptAt,
+		"response_status": d.ResponseStatus,
+		"last_error":      d.LastError,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// RedeliverWebhookDelivery queues a delivery again with a fresh set of
+// attempts, whatever its status.
+func (r *repo) RedeliverWebhookDelivery(id string, at time.Time) error {
+	return r.DB.Model(&model.WebhookDelivery{}).Where(`id = ?`, id).Updates(map[string]interface{}{
+		"status":          model.WebhookDeliveryStatusPending,
+		"attempts":        0,
+		"next_attempt_at": at,
+	}).Error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+)
+
+func (s *Suite) Test_repository_Claim_Webhook_Delivery_Taken() {
+	id := "3c5e7a9b-1d2f-4b6a-8c0e-2f4a6b8c0d1e"
+	until := time.Date(2021, 3, 4, 10, 5, 0, 0, time.UTC)
+
+	query := "UPDATE `webhook_deliveries` SET `next_attempt_at`=?,`version`=version + 1,`updated_at`=? WHERE id = ? AND status = ? AND attempts = ?"
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(query).
+		WithArgs(until, s.Time, id, model.WebhookDeliveryStatusPending, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	err := s.repository.ClaimWebhookDelivery(id, 2, until)
+
+	require.Equal(s.T(), ErrConflict, err)
+}
@@ -0,0 +1,245 @@
+package teamimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+
+	"merchant/blob"
+	"merchant/model"
+	"merchant/repository"
+	utilvalidator "merchant/util/validator"
+)
+
+// SyncRows is the most rows an upload is imported for while it waits;
+// larger files are imported in the background.
+const SyncRows = 100
+
+var (
+	ErrInvalidRows  = errors.New("no team members were imported because some rows are invalid")
+	ErrImportFailed = errors.New("team members could not be imported")
+)
+
+// Importer imports files of team members. Several importers may share the
+// queue of background imports; every import is claimed before it runs.
+type Importer struct {
+	db        repository.Repository
+	blob      blob.Storage
+	validator *validator.Validate
+	logger    *zap.Logger
+
+	// BatchSize is the number of background imports taken at a time.
+	BatchSize int
+	// Lease is how long a claimed import is left alone by other importers.
+	// An import running longer is taken to have been cut off and is run
+	// again, which skips the members it imported already.
+	Lease time.Duration
+}
+
+func NewImporter(db repository.Repository, storage blob.Storage, validator *validator.Validate, logger *zap.Logger) *Importer {
+	return &Importer{
+		db:        db,
+		blob:      storage,
+		validator: validator,
+		logger:    logger,
+		BatchSize: 10,
+		Lease:     10 * time.Minute,
+	}
+}
+
+// Import validates the rows and, unless the import is a dry run, creates the
+// team members they list. Rows are invalid when they fail validation or
+// list the email of a member of the merchant, or of an earlier row. The
+// outcome is recorded on the import, which is left to the caller to store.
+func (im *Importer) Import(i *model.TeamMemberImport, rows []*Row) {
+	rowErrors, valid, err := im.validate(i.MerchantID, rows)
+
+	i.TotalRows = len(rows)
+	i.ValidRows = len(valid)
+	i.ImportedRows = 0
+
+	switch {
+	case err != nil || i.DryRun:
+	case i.Mode == model.TeamMemberImportModeBestEffort:
+		for _, row := range valid {
+			if err := im.create(i.MerchantID, row); err != nil {
+				im.logger.Warn(err.Error())
+				rowErrors = append(rowErrors, &model.TeamMemberImportRowError{Line: row.Line, Errors: []string{"row could not be imported"}})
+				continue
+			}
+			i.ImportedRows++
+		}
+	case len(rowErrors) > 0:
+		err = ErrInvalidRows
+	default:
+		err = im.db.Transaction(func(tx repository.Repository) error {
+			for _, row := range valid {
+				if err := create(tx, i.MerchantID, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			i.ImportedRows = len(valid)
+		}
+	}
+
+	if err != nil && err != ErrInvalidRows {
+		im.logger.Warn(err.Error())
+		err = ErrImportFailed
+	}
+
+	i.Finish(time.Now(), rowErrors, err)
+}
+
+func (im *Importer) validate(merchantId string, rows []*Row) ([]*model.TeamMemberImportRowError, []*Row, error) {
+	members, err := im.db.ListTeamMembersByMerchantId(merchantId, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := make(map[string]int)
+	for _, m := range members {
+		lines[strings.ToLower(m.Email.String())] = 0
+	}
+
+	rowErrors := make([]*model.TeamMemberImportRowError, 0)
+	valid := make([]*Row, 0, len(rows))
+	for _, row := range rows {
+		problems := append([]string(nil), row.Errors...)
+		if err := im.validator.Struct(&row.Form); err != nil {
+			if resp := utilvalidator.ToErrResponse(err); resp != nil {
+				problems = append(problems, resp.Errors...)
+			} else {
+				problems = append(problems, err.Error())
+			}
+		}
+
+		email := strings.ToLower(row.Form.Email)
+		if line, ok := lines[email]; ok && email != "" {
+			if line == 0 {
+				problems = append(problems, "email is a team member already")
+			} else {
+				problems = append(problems, fmt.Sprintf("email is listed on line %d already", line))
+			}
+		} else {
+			lines[email] = row.Line
+		}
+
+		if len(problems) > 0 {
+			rowErrors = append(rowErrors, &model.TeamMemberImportRowError{Line: row.Line, Errors: problems})
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	return rowErrors, valid, nil
+}
+
+func (im *Importer) create(merchantId string, row *Row) error {
+	return im.db.Transaction(func(tx repository.Repository) error {
+		return create(tx, merchantId, row)
+	})
+}
+
+func create(tx repository.Repository, merchantId string, row *Row) error {
+	member := row.Form.ToModel(merchantId)
+	if err := tx.CreateTeamMember(member); err != nil {
+		return err
+	}
+
+	return tx.CreateOutboxEvent(model.NewTeamMemberEvent(model.EventTeamMemberCreated, member))
+}
+
+// Run imports queued files every interval until the context is done.
+func (im *Importer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := im.ImportPending(ctx)
+			if err != nil {
+				im.logger.Warn(err.Error())
+			}
+			if err != nil || n < im.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ImportPending runs one batch of queued imports and returns the number of
+// imports taken from the queue.
+func (im *Importer) ImportPending(ctx context.Context) (int, error) {
+	now := time.Now()
+	imports, err := im.db.ListPendingTeamMemberImports(now.Add(-im.Lease), im.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, i := range imports {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		if err := im.run(ctx, i); err != nil && err != repository.ErrConflict {
+			im.logger.Warn(err.Error())
+		}
+	}
+
+	return len(imports), nil
+}
+
+func (im *Importer) run(ctx context.Context, i *model.TeamMemberImport) error {
+	now := time.Now()
+	if err := im.db.ClaimTeamMemberImport(i.ID, now, now.Add(-im.Lease)); err != nil {
+		return err
+	}
+	i.Status = model.TeamMemberImportStatusRunning
+	i.StartedAt = &now
+
+	// The file was read once when it was uploaded, so failing to read it
+	// now is a failure of the storage.
+	rows, err := im.read(ctx, i)
+	if err != nil {
+		im.logger.Warn(err.Error())
+		i.Finish(time.Now(), nil, ErrImportFailed)
+	} else {
+		im.Import(i, rows)
+	}
+
+	if err := im.db.FinishTeamMemberImport(i); err != nil {
+		return err
+	}
+
+	return im.blob.Delete(ctx, i.BlobKey)
+}
+
+func (im *Importer) read(ctx context.Context, i *model.TeamMemberImport) ([]*Row, error) {
+	rc, err := im.blob.Get(ctx, i.BlobKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	return Read(i.Format, data)
+}
@@ -0,0 +1,112 @@
+package teamimport_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"merchant/mock/mock_repository"
+	"merchant/model"
+	"merchant/repository"
+	"merchant/teamimport"
+	"merchant/util/validator"
+)
+
+const staff = "givenName,familyName,email\n" +
+	"Ada,Lovelace,ada@example.com\n" +
+	"Grace,,grace@example.com\n" +
+	"Alan,Turing,ADA@example.com\n" +
+	"Edsger,Dijkstra,edsger@example.com\n" +
+	"Barbara,Liskov,barbara@example.com\n"
+
+func readStaff(t *testing.T) []*teamimport.Row {
+	rows, err := teamimport.Read(model.TeamMemberImportFormatCSV, []byte(staff))
+	require.NoError(t, err)
+	return rows
+}
+
+func rowErrors(t *testing.T, i *model.TeamMemberImport) []*model.TeamMemberImportRowError {
+	var errs []*model.TeamMemberImportRowError
+	require.NoError(t, json.Unmarshal([]byte(i.RowErrors), &errs))
+	return errs
+}
+
+func newImporter(ctrl *gomock.Controller) (*teamimport.Importer, *mock_repository.MockRepository) {
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListTeamMembersByMerchantId("m1", gomock.Nil()).
+		Return(model.TeamMembers{{Email: "Barbara@Example.com", MerchantID: "m1"}}, nil)
+
+	return teamimport.NewImporter(db, nil, validator.New(), zap.NewNop()), db
+}
+
+func TestImportDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	im, _ := newImporter(ctrl)
+
+	i := &model.TeamMemberImport{MerchantID: "m1", Mode: model.TeamMemberImportModeBestEffort, DryRun: true}
+	im.Import(i, readStaff(t))
+
+	assert.Equal(t, model.TeamMemberImportStatusSucceeded, i.Status)
+	assert.Equal(t, 5, i.TotalRows)
+	assert.Equal(t, 2, i.ValidRows)
+	assert.Equal(t, 0, i.ImportedRows)
+	assert.Equal(t, []*model.TeamMemberImportRowError{
+		{Line: 3, Errors: []string{"familyName is a required field"}},
+		{Line: 4, Errors: []string{"email is listed on line 2 already"}},
+		{Line: 6, Errors: []string{"email is a team member already"}},
+	}, rowErrors(t, i))
+}
+
+func TestImportAllOrNothingWithInvalidRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	im, _ := newImporter(ctrl)
+
+	i := &model.TeamMemberImport{MerchantID: "m1", Mode: model.TeamMemberImportModeAllOrNothing}
+	im.Import(i, readStaff(t))
+
+	assert.Equal(t, model.TeamMemberImportStatusFailed, i.Status)
+	assert.Equal(t, teamimport.ErrInvalidRows.Error(), i.Error)
+	assert.Equal(t, 0, i.ImportedRows)
+	assert.Len(t, rowErrors(t, i), 3)
+}
+
+func TestImportBestEffort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	im, db := newImporter(ctrl)
+	db.EXPECT().Transaction(gomock.Any()).Times(2).DoAndReturn(func(fn func(repository.Repository) error) error {
+		return fn(db)
+	})
+
+	var created []string
+	db.EXPECT().CreateTeamMember(gomock.Any()).Times(2).DoAndReturn(func(m *model.TeamMember) error {
+		require.Equal(t, "m1", m.MerchantID)
+		if m.Email == "edsger@example.com" {
+			return errors.New("connection lost")
+		}
+		created = append(created, m.Email.String())
+		return nil
+	})
+	db.EXPECT().CreateOutboxEvent(gomock.Any()).Return(nil)
+
+	i := &model.TeamMemberImport{MerchantID: "m1", Mode: model.TeamMemberImportModeBestEffort}
+	im.Import(i, readStaff(t))
+
+	assert.Equal(t, model.TeamMemberImportStatusSucceeded, i.Status)
+	assert.Equal(t, 1, i.ImportedRows)
+	assert.Equal(t, []string{"ada@example.com"}, created)
+
+	errs := rowErrors(t, i)
+	require.Len(t, errs, 4)
+	assert.Equal(t, 5, errs[3].Line)
+}
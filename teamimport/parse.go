@@ -0,0 +1,159 @@
+package teamimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"path"
+	"strings"
+
+	"merchant/model"
+	"merchant/util/xlsxutil"
+)
+
+// MaxRows is the most team members a file may list.
+const MaxRows = 10000
+
+var (
+	ErrMissingColumns = errors.New("file must have givenName, familyName and email columns")
+	ErrTooManyRows    = errors.New("file has too many rows")
+)
+
+// Row is a team member read from a line of an import file.
+type Row struct {
+	Line int
+	Form model.TeamMemberCreateForm
+	// Errors lists the problems found reading the row, ahead of validation.
+	Errors []string
+}
+
+// columns maps the accepted column names, lower cased and without spaces,
+// dashes and underscores, to the fields they fill.
+var columns = map[string]string{
+	"givenname":  "givenName",
+	"firstname":  "givenName",
+	"familyname": "familyName",
+	"lastname":   "familyName",
+	"surname":    "familyName",
+	"email":      "email",
+	"isowner":    "isOwner",
+	"owner":      "isOwner",
+}
+
+// DetectFormat returns the format of an import file by its name, or by its
+// content when the name says nothing.
+func DetectFormat(fileName string, data []byte) string {
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".xlsx":
+		return model.TeamMemberImportFormatXLSX
+	case ".csv":
+		return model.TeamMemberImportFormatCSV
+	}
+
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return model.TeamMemberImportFormatXLSX
+	}
+
+	return model.TeamMemberImportFormatCSV
+}
+
+// Read returns the rows of an import file. The first line names the columns
+// and blank lines are skipped; blank lines of CSV files do not count towards
+// the line numbers either.
+func Read(format string, data []byte) ([]*Row, error) {
+	var records [][]string
+	var err error
+	if format == model.TeamMemberImportFormatXLSX {
+		records, err = xlsxutil.ReadRows(bytes.NewReader(data), int64(len(data)))
+	} else {
+		records, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return readRecords(records)
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	cr := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	return cr.ReadAll()
+}
+
+func readRecords(records [][]string) ([]*Row, error) {
+	header := 0
+	for header < len(records) && isBlank(records[header]) {
+		header++
+	}
+	if header == len(records) {
+		return nil, ErrMissingColumns
+	}
+
+	index := make(map[string]int)
+	for i, name := range records[header] {
+		key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+		if field, ok := columns[key]; ok {
+			if _, seen := index[field]; !seen {
+				index[field] = i
+			}
+		}
+	}
+	for _, field := range []string{"givenName", "familyName", "email"} {
+		if _, ok := index[field]; !ok {
+			return nil, ErrMissingColumns
+		}
+	}
+
+	cell := func(record []string, field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := make([]*Row, 0)
+	for i := header + 1; i < len(records); i++ {
+		record := records[i]
+		if isBlank(record) {
+			continue
+		}
+		if len(rows) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+
+		row := &Row{
+			Line: i + 1,
+			Form: model.TeamMemberCreateForm{
+				GivenName:  cell(record, "givenName"),
+				FamilyName: cell(record, "familyName"),
+				Email:      cell(record, "email"),
+			},
+		}
+
+		switch strings.ToLower(cell(record, "isOwner")) {
+		case "", "false", "no", "n", "0":
+		case "true", "yes", "y", "1":
+			row.Form.IsOwner = true
+		default:
+			row.Errors = append(row.Errors, "isOwner must be true or false")
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,45 @@
+package teamimport_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/model"
+	"merchant/teamimport"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := "\xef\xbb\xbfFirst Name,last_name,E-mail,Owner\n" +
+		"Ada,Lovelace,ada@example.com,yes\n" +
+		",,,\n" +
+		"Grace,Hopper,grace@example.com,\n" +
+		"Alan,Turing,alan@example.com,maybe\n"
+
+	rows, err := teamimport.Read(model.TeamMemberImportFormatCSV, []byte(data))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, 2, rows[0].Line)
+	assert.Equal(t, model.TeamMemberCreateForm{IsOwner: true, GivenName: "Ada", FamilyName: "Lovelace", Email: "ada@example.com"}, rows[0].Form)
+	assert.Equal(t, 4, rows[1].Line)
+	assert.False(t, rows[1].Form.IsOwner)
+	assert.Empty(t, rows[1].Errors)
+	assert.Equal(t, []string{"isOwner must be true or false"}, rows[2].Errors)
+}
+
+func TestReadMissingColumns(t *testing.T) {
+	_, err := teamimport.Read(model.TeamMemberImportFormatCSV, []byte("givenName,email\nAda,ada@example.com\n"))
+	assert.Equal(t, teamimport.ErrMissingColumns, err)
+
+	_, err = teamimport.Read(model.TeamMemberImportFormatCSV, nil)
+	assert.Equal(t, teamimport.ErrMissingColumns, err)
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, model.TeamMemberImportFormatXLSX, teamimport.DetectFormat("staff.XLSX", nil))
+	assert.Equal(t, model.TeamMemberImportFormatCSV, teamimport.DetectFormat("staff.csv", []byte("PK\x03\x04")))
+	assert.Equal(t, model.TeamMemberImportFormatXLSX, teamimport.DetectFormat("staff", []byte("PK\x03\x04")))
+	assert.Equal(t, model.TeamMemberImportFormatCSV, teamimport.DetectFormat("", []byte("givenName,familyName,email\n")))
+}
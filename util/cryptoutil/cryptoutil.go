@@ -0,0 +1,160 @@
+package cryptoutil
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+const encryptedPrefix = "enc:v1:"
+
+var (
+	ErrNoCipher            = errors.New("no field encryption key configured")
+	ErrMalformedCipherText = errors.New("malformed cipher text")
+)
+
+// Cipher encrypts and authenticates small values such as database fields.
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+type aesGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM returns a Cipher using AES-GCM with a random nonce prepended to
+// every cipher text. The key must be 16, 24 or 32 bytes long.
+func NewAESGCM(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesGCM{aead: aead}, nil
+}
+
+func (c *aesGCM) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *aesGCM) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, ErrMalformedCipherText
+	}
+
+	return c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
+
+var defaultKeyring atomic.Value
+
+// SetDefault sets a keyring without data keys, encrypting EncryptedString
+// columns with the cipher directly.
+func SetDefault(c Cipher) {
+	SetKeyring(NewKeyring(c))
+}
+
+// SetKeyring sets the keyring used by EncryptedString columns.
+func SetKeyring(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+// DefaultKeyring returns the keyring used by EncryptedString columns, or
+// nil.
+func DefaultKeyring() *Keyring {
+	k, _ := defaultKeyring.Load().(*Keyring)
+	return k
+}
+
+// BlindIndex returns the blind index of the value with the default keyring.
+// The index of an empty value is empty.
+func BlindIndex(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	k := DefaultKeyring()
+	if k == nil {
+		return "", ErrNoCipher
+	}
+
+	return k.BlindIndex(value)
+}
+
+// Stale reports whether the stored value is to be encrypted again with the
+// active data key of the default keyring.
+func Stale(value string) bool {
+	k := DefaultKeyring()
+	return k != nil && k.Stale(value)
+}
+
+// EncryptedString is a string stored encrypted with the default keyring.
+// Empty strings are stored as they are, and values written before a column
+// was encrypted are read back unchanged.
+type EncryptedString string
+
+func (s EncryptedString) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	k := DefaultKeyring()
+	if k == nil {
+		return nil, ErrNoCipher
+	}
+
+	return k.encrypt(string(s))
+}
+
+func (s *EncryptedString) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+		value = ""
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EncryptedString", src)
+	}
+
+	if !strings.HasPrefix(value, encryptedPrefix) && !strings.HasPrefix(value, envelopePrefix) {
+		*s = EncryptedString(value)
+		return nil
+	}
+
+	k := DefaultKeyring()
+	if k == nil {
+		return ErrNoCipher
+	}
+
+	plaintext, err := k.decrypt(value)
+	if err != nil {
+		return err
+	}
+
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+func (s EncryptedString) String() string {
+	return string(s)
+}
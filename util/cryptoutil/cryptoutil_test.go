@@ -0,0 +1,142 @@
+package cryptoutil_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/util/cryptoutil"
+)
+
+var key = bytes.Repeat([]byte{7}, 32)
+
+func TestAESGCMRoundTrip(t *testing.T) {
+	c, err := cryptoutil.NewAESGCM(key)
+	require.NoError(t, err)
+
+	first, err := c.Encrypt([]byte("GB82WEST12345698765432"))
+	require.NoError(t, err)
+	second, err := c.Encrypt([]byte("GB82WEST12345698765432"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "nonces must differ between encryptions")
+
+	plaintext, err := c.Decrypt(first)
+	require.NoError(t, err)
+	assert.Equal(t, "GB82WEST12345698765432", string(plaintext))
+
+	first[len(first)-1] ^= 1
+	_, err = c.Decrypt(first)
+	assert.Error(t, err, "tampered cipher texts must be rejected")
+}
+
+func TestEncryptedString(t *testing.T) {
+	c, err := cryptoutil.NewAESGCM(key)
+	require.NoError(t, err)
+	cryptoutil.SetDefault(c)
+
+	value, err := cryptoutil.EncryptedString("12345678").Value()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(value.(string), "enc:v1:"))
+	assert.NotContains(t, value.(string), "12345678")
+
+	var scanned cryptoutil.EncryptedString
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, cryptoutil.EncryptedString("12345678"), scanned)
+
+	require.NoError(t, scanned.Scan("legacy plaintext"))
+	assert.Equal(t, cryptoutil.EncryptedString("legacy plaintext"), scanned)
+
+	empty, err := cryptoutil.EncryptedString("").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "", empty)
+}
+
+func TestKeyring(t *testing.T) {
+	master, err := cryptoutil.NewAESGCM(key)
+	require.NoError(t, err)
+	cryptoutil.SetDefault(master)
+	legacy, err := cryptoutil.EncryptedString("legacy").Value()
+	require.NoError(t, err)
+
+	k := cryptoutil.NewKeyring(master)
+	require.NoError(t, k.AddKey("first", bytes.Repeat([]byte{1}, 32)))
+	require.NoError(t, k.Activate("first"))
+	assert.Equal(t, cryptoutil.ErrUnknownKey, k.Activate("unknown"))
+	cryptoutil.SetKeyring(k)
+	defer cryptoutil.SetDefault(master)
+
+	value, err := cryptoutil.EncryptedString("jane@example.com").Value()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(value.(string), "enc:v2:first:"))
+	assert.False(t, k.Stale(value.(string)))
+	assert.True(t, k.Stale(legacy.(string)), "values encrypted with the master key must be stale")
+	assert.True(t, k.Stale("plaintext"))
+	assert.False(t, k.Stale(""))
+
+	var scanned cryptoutil.EncryptedString
+	require.NoError(t, scanned.Scan(legacy))
+	assert.Equal(t, cryptoutil.EncryptedString("legacy"), scanned)
+
+	require.NoError(t, k.AddKey("second", bytes.Repeat([]byte{2}, 32)))
+	require.NoError(t, k.Activate("second"))
+	assert.True(t, k.Stale(value.(string)), "values encrypted with a rotated key must be stale")
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, cryptoutil.EncryptedString("jane@example.com"), scanned)
+
+	fetched := cryptoutil.NewKeyring(master)
+	fetched.Fetch = func(id string) ([]byte, error) {
+		assert.Equal(t, "first", id)
+		return bytes.Repeat([]byte{1}, 32), nil
+	}
+	cryptoutil.SetKeyring(fetched)
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, cryptoutil.EncryptedString("jane@example.com"), scanned)
+}
+
+func TestKeyringWrap(t *testing.T) {
+	retired, err := cryptoutil.NewAESGCM(key)
+	require.NoError(t, err)
+	master, err := cryptoutil.ParseKey("r5SVqbULtiTVLkHyp2rTGE+NXcrB6E/2GEl2HR3Au7g=")
+	require.NoError(t, err)
+
+	wrapped, err := cryptoutil.NewKeyring(retired).Wrap([]byte("data key"))
+	require.NoError(t, err)
+
+	k := cryptoutil.NewKeyring(master, retired)
+	unwrapped, rewrap, err := k.Unwrap(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "data key", string(unwrapped))
+	assert.True(t, rewrap, "keys wrapped by a retired master key must be wrapped again")
+
+	wrapped, err = k.Wrap([]byte("data key"))
+	require.NoError(t, err)
+	_, rewrap, err = k.Unwrap(wrapped)
+	require.NoError(t, err)
+	assert.False(t, rewrap)
+
+	_, _, err = cryptoutil.NewKeyring(retired).Unwrap(wrapped)
+	assert.Error(t, err)
+}
+
+func TestBlindIndex(t *testing.T) {
+	master, err := cryptoutil.NewAESGCM(key)
+	require.NoError(t, err)
+
+	k := cryptoutil.NewKeyring(master)
+	_, err = k.BlindIndex("jane@example.com")
+	assert.Equal(t, cryptoutil.ErrNoIndexKey, err)
+
+	k.SetIndexKey(bytes.Repeat([]byte{3}, 32))
+	first, err := k.BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	second, err := k.BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	other, err := k.BlindIndex("john@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+	assert.Len(t, first, 64)
+}
@@ -0,0 +1,267 @@
+package cryptoutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+const envelopePrefix = "enc:v2:"
+
+var (
+	ErrUnknownKey = errors.New("unknown data key")
+	ErrNoIndexKey = errors.New("no blind index key configured")
+)
+
+// Keyring holds the keys of envelope encryption: data keys, which encrypt
+// values, and master keys, which wrap the data keys where they are stored.
+// Values are encrypted with the active data key and carry its id, so they
+// stay readable once another data key is activated.
+//
+// Without data keys, values are encrypted with the master key itself as
+// they were before envelope encryption; such values stay readable too.
+type Keyring struct {
+	masters []Cipher
+
+	mu     sync.RWMutex
+	keys   map[string]Cipher
+	active string
+	index  []byte
+
+	// Fetch, when set, returns the data key of the id for values encrypted
+	// with a key the keyring does not hold, such as a key activated by
+	// another process.
+	Fetch func(id string) ([]byte, error)
+}
+
+// NewKeyring returns a keyring wrapping data keys with the master key.
+// Retired master keys still unwrap the data keys they wrapped, and decrypt
+// the values encrypted with them directly.
+func NewKeyring(master Cipher, retired ...Cipher) *Keyring {
+	return &Keyring{
+		masters: append([]Cipher{master}, retired...),
+		keys:    make(map[string]Cipher),
+	}
+}
+
+// ParseKey returns an AES-GCM cipher of the base64 encoded key.
+func ParseKey(s string) (Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAESGCM(key)
+}
+
+// ReadKeyFile returns an AES-GCM cipher of the base64 encoded key held in
+// the file.
+func ReadKeyFile(path string) (Cipher, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKey(string(b))
+}
+
+// Wrap encrypts the data key with the master key.
+func (k *Keyring) Wrap(key []byte) ([]byte, error) {
+	return k.masters[0].Encrypt(key)
+}
+
+// Unwrap decrypts a wrapped data key. It also reports whether the key was
+// wrapped by a retired master key, in which case it should be wrapped
+// again.
+func (k *Keyring) Unwrap(wrapped []byte) ([]byte, bool, error) {
+	var err error
+	for i, m := range k.masters {
+		key, e := m.Decrypt(wrapped)
+		if e == nil {
+			return key, i > 0, nil
+		}
+		err = e
+	}
+
+	return nil, false, err
+}
+
+// AddKey makes the data key of the id available to decrypt values with.
+func (k *Keyring) AddKey(id string, key []byte) error {
+	c, err := NewAESGCM(key)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[id] = c
+	return nil
+}
+
+// Activate makes the added data key of the id the one new values are
+// encrypted with.
+func (k *Keyring) Activate(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return ErrUnknownKey
+	}
+	k.active = id
+	return nil
+}
+
+// ActiveKeyID returns the id of the data key new values are encrypted with,
+// or an empty string when they are encrypted with the master key.
+func (k *Keyring) ActiveKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// SetIndexKey sets the key of blind indexes. Unlike data keys, it is never
+// rotated, as the indexes computed before would no longer match.
+func (k *Keyring) SetIndexKey(key []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.index = append([]byte(nil), key...)
+}
+
+// BlindIndex returns a keyed hash of the value, equal for equal values, to
+// look encrypted values up by.
+func (k *Keyring) BlindIndex(value string) (string, error) {
+	k.mu.RLock()
+	key := k.index
+	k.mu.RUnlock()
+	if key == nil {
+		return "", ErrNoIndexKey
+	}
+
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(value))
+	return hex.EncodeToString(m.Sum(nil)), nil
+}
+
+// Stale reports whether a stored value is not encrypted with the active
+// data key: it is plaintext, or encrypted with a master key or with a
+// data key activated before.
+func (k *Keyring) Stale(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	active := k.ActiveKeyID()
+	if active == "" {
+		return !strings.HasPrefix(value, encryptedPrefix)
+	}
+	if !strings.HasPrefix(value, envelopePrefix) {
+		return true
+	}
+
+	id, _, _ := splitEnvelope(value)
+	return id != active
+}
+
+func (k *Keyring) encrypt(plaintext string) (string, error) {
+	k.mu.RLock()
+	id, c := k.active, k.keys[k.active]
+	k.mu.RUnlock()
+
+	if id == "" {
+		ciphertext, err := k.masters[0].Encrypt([]byte(plaintext))
+		if err != nil {
+			return "", err
+		}
+		return encryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+	}
+
+	ciphertext, err := c.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + id + ":" + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decrypt returns stored values which are not encrypted unchanged.
+func (k *Keyring) decrypt(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, envelopePrefix):
+		id, encoded, ok := splitEnvelope(value)
+		if !ok {
+			return "", ErrMalformedCipherText
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", ErrMalformedCipherText
+		}
+
+		c, err := k.key(id)
+		if err != nil {
+			return "", err
+		}
+		plaintext, err := c.Decrypt(ciphertext)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+
+	case strings.HasPrefix(value, encryptedPrefix):
+		ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+		if err != nil {
+			return "", ErrMalformedCipherText
+		}
+
+		for _, m := range k.masters {
+			plaintext, e := m.Decrypt(ciphertext)
+			if e == nil {
+				return string(plaintext), nil
+			}
+			err = e
+		}
+		return "", err
+	}
+
+	return value, nil
+}
+
+// key returns the data key of the id, fetching it when the keyring does not
+// hold it.
+func (k *Keyring) key(id string) (Cipher, error) {
+	k.mu.RLock()
+	c, ok := k.keys[id]
+	k.mu.RUnlock()
+	if ok {
+		return c, nil
+	}
+	if k.Fetch == nil {
+		return nil, ErrUnknownKey
+	}
+
+	key, err := k.Fetch(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.AddKey(id, key); err != nil {
+		return nil, err
+	}
+
+	return k.key(id)
+}
+
+// splitEnvelope splits a value encrypted with a data key into the id of the
+// key and the base64 encoded cipher text.
+func splitEnvelope(value string) (string, string, bool) {
+	rest := strings.TrimPrefix(value, envelopePrefix)
+	i := strings.IndexByte(rest, ':')
+	if i < 0 {
+		return "", "", false
+	}
+
+	return rest[:i], rest[i+1:], true
+}
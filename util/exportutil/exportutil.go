@@ -0,0 +1,210 @@
+package exportutil
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// mediaTypes maps the media types an export may be asked for to its format.
+var mediaTypes = map[string]string{
+	"text/csv":             FormatCSV,
+	"text/*":               FormatCSV,
+	"*/*":                  FormatCSV,
+	"application/x-ndjson": FormatNDJSON,
+	"application/ndjson":   FormatNDJSON,
+	"application/jsonl":    FormatNDJSON,
+}
+
+// ContentType returns the media type of an export in the format.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Negotiate picks the format of an export from an Accept header, preferring
+// the media type of the highest quality and, among equals, the one listed
+// first. A missing header asks for CSV. It returns false when none of the
+// acceptable media types is available.
+func Negotiate(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return FormatCSV, true
+	}
+
+	format, best := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+
+		q := 1.0
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.ToLower(kv[0]) == "q" {
+				if v, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		if f, ok := mediaTypes[mediaType]; ok && q > best {
+			format, best = f, q
+		}
+	}
+
+	return format, format != ""
+}
+
+// Columns returns the JSON names of the fields of a struct, in order.
+func Columns(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	columns := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.SplitN(t.Field(i).Tag.Get("json"), ",", 2)[0]
+		if name != "" && name != "-" {
+			columns = append(columns, name)
+		}
+	}
+
+	return columns
+}
+
+// SelectColumns returns the columns named in a comma separated list, or all
+// the available columns when the list is empty. Unknown columns are an
+// error.
+func SelectColumns(available []string, list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return available, nil
+	}
+
+	known := make(map[string]bool, len(available))
+	for _, c := range available {
+		known[c] = true
+	}
+
+	var columns []string
+	for _, c := range strings.Split(list, ",") {
+		c = strings.TrimSpace(c)
+		if !known[c] {
+			return nil, fmt.Errorf("unknown column %q", c)
+		}
+		columns = append(columns, c)
+	}
+
+	return columns, nil
+}
+
+// Writer writes records as CSV, with a header line naming the columns, or as
+// NDJSON, one JSON object per line. Records are structs which are encoded
+// to JSON and cut down to the columns.
+type Writer struct {
+	w       io.Writer
+	columns []string
+	csv     *csv.Writer
+}
+
+func NewWriter(w io.Writer, format string, columns []string) *Writer {
+	ew := &Writer{
+		w:       w,
+		columns: columns,
+	}
+	if format == FormatCSV {
+		ew.csv = csv.NewWriter(w)
+	}
+
+	return ew
+}
+
+// WriteHeader writes the header line of a CSV export.
+func (ew *Writer) WriteHeader() error {
+	if ew.csv == nil {
+		return nil
+	}
+
+	return ew.csv.Write(ew.columns)
+}
+
+func (ew *Writer) Write(record interface{}) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	if ew.csv != nil {
+		cells := make([]string, len(ew.columns))
+		for i, c := range ew.columns {
+			cells[i] = cell(fields[c])
+		}
+		return ew.csv.Write(cells)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, c := range ew.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(c)
+		buf.Write(name)
+		buf.WriteByte(':')
+		if v, ok := fields[c]; ok {
+			buf.Write(v)
+		} else {
+			buf.WriteString("null")
+		}
+	}
+	buf.WriteString("}\n")
+
+	_, err = ew.w.Write(buf.Bytes())
+	return err
+}
+
+// Flush writes any buffered records to the underlying writer.
+func (ew *Writer) Flush() error {
+	if ew.csv == nil {
+		return nil
+	}
+
+	ew.csv.Flush()
+	return ew.csv.Error()
+}
+
+// cell returns the CSV cell of a JSON value. Text starting like a formula is
+// prefixed with a quote, so spreadsheets show it rather than evaluate it.
+func cell(v json.RawMessage) string {
+	if len(v) == 0 || string(v) == "null" {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return string(v)
+	}
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
@@ -0,0 +1,73 @@
+package exportutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/util/exportutil"
+)
+
+type record struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Owner   bool   `json:"isOwner"`
+	Secret  string `json:"-"`
+	Comment string `json:"comment,omitempty"`
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		format string
+		ok     bool
+	}{
+		{"", exportutil.FormatCSV, true},
+		{"*/*", exportutil.FormatCSV, true},
+		{"application/x-ndjson", exportutil.FormatNDJSON, true},
+		{"text/csv;q=0.5, application/x-ndjson", exportutil.FormatNDJSON, true},
+		{"application/x-ndjson;q=0.2, text/csv;q=0.9", exportutil.FormatCSV, true},
+		{"application/json", "", false},
+	}
+
+	for _, tt := range tests {
+		format, ok := exportutil.Negotiate(tt.accept)
+		assert.Equal(t, tt.ok, ok, tt.accept)
+		assert.Equal(t, tt.format, format, tt.accept)
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	available := exportutil.Columns(&record{})
+	assert.Equal(t, []string{"id", "name", "isOwner", "comment"}, available)
+
+	columns, err := exportutil.SelectColumns(available, "name, id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "id"}, columns)
+
+	_, err = exportutil.SelectColumns(available, "id,password")
+	assert.Error(t, err)
+}
+
+func TestWriterCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ew := exportutil.NewWriter(buf, exportutil.FormatCSV, []string{"name", "isOwner", "comment"})
+	require.NoError(t, ew.WriteHeader())
+	require.NoError(t, ew.Write(&record{ID: "1", Name: "Lovelace, Ada", Owner: true}))
+	require.NoError(t, ew.Write(&record{ID: "2", Name: "=HYPERLINK(\"x\")", Comment: "hi"}))
+	require.NoError(t, ew.Flush())
+
+	assert.Equal(t, "name,isOwner,comment\n\"Lovelace, Ada\",true,\n\"'=HYPERLINK(\"\"x\"\")\",false,hi\n", buf.String())
+}
+
+func TestWriterNDJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ew := exportutil.NewWriter(buf, exportutil.FormatNDJSON, []string{"name", "id", "comment"})
+	require.NoError(t, ew.WriteHeader())
+	require.NoError(t, ew.Write(&record{ID: "1", Name: "Ada"}))
+	require.NoError(t, ew.Flush())
+
+	assert.Equal(t, "{\"name\":\"Ada\",\"id\":\"1\",\"comment\":null}\n", buf.String())
+}
@@ -0,0 +1,78 @@
+package imageutil
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Thumbnail scales img down to fit within maxWidth x maxHeight, keeping its
+// aspect ratio. Each destination pixel is the average of the source pixels
+// it covers, which avoids the aliasing of nearest-neighbour sampling.
+// Images that already fit are returned as RGBA copies of the same size.
+func Thumbnail(img image.Image, maxWidth, maxHeight int) *image.RGBA {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+
+	dstW, dstH := Fit(srcW, srcH, maxWidth, maxHeight)
+
+	src := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, (y+1)*srcH/dstH
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, (x+1)*srcW/dstW
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					bl += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(bl / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// Fit returns the largest size with the aspect ratio of width x height that
+// fits within maxWidth x maxHeight, never scaling up.
+func Fit(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+
+	if width*maxHeight > height*maxWidth {
+		h := height * maxWidth / width
+		if h < 1 {
+			h = 1
+		}
+		return maxWidth, h
+	}
+
+	w := width * maxHeight / height
+	if w < 1 {
+		w = 1
+	}
+	return w, maxHeight
+}
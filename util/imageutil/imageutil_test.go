@@ -0,0 +1,59 @@
+package imageutil_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/util/imageutil"
+)
+
+type fitTestCase struct {
+	name                string
+	width, height       int
+	expWidth, expHeight int
+}
+
+var fitTests = []*fitTestCase{
+	{name: "already fits", width: 100, height: 50, expWidth: 100, expHeight: 50},
+	{name: "landscape", width: 1000, height: 500, expWidth: 200, expHeight: 100},
+	{name: "portrait", width: 500, height: 1000, expWidth: 100, expHeight: 200},
+	{name: "square", width: 400, height: 400, expWidth: 200, expHeight: 200},
+	{name: "very thin", width: 10000, height: 1, expWidth: 200, expHeight: 1},
+}
+
+func TestFit(t *testing.T) {
+	for _, tc := range fitTests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, h := imageutil.Fit(tc.width, tc.height, 200, 200)
+			assert.Equal(t, tc.expWidth, w)
+			assert.Equal(t, tc.expHeight, h)
+		})
+	}
+}
+
+func TestThumbnailAveragesPixels(t *testing.T) {
+	t.Parallel()
+
+	// A 4x2 image striped black and white per column scales to mid grey.
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if x%2 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	thumb := imageutil.Thumbnail(img, 2, 2)
+
+	assert.Equal(t, image.Rect(0, 0, 2, 1), thumb.Bounds())
+	assert.Equal(t, color.RGBA{R: 127, G: 127, B: 127, A: 255}, thumb.RGBAAt(0, 0))
+}
@@ -0,0 +1,104 @@
+package netutil
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ErrNonPublicAddress is returned when dialing an address outside the
+// public internet.
+var ErrNonPublicAddress = errors.New("address is not a public internet address")
+
+// nonPublicNetworks are the loopback, private, link-local, shared, multicast
+// and otherwise reserved ranges, which must not be reached on behalf of
+// users.
+var nonPublicNetworks = parseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"100::/64",
+	"2001:db8::/32",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for k, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[k] = network
+	}
+
+	return networks
+}
+
+// IsPublicIP reports whether the IP is a public internet address. IPv4
+// addresses mapped into IPv6 are judged as IPv4 addresses.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsPublicHost reports whether the host of a URL may be public: IP literals
+// must be public addresses and names must not be localhost names. Names are
+// not resolved, so dial with PublicDialControl too.
+func IsPublicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return IsPublicIP(ip)
+	}
+
+	return true
+}
+
+// PublicDialControl is a net.Dialer Control function refusing connections
+// to addresses outside the public internet. It runs after names were
+// resolved, so it also stops names which resolve to internal addresses.
+func PublicDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublicIP(net.ParseIP(host)) {
+		return ErrNonPublicAddress
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package netutil_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"merchant/util/netutil"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	t.Parallel()
+
+	for ip, ok := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::248": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.20.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"::ffff:127.0.0.1":     false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+	} {
+		assert.Equal(t, ok, netutil.IsPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestIsPublicHost(t *testing.T) {
+	t.Parallel()
+
+	for host, ok := range map[string]bool{
+		"example.com":     true,
+		"93.184.216.34":   true,
+		"localhost":       false,
+		"LOCALHOST.":      false,
+		"api.localhost":   false,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"169.254.169.254": false,
+		"":                false,
+	} {
+		assert.Equal(t, ok, netutil.IsPublicHost(host), host)
+	}
+}
+
+func TestPublicDialControl(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, netutil.PublicDialControl("tcp", "93.184.216.34:443", nil))
+	assert.Equal(t, netutil.ErrNonPublicAddress, netutil.PublicDialControl("tcp", "127.0.0.1:443", nil))
+	assert.Equal(t, netutil.ErrNonPublicAddress, netutil.PublicDialControl("tcp", "[::1]:443", nil))
+}
@@ -0,0 +1,214 @@
+package pdfutil
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Document is a minimal PDF writer for simple business documents: text in
+// the standard Helvetica fonts, lines and RGB images. Coordinates are in
+// points from the bottom left corner of the page.
+type Document struct {
+	pages  []*bytes.Buffer
+	images []image.Image
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage starts a new page; subsequent drawing goes onto it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline starting at x, y.
+func (d *Document) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+
+	fmt.Fprintf(d.page(), "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escape(s))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y, size float64, bold bool, s string) {
+	d.Text(x-TextWidth(s, size, bold), y, size, bold, s)
+}
+
+// Line draws a thin grey line from x1, y1 to x2, y2.
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "q 0.6 G 0.5 w %.2f %.2f m %.2f %.2f l S Q\n", x1, y1, x2, y2)
+}
+
+// Image draws img scaled into the w x h box whose bottom left corner is at
+// x, y. Transparent pixels are blended onto white.
+func (d *Document) Image(img image.Image, x, y, w, h float64) {
+	d.images = append(d.images, img)
+	fmt.Fprintf(d.page(), "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, x, y, len(d.images))
+}
+
+// WriteTo writes the document as a PDF file.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	pdf := &writer{buf: &bytes.Buffer{}}
+	pdf.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and the page tree, 3 and 4 the fonts,
+	// followed by the images and then a page and content stream per page.
+	firstImage := 5
+	firstPage := firstImage + len(d.images)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	pdf.object("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	pdf.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	pdf.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	xobjects := make([]string, len(d.images))
+	for i, img := range d.images {
+		data, err := rgb(img)
+		if err != nil {
+			return 0, err
+		}
+
+		b := img.Bounds()
+		pdf.stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+			b.Dx(), b.Dy()), data)
+		xobjects[i] = fmt.Sprintf("/Im%d %d 0 R", i+1, firstImage+i)
+	}
+
+	resources := fmt.Sprintf("<< /Font << /F1 3 0 R /F2 4 0 R >> /XObject << %s >> >>", strings.Join(xobjects, " "))
+	for i, content := range d.pages {
+		pdf.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R >>",
+			PageWidth, PageHeight, resources, firstPage+2*i+1))
+
+		data, err := deflate(content.Bytes())
+		if err != nil {
+			return 0, err
+		}
+		pdf.stream("/Filter /FlateDecode", data)
+	}
+
+	xref := pdf.buf.Len()
+	fmt.Fprintf(pdf.buf, "xref\n0 %d\n0000000000 65535 f \n", len(pdf.offsets)+1)
+	for _, offset := range pdf.offsets {
+		fmt.Fprintf(pdf.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(pdf.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pdf.offsets)+1, xref)
+
+	return pdf.buf.WriteTo(w)
+}
+
+// writer numbers the objects of a PDF file in the order they are written and
+// remembers their offsets for the cross-reference table.
+type writer struct {
+	buf     *bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) object(body string) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(w.buf, "%d 0 obj\n%s\nendobj\n", len(w.offsets), body)
+}
+
+func (w *writer) stream(dict string, data []byte) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(w.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", len(w.offsets), dict, len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func deflate(data []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	zw := zlib.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// rgb returns the compressed 8 bit RGB samples of img, blending transparent
+// pixels onto white.
+func rgb(img image.Image) ([]byte, error) {
+	b := img.Bounds()
+	data := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			data = append(data, byte((r+white)>>8), byte((g+white)>>8), byte((bl+white)>>8))
+		}
+	}
+
+	return deflate(data)
+}
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding has a
+// code for.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// escape encodes s for a PDF string literal in WinAnsiEncoding; characters it
+// can not represent become question marks.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			b.WriteByte(winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+
+	return b.String()
+}
+
+// TextWidth estimates the width of s in points. Helvetica glyphs average a
+// little over half the font size; bold ones are slightly wider.
+func TextWidth(s string, size float64, bold bool) float64 {
+	factor := 0.52
+	if bold {
+		factor = 0.56
+	}
+
+	return float64(len([]rune(s))) * size * factor
+}
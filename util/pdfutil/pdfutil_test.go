@@ -0,0 +1,42 @@
+package pdfutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/util/pdfutil"
+)
+
+func TestDocumentStructure(t *testing.T) {
+	doc := pdfutil.New()
+	doc.Text(50, 800, 12, true, "Invoice (copy)")
+	doc.Image(image.NewRGBA(image.Rect(0, 0, 4, 4)), 50, 700, 32, 32)
+	doc.AddPage()
+	doc.Line(50, 50, 500, 50)
+
+	buf := &bytes.Buffer{}
+	_, err := doc.WriteTo(buf)
+	require.NoError(t, err)
+
+	out := buf.Bytes()
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+	assert.Contains(t, string(out), "/Subtype /Image /Width 4 /Height 4")
+
+	// Every entry of the cross-reference table must point at its object.
+	xref := regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`).FindAllSubmatch(out, -1)
+	require.Len(t, xref, 9)
+	for k, entry := range xref {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj", k+1))), "object %d", k+1)
+	}
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	bicRegex      = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	sortCodeRegex = regexp.MustCompile(`^[0-9]{6}$`)
+	ibanRegex     = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]+$`)
+)
+
+// ibanLengths holds the IBAN length of each country using IBANs.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22,
+	"DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18, "FO": 18, "FR": 27,
+	"GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28,
+	"IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25, "MC": 27,
+	"MD": 24, "ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15,
+	"PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "SA": 24,
+	"SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+// isIBAN checks the country specific length and the ISO 7064 mod 97-10
+// checksum of an IBAN without spaces.
+func isIBAN(fl validator.FieldLevel) bool {
+	iban := fl.Field().String()
+	if !ibanRegex.MatchString(iban) || ibanLengths[iban[:2]] != len(iban) {
+		return false
+	}
+
+	// Move the country code and check digits to the end and read letters as
+	// two digit numbers, A being 10, computing the remainder digit by digit.
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for _, c := range rearranged {
+		if c >= 'A' && c <= 'Z' {
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		}
+	}
+
+	return remainder == 1
+}
+
+func isBIC(fl validator.FieldLevel) bool {
+	return bicRegex.MatchString(fl.Field().String())
+}
+
+func isSortCode(fl validator.FieldLevel) bool {
+	return sortCodeRegex.MatchString(fl.Field().String())
+}
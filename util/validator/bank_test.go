@@ -0,0 +1,61 @@
+package validator_test
+
+import (
+	"testing"
+
+	"merchant/util/validator"
+)
+
+type bankTestCase struct {
+	name  string
+	input interface{}
+	valid bool
+}
+
+type ibanInput struct {
+	IBAN string `json:"iban" form:"iban"`
+}
+
+type bicInput struct {
+	BIC string `json:"bic" form:"bic"`
+}
+
+type sortCodeInput struct {
+	SortCode string `json:"sortCode" form:"sortcode"`
+}
+
+var bankTests = []*bankTestCase{
+	{name: "valid GB IBAN", input: ibanInput{"GB82WEST12345698765432"}, valid: true},
+	{name: "valid DE IBAN", input: ibanInput{"DE89370400440532013000"}, valid: true},
+	{name: "valid NO IBAN", input: ibanInput{"NO9386011117947"}, valid: true},
+	{name: "IBAN with wrong checksum", input: ibanInput{"GB82WEST12345698765433"}, valid: false},
+	{name: "IBAN with wrong length", input: ibanInput{"GB82WEST1234569876543"}, valid: false},
+	{name: "IBAN of unknown country", input: ibanInput{"ZZ82WEST12345698765432"}, valid: false},
+	{name: "IBAN with spaces", input: ibanInput{"GB82 WEST 1234 5698 7654 32"}, valid: false},
+	{name: "valid 8 character BIC", input: bicInput{"DEUTDEFF"}, valid: true},
+	{name: "valid 11 character BIC", input: bicInput{"NWBKGB2L123"}, valid: true},
+	{name: "BIC with wrong length", input: bicInput{"DEUTDEF"}, valid: false},
+	{name: "lower case BIC", input: bicInput{"deutdeff"}, valid: false},
+	{name: "valid sort code", input: sortCodeInput{"401276"}, valid: true},
+	{name: "sort code with dashes", input: sortCodeInput{"40-12-76"}, valid: false},
+	{name: "short sort code", input: sortCodeInput{"40127"}, valid: false},
+}
+
+func TestBankValidators(t *testing.T) {
+	vr := validator.New()
+
+	for _, tc := range bankTests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := vr.Struct(tc.input)
+			if tc.valid && err != nil {
+				t.Fatalf(`Expected valid, Got:"%v"`, err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatal(`Expected invalid, Got: valid`)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package validator
+
+// locales holds the BCP 47 language tags the platform can format for.
+var locales = map[string]bool{
+	"ar": true, "ar-AE": true, "ar-EG": true, "ar-MA": true, "ar-SA": true,
+	"bg-BG": true, "bn-BD": true, "bn-IN": true, "ca-ES": true, "cs-CZ": true,
+	"da-DK": true, "de": true, "de-AT": true, "de-CH": true, "de-DE": true,
+	"el-GR": true, "en": true, "en-AU": true, "en-CA": true, "en-GB": true,
+	"en-HK": true, "en-IE": true, "en-IN": true, "en-MY": true, "en-NG": true,
+	"en-NZ": true, "en-PH": true, "en-SG": true, "en-US": true, "en-ZA": true,
+	"es": true, "es-AR": true, "es-CL": true, "es-CO": true, "es-ES": true,
+	"es-MX": true, "es-PE": true, "es-US": true, "et-EE": true, "fa-IR": true,
+	"fi-FI": true, "fil-PH": true, "fr": true, "fr-BE": true, "fr-CA": true,
+	"fr-CH": true, "fr-FR": true, "he-IL": true, "hi-IN": true, "hr-HR": true,
+	"hu-HU": true, "id-ID": true, "is-IS": true, "it": true, "it-CH": true,
+	"it-IT": true, "ja-JP": true, "kk-KZ": true, "km-KH": true, "ko-KR": true,
+	"lt-LT": true, "lv-LV": true, "ms-MY": true, "ms-SG": true, "my-MM": true,
+	"nb-NO": true, "ne-NP": true, "nl": true, "nl-BE": true, "nl-NL": true,
+	"pl-PL": true, "pt": true, "pt-BR": true, "pt-PT": true, "ro-RO": true,
+	"ru-RU": true, "si-LK": true, "sk-SK": true, "sl-SI": true, "sr-RS": true,
+	"sv-SE": true, "sw-KE": true, "ta-IN": true, "ta-SG": true, "th-TH": true,
+	"tr-TR": true, "uk-UA": true, "ur-PK": true, "vi-VN": true, "zh": true,
+	"zh-CN": true, "zh-HK": true, "zh-SG": true, "zh-TW": true,
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"reflect"
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	MaxMetadataKeys        = 20
+	MaxMetadataKeyLength   = 40
+	MaxMetadataValueLength = 500
+)
+
+var metadataKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// IsMetadataKey reports whether the key may name a metadata entry.
+func IsMetadataKey(key string) bool {
+	return len(key) <= MaxMetadataKeyLength && metadataKeyRegex.MatchString(key)
+}
+
+// isMetadata checks the number of entries of a metadata map, and the
+// length and characters of its keys and values.
+func isMetadata(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.Map || field.Type().Key().Kind() != reflect.String || field.Type().Elem().Kind() != reflect.String {
+		return false
+	}
+	if field.Len() > MaxMetadataKeys {
+		return false
+	}
+
+	iter := field.MapRange()
+	for iter.Next() {
+		if !IsMetadataKey(iter.Key().String()) || len(iter.Value().String()) > MaxMetadataValueLength {
+			return false
+		}
+	}
+
+	return true
+}
@@ -3,11 +3,14 @@ package validator
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
 
+var couponCodeRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 type ErrResponse struct {
 	Errors []string `json:"errors"`
 }
@@ -25,9 +28,30 @@ func New() *validator.Validate {
 		return name
 	})
 
+	_ = validate.RegisterValidation("iso4217", isISO4217)
+	_ = validate.RegisterValidation("locale", isLocale)
+	_ = validate.RegisterValidation("iban", isIBAN)
+	_ = validate.RegisterValidation("bic", isBIC)
+	_ = validate.RegisterValidation("sortcode", isSortCode)
+	_ = validate.RegisterValidation("couponcode", isCouponCode)
+	_ = validate.RegisterValidation("metadata", isMetadata)
+
 	return validate
 }
 
+func isISO4217(fl validator.FieldLevel) bool {
+	_, ok := currencyMinorUnits[fl.Field().String()]
+	return ok
+}
+
+func isLocale(fl validator.FieldLevel) bool {
+	return locales[fl.Field().String()]
+}
+
+func isCouponCode(fl validator.FieldLevel) bool {
+	return couponCodeRegex.MatchString(fl.Field().String())
+}
+
 func ToErrResponse(err error) *ErrResponse {
 	if fieldErrors, ok := err.(validator.ValidationErrors); ok {
 		resp := ErrResponse{
@@ -42,6 +66,8 @@ func ToErrResponse(err error) *ErrResponse {
 				resp.Errors[i] = fmt.Sprintf("%s is a required field", err.Field())
 			case "required_with":
 				resp.Errors[i] = fmt.Sprintf("%s is a required field", err.Field())
+			case "required_without":
+				resp.Errors[i] = fmt.Sprintf("%s is required when %s is not given", err.Field(), err.Param())
 			case "eqfield":
 				resp.Errors[i] = fmt.Sprintf("%s must be equal to %s", err.Field(), err.Param())
 			case "gt":
@@ -58,6 +84,34 @@ func ToErrResponse(err error) *ErrResponse {
 				resp.Errors[i] = fmt.Sprintf("%s must be a valid uuid", err.Field())
 			case "oneof":
 				resp.Errors[i] = fmt.Sprintf("%s must be one of %s", err.Field(), err.Param())
+			case "len":
+				resp.Errors[i] = fmt.Sprintf("%s must be exactly %s in length", err.Field(), err.Param())
+			case "latitude":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid latitude", err.Field())
+			case "longitude":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid longitude", err.Field())
+			case "timezone":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid IANA timezone", err.Field())
+			case "e164":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid E.164 phone number", err.Field())
+			case "iso3166_1_alpha2":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid ISO 3166-1 alpha-2 country code", err.Field())
+			case "iso4217":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid ISO 4217 currency code", err.Field())
+			case "locale":
+				resp.Errors[i] = fmt.Sprintf("%s must be a supported locale", err.Field())
+			case "iban":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid IBAN", err.Field())
+			case "bic":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid BIC", err.Field())
+			case "sortcode":
+				resp.Errors[i] = fmt.Sprintf("%s must be a valid sort code", err.Field())
+			case "couponcode":
+				resp.Errors[i] = fmt.Sprintf("%s must contain only letters, digits, dashes and underscores", err.Field())
+			case "metadata":
+				resp.Errors[i] = fmt.Sprintf("%s must have at most %d keys of letters, digits, dots, dashes and underscores up to %d characters, with values up to %d characters", err.Field(), MaxMetadataKeys, MaxMetadataKeyLength, MaxMetadataValueLength)
+			case "numeric":
+				resp.Errors[i] = fmt.Sprintf("%s must contain only digits", err.Field())
 			case "datetime":
 				if err.Param() == "2006-01-02" {
 					resp.Errors[i] = fmt.Sprintf("%s must be a valid date", err.Field())
@@ -80,6 +80,42 @@ var tests = []*testCase{
 		},
 		expected: "input must be one of saturday sunday",
 	},
+	{
+		name: `timezone`,
+		input: struct {
+			Timezone string `json:"timezone" form:"timezone"`
+		}{
+			Timezone: "Asia/Atlantis",
+		},
+		expected: "timezone must be a valid IANA timezone",
+	},
+	{
+		name: `latitude`,
+		input: struct {
+			Latitude float64 `json:"latitude" form:"latitude"`
+		}{
+			Latitude: 91,
+		},
+		expected: "latitude must be a valid latitude",
+	},
+	{
+		name: `iso4217`,
+		input: struct {
+			Currency string `json:"currency" form:"iso4217"`
+		}{
+			Currency: "usd",
+		},
+		expected: "currency must be a valid ISO 4217 currency code",
+	},
+	{
+		name: `locale`,
+		input: struct {
+			Locale string `json:"locale" form:"locale"`
+		}{
+			Locale: "en_US",
+		},
+		expected: "locale must be a supported locale",
+	},
 	{name: `date`,
 		input: struct {
 			Date string `json:"date" form:"datetime=2006-01-02"`
@@ -88,6 +124,24 @@ var tests = []*testCase{
 		},
 		expected: "date must be a valid date",
 	},
+	{
+		name: `couponcode`,
+		input: struct {
+			Code string `json:"code" form:"couponcode"`
+		}{
+			Code: "SUMMER 10%",
+		},
+		expected: "code must contain only letters, digits, dashes and underscores",
+	},
+	{
+		name: `metadata`,
+		input: struct {
+			Metadata map[string]string `json:"metadata" form:"omitempty,metadata"`
+		}{
+			Metadata: map[string]string{"order id": "1"},
+		},
+		expected: "metadata must have at most 20 keys of letters, digits, dots, dashes and underscores up to 40 characters, with values up to 500 characters",
+	},
 	{
 		name: `month`,
 		input: struct {
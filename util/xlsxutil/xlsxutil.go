@@ -0,0 +1,233 @@
+package xlsxutil
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var ErrNoWorksheet = errors.New("workbook has no worksheet")
+
+// The limits of a worksheet.
+const (
+	maxRows    = 1 << 20
+	maxColumns = 1 << 14
+)
+
+// ReadRows returns the cell values of the first worksheet of an XLSX
+// workbook as text. Row n of the sheet is element n-1, so rows keep their
+// numbers; rows and cells left empty in the sheet are empty. Formulas are
+// read as their cached values and numbers as stored, without formatting.
+func ReadRows(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetName, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetName]
+	if !ok {
+		return nil, ErrNoWorksheet
+	}
+
+	return readSheet(f, shared)
+}
+
+type workbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// firstSheet returns the name of the part holding the first sheet of the
+// workbook.
+func firstSheet(files map[string]*zip.File) (string, error) {
+	wb := &workbook{}
+	if err := decodePart(files, "xl/workbook.xml", wb); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", ErrNoWorksheet
+	}
+
+	rels := &relationships{}
+	if err := decodePart(files, "xl/_rels/workbook.xml.rels", rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+
+	return "", ErrNoWorksheet
+}
+
+type richText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	sst := &struct {
+		Items []richText `xml:"si"`
+	}{}
+	if err := decodeFile(f, sst); err != nil {
+		return nil, err
+	}
+
+	shared := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		shared[i] = item.String()
+	}
+
+	return shared, nil
+}
+
+type worksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string    `xml:"r,attr"`
+			Type   string    `xml:"t,attr"`
+			Value  string    `xml:"v"`
+			Inline *richText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readSheet(f *zip.File, shared []string) ([][]string, error) {
+	ws := &worksheet{}
+	if err := decodeFile(f, ws); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range ws.Rows {
+		number := row.Number
+		if number == 0 {
+			number = len(rows) + 1
+		}
+		if number <= len(rows) || number > maxRows {
+			return nil, fmt.Errorf("row %d is out of order", number)
+		}
+		for len(rows) < number {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for _, c := range row.Cells {
+			col := len(cells)
+			if c.Ref != "" {
+				var err error
+				if col, err = columnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col < len(cells) {
+				return nil, fmt.Errorf("cell %s is out of order", c.Ref)
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+
+			value := c.Value
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, fmt.Errorf("cell %s refers to an unknown string", c.Ref)
+				}
+				value = shared[n]
+			case "inlineStr":
+				if c.Inline != nil {
+					value = c.Inline.String()
+				}
+			}
+			cells = append(cells, value)
+		}
+		rows[number-1] = cells
+	}
+
+	return rows, nil
+}
+
+// columnIndex returns the zero based column of a cell reference such as
+// "C12".
+func columnIndex(ref string) (int, error) {
+	col, i := 0, 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A') + 1
+	}
+	if i == 0 || i > 3 || col > maxColumns {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+
+	return col - 1, nil
+}
+
+func decodePart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("workbook has no %s", name)
+	}
+
+	return decodeFile(f, v)
+}
+
+func decodeFile(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return xml.NewDecoder(rc).Decode(v)
+}
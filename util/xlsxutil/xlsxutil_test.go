@@ -0,0 +1,72 @@
+package xlsxutil_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"merchant/util/xlsxutil"
+)
+
+func newWorkbook(t *testing.T, sheet string) *bytes.Reader {
+	parts := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Staff" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId2" Type="sharedStrings" Target="sharedStrings.xml"/>
+<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>email</t></si><si><r><t>Ada</t></r><r><t> Lovelace</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": sheet,
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadRows(t *testing.T) {
+	sheet := `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="inlineStr"><is><t>isOwner</t></is></c></row>
+<row r="3"><c r="A3" t="s"><v>1</v></c><c r="B3"><v>42</v></c><c r="C3" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`
+
+	r := newWorkbook(t, sheet)
+	rows, err := xlsxutil.ReadRows(r, r.Size())
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"email", "", "isOwner"},
+		nil,
+		{"Ada Lovelace", "42", "1"},
+	}, rows)
+}
+
+func TestReadRowsUnknownString(t *testing.T) {
+	sheet := `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>7</v></c></row></sheetData></worksheet>`
+
+	r := newWorkbook(t, sheet)
+	_, err := xlsxutil.ReadRows(r, r.Size())
+	assert.Error(t, err)
+}
+
+func TestReadRowsNotAWorkbook(t *testing.T) {
+	r := bytes.NewReader([]byte("email,givenName\n"))
+	_, err := xlsxutil.ReadRows(r, r.Size())
+	assert.Error(t, err)
+}
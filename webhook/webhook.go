@@ -0,0 +1,290 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"merchant/model"
+	"merchant/repository"
+	"merchant/util/netutil"
+)
+
+const (
+	// SignatureHeader carries the time a delivery attempt was signed at and
+	// the signature, as t=<unix seconds>,v1=<hex HMAC-SHA256>. The signed
+	// message is the timestamp, a dot and the request body.
+	SignatureHeader = "X-Merchant-Signature"
+	EventHeader     = "X-Merchant-Event"
+	DeliveryHeader  = "X-Merchant-Delivery"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrSignatureExpired = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// Sign returns the signature header value of body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac(secret, timestamp, body)))
+}
+
+// Verify checks the signature header of a delivery received at now, as a
+// subscriber would. Signatures made more than tolerance away from now are
+// rejected so captured deliveries can not be replayed later.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		switch {
+		case strings.HasPrefix(part, "t="):
+			timestamp = strings.TrimPrefix(part, "t=")
+		case strings.HasPrefix(part, "v1="):
+			signature = strings.TrimPrefix(part, "v1=")
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	decoded, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, mac(secret, timestamp, body)) {
+		return ErrInvalidSignature
+	}
+
+	age := now.Sub(time.Unix(unix, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(timestamp))
+	m.Write([]byte("."))
+	m.Write(body)
+	return m.Sum(nil)
+}
+
+// Enqueue queues a delivery of the event for every active subscription of
+// its merchant to the event type, returning the number of deliveries queued.
+func Enqueue(db repository.Repository, event *model.WebhookEvent) (int, error) {
+	subscriptions, err := db.ListWebhookSubscriptionsByMerchantId(event.MerchantID)
+	if err != nil {
+		return 0, err
+	}
+
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	var deliveries model.WebhookDeliveries
+	for _, s := range subscriptions {
+		if s.Subscribes(event.Type) {
+			deliveries = append(deliveries, s.NewDelivery(event, string(payload), event.CreatedAt))
+		}
+	}
+
+	if err := db.CreateWebhookDeliveries(deliveries); err != nil {
+		return 0, err
+	}
+
+	return len(deliveries), nil
+}
+
+// Publisher queues webhook deliveries of the domain events relayed from the
+// outbox. The webhook event takes the ID of the domain event, so subscribers
+// can tell the deliveries of a republished event apart from new events.
+type Publisher struct {
+	db repository.Repository
+}
+
+func NewPublisher(db repository.Repository) *Publisher {
+	return &Publisher{db: db}
+}
+
+func (p *Publisher) Publish(_ context.Context, e *model.OutboxEvent) error {
+	_, err := Enqueue(p.db, &model.WebhookEvent{
+		ID:         e.ID,
+		Type:       e.Type,
+		MerchantID: e.MerchantID,
+		CreatedAt:  e.OccurredAt,
+		Data:       json.RawMessage(e.Payload),
+	})
+	return err
+}
+
+// NewClient returns a client to post deliveries with. It only connects to
+// public internet addresses, checked after host names are resolved and for
+// every redirect, so subscriptions can not reach internal services. Proxies
+// configured in the environment are not used.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   netutil.PublicDialControl,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// Dispatcher works through the delivery queue, posting due deliveries to
+// their subscriptions and recording the outcome. Several dispatchers may
+// share a queue; every delivery is claimed before it is attempted.
+type Dispatcher struct {
+	db     repository.Repository
+	client *http.Client
+	logger *zap.Logger
+
+	// BatchSize is the number of due deliveries taken from the queue at a
+	// time.
+	BatchSize int
+	// Lease is how long a claimed delivery is left alone by other
+	// dispatchers. It must exceed the client timeout.
+	Lease time.Duration
+}
+
+func NewDispatcher(db repository.Repository, client *http.Client, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		db:        db,
+		client:    client,
+		logger:    logger,
+		BatchSize: 50,
+		Lease:     5 * time.Minute,
+	}
+}
+
+// Run delivers due deliveries every interval until the context is done.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := d.DeliverDue(ctx)
+			if err != nil {
+				d.logger.Warn(err.Error())
+			}
+			if err != nil || n < d.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue attempts one batch of due deliveries and returns the number of
+// deliveries taken from the queue.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := d.db.ListDueWebhookDeliveries(time.Now(), d.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		if err := d.deliver(ctx, delivery); err != nil && err != repository.ErrConflict {
+			d.logger.Warn(err.Error())
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *model.WebhookDelivery) error {
+	claimed := delivery.Attempts
+	if err := d.db.ClaimWebhookDelivery(delivery.ID, claimed, time.Now().Add(d.Lease)); err != nil {
+		return err
+	}
+
+	subscription, err := d.db.ReadWebhookSubscriptionById(delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	status, errMessage := 0, ""
+	if subscription.Active {
+		status, err = d.post(ctx, subscription, delivery)
+		if err != nil {
+			errMessage = err.Error()
+		} else if status < 200 || status >= 300 {
+			errMessage = fmt.Sprintf("receiver responded %d", status)
+		}
+	} else {
+		errMessage = "subscription is inactive"
+	}
+
+	delivery.RecordAttempt(time.Now(), status, errMessage)
+
+	return d.db.UpdateWebhookDeliveryById(delivery.ID, claimed, delivery)
+}
+
+// post sends the delivery and returns the response status.
+func (d *Dispatcher) post(ctx context.Context, s *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "merchant-webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(s.Secret.String(), time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a little of the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
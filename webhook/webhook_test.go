@@ -0,0 +1,209 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"merchant/mock/mock_repository"
+	"merchant/model"
+	"merchant/repository"
+	"merchant/util/netutil"
+	"merchant/webhook"
+)
+
+const secret = "whsec_0123456789abcdef"
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	body := []byte(`{"type": "merchant.updated"}`)
+	header := webhook.Sign(secret, now, body)
+
+	assert.NoError(t, webhook.Verify(secret, header, body, 5*time.Minute, now.Add(time.Minute)))
+	assert.Equal(t, webhook.ErrInvalidSignature, webhook.Verify("another secret", header, body, 5*time.Minute, now))
+	assert.Equal(t, webhook.ErrInvalidSignature, webhook.Verify(secret, header, []byte(`{"type": "merchant.deleted"}`), 5*time.Minute, now))
+	assert.Equal(t, webhook.ErrInvalidSignature, webhook.Verify(secret, "v1=abc", body, 5*time.Minute, now))
+	assert.Equal(t, webhook.ErrSignatureExpired, webhook.Verify(secret, header, body, 5*time.Minute, now.Add(10*time.Minute)))
+}
+
+func TestEnqueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListWebhookSubscriptionsByMerchantId("m1").Return(model.WebhookSubscriptions{
+		{Model: model.Model{ID: "s1"}, MerchantID: "m1", EventTypes: "team_member.created", Active: true},
+		{Model: model.Model{ID: "s2"}, MerchantID: "m1", EventTypes: "merchant.updated", Active: true},
+		{Model: model.Model{ID: "s3"}, MerchantID: "m1", EventTypes: "team_member.created", Active: false},
+	}, nil)
+	db.EXPECT().CreateWebhookDeliveries(gomock.Any()).DoAndReturn(func(ds model.WebhookDeliveries) error {
+		require.Len(t, ds, 1)
+		assert.Equal(t, "s1", ds[0].SubscriptionID)
+		assert.Equal(t, model.WebhookDeliveryStatusPending, ds[0].Status)
+		assert.Contains(t, ds[0].Payload, `"type":"team_member.created"`)
+		return nil
+	})
+
+	n, err := webhook.Enqueue(db, &model.WebhookEvent{Type: model.EventTeamMemberCreated, MerchantID: "m1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+// receiver is a subscriber endpoint answering with the given status and
+// recording whether the deliveries it got were signed correctly.
+func receiver(t *testing.T, status int, verified chan<- error) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, model.EventMerchantUpdated, r.Header.Get(webhook.EventHeader))
+		assert.Equal(t, "d1", r.Header.Get(webhook.DeliveryHeader))
+		verified <- webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), body, time.Minute, time.Now())
+
+		w.WriteHeader(status)
+	}))
+}
+
+func dispatch(t *testing.T, url string, attempts int) *model.WebhookDelivery {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	delivery := &model.WebhookDelivery{
+		Model:          model.Model{ID: "d1"},
+		SubscriptionID: "s1",
+		EventType:      model.EventMerchantUpdated,
+		Payload:        `{"type":"merchant.updated"}`,
+		Status:         model.WebhookDeliveryStatusPending,
+		Attempts:       attempts,
+	}
+	subscription := &model.WebhookSubscription{
+		Model:      model.Model{ID: "s1"},
+		URL:        url,
+		EventTypes: model.EventMerchantUpdated,
+		Secret:     secret,
+		Active:     true,
+	}
+
+	var recorded *model.WebhookDelivery
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListDueWebhookDeliveries(gomock.Any(), 50).Return(model.WebhookDeliveries{delivery}, nil)
+	db.EXPECT().ClaimWebhookDelivery("d1", attempts, gomock.Any()).Return(nil)
+	db.EXPECT().ReadWebhookSubscriptionById("s1").Return(subscription, nil)
+	db.EXPECT().UpdateWebhookDeliveryById("d1", attempts, gomock.Any()).DoAndReturn(func(_ string, _ int, d *model.WebhookDelivery) error {
+		recorded = d
+		return nil
+	})
+
+	d := webhook.NewDispatcher(db, &http.Client{Timeout: time.Second}, zap.NewNop())
+	n, err := d.DeliverDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.NotNil(t, recorded)
+
+	return recorded
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	verified := make(chan error, 1)
+	srv := receiver(t, http.StatusNoContent, verified)
+	defer srv.Close()
+
+	d := dispatch(t, srv.URL, 0)
+
+	assert.NoError(t, <-verified)
+	assert.Equal(t, model.WebhookDeliveryStatusSucceeded, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, http.StatusNoContent, d.ResponseStatus)
+	assert.Nil(t, d.NextAttemptAt)
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	verified := make(chan error, 1)
+	srv := receiver(t, http.StatusServiceUnavailable, verified)
+	defer srv.Close()
+
+	d := dispatch(t, srv.URL, 3)
+
+	assert.NoError(t, <-verified)
+	assert.Equal(t, model.WebhookDeliveryStatusPending, d.Status)
+	assert.Equal(t, 4, d.Attempts)
+	assert.Equal(t, "receiver responded 503", d.LastError)
+	require.NotNil(t, d.NextAttemptAt)
+	assert.Equal(t, 4*time.Minute, d.NextAttemptAt.Sub(*d.LastAttemptAt))
+}
+
+func TestDispatcherDeadLetters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	d := dispatch(t, url, model.WebhookMaxAttempts-1)
+
+	assert.Equal(t, model.WebhookDeliveryStatusDead, d.Status)
+	assert.Equal(t, 0, d.ResponseStatus)
+	assert.NotEmpty(t, d.LastError)
+	assert.Nil(t, d.NextAttemptAt)
+}
+
+func TestClientRefusesInternalAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("internal receiver was reached")
+	}))
+	defer srv.Close()
+
+	_, err := webhook.NewClient(time.Second).Post(srv.URL, "application/json", nil)
+
+	assert.True(t, errors.Is(err, netutil.ErrNonPublicAddress), err)
+}
+
+func TestDispatcherSkipsClaimedDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	delivery := &model.WebhookDelivery{Model: model.Model{ID: "d1"}, SubscriptionID: "s1", Status: model.WebhookDeliveryStatusPending}
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListDueWebhookDeliveries(gomock.Any(), 50).Return(model.WebhookDeliveries{delivery}, nil)
+	db.EXPECT().ClaimWebhookDelivery("d1", 0, gomock.Any()).Return(repository.ErrConflict)
+
+	d := webhook.NewDispatcher(db, http.DefaultClient, zap.NewNop())
+	n, err := d.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPublisherQueuesDeliveriesOfDomainEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db := mock_repository.NewMockRepository(ctrl)
+	db.EXPECT().ListWebhookSubscriptionsByMerchantId("m1").Return(model.WebhookSubscriptions{
+		{Model: model.Model{ID: "s1"}, MerchantID: "m1", EventTypes: model.EventTeamMemberCreated, Active: true},
+	}, nil)
+	db.EXPECT().CreateWebhookDeliveries(gomock.Any()).DoAndReturn(func(ds model.WebhookDeliveries) error {
+		require.Len(t, ds, 1)
+		assert.Equal(t, "e1", ds[0].EventID)
+		assert.Contains(t, ds[0].Payload, `"data":{"email":"ada@example.com"}`)
+		return nil
+	})
+
+	err := webhook.NewPublisher(db).Publish(context.Background(), &model.OutboxEvent{
+		ID:         "e1",
+		Type:       model.EventTeamMemberCreated,
+		MerchantID: "m1",
+		Payload:    `{"email":"ada@example.com"}`,
+	})
+	require.NoError(t, err)
+}